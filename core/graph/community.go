@@ -0,0 +1,289 @@
+package graph
+
+import (
+	"math"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+)
+
+// DefaultCommunityMinSize is the smallest community kept by DetectCommunities
+const DefaultCommunityMinSize = 2
+
+// maxLouvainPasses limits the node moving passes per Louvain level
+const maxLouvainPasses = 100
+
+// CommunityOptions configures community detection
+type CommunityOptions struct {
+	// Edges between entities used for detection, entity mentions (the co-occurrence edges) if empty
+	EdgeTypes []model.EdgeType
+	// Resolution of the modularity, values above 1 find more and smaller communities
+	Resolution float64
+	// Communities with fewer members are dropped
+	MinSize int
+}
+
+// DefaultCommunityOptions returns a sensible default configuration
+func DefaultCommunityOptions() CommunityOptions {
+	return CommunityOptions{
+		EdgeTypes:  []model.EdgeType{model.EdgeTypeEntityMention},
+		Resolution: 1.0,
+		MinSize:    DefaultCommunityMinSize,
+	}
+}
+
+// Community is a group of densely connected entities found by DetectCommunities
+type Community struct {
+	EntityIDs []uuid.UUID   // Members, the ones with the strongest connections inside the community first
+	Edges     []*model.Edge // Edges between the members
+	Weight    float64       // Total weight of the edges between the members
+}
+
+// DetectCommunities groups the entities connected by the edges into communities with the Louvain method.
+// Only edges between two entities with a positive weight and one of the option's edge types are used,
+// the direction of the edges is ignored. The result doesn't depend on the order of the edges.
+// Returns the communities with at least opts.MinSize members, the ones with the highest weight first.
+func DetectCommunities(edges []*model.Edge, opts CommunityOptions) []*Community {
+	edgeTypes := opts.EdgeTypes
+	if len(edgeTypes) == 0 {
+		edgeTypes = []model.EdgeType{model.EdgeTypeEntityMention}
+	}
+	resolution := opts.Resolution
+	if resolution <= 0 {
+		resolution = 1.0
+	}
+	minSize := opts.MinSize
+	if minSize <= 0 {
+		minSize = DefaultCommunityMinSize
+	}
+
+	// Keep the weighted edges between two different entities
+	var entityEdges []*model.Edge
+	for _, edge := range edges {
+		if edge.SourceEntityID == nil || edge.TargetEntityID == nil || *edge.SourceEntityID == *edge.TargetEntityID {
+			continue
+		}
+		if !(edge.Weight > 0) || math.IsInf(edge.Weight, 0) || !containsType(edgeTypes, edge.EdgeType) {
+			continue
+		}
+		entityEdges = append(entityEdges, edge)
+	}
+
+	// Index the entities in a stable order
+	var ids []uuid.UUID
+	index := make(map[uuid.UUID]int)
+	for _, edge := range entityEdges {
+		for _, id := range []uuid.UUID{*edge.SourceEntityID, *edge.TargetEntityID} {
+			if _, ok := index[id]; !ok {
+				index[id] = 0
+				ids = append(ids, id)
+			}
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].String() < ids[j].String()
+	})
+	for i, id := range ids {
+		index[id] = i
+	}
+
+	g := newLouvainGraph(len(ids))
+	for _, edge := range entityEdges {
+		g.addEdge(index[*edge.SourceEntityID], index[*edge.TargetEntityID], edge.Weight)
+	}
+
+	membership := louvain(g, resolution)
+
+	// Group the entities and their edges by community
+	communities := make(map[int]*Community)
+	degrees := make(map[uuid.UUID]float64)
+	for i, id := range ids {
+		community, ok := communities[membership[i]]
+		if !ok {
+			community = &Community{}
+			communities[membership[i]] = community
+		}
+		community.EntityIDs = append(community.EntityIDs, id)
+	}
+	for _, edge := range entityEdges {
+		source := membership[index[*edge.SourceEntityID]]
+		if source != membership[index[*edge.TargetEntityID]] {
+			continue
+		}
+		community := communities[source]
+		community.Edges = append(community.Edges, edge)
+		community.Weight += edge.Weight
+		degrees[*edge.SourceEntityID] += edge.Weight
+		degrees[*edge.TargetEntityID] += edge.Weight
+	}
+
+	var result []*Community
+	for _, community := range communities {
+		if len(community.EntityIDs) < minSize {
+			continue
+		}
+		sort.SliceStable(community.EntityIDs, func(i, j int) bool {
+			return degrees[community.EntityIDs[i]] > degrees[community.EntityIDs[j]]
+		})
+		result = append(result, community)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Weight != result[j].Weight {
+			return result[i].Weight > result[j].Weight
+		}
+		if len(result[i].EntityIDs) != len(result[j].EntityIDs) {
+			return len(result[i].EntityIDs) > len(result[j].EntityIDs)
+		}
+		return result[i].EntityIDs[0].String() < result[j].EntityIDs[0].String()
+	})
+
+	return result
+}
+
+// containsType reports whether the edge type is in the list
+func containsType(edgeTypes []model.EdgeType, edgeType model.EdgeType) bool {
+	for _, t := range edgeTypes {
+		if t == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+// louvainGraph is an undirected weighted graph, the nodes of higher levels are communities of the level below
+type louvainGraph struct {
+	neighbors []map[int]float64 // Weight to every other node
+	loops     []float64         // Weight of the edges inside a node
+	degrees   []float64         // Weighted degree, loops count twice
+	total     float64           // Sum of the degrees, twice the total edge weight
+}
+
+// newLouvainGraph creates a graph of n nodes without edges
+func newLouvainGraph(n int) *louvainGraph {
+	g := &louvainGraph{
+		neighbors: make([]map[int]float64, n),
+		loops:     make([]float64, n),
+		degrees:   make([]float64, n),
+	}
+	for i := range g.neighbors {
+		g.neighbors[i] = make(map[int]float64)
+	}
+	return g
+}
+
+// addEdge adds an undirected edge, parallel edges add up
+func (g *louvainGraph) addEdge(a int, b int, weight float64) {
+	if a == b {
+		g.loops[a] += weight
+	} else {
+		g.neighbors[a][b] += weight
+		g.neighbors[b][a] += weight
+	}
+	g.degrees[a] += weight
+	g.degrees[b] += weight
+	g.total += 2 * weight
+}
+
+// louvain returns the community of every node of the graph.
+// Every level moves nodes between communities while the modularity increases and then
+// aggregates the communities to the nodes of the next level, until no node moves anymore.
+func louvain(g *louvainGraph, resolution float64) []int {
+	membership := make([]int, len(g.degrees))
+	for i := range membership {
+		membership[i] = i
+	}
+	if g.total == 0 {
+		return membership
+	}
+
+	for {
+		communities, moved := g.moveNodes(resolution)
+		if !moved {
+			return membership
+		}
+
+		// Renumber the communities in node order
+		numbers := make(map[int]int)
+		for _, community := range communities {
+			if _, ok := numbers[community]; !ok {
+				numbers[community] = len(numbers)
+			}
+		}
+		for i, node := range membership {
+			membership[i] = numbers[communities[node]]
+		}
+
+		// Aggregate the communities to the nodes of the next level
+		next := newLouvainGraph(len(numbers))
+		for node := range g.neighbors {
+			from := numbers[communities[node]]
+			if g.loops[node] > 0 {
+				next.addEdge(from, from, g.loops[node])
+			}
+			for neighbor, weight := range g.neighbors[node] {
+				if neighbor > node {
+					next.addEdge(from, numbers[communities[neighbor]], weight)
+				}
+			}
+		}
+		g = next
+	}
+}
+
+// moveNodes moves every node to the neighbouring community with the highest modularity gain
+// until no node moves. Returns the community of every node and whether any node moved.
+func (g *louvainGraph) moveNodes(resolution float64) ([]int, bool) {
+	communities := make([]int, len(g.degrees))
+	totals := make([]float64, len(g.degrees)) // Sum of the degrees of the community members
+	for i := range communities {
+		communities[i] = i
+		totals[i] = g.degrees[i]
+	}
+
+	movedAny := false
+	for pass := 0; pass < maxLouvainPasses; pass++ {
+		moved := false
+		for node := range communities {
+			current := communities[node]
+			degree := g.degrees[node]
+
+			// Weight from the node to every neighbouring community, in a stable order
+			weights := make(map[int]float64)
+			var candidates []int
+			for neighbor, weight := range g.neighbors[node] {
+				community := communities[neighbor]
+				if _, ok := weights[community]; !ok {
+					candidates = append(candidates, community)
+				}
+				weights[community] += weight
+			}
+			sort.Ints(candidates)
+
+			// Take the node out of its community and put it into the best one
+			totals[current] -= degree
+			best := current
+			bestGain := weights[current] - resolution*totals[current]*degree/g.total
+			for _, community := range candidates {
+				gain := weights[community] - resolution*totals[community]*degree/g.total
+				if gain > bestGain+1e-12 {
+					best = community
+					bestGain = gain
+				}
+			}
+			totals[best] += degree
+
+			if best != current {
+				communities[node] = best
+				moved = true
+				movedAny = true
+			}
+		}
+		if !moved {
+			break
+		}
+	}
+
+	return communities, movedAny
+}
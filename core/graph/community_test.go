@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// coOccurrence creates a bidirectional entity mention edge between two entities
+func coOccurrence(a uuid.UUID, b uuid.UUID, weight float64) *model.Edge {
+	return &model.Edge{
+		ID:             uuid.New(),
+		SourceEntityID: &a,
+		TargetEntityID: &b,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         weight,
+		Bidirectional:  true,
+	}
+}
+
+// newEntityIDs creates n entity IDs
+func newEntityIDs(n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		ids[i] = uuid.New()
+	}
+	return ids
+}
+
+func TestDetectCommunities(t *testing.T) {
+	// Two triangles connected by a weak edge: A-B-C and D-E-F, C-D
+	ids := newEntityIDs(6)
+	a, b, c, d, e, f := ids[0], ids[1], ids[2], ids[3], ids[4], ids[5]
+	edges := []*model.Edge{
+		coOccurrence(a, b, 1.0),
+		coOccurrence(b, c, 1.0),
+		coOccurrence(a, c, 1.0),
+		coOccurrence(d, e, 0.8),
+		coOccurrence(e, f, 0.8),
+		coOccurrence(d, f, 0.8),
+		coOccurrence(c, d, 0.1),
+	}
+
+	t.Run("Densely connected entities form communities", func(t *testing.T) {
+		communities := DetectCommunities(edges, DefaultCommunityOptions())
+
+		require.Len(t, communities, 2)
+		assert.ElementsMatch(t, []uuid.UUID{a, b, c}, communities[0].EntityIDs, "Expected the heavier community first")
+		assert.ElementsMatch(t, []uuid.UUID{d, e, f}, communities[1].EntityIDs)
+		assert.InDelta(t, 3.0, communities[0].Weight, 1e-9)
+		assert.InDelta(t, 2.4, communities[1].Weight, 1e-9)
+		assert.Len(t, communities[0].Edges, 3, "Expected only the edges between the members")
+	})
+
+	t.Run("Members with the strongest connections first", func(t *testing.T) {
+		communities := DetectCommunities(append([]*model.Edge{coOccurrence(a, b, 2.0)}, edges...), DefaultCommunityOptions())
+
+		require.NotEmpty(t, communities)
+		require.Len(t, communities[0].EntityIDs, 3)
+		assert.Contains(t, []uuid.UUID{a, b}, communities[0].EntityIDs[0])
+		assert.Equal(t, c, communities[0].EntityIDs[2], "Expected the bridge edge to not count")
+	})
+
+	t.Run("Result doesn't depend on the edge order", func(t *testing.T) {
+		expected := DetectCommunities(edges, DefaultCommunityOptions())
+
+		shuffled := append([]*model.Edge{}, edges...)
+		rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		communities := DetectCommunities(shuffled, DefaultCommunityOptions())
+
+		require.Len(t, communities, len(expected))
+		for i := range expected {
+			assert.Equal(t, expected[i].EntityIDs, communities[i].EntityIDs)
+		}
+	})
+
+	t.Run("Edges of other types and from chunks are ignored", func(t *testing.T) {
+		chunkID := uuid.New()
+		causal := coOccurrence(a, d, 5.0)
+		causal.EdgeType = model.EdgeTypeCausal
+		mention := &model.Edge{SourceChunkID: &chunkID, TargetEntityID: &a, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0}
+
+		communities := DetectCommunities(append([]*model.Edge{causal, mention}, edges...), DefaultCommunityOptions())
+
+		require.Len(t, communities, 2)
+		assert.ElementsMatch(t, []uuid.UUID{a, b, c}, communities[0].EntityIDs)
+	})
+
+	t.Run("Custom edge types", func(t *testing.T) {
+		causal := coOccurrence(a, d, 1.0)
+		causal.EdgeType = model.EdgeTypeCausal
+		opts := DefaultCommunityOptions()
+		opts.EdgeTypes = []model.EdgeType{model.EdgeTypeCausal}
+
+		communities := DetectCommunities(append([]*model.Edge{causal}, edges...), opts)
+
+		require.Len(t, communities, 1)
+		assert.ElementsMatch(t, []uuid.UUID{a, d}, communities[0].EntityIDs)
+	})
+
+	t.Run("Small communities are dropped", func(t *testing.T) {
+		g := uuid.New()
+		h := uuid.New()
+		opts := DefaultCommunityOptions()
+		opts.MinSize = 3
+
+		communities := DetectCommunities(append([]*model.Edge{coOccurrence(g, h, 1.0)}, edges...), opts)
+
+		require.Len(t, communities, 2, "Expected the pair to be dropped")
+		for _, community := range communities {
+			assert.NotContains(t, community.EntityIDs, g)
+		}
+	})
+
+	t.Run("Higher resolution finds smaller communities", func(t *testing.T) {
+		// A ring of four pairs, each pair strongly connected
+		ring := newEntityIDs(8)
+		var ringEdges []*model.Edge
+		for i := 0; i < len(ring); i += 2 {
+			ringEdges = append(ringEdges, coOccurrence(ring[i], ring[i+1], 1.0))
+			ringEdges = append(ringEdges, coOccurrence(ring[i+1], ring[(i+2)%len(ring)], 0.5))
+		}
+
+		coarse := DetectCommunities(ringEdges, CommunityOptions{Resolution: 0.1})
+		fine := DetectCommunities(ringEdges, CommunityOptions{Resolution: 2.0})
+
+		assert.Less(t, len(coarse), len(fine))
+		assert.Len(t, fine, 4)
+	})
+
+	t.Run("No edges", func(t *testing.T) {
+		communities := DetectCommunities(nil, DefaultCommunityOptions())
+
+		assert.Empty(t, communities)
+	})
+}
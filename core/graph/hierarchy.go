@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// Weights of the hierarchical edges, moving down the tree and forward in reading order
+// is preferred over moving up or backwards
+const (
+	HierarchyChildWeight    = 1.0 // Parent to child
+	HierarchyParentWeight   = 0.8 // Child to parent
+	HierarchyNextWeight     = 0.9 // Sibling to the next sibling
+	HierarchyPreviousWeight = 0.6 // Sibling to the previous sibling
+)
+
+// Relations stored in the "relation" metadata of hierarchical edges
+const (
+	RelationChild    = "child"
+	RelationParent   = "parent"
+	RelationNext     = "next"
+	RelationPrevious = "previous"
+)
+
+// HierarchyEdges derives hierarchical edges from the ltree paths of the chunks of one document.
+// Every chunk is connected to its nearest ancestor chunk (child and parent edge) and to
+// the next chunk with the same parent path (next and previous edge). Siblings are ordered by
+// ChunkIndex, chunks without index follow the indexed ones in their input order. The chunks must have their IDs set.
+func HierarchyEdges(chunks []*model.Chunk) []*model.Edge {
+	byPath := make(map[string]*model.Chunk, len(chunks))
+	for _, chunk := range chunks {
+		byPath[chunk.Path] = chunk
+	}
+
+	var edges []*model.Edge
+	siblings := make(map[string][]*model.Chunk)
+	var parentPaths []string
+
+	for _, chunk := range chunks {
+		parentPath := parentLabelPath(chunk.Path)
+		if _, ok := siblings[parentPath]; !ok {
+			parentPaths = append(parentPaths, parentPath)
+		}
+		siblings[parentPath] = append(siblings[parentPath], chunk)
+
+		// Walk up the path until an ancestor chunk exists
+		for ancestorPath := parentPath; ancestorPath != ""; ancestorPath = parentLabelPath(ancestorPath) {
+			if parent, ok := byPath[ancestorPath]; ok {
+				edges = append(edges,
+					hierarchyEdge(parent, chunk, HierarchyChildWeight, RelationChild),
+					hierarchyEdge(chunk, parent, HierarchyParentWeight, RelationParent),
+				)
+				break
+			}
+		}
+	}
+
+	for _, parentPath := range parentPaths {
+		group := siblings[parentPath]
+		sort.SliceStable(group, func(i, j int) bool {
+			// Chunks without index sort last, the stable sort keeps their input order
+			if group[i].ChunkIndex == nil || group[j].ChunkIndex == nil {
+				return group[j].ChunkIndex == nil && group[i].ChunkIndex != nil
+			}
+			return *group[i].ChunkIndex < *group[j].ChunkIndex
+		})
+
+		for i := 1; i < len(group); i++ {
+			edges = append(edges,
+				hierarchyEdge(group[i-1], group[i], HierarchyNextWeight, RelationNext),
+				hierarchyEdge(group[i], group[i-1], HierarchyPreviousWeight, RelationPrevious),
+			)
+		}
+	}
+
+	return edges
+}
+
+// hierarchyEdge creates a directed hierarchical edge between two chunks
+func hierarchyEdge(source, target *model.Chunk, weight float64, relation string) *model.Edge {
+	return &model.Edge{
+		SourceChunkID: &source.ID,
+		TargetChunkID: &target.ID,
+		EdgeType:      model.EdgeTypeHierarchical,
+		Weight:        weight,
+		Bidirectional: false,
+		Metadata:      model.Metadata{"relation": relation},
+	}
+}
+
+// parentLabelPath returns the ltree path without its last label, or "" for a single label
+func parentLabelPath(path string) string {
+	i := strings.LastIndex(path, ".")
+	if i < 0 {
+		return ""
+	}
+	return path[:i]
+}
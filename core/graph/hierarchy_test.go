@@ -0,0 +1,116 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newHierarchyChunk(path string, index int) *model.Chunk {
+	return &model.Chunk{ID: uuid.New(), Path: path, ChunkIndex: &index}
+}
+
+// findEdge returns the edge between two chunks with the given relation
+func findEdge(edges []*model.Edge, source, target *model.Chunk, relation string) *model.Edge {
+	for _, edge := range edges {
+		if *edge.SourceChunkID == source.ID && *edge.TargetChunkID == target.ID && edge.Metadata["relation"] == relation {
+			return edge
+		}
+	}
+	return nil
+}
+
+func TestHierarchyEdges(t *testing.T) {
+	t.Run("Parent child edges", func(t *testing.T) {
+		section := newHierarchyChunk("doc.sec0", 0)
+		content := newHierarchyChunk("doc.sec0.chunk0", 1)
+
+		edges := HierarchyEdges([]*model.Chunk{section, content})
+
+		require.Len(t, edges, 2)
+		child := findEdge(edges, section, content, RelationChild)
+		require.NotNil(t, child, "Expected edge from parent to child")
+		assert.Equal(t, model.EdgeTypeHierarchical, child.EdgeType)
+		assert.Equal(t, HierarchyChildWeight, child.Weight)
+		assert.False(t, child.Bidirectional)
+
+		parent := findEdge(edges, content, section, RelationParent)
+		require.NotNil(t, parent, "Expected edge from child to parent")
+		assert.Equal(t, HierarchyParentWeight, parent.Weight)
+	})
+
+	t.Run("Parent is the nearest existing ancestor", func(t *testing.T) {
+		section := newHierarchyChunk("doc.sec0", 0)
+		deep := newHierarchyChunk("doc.sec0.sub0.chunk0", 1)
+
+		edges := HierarchyEdges([]*model.Chunk{section, deep})
+
+		assert.NotNil(t, findEdge(edges, section, deep, RelationChild), "Expected missing sub0 to be skipped")
+	})
+
+	t.Run("Sibling edges follow chunk index", func(t *testing.T) {
+		chunk0 := newHierarchyChunk("doc.chunk0", 0)
+		chunk1 := newHierarchyChunk("doc.chunk1", 1)
+		chunk2 := newHierarchyChunk("doc.chunk2", 2)
+
+		edges := HierarchyEdges([]*model.Chunk{chunk2, chunk0, chunk1})
+
+		require.Len(t, edges, 4, "Expected next and previous edges between neighbours only")
+		next := findEdge(edges, chunk0, chunk1, RelationNext)
+		require.NotNil(t, next)
+		assert.Equal(t, HierarchyNextWeight, next.Weight)
+		assert.NotNil(t, findEdge(edges, chunk1, chunk2, RelationNext))
+
+		previous := findEdge(edges, chunk1, chunk0, RelationPrevious)
+		require.NotNil(t, previous)
+		assert.Equal(t, HierarchyPreviousWeight, previous.Weight)
+		assert.NotNil(t, findEdge(edges, chunk2, chunk1, RelationPrevious))
+		assert.Nil(t, findEdge(edges, chunk0, chunk2, RelationNext), "Expected no edge skipping a sibling")
+	})
+
+	t.Run("Siblings without index keep input order", func(t *testing.T) {
+		first := &model.Chunk{ID: uuid.New(), Path: "doc.b"}
+		second := &model.Chunk{ID: uuid.New(), Path: "doc.a"}
+
+		edges := HierarchyEdges([]*model.Chunk{first, second})
+
+		assert.NotNil(t, findEdge(edges, first, second, RelationNext))
+	})
+
+	t.Run("Siblings without index follow indexed siblings", func(t *testing.T) {
+		unindexed1 := &model.Chunk{ID: uuid.New(), Path: "doc.x"}
+		chunk1 := newHierarchyChunk("doc.chunk1", 1)
+		unindexed2 := &model.Chunk{ID: uuid.New(), Path: "doc.y"}
+		chunk0 := newHierarchyChunk("doc.chunk0", 0)
+
+		edges := HierarchyEdges([]*model.Chunk{unindexed1, chunk1, unindexed2, chunk0})
+
+		require.Len(t, edges, 6)
+		assert.NotNil(t, findEdge(edges, chunk0, chunk1, RelationNext))
+		assert.NotNil(t, findEdge(edges, chunk1, unindexed1, RelationNext))
+		assert.NotNil(t, findEdge(edges, unindexed1, unindexed2, RelationNext))
+	})
+
+	t.Run("Sections and their content", func(t *testing.T) {
+		intro := newHierarchyChunk("doc.chunk0", 0)
+		sec0 := newHierarchyChunk("doc.sec0", 1)
+		sec0chunk0 := newHierarchyChunk("doc.sec0.chunk0", 2)
+		sec0sub0 := newHierarchyChunk("doc.sec0.sub0", 3)
+		sec1 := newHierarchyChunk("doc.sec1", 4)
+
+		edges := HierarchyEdges([]*model.Chunk{intro, sec0, sec0chunk0, sec0sub0, sec1})
+
+		assert.NotNil(t, findEdge(edges, intro, sec0, RelationNext))
+		assert.NotNil(t, findEdge(edges, sec0, sec1, RelationNext))
+		assert.NotNil(t, findEdge(edges, sec0chunk0, sec0sub0, RelationNext))
+		assert.NotNil(t, findEdge(edges, sec0, sec0sub0, RelationChild))
+		assert.Nil(t, findEdge(edges, sec0sub0, sec1, RelationNext), "Expected no sibling edge across levels")
+	})
+
+	t.Run("No chunks", func(t *testing.T) {
+		assert.Empty(t, HierarchyEdges(nil))
+	})
+}
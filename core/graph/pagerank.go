@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// Defaults of personalized PageRank
+const (
+	DefaultPageRankDamping    = 0.85
+	DefaultPageRankIterations = 100
+	DefaultPageRankTolerance  = 1e-6
+)
+
+// PageRankOptions configures personalized PageRank
+type PageRankOptions struct {
+	Damping             float64 // Probability of following an edge instead of jumping back to a seed, between 0 and 1
+	MaxIterations       int     // Maximum number of power iterations
+	Tolerance           float64 // Iteration stops once the probabilities change less than this in total
+	FollowBidirectional bool    // Follow bidirectional edges from target to source
+}
+
+// PersonalizedPageRank computes the stationary probability of a random walk over the edges between the nodes.
+// In every step the walk follows an edge with probability opts.Damping, choosing the edge in proportion to its
+// weight, and otherwise jumps back to a seed in proportion to the seed weight. Nodes without outgoing edges
+// always jump back to a seed.
+// Edges are followed like in a traversal (see model.Edge.Neighbor). Edges with a weight that isn't positive and
+// edges leaving the nodes are ignored, seeds are part of the nodes.
+// Returns the probability of every node, summing up to 1, or nil if no seed has a positive weight.
+func PersonalizedPageRank(nodes []model.GraphNode, edges []*model.Edge, seeds map[model.GraphNode]float64, opts PageRankOptions) map[model.GraphNode]float64 {
+	damping := opts.Damping
+	if damping <= 0 || damping >= 1 {
+		damping = DefaultPageRankDamping
+	}
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = DefaultPageRankIterations
+	}
+	tolerance := opts.Tolerance
+	if tolerance <= 0 {
+		tolerance = DefaultPageRankTolerance
+	}
+
+	// Index the nodes and seeds
+	index := make(map[model.GraphNode]int, len(nodes)+len(seeds))
+	var order []model.GraphNode
+	addNode := func(node model.GraphNode) {
+		if _, ok := index[node]; !ok {
+			index[node] = len(order)
+			order = append(order, node)
+		}
+	}
+	for _, node := range nodes {
+		addNode(node)
+	}
+	for node := range seeds {
+		addNode(node)
+	}
+
+	// Normalize the seed weights to the jump distribution
+	jump := make([]float64, len(order))
+	total := 0.0
+	for node, weight := range seeds {
+		if weight > 0 && !math.IsInf(weight, 0) {
+			jump[index[node]] += weight
+			total += weight
+		}
+	}
+	if total <= 0 {
+		return nil
+	}
+	for i := range jump {
+		jump[i] /= total
+	}
+
+	// Collect the weighted transitions leaving every node
+	transitions := make([]map[int]float64, len(order))
+	outWeights := make([]float64, len(order))
+	for _, edge := range edges {
+		if !(edge.Weight > 0) || math.IsInf(edge.Weight, 0) {
+			continue
+		}
+
+		source, okSource := edge.SourceNode()
+		target, okTarget := edge.TargetNode()
+		if !okSource || !okTarget || source == target {
+			continue
+		}
+
+		for _, from := range []model.GraphNode{source, target} {
+			to, ok := edge.Neighbor(from, opts.FollowBidirectional)
+			if !ok {
+				continue
+			}
+			fromIndex, okFrom := index[from]
+			toIndex, okTo := index[to]
+			if !okFrom || !okTo {
+				continue
+			}
+
+			if transitions[fromIndex] == nil {
+				transitions[fromIndex] = make(map[int]float64)
+			}
+			transitions[fromIndex][toIndex] += edge.Weight
+			outWeights[fromIndex] += edge.Weight
+		}
+	}
+
+	// Power iteration starting at the jump distribution
+	ranks := append([]float64{}, jump...)
+	for iteration := 0; iteration < maxIterations; iteration++ {
+		next := make([]float64, len(order))
+		dangling := 0.0
+		for from, rank := range ranks {
+			if outWeights[from] == 0 {
+				dangling += rank
+				continue
+			}
+			for to, weight := range transitions[from] {
+				next[to] += damping * rank * weight / outWeights[from]
+			}
+		}
+
+		restart := 1 - damping + damping*dangling
+		change := 0.0
+		for i := range next {
+			next[i] += restart * jump[i]
+			change += math.Abs(next[i] - ranks[i])
+		}
+
+		ranks = next
+		if change < tolerance {
+			break
+		}
+	}
+
+	result := make(map[model.GraphNode]float64, len(order))
+	for i, node := range order {
+		result[node] = ranks[i]
+	}
+	return result
+}
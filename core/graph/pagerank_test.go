@@ -0,0 +1,118 @@
+package graph
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sumRanks returns the total probability of the ranks
+func sumRanks(ranks map[model.GraphNode]float64) float64 {
+	sum := 0.0
+	for _, rank := range ranks {
+		sum += rank
+	}
+	return sum
+}
+
+func TestPersonalizedPageRank(t *testing.T) {
+	t.Run("Stationary probability of a single edge", func(t *testing.T) {
+		mockDB := &MockPathDB{}
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+		mockDB.addEdge(a, b, model.EdgeTypeReference, 1.0, false)
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a, b}, mockDB.edges, map[model.GraphNode]float64{a: 1}, PageRankOptions{Damping: 0.85})
+
+		// rank(a) = 0.15 + 0.85 * rank(b) (b has no outgoing edge), rank(b) = 0.85 * rank(a)
+		require.Len(t, ranks, 2)
+		assert.InDelta(t, 0.15/(1-0.85*0.85), ranks[a], 1e-4)
+		assert.InDelta(t, 0.85*0.15/(1-0.85*0.85), ranks[b], 1e-4)
+	})
+
+	t.Run("Edge weights split the probability", func(t *testing.T) {
+		mockDB := &MockPathDB{}
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+		c := model.ChunkNode(uuid.New())
+		d := model.ChunkNode(uuid.New())
+		mockDB.addEdge(a, b, model.EdgeTypeReference, 1.0, false)
+		mockDB.addEdge(a, c, model.EdgeTypeSemantic, 0.25, false)
+		mockDB.addEdge(b, d, model.EdgeTypeReference, 1.0, false)
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a, b, c, d}, mockDB.edges, map[model.GraphNode]float64{a: 1}, PageRankOptions{})
+
+		assert.InDelta(t, 1.0, sumRanks(ranks), 1e-6, "Expected a probability distribution")
+		assert.Greater(t, ranks[a], ranks[b])
+		assert.InDelta(t, 4*ranks[c], ranks[b], 1e-6, "Expected the probability in proportion to the edge weights")
+		assert.Greater(t, ranks[b], ranks[d])
+	})
+
+	t.Run("Seed weights", func(t *testing.T) {
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a, b}, nil, map[model.GraphNode]float64{a: 3, b: 1}, PageRankOptions{})
+
+		assert.InDelta(t, 0.75, ranks[a], 1e-6)
+		assert.InDelta(t, 0.25, ranks[b], 1e-6)
+	})
+
+	t.Run("Walk passes through entities", func(t *testing.T) {
+		mockDB := &MockPathDB{}
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+		c := model.ChunkNode(uuid.New())
+		e := model.EntityNode(uuid.New())
+		mockDB.addEdge(a, e, model.EdgeTypeEntityMention, 1.0, false)
+		mockDB.addEdge(b, e, model.EdgeTypeEntityMention, 1.0, false)
+		mockDB.addEdge(c, a, model.EdgeTypeReference, 1.0, false)
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a, b, c, e}, mockDB.edges, map[model.GraphNode]float64{a: 1}, PageRankOptions{})
+
+		assert.Greater(t, ranks[e], 0.0)
+		assert.Greater(t, ranks[b], 0.0, "Expected the chunk mentioning the same entity to be reached")
+		assert.Equal(t, 0.0, ranks[c], "Expected directed edges to not be followed backwards")
+	})
+
+	t.Run("Bidirectional edges", func(t *testing.T) {
+		mockDB := &MockPathDB{}
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+		mockDB.addEdge(b, a, model.EdgeTypeSemantic, 1.0, true)
+		nodes := []model.GraphNode{a, b}
+		seeds := map[model.GraphNode]float64{a: 1}
+
+		ranks := PersonalizedPageRank(nodes, mockDB.edges, seeds, PageRankOptions{FollowBidirectional: true})
+		assert.Greater(t, ranks[b], 0.0)
+
+		ranks = PersonalizedPageRank(nodes, mockDB.edges, seeds, PageRankOptions{FollowBidirectional: false})
+		assert.Equal(t, 0.0, ranks[b])
+	})
+
+	t.Run("Edges leaving the nodes are ignored", func(t *testing.T) {
+		mockDB := &MockPathDB{}
+		a := model.ChunkNode(uuid.New())
+		b := model.ChunkNode(uuid.New())
+		outside := model.ChunkNode(uuid.New())
+		mockDB.addEdge(a, b, model.EdgeTypeReference, 1.0, false)
+		mockDB.addEdge(a, outside, model.EdgeTypeReference, 1.0, false)
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a, b}, mockDB.edges, map[model.GraphNode]float64{a: 1}, PageRankOptions{})
+
+		assert.Len(t, ranks, 2)
+		assert.NotContains(t, ranks, outside)
+		assert.InDelta(t, 1.0, sumRanks(ranks), 1e-6)
+	})
+
+	t.Run("No seeds", func(t *testing.T) {
+		a := model.ChunkNode(uuid.New())
+
+		ranks := PersonalizedPageRank([]model.GraphNode{a}, nil, map[model.GraphNode]float64{a: 0}, PageRankOptions{})
+
+		assert.Nil(t, ranks)
+	})
+}
@@ -0,0 +1,301 @@
+package graph
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+)
+
+// PathDB defines the interface for path queries over chunk and entity nodes
+type PathDB interface {
+	GetEdgesConnectedToNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType) ([]*model.Edge, error)
+}
+
+// EdgeCost is the default cost of following an edge, the inverse of its weight
+func EdgeCost(edge *model.Edge) float64 {
+	if edge.Weight <= 0 {
+		return math.Inf(1)
+	}
+	return 1 / edge.Weight
+}
+
+// ShortestPath finds the path with the lowest cost between two nodes with Dijkstra's algorithm.
+// Returns nil without an error if the nodes are not connected within opts.MaxVisited expanded nodes.
+func ShortestPath(ctx context.Context, db PathDB, from model.GraphNode, to model.GraphNode, opts model.PathOptions) (*model.Path, error) {
+	search := newPathSearch(ctx, db, opts)
+	return search.dijkstra(from, to, nil, nil)
+}
+
+// KShortestPaths finds up to k loopless paths between two nodes ordered by cost with Yen's algorithm.
+// Paths over different edges between the same nodes count as different paths.
+func KShortestPaths(ctx context.Context, db PathDB, from model.GraphNode, to model.GraphNode, k int, opts model.PathOptions) ([]*model.Path, error) {
+	if k <= 0 {
+		return nil, fmt.Errorf("k must be positive, got %d", k)
+	}
+
+	search := newPathSearch(ctx, db, opts)
+	shortest, err := search.dijkstra(from, to, nil, nil)
+	if err != nil || shortest == nil {
+		return nil, err
+	}
+
+	paths := []*model.Path{shortest}
+	seen := map[string]bool{pathKey(shortest): true}
+	var candidates []*model.Path
+
+	for len(paths) < k {
+		previous := paths[len(paths)-1]
+
+		// Deviate from the previous path at every node except the target
+		for i := 0; i < len(previous.Steps); i++ {
+			spurNode := previous.Nodes[i]
+			rootSteps := previous.Steps[:i]
+
+			// Block the next step of every found path sharing the root, so the spur path deviates from them
+			blockedSteps := make(map[string]bool)
+			for _, path := range paths {
+				if len(path.Steps) > i && sameSteps(path.Steps[:i], rootSteps) {
+					blockedSteps[stepKey(path.Steps[i])] = true
+				}
+			}
+
+			// Block the root nodes to keep the path loopless
+			blockedNodes := make(map[model.GraphNode]bool)
+			for _, node := range previous.Nodes[:i] {
+				blockedNodes[node] = true
+			}
+
+			spur, err := search.dijkstra(spurNode, to, blockedNodes, blockedSteps)
+			if err != nil {
+				return nil, err
+			}
+			if spur == nil {
+				continue
+			}
+
+			candidate := joinPaths(previous.Nodes[:i], rootSteps, spur)
+			key := pathKey(candidate)
+			if !seen[key] {
+				seen[key] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+
+		// Move the cheapest candidate to the result, fewer steps first on equal cost
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if candidates[a].Cost != candidates[b].Cost {
+				return candidates[a].Cost < candidates[b].Cost
+			}
+			return len(candidates[a].Steps) < len(candidates[b].Steps)
+		})
+		paths = append(paths, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return paths, nil
+}
+
+// pathSearch runs path queries and caches the steps leaving each node
+type pathSearch struct {
+	ctx   context.Context
+	db    PathDB
+	opts  model.PathOptions
+	cost  func(edge *model.Edge) float64
+	steps map[model.GraphNode][]model.PathStep
+}
+
+// newPathSearch creates a path search, filling in the defaults of the options
+func newPathSearch(ctx context.Context, db PathDB, opts model.PathOptions) *pathSearch {
+	if opts.MaxVisited <= 0 {
+		opts.MaxVisited = model.DefaultPathMaxVisited
+	}
+	cost := opts.Cost
+	if cost == nil {
+		cost = EdgeCost
+	}
+
+	return &pathSearch{
+		ctx:   ctx,
+		db:    db,
+		opts:  opts,
+		cost:  cost,
+		steps: make(map[model.GraphNode][]model.PathStep),
+	}
+}
+
+// neighbors returns the steps that can be taken from a node
+func (s *pathSearch) neighbors(node model.GraphNode) ([]model.PathStep, error) {
+	if steps, ok := s.steps[node]; ok {
+		return steps, nil
+	}
+
+	edges, err := s.db.GetEdgesConnectedToNode(s.ctx, node, s.opts.EdgeTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	var steps []model.PathStep
+	for _, edge := range edges {
+		source, okSource := edge.SourceNode()
+		target, okTarget := edge.TargetNode()
+		if !okSource || !okTarget || source == target {
+			continue
+		}
+
+		cost := s.cost(edge)
+		if cost < 0 || math.IsInf(cost, 0) || math.IsNaN(cost) {
+			continue
+		}
+
+		if source == node {
+			steps = append(steps, model.PathStep{From: node, To: target, Edge: edge, Cost: cost})
+		} else if target == node && (s.opts.IgnoreDirection || (s.opts.FollowBidirectional && edge.Bidirectional) || edge.LinksChunkAndEntity()) {
+			steps = append(steps, model.PathStep{From: node, To: source, Edge: edge, Reversed: true, Cost: cost})
+		}
+	}
+
+	s.steps[node] = steps
+	return steps, nil
+}
+
+// dijkstra finds the cheapest path avoiding the blocked nodes and steps, nil if there is none
+func (s *pathSearch) dijkstra(from model.GraphNode, to model.GraphNode, blockedNodes map[model.GraphNode]bool, blockedSteps map[string]bool) (*model.Path, error) {
+	costs := map[model.GraphNode]float64{from: 0}
+	previous := make(map[model.GraphNode]model.PathStep)
+	done := make(map[model.GraphNode]bool)
+
+	queue := &pathQueue{{node: from, cost: 0}}
+	for queue.Len() > 0 {
+		if err := s.ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		current := heap.Pop(queue).(pathQueueItem)
+		if done[current.node] {
+			continue
+		}
+		done[current.node] = true
+
+		if current.node == to {
+			return buildPath(from, to, previous, current.cost), nil
+		}
+		if len(done) > s.opts.MaxVisited {
+			return nil, nil
+		}
+
+		steps, err := s.neighbors(current.node)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, step := range steps {
+			if done[step.To] || blockedNodes[step.To] || blockedSteps[stepKey(step)] {
+				continue
+			}
+
+			cost := current.cost + step.Cost
+			if known, ok := costs[step.To]; ok && known <= cost {
+				continue
+			}
+			costs[step.To] = cost
+			previous[step.To] = step
+			heap.Push(queue, pathQueueItem{node: step.To, cost: cost})
+		}
+	}
+
+	return nil, nil
+}
+
+// buildPath follows the previous steps back from the target
+func buildPath(from model.GraphNode, to model.GraphNode, previous map[model.GraphNode]model.PathStep, cost float64) *model.Path {
+	var steps []model.PathStep
+	for node := to; node != from; {
+		step := previous[node]
+		steps = append(steps, step)
+		node = step.From
+	}
+
+	path := &model.Path{
+		Nodes: []model.GraphNode{from},
+		Steps: make([]model.PathStep, 0, len(steps)),
+		Cost:  cost,
+	}
+	for i := len(steps) - 1; i >= 0; i-- {
+		path.Steps = append(path.Steps, steps[i])
+		path.Nodes = append(path.Nodes, steps[i].To)
+	}
+	return path
+}
+
+// joinPaths appends a spur path to the root nodes and steps of a previous path
+func joinPaths(rootNodes []model.GraphNode, rootSteps []model.PathStep, spur *model.Path) *model.Path {
+	path := &model.Path{
+		Nodes: append(append([]model.GraphNode{}, rootNodes...), spur.Nodes...),
+		Steps: append(append([]model.PathStep{}, rootSteps...), spur.Steps...),
+	}
+	for _, step := range path.Steps {
+		path.Cost += step.Cost
+	}
+	return path
+}
+
+// sameSteps reports whether two step lists follow the same edges in the same direction
+func sameSteps(a []model.PathStep, b []model.PathStep) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if stepKey(a[i]) != stepKey(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// stepKey identifies a step by its edge and direction
+func stepKey(step model.PathStep) string {
+	edgeID := uuid.Nil
+	if step.Edge != nil {
+		edgeID = step.Edge.ID
+	}
+	return fmt.Sprintf("%s:%s:%s", step.From.ID, edgeID, step.To.ID)
+}
+
+// pathKey identifies a path by its steps
+func pathKey(path *model.Path) string {
+	keys := make([]string, len(path.Steps))
+	for i, step := range path.Steps {
+		keys[i] = stepKey(step)
+	}
+	return strings.Join(keys, "|")
+}
+
+// pathQueueItem is a node waiting in the Dijkstra queue
+type pathQueueItem struct {
+	node model.GraphNode
+	cost float64
+}
+
+// pathQueue is a min-heap of nodes ordered by path cost
+type pathQueue []pathQueueItem
+
+func (q pathQueue) Len() int            { return len(q) }
+func (q pathQueue) Less(i, j int) bool  { return q[i].cost < q[j].cost }
+func (q pathQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pathQueue) Push(x interface{}) { *q = append(*q, x.(pathQueueItem)) }
+func (q *pathQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
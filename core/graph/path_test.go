@@ -0,0 +1,259 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// MockPathDB is a mock implementation of PathDB for testing
+type MockPathDB struct {
+	edges   []*model.Edge
+	queries int
+}
+
+func (m *MockPathDB) GetEdgesConnectedToNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType) ([]*model.Edge, error) {
+	m.queries++
+	var edges []*model.Edge
+	for _, edge := range m.edges {
+		source, _ := edge.SourceNode()
+		target, _ := edge.TargetNode()
+		if source != node && target != node {
+			continue
+		}
+		if len(edgeTypes) > 0 && !containsEdgeType(edgeTypes, edge.EdgeType) {
+			continue
+		}
+		edges = append(edges, edge)
+	}
+	return edges, nil
+}
+
+func containsEdgeType(edgeTypes []model.EdgeType, edgeType model.EdgeType) bool {
+	for _, t := range edgeTypes {
+		if t == edgeType {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *MockPathDB) addEdge(source model.GraphNode, target model.GraphNode, edgeType model.EdgeType, weight float64, bidirectional bool) *model.Edge {
+	edge := &model.Edge{ID: uuid.New(), EdgeType: edgeType, Weight: weight, Bidirectional: bidirectional}
+	if source.Type == model.NodeTypeEntity {
+		edge.SourceEntityID = &source.ID
+	} else {
+		edge.SourceChunkID = &source.ID
+	}
+	if target.Type == model.NodeTypeEntity {
+		edge.TargetEntityID = &target.ID
+	} else {
+		edge.TargetChunkID = &target.ID
+	}
+	m.edges = append(m.edges, edge)
+	return edge
+}
+
+func TestShortestPath(t *testing.T) {
+	mockDB := &MockPathDB{}
+
+	// Create test graph: A -> B -> D (weak direct edge A -> D),
+	//                    A -> C -> D
+	a := model.ChunkNode(uuid.New())
+	b := model.ChunkNode(uuid.New())
+	c := model.ChunkNode(uuid.New())
+	d := model.ChunkNode(uuid.New())
+
+	edgeAB := mockDB.addEdge(a, b, model.EdgeTypeReference, 1.0, false)
+	edgeBD := mockDB.addEdge(b, d, model.EdgeTypeReference, 1.0, false)
+	mockDB.addEdge(a, c, model.EdgeTypeSemantic, 0.5, false)
+	mockDB.addEdge(c, d, model.EdgeTypeSemantic, 0.5, false)
+	mockDB.addEdge(a, d, model.EdgeTypeSemantic, 0.2, false)
+
+	t.Run("Path with lowest cost", func(t *testing.T) {
+		path, err := ShortestPath(context.Background(), mockDB, a, d, model.DefaultPathOptions())
+
+		assert.NoError(t, err, "Expected ShortestPath to not return an error")
+		require.NotNil(t, path, "Expected a path")
+		assert.Equal(t, []model.GraphNode{a, b, d}, path.Nodes)
+		require.Len(t, path.Steps, 2)
+		assert.Equal(t, edgeAB, path.Steps[0].Edge)
+		assert.Equal(t, edgeBD, path.Steps[1].Edge)
+		assert.Equal(t, 2.0, path.Cost, "Expected the inverse weights as cost")
+	})
+
+	t.Run("Path with edge type filter", func(t *testing.T) {
+		opts := model.DefaultPathOptions()
+		opts.EdgeTypes = []model.EdgeType{model.EdgeTypeSemantic}
+
+		path, err := ShortestPath(context.Background(), mockDB, a, d, opts)
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{a, c, d}, path.Nodes)
+		assert.Equal(t, 4.0, path.Cost)
+	})
+
+	t.Run("Path with custom cost", func(t *testing.T) {
+		opts := model.DefaultPathOptions()
+		opts.Cost = func(edge *model.Edge) float64 { return 1 }
+
+		path, err := ShortestPath(context.Background(), mockDB, a, d, opts)
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{a, d}, path.Nodes, "Expected the fewest hops")
+	})
+
+	t.Run("Directed edges are not followed backwards", func(t *testing.T) {
+		path, err := ShortestPath(context.Background(), mockDB, d, a, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		assert.Nil(t, path, "Expected no path against the edge direction")
+	})
+
+	t.Run("Path to the source", func(t *testing.T) {
+		path, err := ShortestPath(context.Background(), mockDB, a, a, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{a}, path.Nodes)
+		assert.Empty(t, path.Steps)
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := ShortestPath(ctx, mockDB, a, d, model.DefaultPathOptions())
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+func TestShortestPathMixedNodes(t *testing.T) {
+	mockDB := &MockPathDB{}
+
+	// Two chunks mentioning the same entity: A -> E <- B, co-occurring entities F <-> E, G -> B
+	a := model.ChunkNode(uuid.New())
+	b := model.ChunkNode(uuid.New())
+	e := model.EntityNode(uuid.New())
+	f := model.EntityNode(uuid.New())
+
+	mockDB.addEdge(a, e, model.EdgeTypeEntityMention, 1.0, false)
+	mention := mockDB.addEdge(b, e, model.EdgeTypeEntityMention, 0.5, false)
+	coOccurrence := mockDB.addEdge(f, e, model.EdgeTypeEntityMention, 1.0, true)
+	g := model.ChunkNode(uuid.New())
+	mockDB.addEdge(g, b, model.EdgeTypeReference, 1.0, false)
+
+	t.Run("Chunks are connected through an entity", func(t *testing.T) {
+		path, err := ShortestPath(context.Background(), mockDB, a, b, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{a, e, b}, path.Nodes)
+		assert.Equal(t, mention, path.Steps[1].Edge)
+		assert.True(t, path.Steps[1].Reversed, "Expected the mention to be followed backwards")
+		assert.Equal(t, 3.0, path.Cost)
+	})
+
+	t.Run("Ignore direction follows directed edges backwards", func(t *testing.T) {
+		opts := model.DefaultPathOptions()
+		opts.IgnoreDirection = true
+
+		path, err := ShortestPath(context.Background(), mockDB, b, g, opts)
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{b, g}, path.Nodes)
+
+		path, err = ShortestPath(context.Background(), mockDB, b, g, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		assert.Nil(t, path)
+	})
+
+	t.Run("Bidirectional edges between entities", func(t *testing.T) {
+		path, err := ShortestPath(context.Background(), mockDB, a, f, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{a, e, f}, path.Nodes)
+		assert.Equal(t, coOccurrence, path.Steps[1].Edge)
+		assert.True(t, path.Steps[1].Reversed)
+
+		opts := model.DefaultPathOptions()
+		opts.FollowBidirectional = false
+		path, err = ShortestPath(context.Background(), mockDB, a, f, opts)
+
+		assert.NoError(t, err)
+		assert.Nil(t, path)
+	})
+}
+
+func TestKShortestPaths(t *testing.T) {
+	mockDB := &MockPathDB{}
+
+	// Create test graph: A -> B -> D, A -> C -> D, A -> D, B -> C
+	a := model.ChunkNode(uuid.New())
+	b := model.ChunkNode(uuid.New())
+	c := model.ChunkNode(uuid.New())
+	d := model.ChunkNode(uuid.New())
+
+	mockDB.addEdge(a, b, model.EdgeTypeReference, 1.0, false)
+	mockDB.addEdge(b, d, model.EdgeTypeReference, 1.0, false)
+	mockDB.addEdge(a, c, model.EdgeTypeSemantic, 0.5, false)
+	mockDB.addEdge(c, d, model.EdgeTypeSemantic, 0.5, false)
+	mockDB.addEdge(a, d, model.EdgeTypeSemantic, 0.2, false)
+	mockDB.addEdge(b, c, model.EdgeTypeReference, 1.0, false)
+
+	t.Run("Paths ordered by cost", func(t *testing.T) {
+		paths, err := KShortestPaths(context.Background(), mockDB, a, d, 3, model.DefaultPathOptions())
+
+		assert.NoError(t, err, "Expected KShortestPaths to not return an error")
+		require.Len(t, paths, 3)
+		assert.Equal(t, []model.GraphNode{a, b, d}, paths[0].Nodes)
+		assert.Equal(t, 2.0, paths[0].Cost)
+		assert.Equal(t, []model.GraphNode{a, c, d}, paths[1].Nodes)
+		assert.Equal(t, 4.0, paths[1].Cost)
+		assert.Equal(t, []model.GraphNode{a, b, c, d}, paths[2].Nodes)
+		assert.Equal(t, 4.0, paths[2].Cost, "Expected fewer steps first on equal cost")
+	})
+
+	t.Run("All paths if k is larger", func(t *testing.T) {
+		paths, err := KShortestPaths(context.Background(), mockDB, a, d, 10, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		require.Len(t, paths, 4)
+		assert.Equal(t, []model.GraphNode{a, d}, paths[3].Nodes)
+		for i := 1; i < len(paths); i++ {
+			assert.LessOrEqual(t, paths[i-1].Cost, paths[i].Cost, "Expected paths ordered by cost")
+		}
+	})
+
+	t.Run("Edges are loaded once per node", func(t *testing.T) {
+		mockDB.queries = 0
+
+		_, err := KShortestPaths(context.Background(), mockDB, a, d, 10, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, mockDB.queries, 4)
+	})
+
+	t.Run("No path", func(t *testing.T) {
+		paths, err := KShortestPaths(context.Background(), mockDB, d, a, 3, model.DefaultPathOptions())
+
+		assert.NoError(t, err)
+		assert.Empty(t, paths)
+	})
+
+	t.Run("Invalid k", func(t *testing.T) {
+		_, err := KShortestPaths(context.Background(), mockDB, a, d, 0, model.DefaultPathOptions())
+
+		assert.Error(t, err)
+	})
+}
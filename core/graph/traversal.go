@@ -10,97 +10,96 @@ import (
 // GraphDB defines the interface for graph operations
 type GraphDB interface {
 	GetChunk(ctx context.Context, id string) (*model.Chunk, error)
-	GetEdgesFromChunk(ctx context.Context, chunkID string, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Edge, error)
+	GetEntity(ctx context.Context, id string) (*model.Entity, error)
+	// GetEdgesFromNode returns the edges starting at the node and, to follow them backwards,
+	// the incoming bidirectional edges (if followBidirectional is set) and edges between a chunk and an entity
+	GetEdgesFromNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Edge, error)
 }
 
-// TraversalResult contains a chunk and its distance from the source
+// TraversalResult contains a chunk or entity and its distance from the source
 type TraversalResult struct {
-	Chunk    *model.Chunk
-	Distance int
-	Path     []uuid.UUID // Path from source to this chunk
+	Node      model.GraphNode // Reached chunk or entity
+	Chunk     *model.Chunk    // Chunk data, nil for entities
+	Entity    *model.Entity   // Entity data, nil for chunks
+	Distance  int
+	Path      []uuid.UUID       // Path from source to this node, may pass through entities
+	PathNodes []model.GraphNode // Typed nodes of the path
 }
 
-// BFS performs breadth-first search from a source chunk
+// BFS performs breadth-first search from a source chunk.
+// Edges between a chunk and an entity are followed in both directions, so the traversal
+// moves from a chunk to the entities it mentions and on to the other chunks mentioning them.
 func BFS(ctx context.Context, db GraphDB, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*TraversalResult, error) {
-	visited := make(map[uuid.UUID]bool)
-	queue := []TraversalResult{{
-		Chunk:    nil,
-		Distance: 0,
-		Path:     []uuid.UUID{sourceID},
-	}}
+	visited := make(map[model.GraphNode]bool)
 
 	// Get source chunk
 	sourceChunk, err := db.GetChunk(ctx, sourceID.String())
 	if err != nil {
 		return nil, err
 	}
-	queue[0].Chunk = sourceChunk
+	source := model.ChunkNode(sourceID)
+	queue := []*TraversalResult{{
+		Node:      source,
+		Chunk:     sourceChunk,
+		Distance:  0,
+		Path:      []uuid.UUID{sourceID},
+		PathNodes: []model.GraphNode{source},
+	}}
 
 	var results []*TraversalResult
-	visited[sourceID] = true
+	visited[source] = true
 
 	for len(queue) > 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
 		current := queue[0]
 		queue = queue[1:]
 
-		results = append(results, &current)
+		results = append(results, current)
 
 		// Stop if we've reached max hops
 		if current.Distance >= maxHops {
 			continue
 		}
 
-		// Get edges from current chunk
-		edges, err := db.GetEdgesFromChunk(ctx, current.Chunk.ID.String(), edgeTypes, followBidirectional)
+		// Get edges from current node
+		edges, err := db.GetEdgesFromNode(ctx, current.Node, edgeTypes, followBidirectional)
 		if err != nil {
 			return nil, err
 		}
 
 		// Process each edge
 		for _, edge := range edges {
-			var targetID uuid.UUID
-
-			// Determine target based on edge direction
-			if edge.SourceChunkID != nil && *edge.SourceChunkID == current.Chunk.ID && edge.TargetChunkID != nil {
-				targetID = *edge.TargetChunkID
-			} else if edge.Bidirectional && edge.TargetChunkID != nil && *edge.TargetChunkID == current.Chunk.ID && edge.SourceChunkID != nil {
-				targetID = *edge.SourceChunkID
-			} else {
-				continue // Skip entity edges or invalid edges
+			target, ok := edge.Neighbor(current.Node, followBidirectional)
+			if !ok {
+				continue // Skip invalid edges
 			}
 
 			// Skip if already visited
-			if visited[targetID] {
+			if visited[target] {
 				continue
 			}
 
-			// Get target chunk
-			targetChunk, err := db.GetChunk(ctx, targetID.String())
+			// Get target chunk or entity
+			next, err := loadNode(ctx, db, target)
 			if err != nil {
-				continue // Skip if chunk not found
+				continue // Skip if node not found
 			}
 
-			visited[targetID] = true
-
-			// Create new path
-			newPath := make([]uuid.UUID, len(current.Path))
-			copy(newPath, current.Path)
-			newPath = append(newPath, targetID)
+			visited[target] = true
 
-			queue = append(queue, TraversalResult{
-				Chunk:    targetChunk,
-				Distance: current.Distance + 1,
-				Path:     newPath,
-			})
+			queue = append(queue, extendResult(current, next))
 		}
 	}
 
 	return results, nil
 }
 
-// DFS performs depth-first search from a source chunk
+// DFS performs depth-first search from a source chunk, passing through entities like BFS
 func DFS(ctx context.Context, db GraphDB, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*TraversalResult, error) {
-	visited := make(map[uuid.UUID]bool)
+	visited := make(map[model.GraphNode]bool)
 	var results []*TraversalResult
 
 	// Get source chunk
@@ -108,9 +107,19 @@ func DFS(ctx context.Context, db GraphDB, sourceID uuid.UUID, maxHops int, edgeT
 	if err != nil {
 		return nil, err
 	}
+	source := model.ChunkNode(sourceID)
 
 	// Start recursive DFS
-	dfsRecursive(ctx, db, sourceChunk, 0, maxHops, []uuid.UUID{sourceID}, edgeTypes, followBidirectional, visited, &results)
+	dfsRecursive(ctx, db, &TraversalResult{
+		Node:      source,
+		Chunk:     sourceChunk,
+		Distance:  0,
+		Path:      []uuid.UUID{sourceID},
+		PathNodes: []model.GraphNode{source},
+	}, maxHops, edgeTypes, followBidirectional, visited, &results)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
@@ -119,73 +128,80 @@ func DFS(ctx context.Context, db GraphDB, sourceID uuid.UUID, maxHops int, edgeT
 func dfsRecursive(
 	ctx context.Context,
 	db GraphDB,
-	current *model.Chunk,
-	distance int,
+	current *TraversalResult,
 	maxHops int,
-	path []uuid.UUID,
 	edgeTypes []model.EdgeType,
 	followBidirectional bool,
-	visited map[uuid.UUID]bool,
+	visited map[model.GraphNode]bool,
 	results *[]*TraversalResult,
 ) {
-	// Mark as visited
-	visited[current.ID] = true
-
-	// Add to results
-	pathCopy := make([]uuid.UUID, len(path))
-	copy(pathCopy, path)
-	*results = append(*results, &TraversalResult{
-		Chunk:    current,
-		Distance: distance,
-		Path:     pathCopy,
-	})
+	// Stop once the context is canceled, DFS returns the context error
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Mark as visited and add to results
+	visited[current.Node] = true
+	*results = append(*results, current)
 
 	// Stop if we've reached max hops
-	if distance >= maxHops {
+	if current.Distance >= maxHops {
 		return
 	}
 
-	// Get edges from current chunk
-	edges, err := db.GetEdgesFromChunk(ctx, current.ID.String(), edgeTypes, followBidirectional)
+	// Get edges from current node
+	edges, err := db.GetEdgesFromNode(ctx, current.Node, edgeTypes, followBidirectional)
 	if err != nil {
 		return
 	}
 
 	// Process each edge
 	for _, edge := range edges {
-		var targetID uuid.UUID
-
-		// Determine target based on edge direction
-		if edge.SourceChunkID != nil && *edge.SourceChunkID == current.ID && edge.TargetChunkID != nil {
-			targetID = *edge.TargetChunkID
-		} else if edge.Bidirectional && edge.TargetChunkID != nil && *edge.TargetChunkID == current.ID && edge.SourceChunkID != nil {
-			targetID = *edge.SourceChunkID
-		} else {
-			continue // Skip entity edges or invalid edges
+		target, ok := edge.Neighbor(current.Node, followBidirectional)
+		if !ok {
+			continue // Skip invalid edges
 		}
 
 		// Skip if already visited
-		if visited[targetID] {
+		if visited[target] {
 			continue
 		}
 
-		// Get target chunk
-		targetChunk, err := db.GetChunk(ctx, targetID.String())
+		// Get target chunk or entity
+		next, err := loadNode(ctx, db, target)
 		if err != nil {
-			continue // Skip if chunk not found
+			continue // Skip if node not found
 		}
 
-		// Create new path
-		newPath := make([]uuid.UUID, len(path))
-		copy(newPath, path)
-		newPath = append(newPath, targetID)
-
 		// Recurse
-		dfsRecursive(ctx, db, targetChunk, distance+1, maxHops, newPath, edgeTypes, followBidirectional, visited, results)
+		dfsRecursive(ctx, db, extendResult(current, next), maxHops, edgeTypes, followBidirectional, visited, results)
+	}
+}
+
+// loadNode loads the chunk or entity of a node
+func loadNode(ctx context.Context, db GraphDB, node model.GraphNode) (*TraversalResult, error) {
+	result := &TraversalResult{Node: node}
+	var err error
+	if node.Type == model.NodeTypeEntity {
+		result.Entity, err = db.GetEntity(ctx, node.ID.String())
+	} else {
+		result.Chunk, err = db.GetChunk(ctx, node.ID.String())
+	}
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
-// GetNeighbors retrieves immediate neighbors (1-hop) of a chunk
+// extendResult sets the distance and path of the next node reached from the current one
+func extendResult(current *TraversalResult, next *TraversalResult) *TraversalResult {
+	next.Distance = current.Distance + 1
+	next.Path = append(append(make([]uuid.UUID, 0, len(current.Path)+1), current.Path...), next.Node.ID)
+	next.PathNodes = append(append(make([]model.GraphNode, 0, len(current.PathNodes)+1), current.PathNodes...), next.Node)
+	return next
+}
+
+// GetNeighbors retrieves the chunks one hop away from a chunk, entities are not included
 func GetNeighbors(ctx context.Context, db GraphDB, chunkID uuid.UUID, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Chunk, error) {
 	results, err := BFS(ctx, db, chunkID, 1, edgeTypes, followBidirectional)
 	if err != nil {
@@ -195,7 +211,9 @@ func GetNeighbors(ctx context.Context, db GraphDB, chunkID uuid.UUID, edgeTypes
 	// Skip the source chunk itself (first result)
 	neighbors := make([]*model.Chunk, 0, len(results)-1)
 	for i := 1; i < len(results); i++ {
-		neighbors = append(neighbors, results[i].Chunk)
+		if results[i].Chunk != nil {
+			neighbors = append(neighbors, results[i].Chunk)
+		}
 	}
 
 	return neighbors, nil
@@ -12,14 +12,16 @@ import (
 
 // MockGraphDB is a mock implementation of GraphDB for testing
 type MockGraphDB struct {
-	chunks map[string]*model.Chunk
-	edges  map[string][]*model.Edge
+	chunks   map[string]*model.Chunk
+	entities map[string]*model.Entity
+	edges    map[string][]*model.Edge
 }
 
 func NewMockGraphDB() *MockGraphDB {
 	return &MockGraphDB{
-		chunks: make(map[string]*model.Chunk),
-		edges:  make(map[string][]*model.Edge),
+		chunks:   make(map[string]*model.Chunk),
+		entities: make(map[string]*model.Entity),
+		edges:    make(map[string][]*model.Edge),
 	}
 }
 
@@ -31,8 +33,16 @@ func (m *MockGraphDB) GetChunk(ctx context.Context, id string) (*model.Chunk, er
 	return chunk, nil
 }
 
-func (m *MockGraphDB) GetEdgesFromChunk(ctx context.Context, chunkID string, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Edge, error) {
-	edges, ok := m.edges[chunkID]
+func (m *MockGraphDB) GetEntity(ctx context.Context, id string) (*model.Entity, error) {
+	entity, ok := m.entities[id]
+	if !ok {
+		return nil, assert.AnError
+	}
+	return entity, nil
+}
+
+func (m *MockGraphDB) GetEdgesFromNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Edge, error) {
+	edges, ok := m.edges[node.ID.String()]
 	if !ok {
 		return []*model.Edge{}, nil
 	}
@@ -112,6 +122,16 @@ func TestBFS(t *testing.T) {
 		assert.Equal(t, 0, results[0].Distance, "Expected distance to be 0")
 	})
 
+	t.Run("BFS with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := BFS(ctx, mockDB, idA, 2, []model.EdgeType{}, false)
+
+		assert.ErrorIs(t, err, context.Canceled, "Expected BFS to return the context error")
+		assert.Nil(t, results)
+	})
+
 	t.Run("BFS with max hops 0", func(t *testing.T) {
 		results, err := BFS(context.Background(), mockDB, idA, 0, []model.EdgeType{}, false)
 
@@ -190,15 +210,43 @@ func TestBFS(t *testing.T) {
 		assert.True(t, foundSource, "Expected to reach source via bidirectional edge")
 	})
 
-	t.Run("BFS skips entity edges (edges without chunk IDs)", func(t *testing.T) {
-		// Test that edges with entity IDs instead of chunk IDs are skipped
+	t.Run("BFS passes through entities", func(t *testing.T) {
+		// Two chunks mentioning the same entity: chunk1 -> entity <- chunk2
+		chunkID1 := uuid.New()
+		chunkID2 := uuid.New()
+		entityID := uuid.New()
+
+		mockDB.chunks[chunkID1.String()] = &model.Chunk{ID: chunkID1, Content: "Chunk 1", Path: "doc.chunk1"}
+		mockDB.chunks[chunkID2.String()] = &model.Chunk{ID: chunkID2, Content: "Chunk 2", Path: "doc.chunk2"}
+		mockDB.entities[entityID.String()] = &model.Entity{ID: entityID, Name: "Entity", Type: "Concept"}
+
+		mention1 := &model.Edge{SourceChunkID: &chunkID1, TargetEntityID: &entityID, EdgeType: model.EdgeTypeEntityMention}
+		mention2 := &model.Edge{SourceChunkID: &chunkID2, TargetEntityID: &entityID, EdgeType: model.EdgeTypeEntityMention}
+
+		mockDB.edges[chunkID1.String()] = []*model.Edge{mention1}
+		mockDB.edges[entityID.String()] = []*model.Edge{mention1, mention2}
+
+		results, err := BFS(context.Background(), mockDB, chunkID1, 2, []model.EdgeType{}, false)
+
+		assert.NoError(t, err, "Expected BFS to not return an error")
+		require.Len(t, results, 3, "Expected source chunk, entity and other chunk")
+		assert.Equal(t, model.EntityNode(entityID), results[1].Node)
+		assert.Nil(t, results[1].Chunk, "Expected no chunk data for the entity")
+		assert.Equal(t, "Entity", results[1].Entity.Name)
+		assert.Equal(t, chunkID2, results[2].Chunk.ID)
+		assert.Equal(t, 2, results[2].Distance)
+		assert.Equal(t, []uuid.UUID{chunkID1, entityID, chunkID2}, results[2].Path)
+		assert.Equal(t, []model.GraphNode{model.ChunkNode(chunkID1), model.EntityNode(entityID), model.ChunkNode(chunkID2)}, results[2].PathNodes)
+	})
+
+	t.Run("BFS skips edges to missing entities", func(t *testing.T) {
 		chunkID := uuid.New()
 		entityID := uuid.New()
 
 		chunk := &model.Chunk{ID: chunkID, Content: "Chunk", Path: "doc.chunk"}
 		mockDB.chunks[chunkID.String()] = chunk
 
-		// Entity edge: has SourceEntityID instead of SourceChunkID
+		// Entity edge to an entity that doesn't exist
 		entityEdge := &model.Edge{
 			SourceEntityID: &entityID,
 			TargetChunkID:  &chunkID,
@@ -210,7 +258,7 @@ func TestBFS(t *testing.T) {
 		results, err := BFS(context.Background(), mockDB, chunkID, 1, []model.EdgeType{}, false)
 
 		assert.NoError(t, err, "Expected BFS to not return an error")
-		require.Len(t, results, 1, "Expected only source chunk (entity edges should be skipped)")
+		require.Len(t, results, 1, "Expected only source chunk")
 		assert.Equal(t, chunkID, results[0].Chunk.ID, "Expected result to be source chunk")
 	})
 
@@ -352,6 +400,16 @@ func TestDFS(t *testing.T) {
 		assert.Equal(t, isolatedID, results[0].Chunk.ID, "Expected result to be isolated chunk")
 	})
 
+	t.Run("DFS with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := DFS(ctx, mockDB, idA, 2, []model.EdgeType{}, false)
+
+		assert.ErrorIs(t, err, context.Canceled, "Expected DFS to return the context error")
+		assert.Nil(t, results)
+	})
+
 	t.Run("DFS with max hops 0", func(t *testing.T) {
 		results, err := DFS(context.Background(), mockDB, idA, 0, []model.EdgeType{}, false)
 
@@ -403,15 +461,43 @@ func TestDFS(t *testing.T) {
 		assert.True(t, foundSource, "Expected to reach source via bidirectional edge")
 	})
 
-	t.Run("DFS skips entity edges (edges without chunk IDs)", func(t *testing.T) {
-		// Test that edges with entity IDs instead of chunk IDs are skipped
+	t.Run("DFS passes through entities", func(t *testing.T) {
+		// Two chunks mentioning the same entity: chunk1 -> entity <- chunk2
+		chunkID1 := uuid.New()
+		chunkID2 := uuid.New()
+		entityID := uuid.New()
+
+		mockDB.chunks[chunkID1.String()] = &model.Chunk{ID: chunkID1, Content: "Chunk 1", Path: "doc.chunk1"}
+		mockDB.chunks[chunkID2.String()] = &model.Chunk{ID: chunkID2, Content: "Chunk 2", Path: "doc.chunk2"}
+		mockDB.entities[entityID.String()] = &model.Entity{ID: entityID, Name: "Entity", Type: "Concept"}
+
+		mention1 := &model.Edge{SourceChunkID: &chunkID1, TargetEntityID: &entityID, EdgeType: model.EdgeTypeEntityMention}
+		mention2 := &model.Edge{SourceChunkID: &chunkID2, TargetEntityID: &entityID, EdgeType: model.EdgeTypeEntityMention}
+
+		mockDB.edges[chunkID1.String()] = []*model.Edge{mention1}
+		mockDB.edges[entityID.String()] = []*model.Edge{mention1, mention2}
+
+		results, err := DFS(context.Background(), mockDB, chunkID1, 2, []model.EdgeType{}, false)
+
+		assert.NoError(t, err, "Expected DFS to not return an error")
+		require.Len(t, results, 3, "Expected source chunk, entity and other chunk")
+		assert.Equal(t, model.EntityNode(entityID), results[1].Node)
+		assert.Nil(t, results[1].Chunk, "Expected no chunk data for the entity")
+		assert.Equal(t, "Entity", results[1].Entity.Name)
+		assert.Equal(t, chunkID2, results[2].Chunk.ID)
+		assert.Equal(t, 2, results[2].Distance)
+		assert.Equal(t, []uuid.UUID{chunkID1, entityID, chunkID2}, results[2].Path)
+		assert.Equal(t, []model.GraphNode{model.ChunkNode(chunkID1), model.EntityNode(entityID), model.ChunkNode(chunkID2)}, results[2].PathNodes)
+	})
+
+	t.Run("DFS skips edges to missing entities", func(t *testing.T) {
 		chunkID := uuid.New()
 		entityID := uuid.New()
 
 		chunk := &model.Chunk{ID: chunkID, Content: "Chunk", Path: "doc.chunk"}
 		mockDB.chunks[chunkID.String()] = chunk
 
-		// Entity edge: has SourceEntityID instead of SourceChunkID
+		// Entity edge to an entity that doesn't exist
 		entityEdge := &model.Edge{
 			SourceEntityID: &entityID,
 			TargetChunkID:  &chunkID,
@@ -423,7 +509,7 @@ func TestDFS(t *testing.T) {
 		results, err := DFS(context.Background(), mockDB, chunkID, 1, []model.EdgeType{}, false)
 
 		assert.NoError(t, err, "Expected DFS to not return an error")
-		require.Len(t, results, 1, "Expected only source chunk (entity edges should be skipped)")
+		require.Len(t, results, 1, "Expected only source chunk")
 		assert.Equal(t, chunkID, results[0].Chunk.ID, "Expected result to be source chunk")
 	})
 
@@ -0,0 +1,133 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// DOCXLoader extracts the text of Word documents from word/document.xml.
+// Paragraphs with a heading style ("Heading1" to "Heading6") become Markdown headings,
+// the first paragraph with the "Title" style becomes the document title
+// and numbered or bulleted paragraphs become "- " list items.
+type DOCXLoader struct{}
+
+// Load reads the DOCX archive and converts the document body
+func (l *DOCXLoader) Load(r io.Reader) (*model.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open docx archive: %w", err)
+	}
+
+	var body io.ReadCloser
+	for _, file := range archive.File {
+		if file.Name == "word/document.xml" {
+			body, err = file.Open()
+			if err != nil {
+				return nil, fmt.Errorf("failed to open document.xml: %w", err)
+			}
+			break
+		}
+	}
+	if body == nil {
+		return nil, fmt.Errorf("docx archive has no word/document.xml")
+	}
+	defer body.Close()
+
+	builder := &contentBuilder{}
+	title := ""
+	var text strings.Builder
+	style := ""
+	isListItem := false
+
+	decoder := xml.NewDecoder(body)
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse document.xml: %w", err)
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				text.Reset()
+				style = ""
+				isListItem = false
+			case "pStyle":
+				style = xmlAttr(t, "val")
+			case "numPr":
+				isListItem = true
+			case "t":
+				var value string
+				if err := decoder.DecodeElement(&value, &t); err != nil {
+					return nil, fmt.Errorf("failed to parse text: %w", err)
+				}
+				text.WriteString(value)
+			case "tab":
+				text.WriteString("\t")
+			case "br", "cr":
+				text.WriteString("\n")
+			}
+		case xml.EndElement:
+			if t.Name.Local != "p" {
+				continue
+			}
+			level := docxHeadingLevel(style)
+			switch {
+			case strings.EqualFold(style, "Title") && title == "":
+				title = collapseSpaces(text.String())
+				builder.heading(1, text.String())
+			case level > 0:
+				builder.heading(level, text.String())
+			case isListItem || strings.HasPrefix(strings.ToLower(style), "list"):
+				if item := strings.TrimSpace(text.String()); item != "" {
+					builder.paragraph("- " + item)
+				}
+			default:
+				builder.paragraph(text.String())
+			}
+		}
+	}
+
+	doc := builder.document("docx")
+	doc.Title = title
+	return doc, nil
+}
+
+// docxHeadingLevel returns the level of a heading style like "Heading2" or 0 for other styles
+func docxHeadingLevel(style string) int {
+	lower := strings.ToLower(style)
+	if !strings.HasPrefix(lower, "heading") {
+		return 0
+	}
+	level, err := strconv.Atoi(strings.TrimSpace(lower[len("heading"):]))
+	if err != nil || level < 1 {
+		return 0
+	}
+	return min(level, 6)
+}
+
+// xmlAttr returns the value of the attribute with the given local name
+func xmlAttr(element xml.StartElement, name string) string {
+	for _, attr := range element.Attr {
+		if attr.Name.Local == name {
+			return attr.Value
+		}
+	}
+	return ""
+}
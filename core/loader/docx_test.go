@@ -0,0 +1,64 @@
+package loader
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDOCX creates a DOCX archive with the given document body
+func newTestDOCX(t *testing.T, body string) []byte {
+	var buffer bytes.Buffer
+	archive := zip.NewWriter(&buffer)
+	file, err := archive.Create("word/document.xml")
+	require.NoError(t, err)
+	_, err = file.Write([]byte(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>` + body + `</w:body></w:document>`))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+	return buffer.Bytes()
+}
+
+func TestDOCXLoader(t *testing.T) {
+	t.Run("Paragraphs, headings and lists", func(t *testing.T) {
+		data := newTestDOCX(t, `
+<w:p><w:pPr><w:pStyle w:val="Title"/></w:pPr><w:r><w:t>Report</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="Heading2"/></w:pPr><w:r><w:t>Results</w:t></w:r></w:p>
+<w:p><w:r><w:t xml:space="preserve">Revenue </w:t></w:r><w:r><w:t>grew.</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/><w:numId w:val="1"/></w:numPr></w:pPr><w:r><w:t>First item</w:t></w:r></w:p>
+<w:p></w:p>`)
+
+		doc, err := (&DOCXLoader{}).Load(bytes.NewReader(data))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Report", doc.Title)
+		assert.Equal(t, "# Report\n\n## Results\n\nRevenue grew.\n\n- First item", doc.Content)
+		assert.Equal(t, []model.Hint{
+			{Type: model.HintTypeHeading, Text: "Report", Level: 1, Offset: 0},
+			{Type: model.HintTypeHeading, Text: "Results", Level: 2, Offset: 10},
+		}, doc.Hints)
+		assert.Equal(t, "docx", doc.Metadata["format"])
+	})
+
+	t.Run("Not a zip archive", func(t *testing.T) {
+		doc, err := (&DOCXLoader{}).Load(bytes.NewReader([]byte("plain text")))
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+
+	t.Run("Archive without document", func(t *testing.T) {
+		var buffer bytes.Buffer
+		archive := zip.NewWriter(&buffer)
+		require.NoError(t, archive.Close())
+
+		doc, err := (&DOCXLoader{}).Load(bytes.NewReader(buffer.Bytes()))
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+}
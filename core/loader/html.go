@@ -0,0 +1,155 @@
+package loader
+
+import (
+	"html"
+	"io"
+	"strings"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// htmlSkippedTags are elements whose content is never part of the text
+var htmlSkippedTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "template": true,
+	"svg": true, "iframe": true, "object": true,
+}
+
+// htmlBlockTags are elements that end the current paragraph
+var htmlBlockTags = map[string]bool{
+	"p": true, "div": true, "section": true, "article": true, "main": true,
+	"header": true, "footer": true, "nav": true, "aside": true, "blockquote": true,
+	"pre": true, "table": true, "tr": true, "ul": true, "ol": true, "dl": true,
+	"dt": true, "dd": true, "figure": true, "figcaption": true, "form": true,
+	"hr": true, "body": true, "html": true,
+}
+
+// HTMLLoader extracts the text of HTML documents. Tags are stripped, while headings
+// become Markdown headings, list items become "- " items and block elements become paragraphs.
+// Scripts and styles are skipped, the <title> becomes the document title.
+type HTMLLoader struct{}
+
+// Load reads and converts the HTML content
+func (l *HTMLLoader) Load(r io.Reader) (*model.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	src := string(data)
+
+	builder := &contentBuilder{}
+	var text strings.Builder
+	title := ""
+	inTitle := false
+	headingLevel := 0
+	skipTag := ""
+	preDepth := 0
+
+	// flush ends the current paragraph or heading
+	flush := func() {
+		if headingLevel > 0 {
+			builder.heading(headingLevel, text.String())
+		} else if preDepth > 0 {
+			builder.paragraph(strings.Trim(text.String(), "\n"))
+		} else {
+			content := collapseSpaces(text.String())
+			if content == "-" {
+				// Keep the list marker for a paragraph inside a list item
+				return
+			}
+			builder.paragraph(content)
+		}
+		text.Reset()
+	}
+
+	for i := 0; i < len(src); {
+		if src[i] != '<' {
+			end := strings.IndexByte(src[i:], '<')
+			if end < 0 {
+				end = len(src) - i
+			}
+			chunk := html.UnescapeString(src[i : i+end])
+			switch {
+			case skipTag != "":
+			case inTitle:
+				title += chunk
+			default:
+				text.WriteString(chunk)
+			}
+			i += end
+			continue
+		}
+
+		// Comments and doctype
+		if strings.HasPrefix(src[i:], "<!--") {
+			end := strings.Index(src[i+4:], "-->")
+			if end < 0 {
+				break
+			}
+			i += end + 7
+			continue
+		}
+
+		end := strings.IndexByte(src[i:], '>')
+		if end < 0 {
+			break
+		}
+		name, closing := htmlTagName(src[i+1 : i+end])
+		i += end + 1
+
+		if skipTag != "" {
+			if closing && name == skipTag {
+				skipTag = ""
+			}
+			continue
+		}
+
+		switch {
+		case name == "title":
+			inTitle = !closing
+		case htmlSkippedTags[name] && !closing:
+			skipTag = name
+		case len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6':
+			flush()
+			if closing {
+				headingLevel = 0
+			} else {
+				headingLevel = int(name[1] - '0')
+			}
+		case name == "li":
+			flush()
+			if !closing {
+				text.WriteString("- ")
+			}
+		case name == "br":
+			text.WriteString("\n")
+		case name == "td" || name == "th":
+			text.WriteString(" ")
+		case htmlBlockTags[name]:
+			flush()
+			if name == "pre" {
+				if closing {
+					preDepth = max(preDepth-1, 0)
+				} else {
+					preDepth++
+				}
+			}
+		}
+	}
+	flush()
+
+	doc := builder.document("html")
+	doc.Title = collapseSpaces(title)
+	return doc, nil
+}
+
+// htmlTagName returns the lowercase name of a tag from its inner text (like `a href="x"` or `/p`)
+// and whether it is a closing tag
+func htmlTagName(tag string) (string, bool) {
+	closing := strings.HasPrefix(tag, "/")
+	tag = strings.TrimPrefix(tag, "/")
+	end := strings.IndexAny(tag, " \t\n\r/")
+	if end >= 0 {
+		tag = tag[:end]
+	}
+	return strings.ToLower(tag), closing
+}
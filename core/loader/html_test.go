@@ -0,0 +1,49 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTMLLoader(t *testing.T) {
+	t.Run("Structure is kept", func(t *testing.T) {
+		src := `<!DOCTYPE html>
+<html>
+<head><title>My  Page</title><style>body { color: red; }</style></head>
+<body>
+<!-- navigation -->
+<script>var x = "<p>not text</p>";</script>
+<h1>Main <em>Heading</em></h1>
+<p>First   paragraph
+with a <a href="/link">link</a> &amp; entity.</p>
+<h2 class="sub">Sub</h2>
+<ul><li>One</li><li><p>Two</p></li></ul>
+<pre>code
+  indented</pre>
+</body>
+</html>`
+
+		doc, err := (&HTMLLoader{}).Load(strings.NewReader(src))
+
+		require.NoError(t, err)
+		assert.Equal(t, "My Page", doc.Title)
+		assert.Equal(t, "# Main Heading\n\nFirst paragraph with a link & entity.\n\n## Sub\n\n- One\n\n- Two\n\ncode\n  indented", doc.Content)
+		assert.Equal(t, []model.Hint{
+			{Type: model.HintTypeHeading, Text: "Main Heading", Level: 1, Offset: 0},
+			{Type: model.HintTypeHeading, Text: "Sub", Level: 2, Offset: 55},
+		}, doc.Hints)
+		assert.Equal(t, "html", doc.Metadata["format"])
+	})
+
+	t.Run("Plain text without tags", func(t *testing.T) {
+		doc, err := (&HTMLLoader{}).Load(strings.NewReader("Just text"))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Just text", doc.Content)
+		assert.Empty(t, doc.Hints)
+	})
+}
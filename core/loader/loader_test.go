@@ -0,0 +1,158 @@
+package loader
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForExtension(t *testing.T) {
+	t.Run("Built-in loaders", func(t *testing.T) {
+		for ext, expected := range map[string]Loader{
+			".txt":  &TextLoader{},
+			".md":   &MarkdownLoader{},
+			".HTML": &HTMLLoader{},
+			".pdf":  &PDFLoader{},
+			".docx": &DOCXLoader{},
+		} {
+			loader, ok := ForExtension(ext)
+			assert.True(t, ok, "Expected loader for %s", ext)
+			assert.IsType(t, expected, loader)
+		}
+	})
+
+	t.Run("Unknown extension", func(t *testing.T) {
+		_, ok := ForExtension(".xyz")
+		assert.False(t, ok)
+	})
+}
+
+func TestForMIMEType(t *testing.T) {
+	t.Run("MIME type with parameters", func(t *testing.T) {
+		loader, ok := ForMIMEType("text/html; charset=utf-8")
+		assert.True(t, ok)
+		assert.IsType(t, &HTMLLoader{}, loader)
+	})
+
+	t.Run("Unknown MIME type", func(t *testing.T) {
+		_, ok := ForMIMEType("image/png")
+		assert.False(t, ok)
+	})
+}
+
+func TestRegister(t *testing.T) {
+	t.Run("Register custom loader", func(t *testing.T) {
+		Register(&TextLoader{}, []string{".CUSTOM"}, []string{"text/x-custom"})
+
+		loader, ok := ForExtension(".custom")
+		assert.True(t, ok)
+		assert.IsType(t, &TextLoader{}, loader)
+		_, ok = ForMIMEType("text/x-custom")
+		assert.True(t, ok)
+	})
+}
+
+// noMetadataLoader returns documents without metadata
+type noMetadataLoader struct{}
+
+func (noMetadataLoader) Load(r io.Reader) (*model.Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &model.Document{Content: string(content)}, nil
+}
+
+func TestLoadFile(t *testing.T) {
+	t.Run("Load HTML file", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "page.html")
+		err := os.WriteFile(filePath, []byte("<html><head><title>Page Title</title></head><body><h1>Heading</h1><p>Text</p></body></html>"), 0644)
+		require.NoError(t, err)
+
+		doc, err := LoadFile(filePath, model.Metadata{"author": "Test"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Page Title", doc.Title)
+		assert.Equal(t, filePath, doc.Source)
+		assert.Equal(t, "# Heading\n\nText", doc.Content)
+		assert.Equal(t, "html", doc.Metadata["format"])
+		assert.Equal(t, "Test", doc.Metadata["author"])
+	})
+
+	t.Run("Title defaults to filename", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "notes.txt")
+		err := os.WriteFile(filePath, []byte("Some notes"), 0644)
+		require.NoError(t, err)
+
+		doc, err := LoadFile(filePath, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, "notes", doc.Title)
+		assert.Equal(t, "Some notes", doc.Content)
+	})
+
+	t.Run("Source is the absolute path", func(t *testing.T) {
+		filePath := filepath.Join(t.TempDir(), "notes.txt")
+		err := os.WriteFile(filePath, []byte("Some notes"), 0644)
+		require.NoError(t, err)
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		relativePath, err := filepath.Rel(cwd, filePath)
+		require.NoError(t, err)
+
+		doc, err := LoadFile("."+string(filepath.Separator)+relativePath, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, filePath, doc.Source, "Expected the same source for a relative path")
+	})
+
+	t.Run("Custom loader without metadata", func(t *testing.T) {
+		Register(noMetadataLoader{}, []string{".nometa"}, nil)
+		filePath := filepath.Join(t.TempDir(), "custom.nometa")
+		err := os.WriteFile(filePath, []byte("Custom content"), 0644)
+		require.NoError(t, err)
+
+		doc, err := LoadFile(filePath, model.Metadata{"author": "Test"})
+
+		require.NoError(t, err)
+		assert.Equal(t, "Custom content", doc.Content)
+		assert.Equal(t, "Test", doc.Metadata["author"])
+	})
+
+	t.Run("Unknown extension", func(t *testing.T) {
+		doc, err := LoadFile("image.png", nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+		assert.Contains(t, err.Error(), "no loader")
+	})
+
+	t.Run("Missing file", func(t *testing.T) {
+		doc, err := LoadFile(filepath.Join(t.TempDir(), "missing.txt"), nil)
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Load by MIME type", func(t *testing.T) {
+		doc, err := Load(strings.NewReader("# Title\n\nText"), "text/markdown")
+
+		require.NoError(t, err)
+		assert.Equal(t, "Title", doc.Title)
+	})
+
+	t.Run("Unknown MIME type", func(t *testing.T) {
+		doc, err := Load(strings.NewReader(""), "image/png")
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+}
@@ -0,0 +1,507 @@
+package loader
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+var (
+	pdfObjectPattern = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	pdfStreamPattern = regexp.MustCompile(`>>\s*stream\r?\n`)
+	pdfRefPattern    = regexp.MustCompile(`(\d+)\s+\d+\s+R`)
+	pdfTypePattern   = regexp.MustCompile(`/Type\s*/(\w+)`)
+	pdfInfoPattern   = regexp.MustCompile(`/Info\s+(\d+)\s+\d+\s+R`)
+	pdfFilterPattern = regexp.MustCompile(`/Filter\s*(\[[^\]]*\]|/\w+)`)
+)
+
+// pdfObject is an indirect object with its dictionary (or value) and decoded stream
+type pdfObject struct {
+	dict   string
+	stream []byte
+}
+
+// PDFLoader extracts the text of PDF documents page by page and adds a hint for the start of every page.
+// It reads uncompressed and Flate compressed content streams (including object streams) and
+// decodes strings as PDFDocEncoding or UTF-16. Fonts with custom encodings (like embedded
+// CID fonts without ToUnicode mapping) and encrypted or scanned PDFs are not supported.
+type PDFLoader struct{}
+
+// Load reads the PDF and extracts the text of its pages
+func (l *PDFLoader) Load(r io.Reader) (*model.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a pdf file")
+	}
+	if bytes.Contains(data, []byte("/Encrypt")) {
+		return nil, fmt.Errorf("encrypted pdf files are not supported")
+	}
+
+	objects := parsePDFObjects(data)
+	pages := pdfPages(objects)
+
+	builder := &contentBuilder{}
+	for i, page := range pages {
+		var text strings.Builder
+		for _, ref := range pdfRefs(page.dict, "Contents") {
+			if content, ok := objects[ref]; ok && content.stream != nil {
+				text.WriteString(pdfContentText(content.stream))
+				text.WriteString("\n")
+			}
+		}
+
+		builder.page(i + 1)
+		builder.paragraph(pdfCleanText(text.String()))
+	}
+
+	doc := builder.document("pdf")
+	doc.Metadata["pages"] = len(pages)
+	if matches := pdfInfoPattern.FindAllSubmatch(data, -1); len(matches) > 0 {
+		infoRef, _ := strconv.Atoi(string(matches[len(matches)-1][1]))
+		if info, ok := objects[infoRef]; ok {
+			doc.Title = pdfDictString(info.dict, "Title")
+		}
+	}
+
+	return doc, nil
+}
+
+// parsePDFObjects collects all indirect objects, later definitions (incremental updates) win
+func parsePDFObjects(data []byte) map[int]*pdfObject {
+	objects := make(map[int]*pdfObject)
+	var objectStreams []*pdfObject
+
+	for _, match := range pdfObjectPattern.FindAllSubmatchIndex(data, -1) {
+		number, err := strconv.Atoi(string(data[match[2]:match[3]]))
+		if err != nil {
+			continue
+		}
+		body := data[match[1]:]
+		if end := bytes.Index(body, []byte("endobj")); end >= 0 {
+			body = body[:end]
+		}
+
+		object := &pdfObject{dict: string(body)}
+		if stream := pdfStreamPattern.FindIndex(body); stream != nil {
+			object.dict = string(body[:stream[0]+2])
+			raw := body[stream[1]:]
+			if end := bytes.LastIndex(raw, []byte("endstream")); end >= 0 {
+				raw = raw[:end]
+			}
+			object.stream = pdfDecodeStream(object.dict, bytes.TrimRight(raw, "\r\n"))
+		}
+
+		objects[number] = object
+		if pdfType(object.dict) == "ObjStm" && object.stream != nil {
+			objectStreams = append(objectStreams, object)
+		}
+	}
+
+	// Objects in object streams don't have their own "obj" keyword
+	for _, stream := range objectStreams {
+		count := pdfInt(stream.dict, "N")
+		first := pdfInt(stream.dict, "First")
+		if first <= 0 || first > len(stream.stream) {
+			continue
+		}
+		header := strings.Fields(string(stream.stream[:first]))
+		for i := 0; i+1 < len(header) && i/2 < count; i += 2 {
+			number, errNumber := strconv.Atoi(header[i])
+			offset, errOffset := strconv.Atoi(header[i+1])
+			if errNumber != nil || errOffset != nil || first+offset > len(stream.stream) {
+				continue
+			}
+			end := len(stream.stream)
+			if i+3 < len(header) {
+				if next, err := strconv.Atoi(header[i+3]); err == nil && first+next <= end && next >= offset {
+					end = first + next
+				}
+			}
+			if _, ok := objects[number]; !ok {
+				objects[number] = &pdfObject{dict: string(stream.stream[first+offset : end])}
+			}
+		}
+	}
+
+	return objects
+}
+
+// pdfDecodeStream decodes a stream without filter or with FlateDecode, other filters return nil
+func pdfDecodeStream(dict string, raw []byte) []byte {
+	match := pdfFilterPattern.FindStringSubmatch(dict)
+	if match == nil {
+		return raw
+	}
+	if filters := strings.Fields(strings.Trim(match[1], "[]")); len(filters) != 1 || filters[0] != "/FlateDecode" {
+		return nil
+	}
+
+	reader, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil
+	}
+	defer reader.Close()
+
+	// Keep what could be decoded from truncated or slightly broken streams
+	decoded, _ := io.ReadAll(reader)
+	return decoded
+}
+
+// pdfPages returns the page objects in document order following the page tree,
+// or all page objects by object number if the tree can't be read
+func pdfPages(objects map[int]*pdfObject) []*pdfObject {
+	var pages []*pdfObject
+	visited := make(map[int]bool)
+
+	var walk func(ref int)
+	walk = func(ref int) {
+		object, ok := objects[ref]
+		if !ok || visited[ref] {
+			return
+		}
+		visited[ref] = true
+
+		switch pdfType(object.dict) {
+		case "Pages":
+			for _, kid := range pdfRefs(object.dict, "Kids") {
+				walk(kid)
+			}
+		case "Page":
+			pages = append(pages, object)
+		}
+	}
+
+	for _, object := range objects {
+		if pdfType(object.dict) == "Catalog" {
+			if refs := pdfRefs(object.dict, "Pages"); len(refs) > 0 {
+				walk(refs[0])
+			}
+			break
+		}
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+
+	numbers := make([]int, 0, len(objects))
+	for number, object := range objects {
+		if pdfType(object.dict) == "Page" {
+			numbers = append(numbers, number)
+		}
+	}
+	sort.Ints(numbers)
+	for _, number := range numbers {
+		pages = append(pages, objects[number])
+	}
+	return pages
+}
+
+// pdfType returns the /Type name of a dictionary
+func pdfType(dict string) string {
+	if match := pdfTypePattern.FindStringSubmatch(dict); match != nil {
+		return match[1]
+	}
+	return ""
+}
+
+// pdfRefs returns the object numbers referenced by a key, either as single reference or array
+func pdfRefs(dict string, key string) []int {
+	pattern := regexp.MustCompile(`/` + key + `\s*(\[[^\]]*\]|\d+\s+\d+\s+R)`)
+	match := pattern.FindStringSubmatch(dict)
+	if match == nil {
+		return nil
+	}
+
+	var refs []int
+	for _, ref := range pdfRefPattern.FindAllStringSubmatch(match[1], -1) {
+		if number, err := strconv.Atoi(ref[1]); err == nil {
+			refs = append(refs, number)
+		}
+	}
+	return refs
+}
+
+// pdfInt returns the integer value of a key or 0
+func pdfInt(dict string, key string) int {
+	match := regexp.MustCompile(`/` + key + `\s+(\d+)`).FindStringSubmatch(dict)
+	if match == nil {
+		return 0
+	}
+	value, _ := strconv.Atoi(match[1])
+	return value
+}
+
+// pdfDictString returns the string value of a key, like the /Title of the document info
+func pdfDictString(dict string, key string) string {
+	index := regexp.MustCompile(`/` + key + `\s*[(<]`).FindStringIndex(dict)
+	if index == nil {
+		return ""
+	}
+	start := index[1] - 1
+	var value []byte
+	if dict[start] == '(' {
+		value, _ = pdfLiteralString([]byte(dict), start)
+	} else {
+		value, _ = pdfHexString([]byte(dict), start)
+	}
+	return collapseSpaces(pdfDecodeString(value))
+}
+
+// pdfOperand is a string or number operand of a content stream operator
+type pdfOperand struct {
+	text     []byte
+	number   float64
+	isNumber bool
+}
+
+// pdfContentText extracts the text shown by the text operators of a content stream
+func pdfContentText(content []byte) string {
+	var text strings.Builder
+	var operands []pdfOperand
+	lastY := 0.0
+
+	lastNumbers := func(n int) []float64 {
+		var numbers []float64
+		for _, operand := range operands {
+			if operand.isNumber {
+				numbers = append(numbers, operand.number)
+			}
+		}
+		if len(numbers) < n {
+			return nil
+		}
+		return numbers[len(numbers)-n:]
+	}
+
+	for i := 0; i < len(content); {
+		c := content[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n' || c == '\f' || c == 0:
+			i++
+		case c == '%':
+			for i < len(content) && content[i] != '\n' && content[i] != '\r' {
+				i++
+			}
+		case c == '(':
+			value, next := pdfLiteralString(content, i)
+			operands = append(operands, pdfOperand{text: value})
+			i = next
+		case c == '<' && i+1 < len(content) && content[i+1] == '<':
+			i += 2
+		case c == '<':
+			value, next := pdfHexString(content, i)
+			operands = append(operands, pdfOperand{text: value})
+			i = next
+		case c == '>' || c == '[' || c == ']' || c == '{' || c == '}':
+			i++
+		case c == '/':
+			i++
+			for i < len(content) && !pdfDelimiter(content[i]) {
+				i++
+			}
+		case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+			start := i
+			i++
+			for i < len(content) && (content[i] == '.' || (content[i] >= '0' && content[i] <= '9')) {
+				i++
+			}
+			number, err := strconv.ParseFloat(string(content[start:i]), 64)
+			if err == nil {
+				operands = append(operands, pdfOperand{number: number, isNumber: true})
+			}
+		default:
+			start := i
+			for i < len(content) && !pdfDelimiter(content[i]) {
+				i++
+			}
+			if i == start {
+				i++
+				continue
+			}
+
+			switch string(content[start:i]) {
+			case "Tj":
+				for _, operand := range operands {
+					if !operand.isNumber {
+						text.WriteString(pdfDecodeString(operand.text))
+					}
+				}
+			case "'", "\"":
+				text.WriteString("\n")
+				if len(operands) > 0 && !operands[len(operands)-1].isNumber {
+					text.WriteString(pdfDecodeString(operands[len(operands)-1].text))
+				}
+			case "TJ":
+				for _, operand := range operands {
+					if !operand.isNumber {
+						text.WriteString(pdfDecodeString(operand.text))
+					} else if operand.number < -200 {
+						// Large negative kerning is a word gap
+						text.WriteString(" ")
+					}
+				}
+			case "T*", "ET":
+				text.WriteString("\n")
+			case "Td", "TD":
+				if numbers := lastNumbers(2); numbers != nil && numbers[1] != 0 {
+					text.WriteString("\n")
+				} else {
+					text.WriteString(" ")
+				}
+			case "Tm":
+				if numbers := lastNumbers(6); numbers != nil {
+					if numbers[5] != lastY {
+						text.WriteString("\n")
+					} else {
+						text.WriteString(" ")
+					}
+					lastY = numbers[5]
+				}
+			case "BI":
+				// Skip inline image data
+				if end := bytes.Index(content[i:], []byte("EI")); end >= 0 {
+					i += end + 2
+				} else {
+					i = len(content)
+				}
+			}
+			operands = operands[:0]
+		}
+	}
+
+	return text.String()
+}
+
+// pdfDelimiter reports whether a byte ends a name, number or operator
+func pdfDelimiter(c byte) bool {
+	return strings.IndexByte(" \t\r\n\f\x00()<>[]{}/%", c) >= 0
+}
+
+// pdfLiteralString parses a literal string "(...)" starting at start and returns its bytes
+// and the index after the closing parenthesis
+func pdfLiteralString(data []byte, start int) ([]byte, int) {
+	var value []byte
+	depth := 0
+	i := start
+	for i < len(data) {
+		c := data[i]
+		i++
+		switch {
+		case c == '(':
+			if depth > 0 {
+				value = append(value, c)
+			}
+			depth++
+		case c == ')':
+			depth--
+			if depth == 0 {
+				return value, i
+			}
+			value = append(value, c)
+		case c == '\\' && i < len(data):
+			escaped := data[i]
+			i++
+			switch escaped {
+			case 'n':
+				value = append(value, '\n')
+			case 'r':
+				value = append(value, '\r')
+			case 't':
+				value = append(value, '\t')
+			case 'b':
+				value = append(value, '\b')
+			case 'f':
+				value = append(value, '\f')
+			case '\r':
+				// Line continuation
+				if i < len(data) && data[i] == '\n' {
+					i++
+				}
+			case '\n':
+				// Line continuation
+			default:
+				if escaped >= '0' && escaped <= '7' {
+					octal := int(escaped - '0')
+					for n := 0; n < 2 && i < len(data) && data[i] >= '0' && data[i] <= '7'; n++ {
+						octal = octal*8 + int(data[i]-'0')
+						i++
+					}
+					value = append(value, byte(octal))
+				} else {
+					value = append(value, escaped)
+				}
+			}
+		default:
+			value = append(value, c)
+		}
+	}
+	return value, i
+}
+
+// pdfHexString parses a hex string "<...>" starting at start and returns its bytes
+// and the index after the closing bracket
+func pdfHexString(data []byte, start int) ([]byte, int) {
+	end := bytes.IndexByte(data[start:], '>')
+	if end < 0 {
+		return nil, len(data)
+	}
+
+	var digits []byte
+	for _, c := range data[start+1 : start+end] {
+		if (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F') {
+			digits = append(digits, c)
+		}
+	}
+	if len(digits)%2 == 1 {
+		digits = append(digits, '0')
+	}
+
+	value := make([]byte, len(digits)/2)
+	for i := range value {
+		b, _ := strconv.ParseUint(string(digits[2*i:2*i+2]), 16, 8)
+		value[i] = byte(b)
+	}
+	return value, start + end + 1
+}
+
+// pdfDecodeString decodes UTF-16 strings with byte order mark and PDFDocEncoding (as Latin-1) otherwise
+func pdfDecodeString(value []byte) string {
+	if len(value) >= 2 && value[0] == 0xFE && value[1] == 0xFF {
+		units := make([]uint16, 0, len(value)/2)
+		for i := 2; i+1 < len(value); i += 2 {
+			units = append(units, uint16(value[i])<<8|uint16(value[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	runes := make([]rune, 0, len(value))
+	for _, b := range value {
+		if b < 0x20 && b != '\t' && b != '\n' {
+			continue
+		}
+		runes = append(runes, rune(b))
+	}
+	return string(runes)
+}
+
+// pdfCleanText collapses the spaces of every line and removes empty lines
+func pdfCleanText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = collapseSpaces(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,101 @@
+package loader
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPDF creates a PDF with one page per content stream, compressed streams use FlateDecode
+func newTestPDF(t *testing.T, compress bool, title string, contents ...string) []byte {
+	var buffer bytes.Buffer
+	buffer.WriteString("%PDF-1.4\n")
+
+	kids := make([]string, len(contents))
+	for i := range contents {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	fmt.Fprintf(&buffer, "1 0 obj\n<< /Type /Catalog /Pages 2 0 R >>\nendobj\n")
+	fmt.Fprintf(&buffer, "2 0 obj\n<< /Type /Pages /Kids [%s] /Count %d >>\nendobj\n", strings.Join(kids, " "), len(contents))
+	fmt.Fprintf(&buffer, "3 0 obj\n<< /Title (%s) >>\nendobj\n", title)
+
+	for i, content := range contents {
+		stream := []byte(content)
+		filter := ""
+		if compress {
+			var compressed bytes.Buffer
+			writer := zlib.NewWriter(&compressed)
+			_, err := writer.Write(stream)
+			require.NoError(t, err)
+			require.NoError(t, writer.Close())
+			stream = compressed.Bytes()
+			filter = " /Filter /FlateDecode"
+		}
+		fmt.Fprintf(&buffer, "%d 0 obj\n<< /Type /Page /Parent 2 0 R /Contents %d 0 R >>\nendobj\n", 4+2*i, 5+2*i)
+		fmt.Fprintf(&buffer, "%d 0 obj\n<< /Length %d%s >>\nstream\n", 5+2*i, len(stream), filter)
+		buffer.Write(stream)
+		buffer.WriteString("\nendstream\nendobj\n")
+	}
+
+	buffer.WriteString("trailer\n<< /Root 1 0 R /Info 3 0 R >>\n%%EOF\n")
+	return buffer.Bytes()
+}
+
+func TestPDFLoader(t *testing.T) {
+	t.Run("Text of every page with page hints", func(t *testing.T) {
+		data := newTestPDF(t, false, "Test Title",
+			"BT /F1 12 Tf 72 720 Td (Hello World) Tj 0 -14 Td (Second line) Tj ET",
+			"BT /F1 12 Tf 72 720 Td [(Ker)-50(ning)-300(works)] TJ ET",
+		)
+
+		doc, err := (&PDFLoader{}).Load(bytes.NewReader(data))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Test Title", doc.Title)
+		assert.Equal(t, "Hello World\nSecond line\n\nKerning works", doc.Content)
+		assert.Equal(t, []model.Hint{
+			{Type: model.HintTypePage, Page: 1, Offset: 0},
+			{Type: model.HintTypePage, Page: 2, Offset: 25},
+		}, doc.Hints)
+		assert.Equal(t, 2, doc.Metadata["pages"])
+		assert.Equal(t, "pdf", doc.Metadata["format"])
+	})
+
+	t.Run("Compressed content streams", func(t *testing.T) {
+		data := newTestPDF(t, true, "Compressed", "BT (Compressed text) Tj ET")
+
+		doc, err := (&PDFLoader{}).Load(bytes.NewReader(data))
+
+		require.NoError(t, err)
+		assert.Equal(t, "Compressed text", doc.Content)
+	})
+
+	t.Run("String escapes and hex strings", func(t *testing.T) {
+		data := newTestPDF(t, false, "Escapes", `BT (a \(b\) c\\d \101) Tj <48656C6C6F> Tj <FEFF00E4> Tj ET`)
+
+		doc, err := (&PDFLoader{}).Load(bytes.NewReader(data))
+
+		require.NoError(t, err)
+		assert.Equal(t, `a (b) c\d AHelloä`, doc.Content)
+	})
+
+	t.Run("Not a PDF", func(t *testing.T) {
+		doc, err := (&PDFLoader{}).Load(strings.NewReader("plain text"))
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+
+	t.Run("Encrypted PDF", func(t *testing.T) {
+		doc, err := (&PDFLoader{}).Load(strings.NewReader("%PDF-1.4\ntrailer << /Encrypt 5 0 R >>"))
+
+		assert.Error(t, err)
+		assert.Nil(t, doc)
+	})
+}
@@ -0,0 +1,73 @@
+package loader
+
+import (
+	"io"
+	"strings"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// TextLoader loads plain text as is
+type TextLoader struct{}
+
+// Load reads the text content
+func (l *TextLoader) Load(r io.Reader) (*model.Document, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Document{
+		Content:  string(content),
+		Metadata: model.Metadata{"format": "text"},
+	}, nil
+}
+
+// MarkdownLoader loads Markdown as is and adds a hint for every ATX heading (like "## Title").
+// The first top level heading becomes the title.
+type MarkdownLoader struct{}
+
+// Load reads the Markdown content and collects the headings
+func (l *MarkdownLoader) Load(r io.Reader) (*model.Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	content := string(data)
+
+	doc := &model.Document{
+		Content:  content,
+		Metadata: model.Metadata{"format": "markdown"},
+	}
+
+	inFence := false
+	offset := 0
+	for _, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			inFence = !inFence
+		case !inFence && !strings.HasPrefix(line, "    ") && strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			text := strings.TrimSpace(trimmed[level:])
+			if level <= 6 && (len(trimmed) == level || trimmed[level] == ' ' || trimmed[level] == '\t') && text != "" {
+				// Remove an optional closing sequence like in "## Title ##"
+				if closing := strings.TrimRight(text, "#"); strings.HasSuffix(closing, " ") {
+					text = strings.TrimSpace(closing)
+				}
+				doc.Hints = append(doc.Hints, model.Hint{
+					Type:   model.HintTypeHeading,
+					Text:   text,
+					Level:  level,
+					Offset: offset,
+				})
+				if level == 1 && doc.Title == "" {
+					doc.Title = text
+				}
+			}
+		}
+		offset += len(line)
+	}
+
+	return doc, nil
+}
@@ -0,0 +1,52 @@
+package loader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTextLoader(t *testing.T) {
+	t.Run("Content is kept as is", func(t *testing.T) {
+		content := "Line one.\n\nLine two."
+
+		doc, err := (&TextLoader{}).Load(strings.NewReader(content))
+
+		require.NoError(t, err)
+		assert.Equal(t, content, doc.Content)
+		assert.Empty(t, doc.Hints)
+		assert.Equal(t, "text", doc.Metadata["format"])
+	})
+}
+
+func TestMarkdownLoader(t *testing.T) {
+	t.Run("Headings become hints", func(t *testing.T) {
+		content := "# Title\n\nIntro.\n\n## Section ##\n\nText.\n"
+
+		doc, err := (&MarkdownLoader{}).Load(strings.NewReader(content))
+
+		require.NoError(t, err)
+		assert.Equal(t, content, doc.Content)
+		assert.Equal(t, "Title", doc.Title)
+		assert.Equal(t, []model.Hint{
+			{Type: model.HintTypeHeading, Text: "Title", Level: 1, Offset: 0},
+			{Type: model.HintTypeHeading, Text: "Section", Level: 2, Offset: 17},
+		}, doc.Hints)
+		assert.Equal(t, "## Section ##", content[17:30])
+	})
+
+	t.Run("Headings in code blocks and hashtags are ignored", func(t *testing.T) {
+		content := "```sh\n# comment\n```\n#hashtag\n### C#\n"
+
+		doc, err := (&MarkdownLoader{}).Load(strings.NewReader(content))
+
+		require.NoError(t, err)
+		require.Len(t, doc.Hints, 1)
+		assert.Equal(t, "C#", doc.Hints[0].Text)
+		assert.Equal(t, 3, doc.Hints[0].Level)
+		assert.Equal(t, "", doc.Title, "Expected no title without top level heading")
+	})
+}
@@ -0,0 +1,161 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+)
+
+// DefaultBatchSize is the number of texts embedded at once if Pipeline.BatchSize is not set
+const DefaultBatchSize = 32
+
+// DefaultExtractionWorkers is the number of chunks extracted in parallel if Pipeline.ExtractionWorkers is not set.
+// Custom extractors aren't required to be safe for concurrent use, so chunks are extracted one by one by default.
+const DefaultExtractionWorkers = 1
+
+// BatchEmbedderFrom adapts an EmbedFunc to a BatchEmbedFunc that embeds the texts one by one
+func BatchEmbedderFrom(embedder EmbedFunc) BatchEmbedFunc {
+	return func(texts []string) ([][]float32, error) {
+		embeddings := make([][]float32, len(texts))
+		for i, text := range texts {
+			embedding, err := embedder(text)
+			if err != nil {
+				return nil, err
+			}
+			embeddings[i] = embedding
+		}
+		return embeddings, nil
+	}
+}
+
+// EmbedderFromBatch adapts a BatchEmbedFunc to an EmbedFunc, e.g. to embed queries with the same model
+func EmbedderFromBatch(embedder BatchEmbedFunc) EmbedFunc {
+	return func(text string) ([]float32, error) {
+		embeddings, err := embedder([]string{text})
+		if err != nil {
+			return nil, err
+		}
+		if len(embeddings) != 1 {
+			return nil, fmt.Errorf("expected 1 embedding, got %d", len(embeddings))
+		}
+		return embeddings[0], nil
+	}
+}
+
+// EmbedBatch embeds the texts in batches of BatchSize with the BatchEmbedder,
+// or with the Embedder if no batch embedder is set. Returns one embedding per text.
+func (p *Pipeline) EmbedBatch(texts []string) ([][]float32, error) {
+	return p.EmbedBatchCtx(context.Background(), texts)
+}
+
+// EmbedBatchCtx is EmbedBatch with a context that is checked before every batch
+func (p *Pipeline) EmbedBatchCtx(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return [][]float32{}, nil
+	}
+
+	embedder := p.BatchEmbedder
+	if embedder == nil {
+		if p.Embedder == nil {
+			return nil, fmt.Errorf("no embedder set")
+		}
+		embedder = BatchEmbedderFrom(p.Embedder)
+	}
+
+	batchSize := p.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += batchSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		batch := texts[start:min(start+batchSize, len(texts))]
+		batchEmbeddings, err := embedder(batch)
+		if err != nil {
+			return nil, err
+		}
+		if len(batchEmbeddings) != len(batch) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(batch), len(batchEmbeddings))
+		}
+		embeddings = append(embeddings, batchEmbeddings...)
+	}
+	return embeddings, nil
+}
+
+// chunkExtraction holds the entities and relations extracted from one chunk
+type chunkExtraction struct {
+	entities  []*model.Entity
+	relations []*model.Edge
+}
+
+// extractChunks runs the entity and relation extractors on the chunks with a bounded worker pool.
+// Returns the extractions in chunk order. Extractor errors skip the extraction of the chunk,
+// chunks that didn't start before the context was canceled are skipped too.
+func (p *Pipeline) extractChunks(ctx context.Context, chunksWithPath []ChunkWithPath) []chunkExtraction {
+	extractions := make([]chunkExtraction, len(chunksWithPath))
+	if p.EntityExtractor == nil && p.RelationExtractor == nil {
+		return extractions
+	}
+
+	workers := p.ExtractionWorkers
+	if workers <= 0 {
+		workers = DefaultExtractionWorkers
+	}
+	workers = min(workers, max(len(chunksWithPath), 1))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue
+				}
+				extractions[i] = p.extractChunk(chunksWithPath[i])
+			}
+		}()
+	}
+	for i := range chunksWithPath {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return extractions
+}
+
+// extractChunk extracts the entities of a chunk and the relations between them
+func (p *Pipeline) extractChunk(cwp ChunkWithPath) chunkExtraction {
+	var extraction chunkExtraction
+
+	// Summaries only repeat their section
+	if p.EntityExtractor != nil && cwp.Metadata["chunk_type"] != ChunkTypeSectionSummary {
+		entities, err := p.EntityExtractor(cwp.Content)
+		if err == nil && entities != nil {
+			// The edges reference the entities by ID until they are remapped to the stored entities
+			for _, entity := range entities {
+				if entity.ID == uuid.Nil {
+					entity.ID = uuid.New()
+				}
+			}
+			extraction.entities = entities
+		}
+	}
+
+	if p.RelationExtractor != nil {
+		relations, err := p.RelationExtractor(cwp.Content, cwp.Path, extraction.entities)
+		if err == nil && relations != nil {
+			extraction.relations = relations
+		}
+	}
+
+	return extraction
+}
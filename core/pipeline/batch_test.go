@@ -0,0 +1,210 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lengthEmbedder embeds a text as its length, recording the size of every batch
+type lengthEmbedder struct {
+	batches []int
+}
+
+func (e *lengthEmbedder) embed(texts []string) ([][]float32, error) {
+	e.batches = append(e.batches, len(texts))
+	embeddings := make([][]float32, len(texts))
+	for i, text := range texts {
+		embeddings[i] = []float32{float32(len(text))}
+	}
+	return embeddings, nil
+}
+
+func TestBatchEmbedderAdapters(t *testing.T) {
+	t.Run("Batch embedder from embedder", func(t *testing.T) {
+		embeddings, err := BatchEmbedderFrom(mockEmbedFunc)([]string{"a", "b"})
+
+		require.NoError(t, err)
+		assert.Len(t, embeddings, 2)
+	})
+
+	t.Run("Batch embedder from embedder with error", func(t *testing.T) {
+		_, err := BatchEmbedderFrom(mockEmbedFunc)([]string{"a", ""})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Embedder from batch embedder", func(t *testing.T) {
+		embedder := &lengthEmbedder{}
+
+		embedding, err := EmbedderFromBatch(embedder.embed)("text")
+
+		require.NoError(t, err)
+		assert.Equal(t, []float32{4}, embedding)
+		assert.Equal(t, []int{1}, embedder.batches)
+	})
+}
+
+func TestPipelineEmbedBatch(t *testing.T) {
+	t.Run("Texts are embedded in batches", func(t *testing.T) {
+		embedder := &lengthEmbedder{}
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetBatchEmbedder(embedder.embed, 2)
+
+		embeddings, err := pipeline.EmbedBatch([]string{"a", "bb", "ccc", "dddd", "eeeee"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []int{2, 2, 1}, embedder.batches)
+		require.Len(t, embeddings, 5)
+		for i, embedding := range embeddings {
+			assert.Equal(t, []float32{float32(i + 1)}, embedding, "Expected embeddings in input order")
+		}
+	})
+
+	t.Run("Embedder is adapted without batch embedder", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		embeddings, err := pipeline.EmbedBatch([]string{"a", "b", "c"})
+
+		require.NoError(t, err)
+		assert.Len(t, embeddings, 3)
+	})
+
+	t.Run("Error when batch embedder returns wrong count", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetBatchEmbedder(func(texts []string) ([][]float32, error) {
+			return [][]float32{{1}}, nil
+		}, 0)
+
+		_, err := pipeline.EmbedBatch([]string{"a", "b"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "expected 2 embeddings")
+	})
+
+	t.Run("Error without embedder", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, nil)
+
+		_, err := pipeline.EmbedBatch([]string{"a"})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Process uses the batch embedder", func(t *testing.T) {
+		embedder := &lengthEmbedder{}
+		pipeline := NewPipeline(mockChunkFunc, func(text string) ([]float32, error) {
+			return nil, errors.New("single embedder must not be called")
+		})
+		pipeline.SetBatchEmbedder(embedder.embed, 10)
+
+		chunks, err := pipeline.Process("text", "doc")
+
+		require.NoError(t, err)
+		assert.Len(t, chunks, 2)
+		assert.Equal(t, []int{2}, embedder.batches, "Expected both chunks in one batch")
+	})
+
+	t.Run("Canceled context stops before the next batch", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		embedder := &lengthEmbedder{}
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetBatchEmbedder(func(texts []string) ([][]float32, error) {
+			cancel()
+			return embedder.embed(texts)
+		}, 2)
+
+		_, err := pipeline.EmbedBatchCtx(ctx, []string{"a", "bb", "ccc", "dddd"})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []int{2}, embedder.batches, "Expected only the first batch to be embedded")
+	})
+}
+
+func TestPipelineExtractChunks(t *testing.T) {
+	chunks := make([]ChunkWithPath, 10)
+	for i := range chunks {
+		chunks[i] = ChunkWithPath{Content: fmt.Sprintf("Entity%d", i), Path: fmt.Sprintf("doc.chunk%d", i)}
+	}
+
+	t.Run("Extraction is bounded and keeps the chunk order", func(t *testing.T) {
+		var mu sync.Mutex
+		running := 0
+		maxRunning := 0
+
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetExtractionWorkers(3)
+		pipeline.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+			mu.Lock()
+			running++
+			maxRunning = max(maxRunning, running)
+			mu.Unlock()
+
+			time.Sleep(5 * time.Millisecond)
+
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return []*model.Entity{{Name: text, Type: "TEST"}}, nil
+		})
+
+		result, err := pipeline.ProcessChunks(chunks)
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, maxRunning, 3, "Expected at most 3 concurrent extractions")
+		assert.Greater(t, maxRunning, 1, "Expected concurrent extractions")
+		require.Len(t, result.Entities, 10)
+		for i, entity := range result.Entities {
+			assert.Equal(t, fmt.Sprintf("Entity%d", i), entity.Name, "Expected entities in chunk order")
+			assert.NotEmpty(t, entity.Embedding, "Expected entity to be embedded")
+		}
+	})
+
+	t.Run("Relation extractor gets the entities of its chunk", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+			return []*model.Entity{{Name: text, Type: "TEST"}}, nil
+		})
+		pipeline.SetRelationExtractor(func(text string, chunkID string, entities []*model.Entity) ([]*model.Edge, error) {
+			if len(entities) != 1 || entities[0].Name != text {
+				return nil, errors.New("unexpected entities")
+			}
+			return []*model.Edge{{EdgeType: model.EdgeTypeReference, Metadata: model.Metadata{"chunk": chunkID}}}, nil
+		})
+
+		result, err := pipeline.ProcessChunks(chunks)
+
+		require.NoError(t, err)
+		references := 0
+		for _, edge := range result.Relations {
+			if edge.EdgeType == model.EdgeTypeReference {
+				references++
+			}
+		}
+		assert.Equal(t, 10, references, "Expected one relation per chunk")
+	})
+	t.Run("Entities without ID get an ID for their edges", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+			return []*model.Entity{{Name: text, Type: "TEST"}}, nil
+		})
+
+		result, err := pipeline.ProcessChunks(chunks[:1])
+
+		require.NoError(t, err)
+		require.Len(t, result.Entities, 1)
+		require.Len(t, result.Relations, 1)
+		entity := result.Entities[0]
+		assert.NotEqual(t, uuid.Nil, entity.ID, "Expected entity to get an ID")
+		assert.Equal(t, entity.ID, *result.Relations[0].TargetEntityID, "Expected mention edge to the entity")
+		assert.NotSame(t, &entity.ID, result.Relations[0].TargetEntityID, "Expected edge to hold a copy of the ID")
+	})
+}
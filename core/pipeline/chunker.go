@@ -4,9 +4,6 @@ import (
 	"fmt"
 	"math"
 	"strings"
-
-	"github.com/knights-analytics/hugot"
-	"github.com/siherrmann/grapher/helper"
 )
 
 // SentenceChunker creates a chunker that splits by sentences
@@ -144,32 +141,18 @@ func cosineSimilarity(a, b []float32) float32 {
 // DefaultChunker creates a semantic chunker that uses embeddings to identify natural boundaries
 // It analyzes semantic similarity between sentences and creates chunks at points where similarity drops
 func DefaultChunker(maxChunkSize int, similarityThreshold float32) ChunkFunc {
+	return DefaultModels.Chunker(maxChunkSize, similarityThreshold)
+}
+
+// Chunker creates a semantic chunker like DefaultChunker with the all-MiniLM-L6-v2 model of the registry.
+// The model is loaded on the first call and reused for every following document.
+func (r *ModelRegistry) Chunker(maxChunkSize int, similarityThreshold float32) ChunkFunc {
 	return func(text string, basePath string) ([]ChunkWithPath, error) {
-		// Prepare model (download if needed)
-		modelName := "sentence-transformers/all-MiniLM-L6-v2"
-		modelPath, err := helper.PrepareModel(modelName, "onnx/model.onnx")
+		sentencePipeline, err := r.sentencePipeline()
 		if err != nil {
 			return nil, err
 		}
 
-		// Initialize hugot session with Go backend
-		session, err := hugot.NewGoSession()
-		if err != nil {
-			return nil, fmt.Errorf("failed to create hugot session: %w", err)
-		}
-		defer session.Destroy()
-
-		// Create sentence transformers pipeline configuration
-		config := hugot.FeatureExtractionConfig{
-			ModelPath:    modelPath,
-			Name:         "semantic-chunker-pipeline",
-			OnnxFilename: "onnx/model.onnx",
-		}
-		sentencePipeline, err := hugot.NewPipeline(session, config)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create sentence pipeline: %w", err)
-		}
-
 		// Split text into sentences
 		text = strings.ReplaceAll(text, "! ", "!|")
 		text = strings.ReplaceAll(text, "? ", "?|")
@@ -189,7 +172,12 @@ func DefaultChunker(maxChunkSize int, similarityThreshold float32) ChunkFunc {
 		}
 
 		// Get embeddings for all sentences
+		unlock, err := r.lockPipeline(SentenceModelName)
+		if err != nil {
+			return nil, err
+		}
 		embeddingResult, err := sentencePipeline.RunPipeline(cleanSentences)
+		unlock()
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate embeddings: %w", err)
 		}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+)
+
+// Limits of the default community summary
+const (
+	summaryMaxEntities  = 10
+	summaryMaxRelations = 5
+)
+
+// DefaultCommunitySummarizer creates an extractive community summarizer that doesn't need a model.
+// It lists the member types, the most connected members and the strongest relations between them,
+// e.g. "Community of 3 entities (2 PER, 1 ORG): Alice (PER), Bob (PER) and Acme (ORG). Strongest relations: Alice - Bob, Bob - Acme."
+// Set a summarizer calling a language model with SetCommunitySummarizer for abstractive summaries.
+func DefaultCommunitySummarizer() CommunitySummarizeFunc {
+	return func(entities []*model.Entity, relations []*model.Edge) (string, error) {
+		if len(entities) == 0 {
+			return "", fmt.Errorf("community has no entities")
+		}
+
+		// Count the members per type, most frequent first
+		typeCounts := make(map[string]int)
+		var types []string
+		for _, entity := range entities {
+			if typeCounts[entity.Type] == 0 {
+				types = append(types, entity.Type)
+			}
+			typeCounts[entity.Type]++
+		}
+		sort.SliceStable(types, func(i, j int) bool {
+			return typeCounts[types[i]] > typeCounts[types[j]]
+		})
+		typeParts := make([]string, len(types))
+		for i, entityType := range types {
+			typeParts[i] = fmt.Sprintf("%d %s", typeCounts[entityType], entityType)
+		}
+
+		// List the most connected members
+		names := make([]string, 0, summaryMaxEntities)
+		for _, entity := range entities[:min(len(entities), summaryMaxEntities)] {
+			names = append(names, fmt.Sprintf("%s (%s)", entity.Name, entity.Type))
+		}
+		if len(entities) > summaryMaxEntities {
+			names = append(names, fmt.Sprintf("%d more", len(entities)-summaryMaxEntities))
+		}
+
+		var summary strings.Builder
+		fmt.Fprintf(&summary, "Community of %d entities (%s): %s.", len(entities), strings.Join(typeParts, ", "), joinNames(names))
+
+		// List the strongest relations between members
+		entityNames := make(map[uuid.UUID]string, len(entities))
+		for _, entity := range entities {
+			entityNames[entity.ID] = entity.Name
+		}
+
+		strongest := append([]*model.Edge{}, relations...)
+		sort.SliceStable(strongest, func(i, j int) bool {
+			return strongest[i].Weight > strongest[j].Weight
+		})
+
+		var relationParts []string
+		seen := make(map[string]bool)
+		for _, relation := range strongest {
+			if len(relationParts) == summaryMaxRelations {
+				break
+			}
+			if relation.SourceEntityID == nil || relation.TargetEntityID == nil {
+				continue
+			}
+			source, okSource := entityNames[*relation.SourceEntityID]
+			target, okTarget := entityNames[*relation.TargetEntityID]
+			if !okSource || !okTarget {
+				continue
+			}
+
+			// Entities co-occurring in several chunks are listed once
+			part := source + " - " + target
+			if seen[part] || seen[target+" - "+source] {
+				continue
+			}
+			seen[part] = true
+			relationParts = append(relationParts, part)
+		}
+		if len(relationParts) > 0 {
+			fmt.Fprintf(&summary, " Strongest relations: %s.", strings.Join(relationParts, ", "))
+		}
+
+		return summary.String(), nil
+	}
+}
+
+// joinNames joins names as an English list ("a, b and c")
+func joinNames(names []string) string {
+	if len(names) <= 1 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
@@ -0,0 +1,66 @@
+package pipeline
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultCommunitySummarizer(t *testing.T) {
+	summarizer := DefaultCommunitySummarizer()
+	require.NotNil(t, summarizer)
+
+	alice := &model.Entity{ID: uuid.New(), Name: "Alice", Type: "PER"}
+	bob := &model.Entity{ID: uuid.New(), Name: "Bob", Type: "PER"}
+	acme := &model.Entity{ID: uuid.New(), Name: "Acme", Type: "ORG"}
+
+	relation := func(source *model.Entity, target *model.Entity, weight float64) *model.Edge {
+		return &model.Edge{
+			SourceEntityID: &source.ID,
+			TargetEntityID: &target.ID,
+			EdgeType:       model.EdgeTypeEntityMention,
+			Weight:         weight,
+			Bidirectional:  true,
+		}
+	}
+
+	t.Run("Summarize members and relations", func(t *testing.T) {
+		summary, err := summarizer(
+			[]*model.Entity{alice, bob, acme},
+			[]*model.Edge{relation(bob, acme, 0.6), relation(alice, bob, 0.9), relation(bob, alice, 0.8)},
+		)
+
+		assert.NoError(t, err, "Expected summarizer to not return an error")
+		assert.Equal(t, "Community of 3 entities (2 PER, 1 ORG): Alice (PER), Bob (PER) and Acme (ORG). Strongest relations: Alice - Bob, Bob - Acme.", summary)
+	})
+
+	t.Run("Summarize without relations", func(t *testing.T) {
+		summary, err := summarizer([]*model.Entity{acme}, nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Community of 1 entities (1 ORG): Acme (ORG).", summary)
+	})
+
+	t.Run("Large communities are shortened", func(t *testing.T) {
+		var entities []*model.Entity
+		for i := 0; i < 12; i++ {
+			entities = append(entities, &model.Entity{ID: uuid.New(), Name: fmt.Sprintf("Entity %d", i), Type: "MISC"})
+		}
+
+		summary, err := summarizer(entities, nil)
+
+		assert.NoError(t, err)
+		assert.Contains(t, summary, "Entity 9 (MISC) and 2 more.")
+		assert.NotContains(t, summary, "Entity 10")
+	})
+
+	t.Run("Empty community", func(t *testing.T) {
+		_, err := summarizer(nil, nil)
+
+		assert.Error(t, err)
+	})
+}
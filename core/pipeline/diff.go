@@ -0,0 +1,113 @@
+package pipeline
+
+import (
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// ChunkMatch pairs a new chunk with the stored chunk that has the same content
+type ChunkMatch struct {
+	Stored *model.Chunk
+	New    ChunkWithPath
+}
+
+// ChunkDiff is the result of matching the new chunks of a document against the stored ones
+type ChunkDiff struct {
+	Matched []ChunkMatch    // Unchanged content, the stored chunk is kept
+	Added   []ChunkWithPath // New or changed content that has to be embedded
+	Removed []*model.Chunk  // Stored chunks without a match
+}
+
+// DiffChunks matches new chunks to stored chunks by the hash of their content.
+// If several stored chunks have the same content (e.g. repeated boilerplate), a chunk
+// at the same path is preferred, otherwise the one with the closest chunk index.
+// Every stored chunk is matched at most once.
+func DiffChunks(stored []*model.Chunk, chunks []ChunkWithPath) *ChunkDiff {
+	byHash := make(map[string][]int)
+	for i, chunk := range stored {
+		hash := helper.HashContent(chunk.Content)
+		byHash[hash] = append(byHash[hash], i)
+	}
+
+	hashes := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		hashes[i] = helper.HashContent(chunk.Content)
+	}
+
+	matchedStored := make([]bool, len(stored))
+	matches := make([]int, len(chunks))
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	// Same content at the same path
+	for i, chunk := range chunks {
+		for _, j := range byHash[hashes[i]] {
+			if !matchedStored[j] && stored[j].Path == chunk.Path {
+				matches[i] = j
+				matchedStored[j] = true
+				break
+			}
+		}
+	}
+
+	// Same content at the closest position
+	for i := range chunks {
+		if matches[i] >= 0 {
+			continue
+		}
+		best := -1
+		for _, j := range byHash[hashes[i]] {
+			if matchedStored[j] {
+				continue
+			}
+			if best < 0 || abs(storedIndex(stored, j)-newIndex(chunks, i)) < abs(storedIndex(stored, best)-newIndex(chunks, i)) {
+				best = j
+			}
+		}
+		if best >= 0 {
+			matches[i] = best
+			matchedStored[best] = true
+		}
+	}
+
+	diff := &ChunkDiff{}
+	for i, chunk := range chunks {
+		if matches[i] >= 0 {
+			diff.Matched = append(diff.Matched, ChunkMatch{Stored: stored[matches[i]], New: chunk})
+		} else {
+			diff.Added = append(diff.Added, chunk)
+		}
+	}
+	for j, chunk := range stored {
+		if !matchedStored[j] {
+			diff.Removed = append(diff.Removed, chunk)
+		}
+	}
+
+	return diff
+}
+
+// storedIndex returns the chunk index of a stored chunk, falling back to its position in the slice
+func storedIndex(stored []*model.Chunk, i int) int {
+	if stored[i].ChunkIndex != nil {
+		return *stored[i].ChunkIndex
+	}
+	return i
+}
+
+// newIndex returns the chunk index of a new chunk, falling back to its position in the slice
+func newIndex(chunks []ChunkWithPath, i int) int {
+	if chunks[i].ChunkIndex != nil {
+		return *chunks[i].ChunkIndex
+	}
+	return i
+}
+
+// abs returns the absolute value of an int
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffChunks(t *testing.T) {
+	stored := []*model.Chunk{
+		{Content: "First paragraph.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+		{Content: "Second paragraph.", Path: "doc.chunk1", ChunkIndex: intPtr(1)},
+		{Content: "Third paragraph.", Path: "doc.chunk2", ChunkIndex: intPtr(2)},
+	}
+
+	t.Run("Unchanged chunks are matched", func(t *testing.T) {
+		diff := DiffChunks(stored, []ChunkWithPath{
+			{Content: "First paragraph.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+			{Content: "Second paragraph.", Path: "doc.chunk1", ChunkIndex: intPtr(1)},
+			{Content: "Third paragraph.", Path: "doc.chunk2", ChunkIndex: intPtr(2)},
+		})
+
+		assert.Len(t, diff.Matched, 3)
+		assert.Empty(t, diff.Added)
+		assert.Empty(t, diff.Removed)
+	})
+
+	t.Run("Changed chunk is added and the old one removed", func(t *testing.T) {
+		diff := DiffChunks(stored, []ChunkWithPath{
+			{Content: "First paragraph.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+			{Content: "Second paragraph, edited.", Path: "doc.chunk1", ChunkIndex: intPtr(1)},
+			{Content: "Third paragraph.", Path: "doc.chunk2", ChunkIndex: intPtr(2)},
+		})
+
+		require.Len(t, diff.Matched, 2)
+		require.Len(t, diff.Added, 1)
+		require.Len(t, diff.Removed, 1)
+		assert.Equal(t, "Second paragraph, edited.", diff.Added[0].Content)
+		assert.Same(t, stored[1], diff.Removed[0])
+	})
+
+	t.Run("Moved chunk keeps the stored chunk", func(t *testing.T) {
+		diff := DiffChunks(stored, []ChunkWithPath{
+			{Content: "Inserted paragraph.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+			{Content: "First paragraph.", Path: "doc.chunk1", ChunkIndex: intPtr(1)},
+			{Content: "Second paragraph.", Path: "doc.chunk2", ChunkIndex: intPtr(2)},
+			{Content: "Third paragraph.", Path: "doc.chunk3", ChunkIndex: intPtr(3)},
+		})
+
+		require.Len(t, diff.Matched, 3)
+		assert.Same(t, stored[0], diff.Matched[0].Stored)
+		assert.Equal(t, "doc.chunk1", diff.Matched[0].New.Path)
+		require.Len(t, diff.Added, 1)
+		assert.Equal(t, "Inserted paragraph.", diff.Added[0].Content)
+		assert.Empty(t, diff.Removed)
+	})
+
+	t.Run("Duplicate content prefers the same path, then the closest index", func(t *testing.T) {
+		duplicates := []*model.Chunk{
+			{Content: "Repeated.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+			{Content: "Repeated.", Path: "doc.chunk5", ChunkIndex: intPtr(5)},
+		}
+
+		diff := DiffChunks(duplicates, []ChunkWithPath{
+			{Content: "Repeated.", Path: "doc.chunk4", ChunkIndex: intPtr(4)},
+			{Content: "Repeated.", Path: "doc.chunk0", ChunkIndex: intPtr(0)},
+		})
+
+		require.Len(t, diff.Matched, 2)
+		assert.Same(t, duplicates[1], diff.Matched[0].Stored, "Expected the closest stored chunk")
+		assert.Same(t, duplicates[0], diff.Matched[1].Stored, "Expected the stored chunk at the same path")
+		assert.Empty(t, diff.Removed)
+	})
+
+	t.Run("No stored chunks", func(t *testing.T) {
+		diff := DiffChunks(nil, []ChunkWithPath{{Content: "New.", Path: "doc.chunk0"}})
+
+		assert.Empty(t, diff.Matched)
+		assert.Len(t, diff.Added, 1)
+	})
+}
@@ -1,45 +1,38 @@
 package pipeline
 
-import (
-	"fmt"
-
-	"github.com/knights-analytics/hugot"
-	"github.com/siherrmann/grapher/helper"
-)
+import "fmt"
 
 // DefaultEmbedder creates an embedder using a real sentence transformer model
 // Uses the all-MiniLM-L6-v2 model which produces 384-dimensional embeddings
 func DefaultEmbedder() (EmbedFunc, error) {
-	// Prepare model (download if needed)
-	modelName := "sentence-transformers/all-MiniLM-L6-v2"
-	modelPath, err := helper.PrepareModel(modelName, "onnx/model.onnx")
-	if err != nil {
-		return nil, err
-	}
+	return DefaultModels.Embedder()
+}
 
-	// Initialize hugot session with Go backend
-	session, err := hugot.NewGoSession()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create hugot session: %w", err)
-	}
+// DefaultBatchEmbedder creates a batch embedder using the same model as DefaultEmbedder,
+// embedding all texts of a batch in one model run
+func DefaultBatchEmbedder() (BatchEmbedFunc, error) {
+	return DefaultModels.BatchEmbedder()
+}
 
-	// Create sentence transformers pipeline configuration
-	config := hugot.FeatureExtractionConfig{
-		ModelPath:    modelPath,
-		Name:         "embedder-pipeline",
-		OnnxFilename: "onnx/model.onnx",
-	}
-	sentencePipeline, err := hugot.NewPipeline(session, config)
-	if err != nil {
-		if destroyErr := session.Destroy(); destroyErr != nil {
-			return nil, fmt.Errorf("failed to create sentence pipeline: %w (cleanup error: %v)", err, destroyErr)
-		}
-		return nil, fmt.Errorf("failed to create sentence pipeline: %w", err)
+// Embedder creates an embedder with the all-MiniLM-L6-v2 model of the registry
+func (r *ModelRegistry) Embedder() (EmbedFunc, error) {
+	if _, err := r.sentencePipeline(); err != nil {
+		return nil, err
 	}
 
 	return func(text string) ([]float32, error) {
+		sentencePipeline, err := r.sentencePipeline()
+		if err != nil {
+			return nil, err
+		}
+
 		// Generate embedding for the text
+		unlock, err := r.lockPipeline(SentenceModelName)
+		if err != nil {
+			return nil, err
+		}
 		result, err := sentencePipeline.RunPipeline([]string{text})
+		unlock()
 		if err != nil {
 			return nil, fmt.Errorf("failed to generate embedding: %w", err)
 		}
@@ -53,3 +46,37 @@ func DefaultEmbedder() (EmbedFunc, error) {
 		return embedding, nil
 	}, nil
 }
+
+// BatchEmbedder creates a batch embedder with the all-MiniLM-L6-v2 model of the registry
+func (r *ModelRegistry) BatchEmbedder() (BatchEmbedFunc, error) {
+	if _, err := r.sentencePipeline(); err != nil {
+		return nil, err
+	}
+
+	return func(texts []string) ([][]float32, error) {
+		if len(texts) == 0 {
+			return [][]float32{}, nil
+		}
+
+		sentencePipeline, err := r.sentencePipeline()
+		if err != nil {
+			return nil, err
+		}
+
+		unlock, err := r.lockPipeline(SentenceModelName)
+		if err != nil {
+			return nil, err
+		}
+		result, err := sentencePipeline.RunPipeline(texts)
+		unlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate embeddings: %w", err)
+		}
+
+		if len(result.Embeddings) != len(texts) {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(result.Embeddings))
+		}
+
+		return result.Embeddings, nil
+	}, nil
+}
@@ -237,3 +237,35 @@ func TestEmbedderDimensions(t *testing.T) {
 		}
 	})
 }
+
+func TestDefaultBatchEmbedder(t *testing.T) {
+	t.Run("Generate embeddings for a batch", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultBatchEmbedder test in short mode (requires model download)")
+		}
+
+		embedder, err := DefaultBatchEmbedder()
+		require.NoError(t, err)
+
+		embeddings, err := embedder([]string{"First sentence.", "Second sentence."})
+
+		require.NoError(t, err)
+		require.Len(t, embeddings, 2)
+		assert.Equal(t, 384, len(embeddings[0]), "all-MiniLM-L6-v2 produces 384-dimensional embeddings")
+		assert.NotEqual(t, embeddings[0], embeddings[1])
+	})
+
+	t.Run("Empty batch", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultBatchEmbedder test in short mode (requires model download)")
+		}
+
+		embedder, err := DefaultBatchEmbedder()
+		require.NoError(t, err)
+
+		embeddings, err := embedder(nil)
+
+		require.NoError(t, err)
+		assert.Empty(t, embeddings)
+	})
+}
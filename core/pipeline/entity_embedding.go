@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// MaxEntityContextSnippets limits the number of context snippets embedded together with an entity name
+const MaxEntityContextSnippets = 3
+
+// EntityContextWindow is the number of bytes before and after a mention used as context snippet
+const EntityContextWindow = 100
+
+// EntityContext returns the text around the mention of an entity in a chunk.
+// It uses the start and end offsets from the entity metadata if the extractor set them,
+// otherwise the beginning of the chunk.
+func EntityContext(content string, entity *model.Entity) string {
+	start, okStart := toInt(entity.Metadata["start"])
+	end, okEnd := toInt(entity.Metadata["end"])
+	if !okStart || !okEnd || start < 0 || end < start || end > len(content) {
+		start, end = 0, 0
+	}
+
+	from := max(0, start-EntityContextWindow)
+	to := min(len(content), end+EntityContextWindow)
+
+	// Don't cut multi-byte characters
+	for from > 0 && !utf8.RuneStart(content[from]) {
+		from--
+	}
+	for to < len(content) && !utf8.RuneStart(content[to]) {
+		to++
+	}
+
+	return strings.TrimSpace(content[from:to])
+}
+
+// EntityEmbeddingText builds the text embedded for an entity from its name and context snippets
+func EntityEmbeddingText(name string, snippets []string) string {
+	if len(snippets) > MaxEntityContextSnippets {
+		snippets = snippets[:MaxEntityContextSnippets]
+	}
+	return strings.Join(append([]string{name}, snippets...), "\n")
+}
+
+// entityMentions collects the mentions of an entity within one processed text
+type entityMentions struct {
+	entities []*model.Entity
+	snippets []string
+}
+
+// embedEntities embeds every distinct entity (by name and type) once
+// from its name and the context snippets of its mentions
+func (p *Pipeline) embedEntities(ctx context.Context, mentions map[string]*entityMentions, order []string) error {
+	texts := make([]string, len(order))
+	for i, key := range order {
+		mention := mentions[key]
+		texts[i] = EntityEmbeddingText(mention.entities[0].Name, mention.snippets)
+	}
+
+	embeddings, err := p.EmbedBatchCtx(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed entities: %w", err)
+	}
+
+	for i, key := range order {
+		for _, entity := range mentions[key].entities {
+			entity.Embedding = embeddings[i]
+		}
+	}
+	return nil
+}
+
+// entityKey builds the key entities are unique by
+func entityKey(entity *model.Entity) string {
+	return entity.Name + "\x00" + entity.Type
+}
+
+// toInt converts integer metadata values (like NER offsets) to int
+func toInt(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int64:
+		return int(v), true
+	case uint:
+		// #nosec G115
+		return int(v), true
+	case float64:
+		return int(v), true
+	default:
+		return 0, false
+	}
+}
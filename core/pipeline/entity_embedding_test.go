@@ -0,0 +1,103 @@
+package pipeline
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntityContext(t *testing.T) {
+	t.Run("Context around mention", func(t *testing.T) {
+		content := strings.Repeat("a", 200) + "Alice" + strings.Repeat("b", 200)
+		entity := &model.Entity{Name: "Alice", Metadata: model.Metadata{"start": uint(200), "end": uint(205)}}
+
+		context := EntityContext(content, entity)
+
+		assert.Equal(t, strings.Repeat("a", EntityContextWindow)+"Alice"+strings.Repeat("b", EntityContextWindow), context)
+	})
+
+	t.Run("Context without offsets uses chunk start", func(t *testing.T) {
+		content := "Alice went to the market. " + strings.Repeat("x", 200)
+		entity := &model.Entity{Name: "Alice"}
+
+		context := EntityContext(content, entity)
+
+		assert.Equal(t, content[:EntityContextWindow], context)
+	})
+
+	t.Run("Context with invalid offsets uses chunk start", func(t *testing.T) {
+		entity := &model.Entity{Name: "Alice", Metadata: model.Metadata{"start": 50, "end": 10}}
+
+		context := EntityContext("Alice", entity)
+
+		assert.Equal(t, "Alice", context)
+	})
+
+	t.Run("Context doesn't cut multi-byte characters", func(t *testing.T) {
+		content := strings.Repeat("ä", 100)
+		entity := &model.Entity{Name: "ä", Metadata: model.Metadata{"start": 101, "end": 103}}
+
+		context := EntityContext(content, entity)
+
+		assert.True(t, strings.HasPrefix(context, "ä"), "Expected context to start at a character boundary")
+		assert.True(t, strings.HasSuffix(context, "ä"), "Expected context to end at a character boundary")
+	})
+}
+
+func TestEntityEmbeddingText(t *testing.T) {
+	t.Run("Name and snippets", func(t *testing.T) {
+		text := EntityEmbeddingText("Alice", []string{"Alice works at ACME", "Alice lives in Berlin"})
+
+		assert.Equal(t, "Alice\nAlice works at ACME\nAlice lives in Berlin", text)
+	})
+
+	t.Run("Snippets are limited", func(t *testing.T) {
+		text := EntityEmbeddingText("Alice", []string{"1", "2", "3", "4", "5"})
+
+		assert.Equal(t, "Alice\n1\n2\n3", text)
+	})
+}
+
+func TestPipelineEmbedEntities(t *testing.T) {
+	extractor := func(text string) ([]*model.Entity, error) {
+		return []*model.Entity{{Name: "Alice", Type: "PERSON"}}, nil
+	}
+
+	t.Run("Entities are embedded once per name and type", func(t *testing.T) {
+		var embedded []string
+		embedder := func(text string) ([]float32, error) {
+			embedded = append(embedded, text)
+			return []float32{float32(len(text))}, nil
+		}
+		pipeline := NewPipeline(mockChunkFunc, embedder)
+		pipeline.SetEntityExtractor(extractor)
+
+		result, err := pipeline.ProcessWithExtraction("Alice knows Bob", "doc")
+
+		assert.NoError(t, err, "Expected no error")
+		require.Len(t, result.Entities, 2, "Expected one entity per chunk")
+		assert.NotEmpty(t, result.Entities[0].Embedding, "Expected entity to be embedded")
+		assert.Equal(t, result.Entities[0].Embedding, result.Entities[1].Embedding, "Expected duplicates to share the embedding")
+		assert.Contains(t, embedded, "Alice\nChunk 1\nChunk 2", "Expected name and contexts of both chunks to be embedded")
+		assert.Len(t, embedded, 3, "Expected two chunk embeddings and one entity embedding")
+	})
+
+	t.Run("Entity embedding error is returned", func(t *testing.T) {
+		embedder := func(text string) ([]float32, error) {
+			if strings.HasPrefix(text, "Alice") {
+				return nil, errors.New("entity embedding error")
+			}
+			return []float32{0.1}, nil
+		}
+		pipeline := NewPipeline(mockChunkFunc, embedder)
+		pipeline.SetEntityExtractor(extractor)
+
+		_, err := pipeline.ProcessWithExtraction("Alice knows Bob", "doc")
+
+		assert.Error(t, err, "Expected error from entity embedding")
+	})
+}
@@ -5,9 +5,6 @@ import (
 	"strings"
 
 	"github.com/google/uuid"
-	"github.com/knights-analytics/hugot"
-	"github.com/knights-analytics/hugot/pipelines"
-	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
 )
 
@@ -15,40 +12,28 @@ import (
 // Uses distilbert-NER for named entity recognition
 // Detects: PERSON, ORGANIZATION, LOCATION, MISC entities
 func DefaultEntityExtractor() (EntityExtractFunc, error) {
-	// Prepare model (download if needed)
-	// Using KnightsAnalytics optimized distilbert-NER model
-	modelName := "KnightsAnalytics/distilbert-NER"
-	modelPath, err := helper.PrepareModel(modelName, "model.onnx")
-	if err != nil {
-		return nil, err
-	}
+	return DefaultModels.EntityExtractor()
+}
 
-	// Initialize hugot session with Go backend
-	session, err := hugot.NewGoSession()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create hugot session: %w", err)
+// EntityExtractor creates an entity extractor like DefaultEntityExtractor with the distilbert-NER model of the registry
+func (r *ModelRegistry) EntityExtractor() (EntityExtractFunc, error) {
+	if _, err := r.nerPipeline(); err != nil {
+		return nil, err
 	}
 
-	// Create token classification pipeline for NER
-	config := hugot.TokenClassificationConfig{
-		ModelPath: modelPath,
-		Name:      "ner-pipeline",
-		Options: []hugot.TokenClassificationOption{
-			pipelines.WithSimpleAggregation(),
-			pipelines.WithIgnoreLabels([]string{"O"}), // Ignore non-entity tokens
-		},
-	}
-	nerPipeline, err := hugot.NewPipeline(session, config)
-	if err != nil {
-		if destroyErr := session.Destroy(); destroyErr != nil {
-			return nil, fmt.Errorf("failed to create NER pipeline: %w (cleanup error: %v)", err, destroyErr)
+	return func(text string) ([]*model.Entity, error) {
+		nerPipeline, err := r.nerPipeline()
+		if err != nil {
+			return nil, err
 		}
-		return nil, fmt.Errorf("failed to create NER pipeline: %w", err)
-	}
 
-	return func(text string) ([]*model.Entity, error) {
 		// Run NER on the text
+		unlock, err := r.lockPipeline(NERModelName)
+		if err != nil {
+			return nil, err
+		}
 		result, err := nerPipeline.RunPipeline([]string{text})
+		unlock()
 		if err != nil {
 			return nil, fmt.Errorf("failed to run NER: %w", err)
 		}
@@ -1,14 +1,28 @@
 package pipeline
 
-import "github.com/siherrmann/grapher/model"
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siherrmann/grapher/model"
+)
 
 // ChunkFunc is a function that splits text into chunks with their hierarchical paths
 // The path should follow ltree format (e.g., "doc.chapter1.section2.chunk3")
 type ChunkFunc func(text string, basePath string) ([]ChunkWithPath, error)
 
+// HintChunkFunc is a ChunkFunc that also gets the structural hints of the document (see model.Hint)
+// The hints are nil if the document has none, e.g. if it wasn't loaded with the loader package
+type HintChunkFunc func(text string, basePath string, hints []model.Hint) ([]ChunkWithPath, error)
+
 // EmbedFunc is a function that generates embeddings for text
 type EmbedFunc func(text string) ([]float32, error)
 
+// BatchEmbedFunc is a function that generates embeddings for several texts at once
+// Returns one embedding per text in the order of the input
+type BatchEmbedFunc func(texts []string) ([][]float32, error)
+
 // EntityExtractFunc extracts entities from text
 // Returns a list of entities with their types and metadata
 type EntityExtractFunc func(text string) ([]*model.Entity, error)
@@ -17,6 +31,14 @@ type EntityExtractFunc func(text string) ([]*model.Entity, error)
 // Returns a list of edges representing the relationships
 type RelationExtractFunc func(text string, chunkID string, entities []*model.Entity) ([]*model.Edge, error)
 
+// RerankFunc scores documents by their relevance to a query
+// Returns one score per document in the order of the input, higher is more relevant
+type RerankFunc func(query string, documents []string) ([]float32, error)
+
+// CommunitySummarizeFunc summarizes a community of entities for global search
+// The entities are ordered by their connections inside the community, the relations are the edges between them
+type CommunitySummarizeFunc func(entities []*model.Entity, relations []*model.Edge) (string, error)
+
 // ChunkWithPath represents a chunk with its hierarchical path
 type ChunkWithPath struct {
 	Content    string
@@ -31,8 +53,18 @@ type ChunkWithPath struct {
 type Pipeline struct {
 	Chunker           ChunkFunc
 	Embedder          EmbedFunc
+	BatchEmbedder     BatchEmbedFunc      // Optional, adapted from Embedder if not set
 	EntityExtractor   EntityExtractFunc   // Optional
 	RelationExtractor RelationExtractFunc // Optional
+	Reranker          RerankFunc          // Optional
+	HintChunker       HintChunkFunc       // Optional, used instead of Chunker if set
+	// Optional, DefaultCommunitySummarizer is used for community detection if not set
+	CommunitySummarizer CommunitySummarizeFunc
+	// Number of texts passed to the batch embedder at once, defaults to DefaultBatchSize
+	BatchSize int
+	// Number of chunks extracted in parallel, defaults to DefaultExtractionWorkers (1).
+	// The extractors must be safe for concurrent use if it is greater than 1.
+	ExtractionWorkers int
 }
 
 // NewPipeline creates a new processing pipeline
@@ -43,6 +75,23 @@ func NewPipeline(chunker ChunkFunc, embedder EmbedFunc) *Pipeline {
 	}
 }
 
+// SetHintChunker sets a chunker that follows the structural hints of documents, it's used instead of Chunker
+func (p *Pipeline) SetHintChunker(chunker HintChunkFunc) {
+	p.HintChunker = chunker
+}
+
+// SetBatchEmbedder sets the function used to embed chunks and entities in batches of batchSize texts.
+// A batchSize <= 0 uses DefaultBatchSize.
+func (p *Pipeline) SetBatchEmbedder(embedder BatchEmbedFunc, batchSize int) {
+	p.BatchEmbedder = embedder
+	p.BatchSize = batchSize
+}
+
+// SetExtractionWorkers sets the number of chunks extracted in parallel, a value <= 0 uses DefaultExtractionWorkers
+func (p *Pipeline) SetExtractionWorkers(workers int) {
+	p.ExtractionWorkers = workers
+}
+
 // SetEntityExtractor sets the entity extraction function
 func (p *Pipeline) SetEntityExtractor(extractor EntityExtractFunc) {
 	p.EntityExtractor = extractor
@@ -53,6 +102,16 @@ func (p *Pipeline) SetRelationExtractor(extractor RelationExtractFunc) {
 	p.RelationExtractor = extractor
 }
 
+// SetReranker sets the reranking function used after retrieval
+func (p *Pipeline) SetReranker(reranker RerankFunc) {
+	p.Reranker = reranker
+}
+
+// SetCommunitySummarizer sets the function summarizing detected communities
+func (p *Pipeline) SetCommunitySummarizer(summarizer CommunitySummarizeFunc) {
+	p.CommunitySummarizer = summarizer
+}
+
 // ProcessingResult contains chunks and optionally extracted entities and relations
 type ProcessingResult struct {
 	Chunks    []*model.Chunk
@@ -62,60 +121,200 @@ type ProcessingResult struct {
 
 // Process processes text through the pipeline, returning chunks with embeddings
 func (p *Pipeline) Process(text string, basePath string) ([]*model.Chunk, error) {
-	result, err := p.ProcessWithExtraction(text, basePath)
+	return p.ProcessCtx(context.Background(), text, basePath)
+}
+
+// ProcessCtx is Process with a context that stops the processing once it is canceled
+func (p *Pipeline) ProcessCtx(ctx context.Context, text string, basePath string) ([]*model.Chunk, error) {
+	result, err := p.ProcessWithExtractionCtx(ctx, text, basePath)
 	if err != nil {
 		return nil, err
 	}
 	return result.Chunks, nil
 }
 
-// ProcessWithExtraction processes text and optionally extracts entities and relations
+// Embed embeds a single text with the Embedder
+func (p *Pipeline) Embed(text string) ([]float32, error) {
+	return p.EmbedCtx(context.Background(), text)
+}
+
+// EmbedCtx is Embed with a context, a canceled context returns its error without calling the embedder
+func (p *Pipeline) EmbedCtx(ctx context.Context, text string) ([]float32, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if p.Embedder == nil {
+		return nil, fmt.Errorf("no embedder set")
+	}
+	return p.Embedder(text)
+}
+
+// ProcessWithExtraction processes text and optionally extracts entities and relations.
+// Extracted entities are embedded from their name and the context of their mentions.
 func (p *Pipeline) ProcessWithExtraction(text string, basePath string) (*ProcessingResult, error) {
+	return p.ProcessWithExtractionCtx(context.Background(), text, basePath)
+}
+
+// ProcessWithExtractionCtx is ProcessWithExtraction with a context that stops the processing once it is canceled
+func (p *Pipeline) ProcessWithExtractionCtx(ctx context.Context, text string, basePath string) (*ProcessingResult, error) {
+	return p.ProcessWithHintsCtx(ctx, text, basePath, nil)
+}
+
+// ProcessWithHintsCtx is ProcessWithExtractionCtx for text with structural hints, like the content of a loaded document
+func (p *Pipeline) ProcessWithHintsCtx(ctx context.Context, text string, basePath string, hints []model.Hint) (*ProcessingResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	// Split into chunks
-	chunksWithPath, err := p.Chunker(text, basePath)
+	chunksWithPath, err := p.Chunk(text, basePath, hints)
 	if err != nil {
 		return nil, err
 	}
 
-	// Generate embeddings
-	chunks := make([]*model.Chunk, 0, len(chunksWithPath))
-	var allEntities []*model.Entity
-	var allRelations []*model.Edge
+	return p.ProcessChunksCtx(ctx, chunksWithPath)
+}
 
-	for _, cwp := range chunksWithPath {
-		embedding, err := p.Embedder(cwp.Content)
-		if err != nil {
-			return nil, err
+// Chunk splits text into chunks with the HintChunker, or the Chunker if no HintChunker is set.
+// Chunks with a start position get the number of the page they start on as "page" metadata
+// (and "page_end" if they end on a later page) from the page hints.
+func (p *Pipeline) Chunk(text string, basePath string, hints []model.Hint) ([]ChunkWithPath, error) {
+	var chunksWithPath []ChunkWithPath
+	var err error
+	switch {
+	case p.HintChunker != nil:
+		chunksWithPath, err = p.HintChunker(text, basePath, hints)
+	case p.Chunker != nil:
+		chunksWithPath, err = p.Chunker(text, basePath)
+	default:
+		return nil, fmt.Errorf("no chunker set")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	addPageMetadata(chunksWithPath, hints)
+
+	return chunksWithPath, nil
+}
+
+// addPageMetadata sets the page a chunk starts and ends on from the page hints
+func addPageMetadata(chunksWithPath []ChunkWithPath, hints []model.Hint) {
+	var pages []model.Hint
+	for _, hint := range hints {
+		if hint.Type == model.HintTypePage {
+			pages = append(pages, hint)
 		}
+	}
+	if len(pages) == 0 {
+		return
+	}
+	sort.SliceStable(pages, func(i, j int) bool {
+		return pages[i].Offset < pages[j].Offset
+	})
 
-		chunk := &model.Chunk{
+	// pageAt returns the page of the last page hint at or before the offset, 0 if there is none
+	pageAt := func(offset int) int {
+		i := sort.Search(len(pages), func(i int) bool {
+			return pages[i].Offset > offset
+		})
+		if i == 0 {
+			return 0
+		}
+		return pages[i-1].Page
+	}
+
+	for i := range chunksWithPath {
+		cwp := &chunksWithPath[i]
+		if cwp.StartPos == nil {
+			continue
+		}
+		page := pageAt(*cwp.StartPos)
+		if page == 0 {
+			continue
+		}
+		if cwp.Metadata == nil {
+			cwp.Metadata = map[string]interface{}{}
+		}
+		cwp.Metadata["page"] = page
+		// The end position is exclusive
+		if cwp.EndPos != nil && *cwp.EndPos > *cwp.StartPos {
+			if pageEnd := pageAt(*cwp.EndPos - 1); pageEnd > page {
+				cwp.Metadata["page_end"] = pageEnd
+			}
+		}
+	}
+}
+
+// ProcessChunks embeds already split chunks and optionally extracts entities and relations,
+// e.g. only the changed chunks of an updated document
+func (p *Pipeline) ProcessChunks(chunksWithPath []ChunkWithPath) (*ProcessingResult, error) {
+	return p.ProcessChunksCtx(context.Background(), chunksWithPath)
+}
+
+// ProcessChunksCtx is ProcessChunks with a context. The pipeline functions can't be interrupted,
+// so the context is checked before every embedding batch and chunk extraction.
+func (p *Pipeline) ProcessChunksCtx(ctx context.Context, chunksWithPath []ChunkWithPath) (*ProcessingResult, error) {
+	// Generate embeddings in batches
+	texts := make([]string, len(chunksWithPath))
+	for i, cwp := range chunksWithPath {
+		texts[i] = cwp.Content
+	}
+	embeddings, err := p.EmbedBatchCtx(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	chunks := make([]*model.Chunk, 0, len(chunksWithPath))
+	for i, cwp := range chunksWithPath {
+		chunks = append(chunks, &model.Chunk{
 			Content:    cwp.Content,
 			Path:       cwp.Path,
-			Embedding:  embedding,
+			Embedding:  embeddings[i],
 			StartPos:   cwp.StartPos,
 			EndPos:     cwp.EndPos,
 			ChunkIndex: cwp.ChunkIndex,
 			Metadata:   cwp.Metadata,
-		}
-		chunks = append(chunks, chunk)
-
-		// Extract entities if extractor is set
-		var chunkEntities []*model.Entity
-		if p.EntityExtractor != nil {
-			entities, err := p.EntityExtractor(cwp.Content)
-			if err == nil && entities != nil {
-				chunkEntities = entities
-				allEntities = append(allEntities, entities...)
-			}
-		}
+		})
+	}
 
-		// Extract relations if extractor is set
-		if p.RelationExtractor != nil {
-			relations, err := p.RelationExtractor(cwp.Content, cwp.Path, chunkEntities)
-			if err == nil && relations != nil {
-				allRelations = append(allRelations, relations...)
+	// Extract entities and relations of the chunks in parallel
+	extractions := p.extractChunks(ctx, chunksWithPath)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Collect the results in chunk order, so the output doesn't depend on the scheduling
+	var allEntities []*model.Entity
+	var allRelations []*model.Edge
+	mentions := make(map[string]*entityMentions)
+	var mentionOrder []string
+
+	for i, cwp := range chunksWithPath {
+		chunkEntities := extractions[i].entities
+		allEntities = append(allEntities, chunkEntities...)
+
+		// Collect the context of every mention for the entity embeddings
+		for _, entity := range chunkEntities {
+			key := entityKey(entity)
+			mention, ok := mentions[key]
+			if !ok {
+				mention = &entityMentions{}
+				mentions[key] = mention
+				mentionOrder = append(mentionOrder, key)
 			}
+			mention.entities = append(mention.entities, entity)
+			mention.snippets = append(mention.snippets, EntityContext(cwp.Content, entity))
 		}
+
+		// Link the chunk to every entity it mentions
+		allRelations = append(allRelations, MentionEdges(cwp.Path, chunkEntities)...)
+		allRelations = append(allRelations, extractions[i].relations...)
+	}
+
+	// Embed entities from their name and context snippets
+	if err := p.embedEntities(ctx, mentions, mentionOrder); err != nil {
+		return nil, err
 	}
 
 	return &ProcessingResult{
@@ -124,3 +323,54 @@ func (p *Pipeline) ProcessWithExtraction(text string, basePath string) (*Process
 		Relations: allRelations,
 	}, nil
 }
+
+// MentionEdges creates chunk to entity mention edges for the entities extracted from a chunk.
+// The source chunk is not known yet, so it is referenced by its path in the "extracted_from" metadata.
+// Span offsets and confidence are taken from the entity metadata if the extractor set them.
+// The edges hold a copy of the entity ID, so they have to be remapped if the entity is stored under another ID.
+func MentionEdges(chunkPath string, entities []*model.Entity) []*model.Edge {
+	edges := make([]*model.Edge, 0, len(entities))
+	for _, entity := range entities {
+		metadata := map[string]interface{}{
+			"extracted_from": chunkPath,
+			"entity_name":    entity.Name,
+			"entity_type":    entity.Type,
+		}
+
+		weight := 1.0
+		if confidence, ok := toFloat64(entity.Metadata["confidence"]); ok {
+			weight = confidence
+			metadata["confidence"] = confidence
+		}
+		if start, ok := entity.Metadata["start"]; ok {
+			metadata["start"] = start
+		}
+		if end, ok := entity.Metadata["end"]; ok {
+			metadata["end"] = end
+		}
+
+		entityID := entity.ID
+		edges = append(edges, &model.Edge{
+			TargetEntityID: &entityID,
+			EdgeType:       model.EdgeTypeEntityMention,
+			Weight:         weight,
+			Bidirectional:  false,
+			Metadata:       metadata,
+		})
+	}
+	return edges
+}
+
+// toFloat64 converts numeric metadata values to float64
+func toFloat64(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
@@ -1,6 +1,7 @@
 package pipeline
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -70,6 +71,34 @@ func TestNewPipeline(t *testing.T) {
 		assert.Nil(t, pipeline.Chunker, "Expected chunker to be nil")
 		assert.Nil(t, pipeline.Embedder, "Expected embedder to be nil")
 	})
+
+	t.Run("Set reranker", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		assert.Nil(t, pipeline.Reranker, "Expected reranker to be nil by default")
+
+		pipeline.SetReranker(func(query string, documents []string) ([]float32, error) {
+			return make([]float32, len(documents)), nil
+		})
+
+		require.NotNil(t, pipeline.Reranker, "Expected reranker to be set")
+		scores, err := pipeline.Reranker("query", []string{"a", "b"})
+		assert.NoError(t, err)
+		assert.Len(t, scores, 2)
+	})
+
+	t.Run("Set community summarizer", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		assert.Nil(t, pipeline.CommunitySummarizer, "Expected community summarizer to be nil by default")
+
+		pipeline.SetCommunitySummarizer(func(entities []*model.Entity, relations []*model.Edge) (string, error) {
+			return "summary", nil
+		})
+
+		require.NotNil(t, pipeline.CommunitySummarizer, "Expected community summarizer to be set")
+		summary, err := pipeline.CommunitySummarizer(nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "summary", summary)
+	})
 }
 
 func TestPipelineProcess(t *testing.T) {
@@ -172,6 +201,212 @@ func TestPipelineProcess(t *testing.T) {
 	})
 }
 
+func TestPipelineProcessWithExtraction(t *testing.T) {
+	extractor := func(text string) ([]*model.Entity, error) {
+		return []*model.Entity{
+			{
+				Name:     "Alice",
+				Type:     "PERSON",
+				Metadata: model.Metadata{"start": 0, "end": 5, "confidence": float32(0.75)},
+			},
+		}, nil
+	}
+
+	t.Run("Process with extraction creates mention edges", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetEntityExtractor(extractor)
+
+		result, err := pipeline.ProcessWithExtraction("Alice knows Bob", "doc")
+
+		assert.NoError(t, err, "Expected no error")
+		require.Len(t, result.Entities, 2, "Expected one entity per chunk")
+		require.Len(t, result.Relations, 2, "Expected one mention edge per entity")
+
+		for i, edge := range result.Relations {
+			assert.Equal(t, model.EdgeTypeEntityMention, edge.EdgeType, "Expected mention edge")
+			assert.Equal(t, result.Entities[i].ID, *edge.TargetEntityID, "Expected edge to reference the extracted entity")
+			assert.Nil(t, edge.SourceChunkID, "Expected source chunk to be resolved on insert")
+			assert.Equal(t, result.Chunks[i].Path, edge.Metadata["extracted_from"], "Expected chunk path in metadata")
+		}
+	})
+
+	t.Run("Process without extractor creates no mention edges", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		result, err := pipeline.ProcessWithExtraction("Alice knows Bob", "doc")
+
+		assert.NoError(t, err, "Expected no error")
+		assert.Empty(t, result.Relations, "Expected no relations")
+	})
+}
+
+func TestPipelineProcessChunks(t *testing.T) {
+	t.Run("Process only the given chunks", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		result, err := pipeline.ProcessChunks([]ChunkWithPath{
+			{Content: "Changed chunk", Path: "doc.chunk2", ChunkIndex: intPtr(1)},
+		})
+
+		assert.NoError(t, err, "Expected no error")
+		require.Len(t, result.Chunks, 1, "Expected one chunk")
+		assert.Equal(t, "doc.chunk2", result.Chunks[0].Path)
+		assert.Equal(t, 1, *result.Chunks[0].ChunkIndex)
+		assert.NotEmpty(t, result.Chunks[0].Embedding, "Expected chunk to be embedded")
+	})
+
+	t.Run("Process no chunks", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		result, err := pipeline.ProcessChunks(nil)
+
+		assert.NoError(t, err, "Expected no error")
+		assert.Empty(t, result.Chunks)
+	})
+
+	t.Run("Process chunks with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		pipeline.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+			t.Error("Extractor must not be called for a canceled context")
+			return nil, nil
+		})
+
+		_, err := pipeline.ProcessChunksCtx(ctx, []ChunkWithPath{{Content: "Chunk", Path: "doc.chunk0"}})
+
+		assert.ErrorIs(t, err, context.Canceled, "Expected the context error")
+	})
+}
+
+func TestPipelineChunk(t *testing.T) {
+	t.Run("Chunks get their pages", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		hints := []model.Hint{
+			{Type: model.HintTypePage, Page: 1, Offset: 0},
+			{Type: model.HintTypePage, Page: 2, Offset: 5},
+			{Type: model.HintTypePage, Page: 3, Offset: 12},
+		}
+
+		chunks, err := pipeline.Chunk("Chunk 1 Chunk 2", "doc", hints)
+
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.Equal(t, 1, chunks[0].Metadata["page"])
+		assert.Equal(t, 2, chunks[0].Metadata["page_end"])
+		assert.Equal(t, 2, chunks[1].Metadata["page"])
+		assert.Equal(t, 3, chunks[1].Metadata["page_end"])
+	})
+
+	t.Run("Chunks without page hints", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		chunks, err := pipeline.Chunk("Chunk 1 Chunk 2", "doc", []model.Hint{{Type: model.HintTypeHeading, Text: "Title", Level: 1}})
+
+		require.NoError(t, err)
+		require.Len(t, chunks, 2)
+		assert.NotContains(t, chunks[0].Metadata, "page")
+	})
+
+	t.Run("Hint chunker is used instead of chunker", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+		var received []model.Hint
+		pipeline.SetHintChunker(func(text string, basePath string, hints []model.Hint) ([]ChunkWithPath, error) {
+			received = hints
+			return []ChunkWithPath{{Content: text, Path: basePath + ".chunk0", StartPos: intPtr(0)}}, nil
+		})
+		hints := []model.Hint{{Type: model.HintTypePage, Page: 4, Offset: 0}}
+
+		result, err := pipeline.ProcessWithHintsCtx(context.Background(), "Text", "doc", hints)
+
+		require.NoError(t, err)
+		assert.Equal(t, hints, received, "Expected the hints to be passed to the hint chunker")
+		require.Len(t, result.Chunks, 1)
+		assert.Equal(t, 4, result.Chunks[0].Metadata["page"])
+	})
+
+	t.Run("Chunk without chunker", func(t *testing.T) {
+		pipeline := NewPipeline(nil, mockEmbedFunc)
+
+		_, err := pipeline.Chunk("Text", "doc", nil)
+
+		assert.Error(t, err, "Expected error without chunker")
+	})
+}
+
+func TestPipelineEmbed(t *testing.T) {
+	t.Run("Embed text", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		embedding, err := pipeline.Embed("query")
+
+		assert.NoError(t, err)
+		assert.Len(t, embedding, 4)
+	})
+
+	t.Run("Embed with canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		pipeline := NewPipeline(mockChunkFunc, mockEmbedFunc)
+
+		_, err := pipeline.EmbedCtx(ctx, "query")
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("Embed without embedder", func(t *testing.T) {
+		pipeline := NewPipeline(mockChunkFunc, nil)
+
+		_, err := pipeline.Embed("query")
+
+		assert.Error(t, err)
+	})
+}
+
+func TestMentionEdges(t *testing.T) {
+	t.Run("Mention edge with span and confidence", func(t *testing.T) {
+		entity := &model.Entity{
+			Name:     "Alice",
+			Type:     "PERSON",
+			Metadata: model.Metadata{"start": 3, "end": 8, "confidence": 0.9},
+		}
+
+		edges := MentionEdges("doc.chunk1", []*model.Entity{entity})
+
+		require.Len(t, edges, 1, "Expected one edge")
+		edge := edges[0]
+		assert.Equal(t, model.EdgeTypeEntityMention, edge.EdgeType, "Expected mention edge")
+		assert.Equal(t, entity.ID, *edge.TargetEntityID, "Expected entity ID as target")
+		assert.NotSame(t, &entity.ID, edge.TargetEntityID, "Expected target to not alias the entity ID")
+		assert.False(t, edge.Bidirectional, "Expected directed edge")
+		assert.Equal(t, 0.9, edge.Weight, "Expected confidence as weight")
+		assert.Equal(t, "doc.chunk1", edge.Metadata["extracted_from"], "Expected chunk path")
+		assert.Equal(t, "Alice", edge.Metadata["entity_name"], "Expected entity name")
+		assert.Equal(t, "PERSON", edge.Metadata["entity_type"], "Expected entity type")
+		assert.Equal(t, 3, edge.Metadata["start"], "Expected start offset")
+		assert.Equal(t, 8, edge.Metadata["end"], "Expected end offset")
+		assert.Equal(t, 0.9, edge.Metadata["confidence"], "Expected confidence")
+	})
+
+	t.Run("Mention edge without span and confidence", func(t *testing.T) {
+		entity := &model.Entity{Name: "Bob", Type: "PERSON"}
+
+		edges := MentionEdges("doc.chunk2", []*model.Entity{entity})
+
+		require.Len(t, edges, 1, "Expected one edge")
+		assert.Equal(t, 1.0, edges[0].Weight, "Expected default weight")
+		assert.NotContains(t, edges[0].Metadata, "start", "Expected no start offset")
+		assert.NotContains(t, edges[0].Metadata, "end", "Expected no end offset")
+		assert.NotContains(t, edges[0].Metadata, "confidence", "Expected no confidence")
+	})
+
+	t.Run("No entities", func(t *testing.T) {
+		edges := MentionEdges("doc.chunk", nil)
+
+		assert.Empty(t, edges, "Expected no edges")
+	})
+}
+
 func TestChunkWithPath(t *testing.T) {
 	t.Run("Create ChunkWithPath with all fields", func(t *testing.T) {
 		startPos := 10
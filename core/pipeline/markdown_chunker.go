@@ -0,0 +1,337 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// ChunkTypeSectionSummary marks the summary chunks MarkdownChunker emits for every section.
+// Entities are not extracted from summary chunks, since their text repeats the section content.
+const ChunkTypeSectionSummary = "section_summary"
+
+// ChunkTypeContent marks the content chunks MarkdownChunker emits
+const ChunkTypeContent = "content"
+
+// markdownSection is a heading with its content blocks and subsections
+type markdownSection struct {
+	title    string
+	level    int
+	start    int
+	end      int
+	blocks   []markdownBlock
+	children []*markdownSection
+}
+
+// markdownBlock is a paragraph, list or code block within a section
+type markdownBlock struct {
+	start  int
+	end    int
+	isList bool
+}
+
+// MarkdownChunker creates a chunker that follows the heading structure of Markdown text.
+// Every heading becomes a node in the ltree path (top level sections as secN, nested sections as subN)
+// with a summary chunk at the section path itself, so ancestors, descendants and siblings
+// of a chunk are the surrounding sections. The content of a section is split at paragraphs
+// into chunkN children of at most maxChunkSize bytes. Lists and fenced code blocks are never split,
+// so a single block larger than maxChunkSize becomes its own chunk.
+func MarkdownChunker(maxChunkSize int) ChunkFunc {
+	chunker := MarkdownHintChunker(maxChunkSize)
+	return func(text string, basePath string) ([]ChunkWithPath, error) {
+		return chunker(text, basePath, nil)
+	}
+}
+
+// MarkdownHintChunker is MarkdownChunker taking the headings from the hints of a loaded document
+// (see loader.LoadFile), so only lines the loader recognized as headings start a section, and
+// e.g. a line of PDF text starting with "#" doesn't. Without hints the headings are parsed from the text.
+func MarkdownHintChunker(maxChunkSize int) HintChunkFunc {
+	return func(text string, basePath string, hints []model.Hint) ([]ChunkWithPath, error) {
+		if maxChunkSize <= 0 {
+			return nil, fmt.Errorf("max chunk size must be positive")
+		}
+
+		// Handle empty or whitespace-only text
+		if strings.TrimSpace(text) == "" {
+			return []ChunkWithPath{}, nil
+		}
+
+		root := parseMarkdown(text, hints)
+
+		chunks := []ChunkWithPath{}
+		appendMarkdownChunks(&chunks, text, root, basePath, nil, maxChunkSize)
+
+		return chunks, nil
+	}
+}
+
+// parseMarkdown splits Markdown text into a tree of sections with the text before
+// the first heading as content of the root section. If hints are given, only the lines
+// at the offsets of heading hints are headings.
+func parseMarkdown(text string, hints []model.Hint) *markdownSection {
+	var headings map[int]model.Hint
+	if hints != nil {
+		headings = map[int]model.Hint{}
+		for _, hint := range hints {
+			if hint.Type == model.HintTypeHeading && hint.Level > 0 {
+				headings[hint.Offset] = hint
+			}
+		}
+	}
+
+	// heading returns the level and title of the heading starting at pos, level 0 if the line is no heading
+	heading := func(pos int, line string) (int, string) {
+		if headings == nil {
+			return headingLevel(line), headingTitle(line)
+		}
+		hint, ok := headings[pos]
+		if !ok {
+			return 0, ""
+		}
+		return min(hint.Level, 6), hint.Text
+	}
+
+	root := &markdownSection{level: 0, end: len(text)}
+	stack := []*markdownSection{root}
+
+	blockStart := -1
+	blockEnd := 0
+	blockIsList := false
+	inFence := false
+	fenceMarker := ""
+
+	flush := func() {
+		if blockStart < 0 {
+			return
+		}
+		current := stack[len(stack)-1]
+		// Keep lists (including items separated by blank lines) in one block
+		if n := len(current.blocks); n > 0 && current.blocks[n-1].isList &&
+			(blockIsList || startsIndented(text[blockStart:blockEnd])) &&
+			strings.TrimSpace(text[current.blocks[n-1].end:blockStart]) == "" {
+			current.blocks[n-1].end = blockEnd
+		} else {
+			current.blocks = append(current.blocks, markdownBlock{start: blockStart, end: blockEnd, isList: blockIsList})
+		}
+		blockStart = -1
+	}
+
+	pos := 0
+	for pos < len(text) {
+		lineEnd := strings.IndexByte(text[pos:], '\n')
+		next := len(text)
+		if lineEnd >= 0 {
+			lineEnd += pos
+			next = lineEnd + 1
+		} else {
+			lineEnd = len(text)
+		}
+		line := text[pos:lineEnd]
+		trimmed := strings.TrimSpace(line)
+		level, title := heading(pos, line)
+
+		switch {
+		case inFence:
+			if strings.HasPrefix(trimmed, fenceMarker) {
+				inFence = false
+			}
+			blockEnd = lineEnd
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			if blockStart < 0 {
+				blockStart = pos
+				blockIsList = false
+			}
+			inFence = true
+			fenceMarker = trimmed[:3]
+			blockEnd = lineEnd
+		case trimmed == "":
+			flush()
+		case level > 0:
+			flush()
+			for len(stack) > 1 && stack[len(stack)-1].level >= level {
+				stack[len(stack)-1].end = pos
+				stack = stack[:len(stack)-1]
+			}
+			section := &markdownSection{
+				title: title,
+				level: level,
+				start: pos,
+				end:   len(text),
+			}
+			parent := stack[len(stack)-1]
+			parent.children = append(parent.children, section)
+			stack = append(stack, section)
+		default:
+			if blockStart < 0 {
+				blockStart = pos
+				blockIsList = isListItem(trimmed)
+			}
+			blockEnd = lineEnd
+		}
+
+		pos = next
+	}
+	flush()
+
+	return root
+}
+
+// appendMarkdownChunks appends the summary and content chunks of a section and its subsections
+func appendMarkdownChunks(chunks *[]ChunkWithPath, text string, section *markdownSection, path string, headings []string, maxChunkSize int) {
+	if section.level > 0 {
+		headings = append(headings[:len(headings):len(headings)], section.title)
+		startPos := section.start
+		endPos := trimmedEnd(text, section.start, section.end)
+		chunkIndex := len(*chunks)
+		*chunks = append(*chunks, ChunkWithPath{
+			Content:    sectionSummary(text, section, headings, maxChunkSize),
+			Path:       path,
+			StartPos:   &startPos,
+			EndPos:     &endPos,
+			ChunkIndex: &chunkIndex,
+			Metadata: map[string]interface{}{
+				"chunking_method": "markdown",
+				"chunk_type":      ChunkTypeSectionSummary,
+				"section":         section.title,
+				"section_path":    strings.Join(headings, " > "),
+				"level":           section.level,
+			},
+		})
+	}
+
+	// Group consecutive blocks into chunks of at most maxChunkSize
+	for i, group := range groupBlocks(section.blocks, maxChunkSize) {
+		startPos := group[0].start
+		endPos := group[len(group)-1].end
+		chunkIndex := len(*chunks)
+		metadata := map[string]interface{}{
+			"chunking_method": "markdown",
+			"chunk_type":      ChunkTypeContent,
+		}
+		if section.level > 0 {
+			metadata["section"] = section.title
+			metadata["section_path"] = strings.Join(headings, " > ")
+		}
+		*chunks = append(*chunks, ChunkWithPath{
+			Content:    strings.TrimSpace(text[startPos:endPos]),
+			Path:       fmt.Sprintf("%s.chunk%d", path, i),
+			StartPos:   &startPos,
+			EndPos:     &endPos,
+			ChunkIndex: &chunkIndex,
+			Metadata:   metadata,
+		})
+	}
+
+	label := "sub"
+	if section.level == 0 {
+		label = "sec"
+	}
+	for i, child := range section.children {
+		appendMarkdownChunks(chunks, text, child, fmt.Sprintf("%s.%s%d", path, label, i), headings, maxChunkSize)
+	}
+}
+
+// groupBlocks groups consecutive blocks while their combined size stays within maxChunkSize
+func groupBlocks(blocks []markdownBlock, maxChunkSize int) [][]markdownBlock {
+	var groups [][]markdownBlock
+	var current []markdownBlock
+	for _, block := range blocks {
+		if len(current) > 0 && block.end-current[0].start > maxChunkSize {
+			groups = append(groups, current)
+			current = nil
+		}
+		current = append(current, block)
+	}
+	if len(current) > 0 {
+		groups = append(groups, current)
+	}
+	return groups
+}
+
+// sectionSummary builds the text of a section summary chunk from the heading path,
+// the first block of the section and the titles of its subsections
+func sectionSummary(text string, section *markdownSection, headings []string, maxChunkSize int) string {
+	parts := []string{strings.Join(headings, " > ")}
+	if len(section.blocks) > 0 {
+		parts = append(parts, strings.TrimSpace(text[section.blocks[0].start:section.blocks[0].end]))
+	}
+	if len(section.children) > 0 {
+		titles := make([]string, len(section.children))
+		for i, child := range section.children {
+			titles[i] = child.title
+		}
+		parts = append(parts, "Subsections: "+strings.Join(titles, ", "))
+	}
+	return truncateAtSpace(strings.Join(parts, "\n"), maxChunkSize)
+}
+
+// headingLevel returns the level of an ATX heading line ("## Title" is 2) or 0 if the line is no heading
+func headingLevel(line string) int {
+	if strings.HasPrefix(line, "    ") || strings.HasPrefix(line, "\t") {
+		return 0
+	}
+	trimmed := strings.TrimLeft(line, " ")
+	level := 0
+	for level < len(trimmed) && trimmed[level] == '#' {
+		level++
+	}
+	if level == 0 || level > 6 {
+		return 0
+	}
+	if level < len(trimmed) && trimmed[level] != ' ' && trimmed[level] != '\t' {
+		return 0
+	}
+	return level
+}
+
+// headingTitle returns the text of a heading line without the opening and optional closing hashes
+func headingTitle(line string) string {
+	title := strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+	closing := strings.TrimRight(title, "#")
+	if closing == "" || strings.HasSuffix(closing, " ") {
+		title = closing
+	}
+	return strings.TrimSpace(title)
+}
+
+// isListItem reports whether a trimmed line starts a bullet or numbered list item
+func isListItem(trimmed string) bool {
+	if strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* ") || strings.HasPrefix(trimmed, "+ ") {
+		return true
+	}
+	digits := 0
+	for digits < len(trimmed) && unicode.IsDigit(rune(trimmed[digits])) {
+		digits++
+	}
+	return digits > 0 && digits+1 < len(trimmed) &&
+		(trimmed[digits] == '.' || trimmed[digits] == ')') && trimmed[digits+1] == ' '
+}
+
+// startsIndented reports whether a block starts with an indented line (e.g. a list item continuation)
+func startsIndented(block string) bool {
+	return strings.HasPrefix(block, " ") || strings.HasPrefix(block, "\t")
+}
+
+// trimmedEnd returns the end offset of text[start:end] without trailing whitespace
+func trimmedEnd(text string, start, end int) int {
+	return start + len(strings.TrimRightFunc(text[start:end], unicode.IsSpace))
+}
+
+// truncateAtSpace cuts text to at most maxLen bytes at the last space before the limit
+func truncateAtSpace(text string, maxLen int) string {
+	if len(text) <= maxLen {
+		return text
+	}
+	cut := strings.LastIndexAny(text[:maxLen+1], " \n")
+	if cut <= 0 {
+		cut = maxLen
+		// Don't cut multi-byte characters
+		for cut > 0 && text[cut]&0xC0 == 0x80 {
+			cut--
+		}
+	}
+	return strings.TrimSpace(text[:cut])
+}
@@ -0,0 +1,240 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testMarkdown = `Intro text before any heading.
+
+# Installation
+
+Install the package with go get.
+
+## Requirements
+
+- PostgreSQL 16
+- pgvector
+
+- ltree
+
+## Setup
+
+Run the init function.
+
+# Usage
+
+` + "```go\ng := NewGrapher()\n\n// comment\n```" + `
+`
+
+func chunkPaths(chunks []ChunkWithPath) []string {
+	paths := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		paths[i] = chunk.Path
+	}
+	return paths
+}
+
+func TestMarkdownChunker(t *testing.T) {
+	t.Run("Headings become nested paths", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker(testMarkdown, "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"doc.chunk0",
+			"doc.sec0",
+			"doc.sec0.chunk0",
+			"doc.sec0.sub0",
+			"doc.sec0.sub0.chunk0",
+			"doc.sec0.sub1",
+			"doc.sec0.sub1.chunk0",
+			"doc.sec1",
+			"doc.sec1.chunk0",
+		}, chunkPaths(chunks))
+
+		for i, chunk := range chunks {
+			require.NotNil(t, chunk.ChunkIndex)
+			assert.Equal(t, i, *chunk.ChunkIndex, "Expected chunk indexes in document order")
+		}
+	})
+
+	t.Run("Sections get summary chunks", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker(testMarkdown, "doc")
+
+		require.NoError(t, err)
+		summary := chunks[1]
+		assert.Equal(t, ChunkTypeSectionSummary, summary.Metadata["chunk_type"])
+		assert.Equal(t, "Installation", summary.Metadata["section"])
+		assert.Equal(t, 1, summary.Metadata["level"])
+		assert.Equal(t, "Installation\nInstall the package with go get.\nSubsections: Requirements, Setup", summary.Content)
+
+		sub := chunks[3]
+		assert.Equal(t, "Installation > Requirements", sub.Metadata["section_path"])
+		assert.Equal(t, 2, sub.Metadata["level"])
+	})
+
+	t.Run("Lists and code blocks are kept together", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker(testMarkdown, "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, "- PostgreSQL 16\n- pgvector\n\n- ltree", chunks[4].Content)
+		assert.Equal(t, "```go\ng := NewGrapher()\n\n// comment\n```", chunks[8].Content)
+		assert.Equal(t, ChunkTypeContent, chunks[8].Metadata["chunk_type"])
+		assert.Equal(t, "Usage", chunks[8].Metadata["section"])
+	})
+
+	t.Run("Positions point into the text", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker(testMarkdown, "doc")
+
+		require.NoError(t, err)
+		for _, chunk := range chunks {
+			require.NotNil(t, chunk.StartPos)
+			require.NotNil(t, chunk.EndPos)
+			if chunk.Metadata["chunk_type"] == ChunkTypeContent {
+				assert.Equal(t, chunk.Content, testMarkdown[*chunk.StartPos:*chunk.EndPos])
+			}
+		}
+		assert.Equal(t, "# Installation", testMarkdown[*chunks[1].StartPos:*chunks[1].StartPos+14], "Expected summary to start at the heading")
+	})
+
+	t.Run("Paragraphs are grouped up to max chunk size", func(t *testing.T) {
+		chunker := MarkdownChunker(30)
+		text := "# Title\n\nFirst paragraph.\n\nSecond one.\n\nThird paragraph here."
+
+		chunks, err := chunker(text, "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.sec0", "doc.sec0.chunk0", "doc.sec0.chunk1"}, chunkPaths(chunks))
+		assert.Equal(t, "First paragraph.\n\nSecond one.", chunks[1].Content)
+		assert.Equal(t, "Third paragraph here.", chunks[2].Content)
+	})
+
+	t.Run("Skipped heading levels and closing hashes", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+		text := "## Second ##\n\nText.\n\n#### Deep\n\nMore.\n\n# C#\n\nLast."
+
+		chunks, err := chunker(text, "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.sec0", "doc.sec0.chunk0", "doc.sec0.sub0", "doc.sec0.sub0.chunk0", "doc.sec1", "doc.sec1.chunk0"}, chunkPaths(chunks))
+		assert.Equal(t, "Second", chunks[0].Metadata["section"])
+		assert.Equal(t, "C#", chunks[4].Metadata["section"])
+	})
+
+	t.Run("Headings inside code blocks are ignored", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+		text := "# Title\n\n```sh\n# not a heading\n```"
+
+		chunks, err := chunker(text, "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.sec0", "doc.sec0.chunk0"}, chunkPaths(chunks))
+	})
+
+	t.Run("Text without headings", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker("Just one paragraph.\n\nAnd another.", "doc")
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.chunk0"}, chunkPaths(chunks))
+	})
+
+	t.Run("Empty text", func(t *testing.T) {
+		chunker := MarkdownChunker(500)
+
+		chunks, err := chunker("  \n ", "doc")
+
+		require.NoError(t, err)
+		assert.Empty(t, chunks)
+	})
+
+	t.Run("Invalid max chunk size", func(t *testing.T) {
+		chunker := MarkdownChunker(0)
+
+		chunks, err := chunker("# Title", "doc")
+
+		assert.Error(t, err)
+		assert.Nil(t, chunks)
+	})
+
+	t.Run("Long summaries are truncated", func(t *testing.T) {
+		chunker := MarkdownChunker(20)
+
+		chunks, err := chunker("# Title\n\nA paragraph that is longer than the limit.", "doc")
+
+		require.NoError(t, err)
+		assert.LessOrEqual(t, len(chunks[0].Content), 20)
+		assert.Equal(t, "Title\nA paragraph", chunks[0].Content)
+	})
+}
+
+func TestMarkdownHintChunker(t *testing.T) {
+	t.Run("Headings come from the hints", func(t *testing.T) {
+		chunker := MarkdownHintChunker(500)
+		text := "## Results\n\nThe survey found:\n\n# 3 of 5 people agree\n\n## Summary\n\nDone."
+		hints := []model.Hint{
+			{Type: model.HintTypeHeading, Text: "Results", Level: 1, Offset: 0},
+			{Type: model.HintTypeHeading, Text: "Summary", Level: 1, Offset: strings.Index(text, "## Summary")},
+		}
+
+		chunks, err := chunker(text, "doc", hints)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.sec0", "doc.sec0.chunk0", "doc.sec1", "doc.sec1.chunk0"}, chunkPaths(chunks))
+		assert.Equal(t, "Results", chunks[0].Metadata["section"])
+		assert.Equal(t, 1, chunks[0].Metadata["level"], "Expected the level of the hint")
+		assert.Contains(t, chunks[1].Content, "# 3 of 5 people agree", "Expected unhinted line to stay content")
+	})
+
+	t.Run("Hints without headings", func(t *testing.T) {
+		chunker := MarkdownHintChunker(500)
+		text := "# 3 of 5 people agree\n\nText."
+
+		chunks, err := chunker(text, "doc", []model.Hint{{Type: model.HintTypePage, Page: 1, Offset: 0}})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"doc.chunk0"}, chunkPaths(chunks))
+	})
+
+	t.Run("Headings are parsed without hints", func(t *testing.T) {
+		chunker := MarkdownHintChunker(500)
+
+		chunks, err := chunker(testMarkdown, "doc", nil)
+
+		require.NoError(t, err)
+		expected, err := MarkdownChunker(500)(testMarkdown, "doc")
+		require.NoError(t, err)
+		assert.Equal(t, expected, chunks)
+	})
+}
+
+func TestPipelineMarkdownSummaries(t *testing.T) {
+	t.Run("Entities are not extracted from summary chunks", func(t *testing.T) {
+		calls := 0
+		pipeline := NewPipeline(MarkdownChunker(500), mockEmbedFunc)
+		pipeline.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+			calls++
+			return []*model.Entity{{Name: "Alice", Type: "PERSON"}}, nil
+		})
+
+		result, err := pipeline.ProcessWithExtraction("# Title\n\nAlice wrote this.", "doc")
+
+		require.NoError(t, err)
+		assert.Len(t, result.Chunks, 2, "Expected summary and content chunk")
+		assert.Equal(t, 1, calls, "Expected extraction only for the content chunk")
+		assert.Len(t, result.Entities, 1)
+	})
+}
@@ -0,0 +1,192 @@
+package pipeline
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/knights-analytics/hugot"
+	"github.com/knights-analytics/hugot/backends"
+	"github.com/knights-analytics/hugot/pipelines"
+	"github.com/siherrmann/grapher/helper"
+)
+
+// Models used by the default pipeline functions
+const (
+	SentenceModelName = "sentence-transformers/all-MiniLM-L6-v2"
+	NERModelName      = "KnightsAnalytics/distilbert-NER"
+	RerankerModelName = "KnightsAnalytics/jina-reranker-v1-tiny-en"
+)
+
+// DefaultModels is the registry used by DefaultChunker, DefaultEmbedder, DefaultBatchEmbedder,
+// DefaultEntityExtractor, DefaultRelationExtractor and DefaultReranker.
+// It lives as long as the process unless it is closed explicitly.
+var DefaultModels = NewModelRegistry()
+
+// ModelRegistry loads every ONNX model once into a shared hugot session and caches its pipeline,
+// so e.g. the semantic chunker and the embedder use the same all-MiniLM-L6-v2 instance.
+// Models are loaded lazily on first use. Every pipeline runs one call at a time, so the functions
+// created from the registry are safe for concurrent use (e.g. by extraction or ingestion workers).
+// Close waits for running pipelines and destroys the session and all pipelines,
+// functions created from the registry return an error afterwards.
+type ModelRegistry struct {
+	mu        sync.Mutex
+	runMu     sync.RWMutex // Held for reading while a pipeline runs and for writing by Close
+	session   *hugot.Session
+	pipelines map[string]backends.Pipeline
+	running   map[string]*sync.Mutex // Serializes the runs of every pipeline
+	options   *helper.ModelOptions   // nil reads the options from the environment on load
+	closed    bool
+}
+
+// NewModelRegistry creates an empty model registry
+func NewModelRegistry() *ModelRegistry {
+	return &ModelRegistry{
+		pipelines: make(map[string]backends.Pipeline),
+		running:   make(map[string]*sync.Mutex),
+	}
+}
+
+// NewModelRegistryWithOptions creates an empty model registry loading the models with the given options
+// (e.g. a custom model directory, offline mode or expected checksums) instead of the environment
+func NewModelRegistryWithOptions(options *helper.ModelOptions) *ModelRegistry {
+	r := NewModelRegistry()
+	r.options = options
+	return r
+}
+
+// Close destroys the session with all loaded models, closing a closed registry is a no-op.
+// It waits until the running pipelines are done, later runs return an error.
+func (r *ModelRegistry) Close() error {
+	r.runMu.Lock()
+	defer r.runMu.Unlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.closed {
+		return nil
+	}
+	r.closed = true
+	r.pipelines = nil
+
+	if r.session == nil {
+		return nil
+	}
+	err := r.session.Destroy()
+	r.session = nil
+	if err != nil {
+		return fmt.Errorf("failed to destroy hugot session: %w", err)
+	}
+	return nil
+}
+
+// Loaded returns the number of loaded pipelines
+func (r *ModelRegistry) Loaded() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pipelines)
+}
+
+// lockPipeline waits until no other call runs the pipeline with the name and locks it,
+// the registry can't be closed until it is unlocked again.
+// Returns the function unlocking the pipeline or an error if the registry is closed.
+func (r *ModelRegistry) lockPipeline(name string) (func(), error) {
+	r.runMu.RLock()
+
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		r.runMu.RUnlock()
+		return nil, fmt.Errorf("model registry is closed")
+	}
+	lock, ok := r.running[name]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.running[name] = lock
+	}
+	r.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+		r.runMu.RUnlock()
+	}, nil
+}
+
+// sentencePipeline returns the all-MiniLM-L6-v2 feature extraction pipeline
+func (r *ModelRegistry) sentencePipeline() (*pipelines.FeatureExtractionPipeline, error) {
+	return loadPipeline(r, SentenceModelName, "onnx/model.onnx", hugot.FeatureExtractionConfig{
+		Name:         SentenceModelName,
+		OnnxFilename: "onnx/model.onnx",
+	})
+}
+
+// nerPipeline returns the distilbert-NER token classification pipeline,
+// shared by the entity and the relation extractor
+func (r *ModelRegistry) nerPipeline() (*pipelines.TokenClassificationPipeline, error) {
+	return loadPipeline(r, NERModelName, "model.onnx", hugot.TokenClassificationConfig{
+		Name: NERModelName,
+		Options: []hugot.TokenClassificationOption{
+			pipelines.WithSimpleAggregation(),
+			pipelines.WithIgnoreLabels([]string{"O"}), // Ignore non-entity tokens
+		},
+	})
+}
+
+// rerankerPipeline returns the jina-reranker cross encoder pipeline
+func (r *ModelRegistry) rerankerPipeline() (*pipelines.CrossEncoderPipeline, error) {
+	return loadPipeline(r, RerankerModelName, "model.onnx", hugot.CrossEncoderConfig{
+		Name: RerankerModelName,
+	})
+}
+
+// loadPipeline returns the cached pipeline with the config name or downloads the model and creates it
+func loadPipeline[T backends.Pipeline](r *ModelRegistry, modelName string, onnxFilePath string, config backends.PipelineConfig[T]) (T, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var empty T
+	if r.closed {
+		return empty, fmt.Errorf("model registry is closed")
+	}
+
+	if cached, ok := r.pipelines[config.Name]; ok {
+		typed, ok := cached.(T)
+		if !ok {
+			return empty, fmt.Errorf("pipeline %s has a different type", config.Name)
+		}
+		return typed, nil
+	}
+
+	// Prepare model (download if needed)
+	options := r.options
+	if options == nil {
+		var err error
+		options, err = helper.NewModelOptions()
+		if err != nil {
+			return empty, err
+		}
+	}
+	modelPath, err := options.PrepareModel(modelName, onnxFilePath)
+	if err != nil {
+		return empty, err
+	}
+
+	// Initialize the shared hugot session with Go backend
+	if r.session == nil {
+		session, err := hugot.NewGoSession()
+		if err != nil {
+			return empty, fmt.Errorf("failed to create hugot session: %w", err)
+		}
+		r.session = session
+	}
+
+	config.ModelPath = modelPath
+	created, err := hugot.NewPipeline(r.session, config)
+	if err != nil {
+		return empty, fmt.Errorf("failed to create pipeline %s: %w", config.Name, err)
+	}
+
+	r.pipelines[config.Name] = created
+	return created, nil
+}
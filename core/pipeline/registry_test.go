@@ -0,0 +1,145 @@
+package pipeline
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/siherrmann/grapher/helper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModelRegistry(t *testing.T) {
+	t.Run("New registry loads nothing", func(t *testing.T) {
+		registry := NewModelRegistry()
+
+		assert.Equal(t, 0, registry.Loaded())
+		assert.NoError(t, registry.Close(), "Expected closing an unused registry to not return an error")
+	})
+
+	t.Run("Closing twice is a no-op", func(t *testing.T) {
+		registry := NewModelRegistry()
+
+		require.NoError(t, registry.Close())
+		assert.NoError(t, registry.Close())
+	})
+
+	t.Run("Closed registry returns errors", func(t *testing.T) {
+		registry := NewModelRegistry()
+		require.NoError(t, registry.Close())
+
+		_, err := registry.Embedder()
+		assert.ErrorContains(t, err, "model registry is closed")
+
+		_, err = registry.BatchEmbedder()
+		assert.ErrorContains(t, err, "model registry is closed")
+
+		_, err = registry.EntityExtractor()
+		assert.ErrorContains(t, err, "model registry is closed")
+
+		_, err = registry.RelationExtractor()
+		assert.ErrorContains(t, err, "model registry is closed")
+
+		_, err = registry.Reranker()
+		assert.ErrorContains(t, err, "model registry is closed")
+
+		_, err = registry.Chunker(500, 0.7)("Some text.", "doc")
+		assert.ErrorContains(t, err, "model registry is closed")
+	})
+
+	t.Run("Pipeline runs are serialized", func(t *testing.T) {
+		registry := NewModelRegistry()
+		defer registry.Close()
+
+		var running, maxRunning int32
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				unlock, err := registry.lockPipeline(NERModelName)
+				if !assert.NoError(t, err) {
+					return
+				}
+				defer unlock()
+
+				current := atomic.AddInt32(&running, 1)
+				for {
+					seen := atomic.LoadInt32(&maxRunning)
+					if current <= seen || atomic.CompareAndSwapInt32(&maxRunning, seen, current) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), maxRunning, "Expected one run of the pipeline at a time")
+
+		// Other pipelines aren't blocked
+		unlock, err := registry.lockPipeline(NERModelName)
+		require.NoError(t, err)
+		defer unlock()
+		unlockSentence, err := registry.lockPipeline(SentenceModelName)
+		require.NoError(t, err)
+		unlockSentence()
+	})
+
+	t.Run("Close waits for running pipelines", func(t *testing.T) {
+		registry := NewModelRegistry()
+
+		unlock, err := registry.lockPipeline(NERModelName)
+		require.NoError(t, err)
+
+		var closed atomic.Bool
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			assert.NoError(t, registry.Close())
+			closed.Store(true)
+		}()
+
+		time.Sleep(10 * time.Millisecond)
+		assert.False(t, closed.Load(), "Expected Close to wait for the running pipeline")
+
+		unlock()
+		<-done
+		assert.True(t, closed.Load())
+
+		_, err = registry.lockPipeline(NERModelName)
+		assert.ErrorContains(t, err, "model registry is closed", "Expected runs after Close to fail")
+	})
+
+	t.Run("Registry uses model options", func(t *testing.T) {
+		dir := t.TempDir()
+		registry := NewModelRegistryWithOptions(&helper.ModelOptions{Dir: dir, Offline: true})
+		defer registry.Close()
+
+		_, err := registry.Embedder()
+		assert.ErrorContains(t, err, "offline mode", "Expected missing model to fail in offline mode")
+		assert.ErrorContains(t, err, dir)
+		assert.Equal(t, 0, registry.Loaded())
+	})
+
+	t.Run("Chunker and embedder share the model", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping model registry test in short mode (requires model download)")
+		}
+
+		registry := NewModelRegistry()
+		defer registry.Close()
+
+		_, err := registry.Chunker(500, 0.7)("First sentence. Second sentence.", "doc")
+		require.NoError(t, err)
+		_, err = registry.Embedder()
+		require.NoError(t, err)
+		_, err = registry.BatchEmbedder()
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, registry.Loaded(), "Expected the sentence model to be loaded once")
+	})
+}
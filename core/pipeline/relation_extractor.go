@@ -1,14 +1,10 @@
 package pipeline
 
 import (
-	"fmt"
 	"math"
 	"regexp"
 	"strings"
 
-	"github.com/knights-analytics/hugot"
-	"github.com/knights-analytics/hugot/pipelines"
-	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
 )
 
@@ -16,34 +12,15 @@ import (
 // Uses token classification to detect citation-related entities and references
 // Detects: Citations, references, and relationships between entities
 func DefaultRelationExtractor() (RelationExtractFunc, error) {
-	// Prepare citation detection model (using NER to detect citation entities)
-	modelName := "KnightsAnalytics/distilbert-NER"
-	modelPath, err := helper.PrepareModel(modelName, "model.onnx")
-	if err != nil {
-		return nil, err
-	}
-
-	// Initialize hugot session with Go backend
-	session, err := hugot.NewGoSession()
-	if err != nil {
-		return nil, fmt.Errorf("failed to create hugot session: %w", err)
-	}
+	return DefaultModels.RelationExtractor()
+}
 
-	// Create token classification pipeline for citation detection
-	config := hugot.TokenClassificationConfig{
-		ModelPath: modelPath,
-		Name:      "citation-pipeline",
-		Options: []hugot.TokenClassificationOption{
-			pipelines.WithSimpleAggregation(),
-			pipelines.WithIgnoreLabels([]string{"O"}),
-		},
-	}
-	citationPipeline, err := hugot.NewPipeline(session, config)
-	if err != nil {
-		if destroyErr := session.Destroy(); destroyErr != nil {
-			return nil, fmt.Errorf("failed to create citation pipeline: %w (cleanup error: %v)", err, destroyErr)
-		}
-		return nil, fmt.Errorf("failed to create citation pipeline: %w", err)
+// RelationExtractor creates a relation extractor like DefaultRelationExtractor with the distilbert-NER model
+// of the registry, the model is shared with the entity extractor
+func (r *ModelRegistry) RelationExtractor() (RelationExtractFunc, error) {
+	// Citation detection uses NER to detect citation entities
+	if _, err := r.nerPipeline(); err != nil {
+		return nil, err
 	}
 
 	// Fallback patterns for specific citation formats
@@ -57,11 +34,21 @@ func DefaultRelationExtractor() (RelationExtractFunc, error) {
 	}
 
 	return func(text string, chunkPath string, entities []*model.Entity) ([]*model.Edge, error) {
+		citationPipeline, err := r.nerPipeline()
+		if err != nil {
+			return nil, err
+		}
+
 		var edges []*model.Edge
 
 		// Use NER model to detect citation-related entities
 		// The model can detect MISC (miscellaneous) entities which often include citations
+		unlock, err := r.lockPipeline(NERModelName)
+		if err != nil {
+			return nil, err
+		}
 		result, err := citationPipeline.RunPipeline([]string{text})
+		unlock()
 		if err == nil && len(result.Entities) > 0 {
 			for _, entity := range result.Entities[0] {
 				// Look for entities that might be citations
@@ -150,9 +137,10 @@ func DefaultRelationExtractor() (RelationExtractFunc, error) {
 
 						// If entities are within 100 characters, create an entity mention edge
 						if distance < 100 {
+							sourceID, targetID := entity1.ID, entity2.ID
 							edge := &model.Edge{
-								SourceEntityID: &entity1.ID,
-								TargetEntityID: &entity2.ID,
+								SourceEntityID: &sourceID,
+								TargetEntityID: &targetID,
 								EdgeType:       model.EdgeTypeEntityMention,
 								Weight:         calculateCoOccurrenceWeight(distance),
 								Bidirectional:  true,
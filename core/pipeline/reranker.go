@@ -0,0 +1,50 @@
+package pipeline
+
+import "fmt"
+
+// DefaultReranker creates a reranker using a cross-encoder model
+// Uses the jina-reranker-v1-tiny-en model which scores query-document pairs jointly
+func DefaultReranker() (RerankFunc, error) {
+	return DefaultModels.Reranker()
+}
+
+// Reranker creates a reranker like DefaultReranker with the jina-reranker model of the registry
+func (r *ModelRegistry) Reranker() (RerankFunc, error) {
+	if _, err := r.rerankerPipeline(); err != nil {
+		return nil, err
+	}
+
+	return func(query string, documents []string) ([]float32, error) {
+		if len(documents) == 0 {
+			return nil, nil
+		}
+
+		crossEncoderPipeline, err := r.rerankerPipeline()
+		if err != nil {
+			return nil, err
+		}
+
+		// Score all documents against the query
+		unlock, err := r.lockPipeline(RerankerModelName)
+		if err != nil {
+			return nil, err
+		}
+		result, err := crossEncoderPipeline.RunPipeline(query, documents)
+		unlock()
+		if err != nil {
+			return nil, fmt.Errorf("failed to rerank documents: %w", err)
+		}
+
+		if len(result.Results) != len(documents) {
+			return nil, fmt.Errorf("expected %d scores, got %d", len(documents), len(result.Results))
+		}
+
+		// Results are sorted by score, map them back to the input order
+		scores := make([]float32, len(documents))
+		for _, r := range result.Results {
+			scores[r.Index] = r.Score
+		}
+
+		return scores, nil
+	}, nil
+}
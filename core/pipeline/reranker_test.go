@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultReranker(t *testing.T) {
+	// Note: DefaultReranker uses hugot which requires downloading models
+	// These tests may take longer on first run
+
+	t.Run("Create reranker successfully", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultReranker test in short mode (requires model download)")
+		}
+
+		reranker, err := DefaultReranker()
+
+		require.NoError(t, err)
+		assert.NotNil(t, reranker)
+	})
+
+	t.Run("Relevant document scores higher", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultReranker test in short mode (requires model download)")
+		}
+
+		reranker, err := DefaultReranker()
+		require.NoError(t, err)
+
+		documents := []string{
+			"The recipe needs two cups of flour and one egg.",
+			"Paris is the capital and most populous city of France.",
+		}
+		scores, err := reranker("What is the capital of France?", documents)
+
+		require.NoError(t, err)
+		require.Len(t, scores, 2, "Expected one score per document")
+		assert.Greater(t, scores[1], scores[0], "Expected relevant document to score higher")
+	})
+
+	t.Run("Scores keep input order", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultReranker test in short mode (requires model download)")
+		}
+
+		reranker, err := DefaultReranker()
+		require.NoError(t, err)
+
+		query := "What is the capital of France?"
+		documents := []string{
+			"Paris is the capital and most populous city of France.",
+			"The recipe needs two cups of flour and one egg.",
+		}
+		scores, err := reranker(query, documents)
+		require.NoError(t, err)
+
+		reversed, err := reranker(query, []string{documents[1], documents[0]})
+		require.NoError(t, err)
+
+		assert.InDelta(t, scores[0], reversed[1], 1e-4, "Expected score to follow its document")
+		assert.InDelta(t, scores[1], reversed[0], 1e-4, "Expected score to follow its document")
+	})
+
+	t.Run("No documents returns no scores", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("Skipping DefaultReranker test in short mode (requires model download)")
+		}
+
+		reranker, err := DefaultReranker()
+		require.NoError(t, err)
+
+		scores, err := reranker("query", nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, scores)
+	})
+}
@@ -0,0 +1,266 @@
+package resolution
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// EmbedFunc generates the embeddings of several entity names in one call
+type EmbedFunc func(texts []string) ([][]float32, error)
+
+// Config controls when two entities of the same type are considered the same
+type Config struct {
+	StringThreshold    float64 // Minimum name similarity (see Similarity)
+	EmbeddingThreshold float64 // Minimum cosine similarity of the name embeddings, only used with an embedder
+}
+
+// DefaultConfig returns the default resolution configuration
+func DefaultConfig() Config {
+	return Config{
+		StringThreshold:    0.92,
+		EmbeddingThreshold: 0.9,
+	}
+}
+
+// Cluster is a group of entities that refer to the same real-world entity
+type Cluster struct {
+	Canonical  *model.Entity   // The entity the duplicates are merged into
+	Duplicates []*model.Entity // The other entities of the cluster
+}
+
+// Resolver clusters entities by name and embedding similarity
+type Resolver struct {
+	config   Config
+	embedder EmbedFunc
+}
+
+// NewResolver creates a new resolver.
+// The embedder is optional, it embeds the names of entities without a stored embedding.
+// Without it only the stored embeddings are compared.
+func NewResolver(config Config, embedder EmbedFunc) *Resolver {
+	return &Resolver{
+		config:   config,
+		embedder: embedder,
+	}
+}
+
+// Resolve clusters the given entities and returns all clusters with duplicates.
+// Only entities of the same type are compared. Two entities match if their names
+// or their embeddings are similar enough. Clusters are built with complete linkage,
+// an entity only joins a cluster if it matches every member, so a chain of
+// similar names ("A" matches "B", "B" matches "C") doesn't merge unrelated entities.
+// The canonical entity of a cluster is the one with the most mentions,
+// then the one with the longest name.
+func (r *Resolver) Resolve(entities []*model.Entity) ([]*Cluster, error) {
+	embeddings, err := r.embeddings(entities)
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect the matching pairs with their margin above the threshold
+	var matches []match
+	for i := range entities {
+		for j := i + 1; j < len(entities); j++ {
+			if entities[i].Type != entities[j].Type {
+				continue
+			}
+
+			margin := Similarity(entities[i].Name, entities[j].Name) - r.config.StringThreshold
+			if embeddings[i] != nil && embeddings[j] != nil {
+				margin = math.Max(margin, cosineSimilarity(embeddings[i], embeddings[j])-r.config.EmbeddingThreshold)
+			}
+			if margin >= 0 {
+				matches = append(matches, match{i: i, j: j, margin: margin})
+			}
+		}
+	}
+
+	assignment := completeLinkage(len(entities), matches)
+
+	// Group the entities by their cluster, keeping the input order
+	groups := make(map[int][]*model.Entity)
+	var roots []int
+	for i, entity := range entities {
+		root := assignment[i]
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], entity)
+	}
+
+	var clusters []*Cluster
+	for _, root := range roots {
+		members := groups[root]
+		if len(members) < 2 {
+			continue
+		}
+
+		sort.SliceStable(members, func(i, j int) bool {
+			return isMoreCanonical(members[i], members[j])
+		})
+		clusters = append(clusters, &Cluster{
+			Canonical:  members[0],
+			Duplicates: members[1:],
+		})
+	}
+
+	return clusters, nil
+}
+
+// embeddings returns the embedding of every entity, nil if it has none.
+// Stored embeddings are used as they are, the names of the other entities are embedded in one batch.
+func (r *Resolver) embeddings(entities []*model.Entity) ([][]float32, error) {
+	embeddings := make([][]float32, len(entities))
+	var missing []int
+	var names []string
+	for i, entity := range entities {
+		if len(entity.Embedding) > 0 {
+			embeddings[i] = entity.Embedding
+			continue
+		}
+		missing = append(missing, i)
+		names = append(names, entity.Name)
+	}
+
+	if r.embedder == nil || len(missing) == 0 {
+		return embeddings, nil
+	}
+
+	embedded, err := r.embedder(names)
+	if err != nil {
+		return nil, helper.NewError("embed entity names", err)
+	}
+	if len(embedded) != len(missing) {
+		return nil, helper.NewError("embed entity names", fmt.Errorf("got %d embeddings for %d names", len(embedded), len(missing)))
+	}
+	for k, i := range missing {
+		embeddings[i] = embedded[k]
+	}
+
+	return embeddings, nil
+}
+
+// isMoreCanonical checks if entity a should be preferred over entity b as canonical entity
+func isMoreCanonical(a *model.Entity, b *model.Entity) bool {
+	mentionsA := mentionCount(a)
+	mentionsB := mentionCount(b)
+	if mentionsA != mentionsB {
+		return mentionsA > mentionsB
+	}
+	return len([]rune(a.Name)) > len([]rune(b.Name))
+}
+
+// mentionCount returns the mention_count of an entity, defaulting to 1
+func mentionCount(entity *model.Entity) float64 {
+	switch v := entity.Metadata["mention_count"].(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	default:
+		return 1
+	}
+}
+
+// cosineSimilarity calculates cosine similarity between two vectors
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dotProduct, normA, normB float64
+	for i := range a {
+		dotProduct += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dotProduct / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// match is a pair of matching entities, margin is how far their similarity exceeds the threshold
+type match struct {
+	i      int
+	j      int
+	margin float64
+}
+
+// completeLinkage clusters the indexes 0..n-1 by agglomerative clustering with complete linkage.
+// The linkage of two clusters is the smallest margin of all their member pairs, clusters with a
+// non-matching pair are never merged. The pair of clusters with the highest linkage is merged first.
+// Returns the cluster of every index, a cluster is identified by its smallest index.
+func completeLinkage(n int, matches []match) []int {
+	assignment := make([]int, n)
+	for i := range assignment {
+		assignment[i] = i
+	}
+
+	// linkage between the clusters identified by their smallest index, only for matching clusters
+	linkage := make(map[[2]int]float64, len(matches))
+	for _, m := range matches {
+		linkage[[2]int{m.i, m.j}] = m.margin
+	}
+
+	for len(linkage) > 0 {
+		// Find the pair with the highest linkage, ties go to the smallest indexes
+		var best [2]int
+		bestMargin := math.Inf(-1)
+		for pair, margin := range linkage {
+			if margin > bestMargin || (margin == bestMargin && (pair[0] < best[0] || (pair[0] == best[0] && pair[1] < best[1]))) {
+				best = pair
+				bestMargin = margin
+			}
+		}
+
+		// Merge the second cluster into the first one, best[0] < best[1]
+		keep, drop := best[0], best[1]
+		for i := range assignment {
+			if assignment[i] == drop {
+				assignment[i] = keep
+			}
+		}
+
+		// The merged cluster only matches the clusters both parts matched, with the smaller linkage
+		next := make(map[[2]int]float64, len(linkage))
+		for pair, margin := range linkage {
+			if pair == best {
+				continue
+			}
+			if pair[0] != keep && pair[1] != keep && pair[0] != drop && pair[1] != drop {
+				next[pair] = margin
+				continue
+			}
+
+			other := pair[0]
+			if other == keep || other == drop {
+				other = pair[1]
+			}
+			keepMargin, keepOk := linkage[orderedPair(keep, other)]
+			dropMargin, dropOk := linkage[orderedPair(drop, other)]
+			if keepOk && dropOk {
+				next[orderedPair(keep, other)] = math.Min(keepMargin, dropMargin)
+			}
+		}
+		linkage = next
+	}
+
+	return assignment
+}
+
+// orderedPair returns the key of the clusters a and b with the smaller index first
+func orderedPair(a int, b int) [2]int {
+	if a < b {
+		return [2]int{a, b}
+	}
+	return [2]int{b, a}
+}
@@ -0,0 +1,166 @@
+package resolution
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolverResolve(t *testing.T) {
+	t.Run("Cluster aliases by name", func(t *testing.T) {
+		ibm := &model.Entity{Name: "IBM", Type: "ORG"}
+		dotted := &model.Entity{Name: "I.B.M.", Type: "ORG"}
+		long := &model.Entity{Name: "International Business Machines", Type: "ORG"}
+		other := &model.Entity{Name: "Microsoft", Type: "ORG"}
+
+		clusters, err := NewResolver(DefaultConfig(), nil).Resolve([]*model.Entity{ibm, other, dotted, long})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1, "Expected one cluster")
+		assert.Equal(t, long, clusters[0].Canonical, "Expected longest name to be canonical")
+		assert.ElementsMatch(t, []*model.Entity{ibm, dotted}, clusters[0].Duplicates)
+	})
+
+	t.Run("Canonical entity has most mentions", func(t *testing.T) {
+		ibm := &model.Entity{Name: "IBM", Type: "ORG", Metadata: model.Metadata{"mention_count": float64(10)}}
+		long := &model.Entity{Name: "International Business Machines", Type: "ORG", Metadata: model.Metadata{"mention_count": float64(2)}}
+
+		clusters, err := NewResolver(DefaultConfig(), nil).Resolve([]*model.Entity{long, ibm})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1)
+		assert.Equal(t, ibm, clusters[0].Canonical)
+	})
+
+	t.Run("Different types are not clustered", func(t *testing.T) {
+		person := &model.Entity{Name: "Jordan", Type: "PERSON"}
+		location := &model.Entity{Name: "Jordan", Type: "LOCATION"}
+
+		clusters, err := NewResolver(DefaultConfig(), nil).Resolve([]*model.Entity{person, location})
+
+		assert.NoError(t, err)
+		assert.Empty(t, clusters)
+	})
+
+	t.Run("Matching names form one cluster", func(t *testing.T) {
+		a := &model.Entity{Name: "ACME Corp", Type: "ORG"}
+		b := &model.Entity{Name: "Acme", Type: "ORG"}
+		c := &model.Entity{Name: "A.C.M.E.", Type: "ORG"}
+
+		config := DefaultConfig()
+		clusters, err := NewResolver(config, nil).Resolve([]*model.Entity{a, b, c})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1)
+		assert.Len(t, clusters[0].Duplicates, 2)
+	})
+
+	t.Run("Clusters don't chain", func(t *testing.T) {
+		// Alpha matches Bravo and Bravo matches Charlie, but Alpha doesn't match Charlie
+		alpha := &model.Entity{Name: "Alpha", Type: "ORG", Embedding: []float32{1, 0, 0}}
+		bravo := &model.Entity{Name: "Bravo", Type: "ORG", Embedding: []float32{0.95, 0.31, 0}}
+		charlie := &model.Entity{Name: "Charlie", Type: "ORG", Embedding: []float32{0.8, 0.6, 0}}
+
+		clusters, err := NewResolver(DefaultConfig(), nil).Resolve([]*model.Entity{alpha, bravo, charlie})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1, "Expected only the closest pair to be clustered")
+		assert.ElementsMatch(t, []*model.Entity{alpha, bravo}, append([]*model.Entity{clusters[0].Canonical}, clusters[0].Duplicates...))
+	})
+
+	t.Run("Cluster by embedding similarity", func(t *testing.T) {
+		embeddings := map[string][]float32{
+			"Big Blue": {1, 0, 0},
+			"IBM":      {0.99, 0.1, 0},
+			"Apple":    {0, 1, 0},
+		}
+		calls := 0
+		embedder := func(texts []string) ([][]float32, error) {
+			calls++
+			result := make([][]float32, len(texts))
+			for i, text := range texts {
+				result[i] = embeddings[text]
+			}
+			return result, nil
+		}
+
+		bigBlue := &model.Entity{Name: "Big Blue", Type: "ORG"}
+		ibm := &model.Entity{Name: "IBM", Type: "ORG"}
+		apple := &model.Entity{Name: "Apple", Type: "ORG"}
+
+		clusters, err := NewResolver(DefaultConfig(), embedder).Resolve([]*model.Entity{bigBlue, ibm, apple})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1)
+		assert.Equal(t, bigBlue, clusters[0].Canonical)
+		assert.Equal(t, []*model.Entity{ibm}, clusters[0].Duplicates)
+		assert.Equal(t, 1, calls, "Expected all names to be embedded in one batch")
+	})
+
+	t.Run("Stored embeddings are not embedded again", func(t *testing.T) {
+		embedder := func(texts []string) ([][]float32, error) {
+			return nil, errors.New("unexpected embedding")
+		}
+
+		bigBlue := &model.Entity{Name: "Big Blue", Type: "ORG", Embedding: []float32{1, 0, 0}}
+		ibm := &model.Entity{Name: "IBM", Type: "ORG", Embedding: []float32{0.99, 0.1, 0}}
+
+		clusters, err := NewResolver(DefaultConfig(), embedder).Resolve([]*model.Entity{bigBlue, ibm})
+
+		assert.NoError(t, err)
+		require.Len(t, clusters, 1)
+		assert.Equal(t, []*model.Entity{ibm}, clusters[0].Duplicates)
+	})
+
+	t.Run("Embedder error is returned", func(t *testing.T) {
+		embedder := func(texts []string) ([][]float32, error) {
+			return nil, errors.New("embedding failed")
+		}
+
+		_, err := NewResolver(DefaultConfig(), embedder).Resolve([]*model.Entity{{Name: "IBM", Type: "ORG"}})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("No entities", func(t *testing.T) {
+		clusters, err := NewResolver(DefaultConfig(), nil).Resolve(nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, clusters)
+	})
+}
+
+func TestCompleteLinkage(t *testing.T) {
+	t.Run("Merges clusters matching in every pair", func(t *testing.T) {
+		matches := []match{
+			{i: 0, j: 1, margin: 0.1},
+			{i: 0, j: 2, margin: 0.05},
+			{i: 1, j: 2, margin: 0.02},
+		}
+
+		assignment := completeLinkage(4, matches)
+
+		assert.Equal(t, []int{0, 0, 0, 3}, assignment)
+	})
+
+	t.Run("Highest linkage is merged first", func(t *testing.T) {
+		// 1 matches 0 and 2, but 0 and 2 don't match
+		matches := []match{
+			{i: 0, j: 1, margin: 0.01},
+			{i: 1, j: 2, margin: 0.08},
+		}
+
+		assignment := completeLinkage(3, matches)
+
+		assert.Equal(t, []int{0, 1, 1}, assignment)
+	})
+
+	t.Run("Singletons stay separate", func(t *testing.T) {
+		assignment := completeLinkage(2, nil)
+
+		assert.Equal(t, []int{0, 1}, assignment)
+	})
+}
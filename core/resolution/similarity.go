@@ -0,0 +1,183 @@
+package resolution
+
+import (
+	"strings"
+	"unicode"
+)
+
+// AcronymScore is the similarity of a name and its acronym (e.g. "IBM" and "International Business Machines")
+const AcronymScore = 0.95
+
+// legalSuffixes are dropped from the end of names before comparing them
+var legalSuffixes = map[string]bool{
+	"inc":          true,
+	"incorporated": true,
+	"corp":         true,
+	"corporation":  true,
+	"co":           true,
+	"company":      true,
+	"ltd":          true,
+	"limited":      true,
+	"llc":          true,
+	"plc":          true,
+	"gmbh":         true,
+	"ag":           true,
+}
+
+// acronymStopwords are skipped when building an acronym
+var acronymStopwords = map[string]bool{
+	"of":  true,
+	"the": true,
+	"and": true,
+	"for": true,
+	"in":  true,
+	"on":  true,
+	"at":  true,
+}
+
+// Normalize normalizes an entity name for comparison.
+// It follows the normalize_entity_name SQL function: dots and apostrophes are removed
+// ("I.B.M." becomes "ibm"), all other runs of non-alphanumeric characters become
+// a single space and the result is lowercased.
+func Normalize(name string) string {
+	var builder strings.Builder
+	pendingSpace := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case r == '.' || r == '\'':
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingSpace && builder.Len() > 0 {
+				builder.WriteRune(' ')
+			}
+			pendingSpace = false
+			builder.WriteRune(r)
+		default:
+			pendingSpace = true
+		}
+	}
+	return builder.String()
+}
+
+// Acronym builds the lowercase acronym of a name from the first letter of each word,
+// skipping stopwords and legal suffixes ("International Business Machines Corp." becomes "ibm")
+func Acronym(name string) string {
+	var builder strings.Builder
+	for _, word := range strings.Fields(stripLegalSuffixes(Normalize(name))) {
+		if acronymStopwords[word] {
+			continue
+		}
+		for _, r := range word {
+			builder.WriteRune(r)
+			break
+		}
+	}
+	return builder.String()
+}
+
+// Similarity scores how likely two names refer to the same entity, between 0 and 1.
+// Names that are equal after normalization (ignoring spaces and legal suffixes) score 1,
+// a name and its acronym score AcronymScore and all other names are compared by
+// Jaro-Winkler similarity of their normalized forms.
+func Similarity(a string, b string) float64 {
+	normalizedA := stripLegalSuffixes(Normalize(a))
+	normalizedB := stripLegalSuffixes(Normalize(b))
+	if normalizedA == "" || normalizedB == "" {
+		return 0
+	}
+
+	compactA := strings.ReplaceAll(normalizedA, " ", "")
+	compactB := strings.ReplaceAll(normalizedB, " ", "")
+	if compactA == compactB {
+		return 1
+	}
+
+	if isAcronymOf(compactA, normalizedB) || isAcronymOf(compactB, normalizedA) {
+		return AcronymScore
+	}
+
+	return jaroWinkler(normalizedA, normalizedB)
+}
+
+// isAcronymOf checks if the compact name is the acronym of the multi word name
+func isAcronymOf(compact string, name string) bool {
+	if len(strings.Fields(name)) < 2 || len([]rune(compact)) < 2 {
+		return false
+	}
+	return compact == Acronym(name)
+}
+
+// stripLegalSuffixes removes trailing legal suffixes like "inc" or "ltd" from a normalized name.
+// A name that only consists of suffixes is kept as is.
+func stripLegalSuffixes(normalized string) string {
+	words := strings.Fields(normalized)
+	end := len(words)
+	for end > 1 && legalSuffixes[words[end-1]] {
+		end--
+	}
+	return strings.Join(words[:end], " ")
+}
+
+// jaroWinkler returns the Jaro-Winkler similarity of two strings
+func jaroWinkler(a string, b string) float64 {
+	runesA := []rune(a)
+	runesB := []rune(b)
+	if len(runesA) == 0 && len(runesB) == 0 {
+		return 1
+	}
+	if len(runesA) == 0 || len(runesB) == 0 {
+		return 0
+	}
+
+	matchDistance := max(len(runesA), len(runesB))/2 - 1
+	if matchDistance < 0 {
+		matchDistance = 0
+	}
+
+	matchedA := make([]bool, len(runesA))
+	matchedB := make([]bool, len(runesB))
+	matches := 0
+	for i := range runesA {
+		start := max(0, i-matchDistance)
+		end := min(len(runesB), i+matchDistance+1)
+		for j := start; j < end; j++ {
+			if matchedB[j] || runesA[i] != runesB[j] {
+				continue
+			}
+			matchedA[i] = true
+			matchedB[j] = true
+			matches++
+			break
+		}
+	}
+	if matches == 0 {
+		return 0
+	}
+
+	// Count transpositions of matched characters
+	transpositions := 0
+	j := 0
+	for i := range runesA {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if runesA[i] != runesB[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(runesA)) + m/float64(len(runesB)) + (m-float64(transpositions)/2)/m) / 3
+
+	// Boost for a common prefix of up to 4 characters
+	prefix := 0
+	for prefix < min(4, len(runesA), len(runesB)) && runesA[prefix] == runesB[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*0.1*(1-jaro)
+}
@@ -0,0 +1,77 @@
+package resolution
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("Remove dots and lowercase", func(t *testing.T) {
+		assert.Equal(t, "ibm", Normalize("I.B.M."))
+	})
+
+	t.Run("Collapse punctuation and whitespace", func(t *testing.T) {
+		assert.Equal(t, "johnson johnson", Normalize("  Johnson & Johnson "))
+	})
+
+	t.Run("Remove apostrophes", func(t *testing.T) {
+		assert.Equal(t, "mcdonalds", Normalize("McDonald's"))
+	})
+
+	t.Run("Keep unicode letters", func(t *testing.T) {
+		assert.Equal(t, "münchen", Normalize("München"))
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		assert.Equal(t, "", Normalize("..."))
+	})
+}
+
+func TestAcronym(t *testing.T) {
+	t.Run("Acronym of multi word name", func(t *testing.T) {
+		assert.Equal(t, "ibm", Acronym("International Business Machines"))
+	})
+
+	t.Run("Acronym skips stopwords and legal suffixes", func(t *testing.T) {
+		assert.Equal(t, "fbi", Acronym("The Federal Bureau of Investigation Inc."))
+	})
+}
+
+func TestSimilarity(t *testing.T) {
+	t.Run("Equal after normalization", func(t *testing.T) {
+		assert.Equal(t, 1.0, Similarity("IBM", "I.B.M."))
+		assert.Equal(t, 1.0, Similarity("Acme Inc.", "ACME"))
+	})
+
+	t.Run("Acronym", func(t *testing.T) {
+		assert.Equal(t, AcronymScore, Similarity("IBM", "International Business Machines"))
+		assert.Equal(t, AcronymScore, Similarity("International Business Machines Corporation", "I.B.M."))
+	})
+
+	t.Run("Similar spelling", func(t *testing.T) {
+		score := Similarity("Jon Smith", "John Smith")
+		assert.Greater(t, score, 0.9, "Expected typo to score high")
+	})
+
+	t.Run("Different names", func(t *testing.T) {
+		score := Similarity("Microsoft", "Apple")
+		assert.Less(t, score, 0.6, "Expected different names to score low")
+	})
+
+	t.Run("Empty name", func(t *testing.T) {
+		assert.Equal(t, 0.0, Similarity("", "IBM"))
+	})
+}
+
+func TestJaroWinkler(t *testing.T) {
+	t.Run("Known values", func(t *testing.T) {
+		assert.InDelta(t, 0.961, jaroWinkler("martha", "marhta"), 0.001)
+		assert.InDelta(t, 0.840, jaroWinkler("dwayne", "duane"), 0.001)
+	})
+
+	t.Run("Identical and disjoint strings", func(t *testing.T) {
+		assert.Equal(t, 1.0, jaroWinkler("abc", "abc"))
+		assert.Equal(t, 0.0, jaroWinkler("abc", "xyz"))
+	})
+}
@@ -0,0 +1,67 @@
+package retrieval
+
+import (
+	"context"
+	"sort"
+
+	"github.com/siherrmann/grapher/model"
+)
+
+// DefaultCommunityTopK is the number of communities retrieved if QueryConfig.TopK is not set
+const DefaultCommunityTopK = 5
+
+// CommunitiesDB defines the interface for community operations
+type CommunitiesDB interface {
+	SelectCommunitiesBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64) ([]*model.Community, error)
+}
+
+// CommunityStrategy retrieves the summaries of entity communities (GraphRAG-style global search).
+// Chunk retrieval only finds passages similar to the query, which can't answer questions about
+// the whole corpus like "what are the main themes", the community summaries can.
+type CommunityStrategy struct {
+	communitiesDB CommunitiesDB
+}
+
+// NewCommunityStrategy creates a new community strategy
+func NewCommunityStrategy(communitiesDB CommunitiesDB) *CommunityStrategy {
+	return &CommunityStrategy{communitiesDB: communitiesDB}
+}
+
+// Retrieve ranks the community summaries by their similarity to the query and returns the top-k.
+// Like in global search every community is a candidate, the similarity threshold isn't applied.
+func (s *CommunityStrategy) Retrieve(ctx context.Context, embedding []float32, config *model.QueryConfig) ([]*model.CommunityResult, error) {
+	topK := config.TopK
+	if topK <= 0 {
+		topK = DefaultCommunityTopK
+	}
+
+	communities, err := s.communitiesDB.SelectCommunitiesBySimilarity(ctx, embedding, topK, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*model.CommunityResult, 0, len(communities))
+	for _, community := range communities {
+		similarity := 0.0
+		if community.Similarity != nil {
+			similarity = *community.Similarity
+		}
+
+		results = append(results, &model.CommunityResult{
+			Community:       community,
+			Score:           similarity,
+			SimilarityScore: similarity,
+			RetrievalMethod: "community",
+		})
+	}
+
+	// Sort by score, larger communities first on equal score
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Community.Weight > results[j].Community.Weight
+	})
+
+	return results, nil
+}
@@ -0,0 +1,101 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/database"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewCommunityStrategy(t *testing.T) {
+	t.Run("Create community strategy", func(t *testing.T) {
+		_, _, _ = initHandlers(t)
+		communities, err := database.NewCommunitiesDBHandler(initDB(t), 384, true)
+		require.NoError(t, err)
+
+		strategy := NewCommunityStrategy(communities)
+
+		require.NotNil(t, strategy)
+		assert.NotNil(t, strategy.communitiesDB)
+	})
+}
+
+func TestCommunityStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
+	_, _, entities := initHandlers(t)
+	db := initDB(t)
+	communities, err := database.NewCommunitiesDBHandler(db, 384, true)
+	require.NoError(t, err)
+	strategy := NewCommunityStrategy(communities)
+
+	_, err = communities.DeleteAllCommunities(ctx, db.Instance)
+	require.NoError(t, err)
+
+	// Create test communities, the query matches the first summary
+	queryEmbedding := make([]float32, 384)
+	orthogonal := make([]float32, 384)
+	for i := range queryEmbedding {
+		queryEmbedding[i] = 0.5
+		orthogonal[i] = 0.5
+		if i%2 == 1 {
+			orthogonal[i] = -0.5
+		}
+	}
+
+	entity1 := &model.Entity{Name: "Community Strategy One", Type: "Person", Metadata: map[string]interface{}{}}
+	entity2 := &model.Entity{Name: "Community Strategy Two", Type: "Person", Metadata: map[string]interface{}{}}
+	require.NoError(t, entities.InsertEntity(ctx, db.Instance, entity1))
+	require.NoError(t, entities.InsertEntity(ctx, db.Instance, entity2))
+
+	matching := &model.Community{
+		Title:     "Community Strategy One",
+		Summary:   "Matching summary.",
+		Weight:    1.0,
+		EntityIDs: []uuid.UUID{entity1.ID},
+		Metadata:  map[string]interface{}{},
+		Embedding: queryEmbedding,
+	}
+	other := &model.Community{
+		Title:     "Community Strategy Two",
+		Summary:   "Other summary.",
+		Weight:    2.0,
+		EntityIDs: []uuid.UUID{entity2.ID},
+		Metadata:  map[string]interface{}{},
+		Embedding: orthogonal,
+	}
+	require.NoError(t, communities.InsertCommunity(ctx, db.Instance, matching))
+	require.NoError(t, communities.InsertCommunity(ctx, db.Instance, other))
+
+	t.Run("Community retrieve ranks summaries by similarity", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+
+		results, err := strategy.Retrieve(context.Background(), queryEmbedding, &config)
+
+		assert.NoError(t, err, "Expected Retrieve to not return an error")
+		require.Len(t, results, 2, "Expected all communities as candidates regardless of the threshold")
+		assert.Equal(t, matching.ID, results[0].Community.ID)
+		assert.Equal(t, []uuid.UUID{entity1.ID}, results[0].Community.EntityIDs)
+		assert.InDelta(t, 1.0, results[0].Score, 0.001)
+		assert.Equal(t, "community", results[0].RetrievalMethod)
+	})
+
+	t.Run("Community retrieve respects TopK limit", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 1
+
+		results, err := strategy.Retrieve(context.Background(), queryEmbedding, &config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, matching.ID, results[0].Community.ID)
+	})
+
+	// Cleanup
+	communities.DeleteAllCommunities(ctx, db.Instance)
+	entities.DeleteEntity(ctx, entity1.ID)
+	entities.DeleteEntity(ctx, entity2.ID)
+}
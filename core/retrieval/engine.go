@@ -2,9 +2,13 @@ package retrieval
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 
 	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/core/graph"
 	"github.com/siherrmann/grapher/database"
+	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
 )
 
@@ -26,7 +30,7 @@ func NewEngine(chunks *database.ChunksDBHandler, edges *database.EdgesDBHandler,
 
 // VectorRetrieve performs pure vector similarity search
 func (e *Engine) VectorRetrieve(ctx context.Context, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
-	chunks, err := e.chunks.SelectChunksBySimilarity(embedding, config.TopK, config.SimilarityThreshold, config.DocumentRIDs)
+	chunks, err := e.chunks.SelectChunksBySimilarity(ctx, embedding, config.TopK, config.SimilarityThreshold, config.DocumentRIDs)
 	if err != nil {
 		return nil, err
 	}
@@ -49,9 +53,44 @@ func (e *Engine) VectorRetrieve(ctx context.Context, embedding []float32, config
 	return results, nil
 }
 
+// KeywordRetrieve performs full-text keyword search
+func (e *Engine) KeywordRetrieve(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	chunks, err := e.chunks.SelectChunksByKeyword(ctx, query, config.TopK, config.DocumentRIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*model.RetrievalResult, len(chunks))
+	for i, chunk := range chunks {
+		score := 0.0
+		if chunk.KeywordRank != nil {
+			score = *chunk.KeywordRank
+		}
+		results[i] = &model.RetrievalResult{
+			Chunk:           chunk,
+			Score:           score,
+			SimilarityScore: 0,
+			GraphDistance:   0,
+			RetrievalMethod: "keyword",
+		}
+	}
+
+	return results, nil
+}
+
+// EntityRetrieve performs vector similarity search over the entities, returning up to config.EntityTopK entities
+func (e *Engine) EntityRetrieve(ctx context.Context, embedding []float32, config *model.QueryConfig) ([]*model.Entity, error) {
+	entityTopK := config.EntityTopK
+	if entityTopK <= 0 {
+		entityTopK = 3
+	}
+
+	return e.entities.SelectEntitiesBySimilarity(ctx, embedding, entityTopK, config.SimilarityThreshold, nil)
+}
+
 // GetNeighbors retrieves immediate neighbors of a chunk
 func (e *Engine) GetNeighbors(ctx context.Context, chunkID uuid.UUID, edgeTypes []model.EdgeType, followBidirectional bool) ([]*model.Chunk, error) {
-	allEdges, err := e.edges.SelectEdgesFromChunk(chunkID, nil)
+	allEdges, err := e.edges.SelectEdgesFromChunk(ctx, chunkID, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -93,8 +132,11 @@ func (e *Engine) GetNeighbors(ctx context.Context, chunkID uuid.UUID, edgeTypes
 		visited[targetID] = true
 
 		// Get chunk
-		chunk, err := e.chunks.SelectChunk(targetID)
+		chunk, err := e.chunks.SelectChunk(ctx, targetID)
 		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
 			continue
 		}
 
@@ -109,21 +151,21 @@ func (e *Engine) GetHierarchicalContext(ctx context.Context, path string, config
 	var allChunks []*model.Chunk
 
 	if config.IncludeAncestors {
-		chunks, err := e.chunks.SelectAllChunksByPathAncestor(path)
+		chunks, err := e.chunks.SelectAllChunksByPathAncestor(ctx, path)
 		if err == nil {
 			allChunks = append(allChunks, chunks...)
 		}
 	}
 
 	if config.IncludeDescendants {
-		chunks, err := e.chunks.SelectAllChunksByPathDescendant(path)
+		chunks, err := e.chunks.SelectAllChunksByPathDescendant(ctx, path)
 		if err == nil {
 			allChunks = append(allChunks, chunks...)
 		}
 	}
 
 	if config.IncludeSiblings {
-		chunks, err := e.chunks.SelectSiblingChunks(path)
+		chunks, err := e.chunks.SelectSiblingChunks(ctx, path)
 		if err == nil {
 			allChunks = append(allChunks, chunks...)
 		}
@@ -132,203 +174,228 @@ func (e *Engine) GetHierarchicalContext(ctx context.Context, path string, config
 	return allChunks, nil
 }
 
-// TraversalResult contains a chunk and its distance from the source
+// TraversalResult contains a chunk or entity and its distance from the source
 type TraversalResult struct {
-	Chunk    *model.Chunk
-	Distance int
-	Path     []uuid.UUID // Path from source to this chunk
+	Node      model.GraphNode // Reached chunk or entity
+	Chunk     *model.Chunk    // Chunk data, nil for entities
+	Entity    *model.Entity   // Entity data, nil for chunks
+	Distance  int
+	Path      []uuid.UUID       // Path from source to this node, may pass through entities
+	PathNodes []model.GraphNode // Typed nodes of the path
 }
 
-// BFS performs breadth-first search from a source chunk
+// BFS performs breadth-first search from a source chunk or entity in a single database round trip.
+// Edges between a chunk and an entity are followed in both directions, so the traversal moves from
+// a chunk to the entities it mentions and on to the other chunks mentioning them.
+// Entities are part of the results with a nil Chunk.
 func (e *Engine) BFS(ctx context.Context, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*TraversalResult, error) {
-	visited := make(map[uuid.UUID]bool)
-	queue := []TraversalResult{{
-		Chunk:    nil,
-		Distance: 0,
-		Path:     []uuid.UUID{sourceID},
-	}}
-
-	// Get source chunk
-	sourceChunk, err := e.chunks.SelectChunk(sourceID)
+	traversals, err := e.Traverse(ctx, []uuid.UUID{sourceID}, maxHops, edgeTypes, followBidirectional, 0)
 	if err != nil {
 		return nil, err
 	}
-	queue[0].Chunk = sourceChunk
 
-	var results []*TraversalResult
-	visited[sourceID] = true
+	results := traversals[sourceID]
+	if len(results) == 0 {
+		return nil, helper.NewError("select source chunk", sql.ErrNoRows)
+	}
 
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+	return results, nil
+}
 
-		results = append(results, &current)
+// Traverse performs breadth-first search from several source chunks or entities in a single database round trip.
+// The results are grouped by source, every chunk and entity reachable from a source is contained once with its
+// shortest distance, ordered by distance. Sources that don't exist have no results.
+// A maxFanout greater than 0 limits the neighbours followed per chunk and hop to the ones with the highest edge weight.
+func (e *Engine) Traverse(ctx context.Context, sourceIDs []uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool, maxFanout int) (map[uuid.UUID][]*TraversalResult, error) {
+	results := make(map[uuid.UUID][]*TraversalResult, len(sourceIDs))
+	if len(sourceIDs) == 0 {
+		return results, nil
+	}
 
-		// Stop if we've reached max hops
-		if current.Distance >= maxHops {
-			continue
+	nodes, err := e.edges.TraverseGraph(ctx, sourceIDs, maxHops, edgeTypes, followBidirectional, maxFanout)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
+		return nil, err
+	}
 
-		// Get edges from current chunk
-		allEdges, err := e.edges.SelectEdgesFromChunk(current.Chunk.ID, nil)
-		if err != nil {
-			return nil, err
-		}
+	for _, node := range nodes {
+		results[node.SeedID] = append(results[node.SeedID], &TraversalResult{
+			Node:      node.Node,
+			Chunk:     node.Chunk,
+			Entity:    node.Entity,
+			Distance:  node.Depth,
+			Path:      node.Path,
+			PathNodes: node.PathNodes,
+		})
+	}
 
-		// Filter by edge types if specified
-		var edges []*model.Edge
-		if len(edgeTypes) == 0 {
-			edges = allEdges
-		} else {
-			for _, edge := range allEdges {
-				for _, edgeType := range edgeTypes {
-					if edge.EdgeType == edgeType {
-						edges = append(edges, edge)
-						break
-					}
-				}
-			}
-		}
+	return results, nil
+}
 
-		// Process each edge
-		for _, edge := range edges {
-			var targetID uuid.UUID
-
-			// Determine target based on edge direction
-			if edge.SourceChunkID != nil && *edge.SourceChunkID == current.Chunk.ID && edge.TargetChunkID != nil {
-				targetID = *edge.TargetChunkID
-			} else if edge.Bidirectional && edge.TargetChunkID != nil && *edge.TargetChunkID == current.Chunk.ID && edge.SourceChunkID != nil {
-				targetID = *edge.SourceChunkID
-			} else {
-				continue // Skip entity edges or invalid edges
-			}
+// Subgraph is the part of the graph around a set of seeds
+type Subgraph struct {
+	Nodes map[model.GraphNode]*TraversalResult // Reached chunks and entities with their shortest distance to any seed
+	Edges []*model.Edge                        // Edges between the reached nodes
+}
 
-			// Skip if already visited
-			if visited[targetID] {
-				continue
-			}
+// Subgraph collects the chunks and entities reachable from the seeds like Traverse and the edges between them
+func (e *Engine) Subgraph(ctx context.Context, seedIDs []uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool, maxFanout int) (*Subgraph, error) {
+	traversals, err := e.Traverse(ctx, seedIDs, maxHops, edgeTypes, followBidirectional, maxFanout)
+	if err != nil {
+		return nil, err
+	}
 
-			// Get target chunk
-			targetChunk, err := e.chunks.SelectChunk(targetID)
-			if err != nil {
-				continue // Skip if chunk not found
+	subgraph := &Subgraph{Nodes: make(map[model.GraphNode]*TraversalResult)}
+	for _, results := range traversals {
+		for _, result := range results {
+			if existing, ok := subgraph.Nodes[result.Node]; !ok || result.Distance < existing.Distance {
+				subgraph.Nodes[result.Node] = result
 			}
+		}
+	}
+	if len(subgraph.Nodes) == 0 {
+		return subgraph, nil
+	}
 
-			visited[targetID] = true
-
-			// Create new path
-			newPath := make([]uuid.UUID, len(current.Path))
-			copy(newPath, current.Path)
-			newPath = append(newPath, targetID)
+	nodeIDs := make([]uuid.UUID, 0, len(subgraph.Nodes))
+	for node := range subgraph.Nodes {
+		nodeIDs = append(nodeIDs, node.ID)
+	}
 
-			queue = append(queue, TraversalResult{
-				Chunk:    targetChunk,
-				Distance: current.Distance + 1,
-				Path:     newPath,
-			})
+	subgraph.Edges, err = e.edges.SelectEdgesBetweenNodes(ctx, nodeIDs, edgeTypes)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
 		}
+		return nil, err
 	}
 
-	return results, nil
+	return subgraph, nil
+}
+
+// ShortestPath finds the path with the lowest cost between two chunks or entities, following the edges by weight.
+// Returns nil without an error if the nodes are not connected.
+func (e *Engine) ShortestPath(ctx context.Context, from model.GraphNode, to model.GraphNode, opts model.PathOptions) (*model.Path, error) {
+	return graph.ShortestPath(ctx, &pathDB{edges: e.edges}, from, to, opts)
+}
+
+// KShortestPaths finds up to k loopless paths between two chunks or entities ordered by cost
+func (e *Engine) KShortestPaths(ctx context.Context, from model.GraphNode, to model.GraphNode, k int, opts model.PathOptions) ([]*model.Path, error) {
+	return graph.KShortestPaths(ctx, &pathDB{edges: e.edges}, from, to, k, opts)
 }
 
-// DFS performs depth-first search from a source chunk
+// pathDB loads the edges of path queries with the edges handler
+type pathDB struct {
+	edges *database.EdgesDBHandler
+}
+
+// GetEdgesConnectedToNode returns the edges starting or ending at the node
+func (p *pathDB) GetEdgesConnectedToNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType) ([]*model.Edge, error) {
+	return p.edges.SelectEdgesConnectedToNode(ctx, node, edgeTypes)
+}
+
+// DFS performs depth-first search from a source chunk or entity, passing through entities like BFS
 func (e *Engine) DFS(ctx context.Context, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*TraversalResult, error) {
-	visited := make(map[uuid.UUID]bool)
+	visited := make(map[model.GraphNode]bool)
 	var results []*TraversalResult
 
-	// Get source chunk
-	sourceChunk, err := e.chunks.SelectChunk(sourceID)
+	source, err := e.sourceResult(ctx, sourceID)
 	if err != nil {
 		return nil, err
 	}
 
 	// Start recursive DFS
-	e.dfsRecursive(ctx, sourceChunk, 0, maxHops, []uuid.UUID{sourceID}, edgeTypes, followBidirectional, visited, &results)
+	e.dfsRecursive(ctx, source, maxHops, edgeTypes, followBidirectional, visited, &results)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	return results, nil
 }
 
+// sourceResult loads the start node of a traversal. Like traverse_graph, the ID is an entity
+// if an entity with the ID exists and a chunk otherwise.
+func (e *Engine) sourceResult(ctx context.Context, sourceID uuid.UUID) (*TraversalResult, error) {
+	result := &TraversalResult{
+		Distance: 0,
+		Path:     []uuid.UUID{sourceID},
+	}
+
+	entity, err := e.entities.SelectEntity(ctx, sourceID)
+	if err == nil {
+		result.Node = model.EntityNode(sourceID)
+		result.Entity = entity
+	} else if errors.Is(err, sql.ErrNoRows) {
+		chunk, err := e.chunks.SelectChunk(ctx, sourceID)
+		if err != nil {
+			return nil, helper.NewError("select source chunk", err)
+		}
+		result.Node = model.ChunkNode(sourceID)
+		result.Chunk = chunk
+	} else {
+		return nil, helper.NewError("select source entity", err)
+	}
+
+	result.PathNodes = []model.GraphNode{result.Node}
+	return result, nil
+}
+
 // dfsRecursive is the recursive helper for DFS
 func (e *Engine) dfsRecursive(
 	ctx context.Context,
-	current *model.Chunk,
-	distance int,
+	current *TraversalResult,
 	maxHops int,
-	path []uuid.UUID,
 	edgeTypes []model.EdgeType,
 	followBidirectional bool,
-	visited map[uuid.UUID]bool,
+	visited map[model.GraphNode]bool,
 	results *[]*TraversalResult,
 ) {
-	// Mark as visited
-	visited[current.ID] = true
-
-	// Add to results
-	pathCopy := make([]uuid.UUID, len(path))
-	copy(pathCopy, path)
-	*results = append(*results, &TraversalResult{
-		Chunk:    current,
-		Distance: distance,
-		Path:     pathCopy,
-	})
+	// Stop once the context is canceled, DFS returns the context error
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Mark as visited and add to results
+	visited[current.Node] = true
+	*results = append(*results, current)
 
 	// Stop if we've reached max hops
-	if distance >= maxHops {
+	if current.Distance >= maxHops {
 		return
 	}
 
-	// Get edges from current chunk
-	allEdges, err := e.edges.SelectEdgesFromChunk(current.ID, nil)
+	// Get edges connected to the current chunk or entity
+	edges, err := e.edges.SelectEdgesConnectedToNode(ctx, current.Node, edgeTypes)
 	if err != nil {
 		return
 	}
 
-	// Filter by edge types if specified
-	var edges []*model.Edge
-	if len(edgeTypes) == 0 {
-		edges = allEdges
-	} else {
-		for _, edge := range allEdges {
-			for _, edgeType := range edgeTypes {
-				if edge.EdgeType == edgeType {
-					edges = append(edges, edge)
-					break
-				}
-			}
-		}
-	}
-
 	// Process each edge
 	for _, edge := range edges {
-		var targetID uuid.UUID
-
-		// Determine target based on edge direction
-		if edge.SourceChunkID != nil && *edge.SourceChunkID == current.ID && edge.TargetChunkID != nil {
-			targetID = *edge.TargetChunkID
-		} else if edge.Bidirectional && edge.TargetChunkID != nil && *edge.TargetChunkID == current.ID && edge.SourceChunkID != nil {
-			targetID = *edge.SourceChunkID
-		} else {
-			continue // Skip entity edges or invalid edges
-		}
-
-		// Skip if already visited
-		if visited[targetID] {
+		target, ok := edge.Neighbor(current.Node, followBidirectional)
+		if !ok || visited[target] {
 			continue
 		}
 
-		// Get target chunk
-		targetChunk, err := e.chunks.SelectChunk(targetID)
+		// Get target chunk or entity
+		next := &TraversalResult{
+			Node:      target,
+			Distance:  current.Distance + 1,
+			Path:      append(append(make([]uuid.UUID, 0, len(current.Path)+1), current.Path...), target.ID),
+			PathNodes: append(append(make([]model.GraphNode, 0, len(current.PathNodes)+1), current.PathNodes...), target),
+		}
+		if target.Type == model.NodeTypeEntity {
+			next.Entity, err = e.entities.SelectEntity(ctx, target.ID)
+		} else {
+			next.Chunk, err = e.chunks.SelectChunk(ctx, target.ID)
+		}
 		if err != nil {
-			continue // Skip if chunk not found
+			continue // Skip if node not found
 		}
 
-		// Create new path
-		newPath := make([]uuid.UUID, len(path))
-		copy(newPath, path)
-		newPath = append(newPath, targetID)
-
 		// Recurse
-		e.dfsRecursive(ctx, targetChunk, distance+1, maxHops, newPath, edgeTypes, followBidirectional, visited, results)
+		e.dfsRecursive(ctx, next, maxHops, edgeTypes, followBidirectional, visited, results)
 	}
 }
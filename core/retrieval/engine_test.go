@@ -2,8 +2,11 @@ package retrieval
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/database"
 	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
@@ -20,6 +23,7 @@ func TestNewEngine(t *testing.T) {
 }
 
 func TestVectorRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 
@@ -34,7 +38,7 @@ func TestVectorRetrieve(t *testing.T) {
 	db := initDB(t)
 	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
 	require.NoError(t, err)
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks with embeddings
@@ -60,9 +64,9 @@ func TestVectorRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(chunk1)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(chunk2)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk2)
 	require.NoError(t, err)
 
 	t.Run("Vector retrieve with results", func(t *testing.T) {
@@ -85,12 +89,80 @@ func TestVectorRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	chunks.DeleteChunk(chunk1.ID)
-	chunks.DeleteChunk(chunk2.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestKeywordRetrieve(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Order XJ9000 was shipped yesterday",
+		Path:       "doc.section1",
+		Metadata:   map[string]interface{}{},
+	}
+	chunk2 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Orders are usually shipped within two days",
+		Path:       "doc.section2",
+		Metadata:   map[string]interface{}{},
+	}
+
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
+	require.NoError(t, err)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk2)
+	require.NoError(t, err)
+
+	t.Run("Keyword retrieve with results", func(t *testing.T) {
+		config := &model.QueryConfig{
+			TopK: 10,
+		}
+
+		results, err := engine.KeywordRetrieve(context.Background(), "XJ9000", config)
+
+		assert.NoError(t, err, "Expected KeywordRetrieve to not return an error")
+		require.Len(t, results, 1, "Expected one result")
+		assert.Equal(t, chunk1.ID, results[0].Chunk.ID)
+		assert.Equal(t, "keyword", results[0].RetrievalMethod, "Expected retrieval method to be 'keyword'")
+		assert.Greater(t, results[0].Score, 0.0, "Expected score to be set from keyword rank")
+	})
+
+	t.Run("Keyword retrieve orders by rank", func(t *testing.T) {
+		config := &model.QueryConfig{
+			TopK: 10,
+		}
+
+		results, err := engine.KeywordRetrieve(context.Background(), "shipped order", config)
+
+		assert.NoError(t, err, "Expected KeywordRetrieve to not return an error")
+		require.Len(t, results, 2, "Expected both chunks to match")
+		assert.GreaterOrEqual(t, results[0].Score, results[1].Score, "Expected results to be sorted by score")
+	})
+
+	// Cleanup
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestGetNeighbors(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 
@@ -104,7 +176,7 @@ func TestGetNeighbors(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks
@@ -127,11 +199,11 @@ func TestGetNeighbors(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(sourceChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, sourceChunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(target1Chunk)
+	err = chunks.InsertChunk(ctx, db.Instance, target1Chunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(target2Chunk)
+	err = chunks.InsertChunk(ctx, db.Instance, target2Chunk)
 	require.NoError(t, err)
 
 	// Create edges
@@ -149,9 +221,9 @@ func TestGetNeighbors(t *testing.T) {
 		Metadata:      map[string]interface{}{},
 	}
 
-	err = edges.InsertEdge(edge1)
+	err = edges.InsertEdge(ctx, db.Instance, edge1)
 	require.NoError(t, err)
-	err = edges.InsertEdge(edge2)
+	err = edges.InsertEdge(ctx, db.Instance, edge2)
 	require.NoError(t, err)
 
 	t.Run("Get neighbors from source chunk", func(t *testing.T) {
@@ -169,15 +241,16 @@ func TestGetNeighbors(t *testing.T) {
 	})
 
 	// Cleanup
-	edges.DeleteEdge(edge1.ID)
-	edges.DeleteEdge(edge2.ID)
-	chunks.DeleteChunk(sourceChunk.ID)
-	chunks.DeleteChunk(target1Chunk.ID)
-	chunks.DeleteChunk(target2Chunk.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	edges.DeleteEdge(ctx, edge1.ID)
+	edges.DeleteEdge(ctx, edge2.ID)
+	chunks.DeleteChunk(ctx, sourceChunk.ID)
+	chunks.DeleteChunk(ctx, target1Chunk.ID)
+	chunks.DeleteChunk(ctx, target2Chunk.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestGetHierarchicalContext(t *testing.T) {
+	ctx := context.Background()
 	chunkHandler, edgesHandler, entitiesHandler := initHandlers(t)
 	engine := NewEngine(chunkHandler, edgesHandler, entitiesHandler)
 
@@ -191,7 +264,7 @@ func TestGetHierarchicalContext(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks with hierarchical paths
@@ -208,9 +281,9 @@ func TestGetHierarchicalContext(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunkHandler.InsertChunk(chunk1)
+	err = chunkHandler.InsertChunk(ctx, db.Instance, chunk1)
 	require.NoError(t, err)
-	err = chunkHandler.InsertChunk(chunk2)
+	err = chunkHandler.InsertChunk(ctx, db.Instance, chunk2)
 	require.NoError(t, err)
 
 	t.Run("Get hierarchical context", func(t *testing.T) {
@@ -227,12 +300,13 @@ func TestGetHierarchicalContext(t *testing.T) {
 	})
 
 	// Cleanup
-	chunkHandler.DeleteChunk(chunk1.ID)
-	chunkHandler.DeleteChunk(chunk2.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	chunkHandler.DeleteChunk(ctx, chunk1.ID)
+	chunkHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestDFS(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 
@@ -246,7 +320,7 @@ func TestDFS(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks
@@ -269,11 +343,11 @@ func TestDFS(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(chunk1)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(chunk2)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk2)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(chunk3)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk3)
 	require.NoError(t, err)
 
 	// Create edges to form a graph
@@ -292,9 +366,9 @@ func TestDFS(t *testing.T) {
 		Bidirectional: false,
 	}
 
-	err = edges.InsertEdge(edge1)
+	err = edges.InsertEdge(ctx, db.Instance, edge1)
 	require.NoError(t, err)
-	err = edges.InsertEdge(edge2)
+	err = edges.InsertEdge(ctx, db.Instance, edge2)
 	require.NoError(t, err)
 
 	t.Run("DFS traverses graph depth-first", func(t *testing.T) {
@@ -324,11 +398,275 @@ func TestDFS(t *testing.T) {
 		assert.NotEmpty(t, results)
 	})
 
+	t.Run("Canceled context stops traversal", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := engine.DFS(ctx, chunk1.ID, 2, []model.EdgeType{}, false)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		_, err = engine.BFS(ctx, chunk1.ID, 2, []model.EdgeType{}, false)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	// Cleanup
+	edges.DeleteEdge(ctx, edge1.ID)
+	edges.DeleteEdge(ctx, edge2.ID)
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	chunks.DeleteChunk(ctx, chunk3.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestTraverse(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+
+	// Create test document
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	// Create test chunks
+	var testChunks []*model.Chunk
+	for i, path := range []string{"doc.section1", "doc.section2", "doc.section3", "doc.section4"} {
+		chunk := &model.Chunk{
+			DocumentID: doc.ID,
+			Content:    fmt.Sprintf("Chunk %d", i+1),
+			Path:       path,
+			Metadata:   map[string]interface{}{},
+		}
+		err = chunks.InsertChunk(ctx, db.Instance, chunk)
+		require.NoError(t, err)
+		testChunks = append(testChunks, chunk)
+	}
+	chunk1, chunk2, chunk3, chunk4 := testChunks[0], testChunks[1], testChunks[2], testChunks[3]
+
+	// chunk1 -> chunk2 -> chunk3, chunk4 <-> chunk1
+	testEdges := []*model.Edge{
+		{SourceChunkID: &chunk1.ID, TargetChunkID: &chunk2.ID, EdgeType: model.EdgeTypeSemantic, Weight: 1.0},
+		{SourceChunkID: &chunk2.ID, TargetChunkID: &chunk3.ID, EdgeType: model.EdgeTypeSemantic, Weight: 1.0},
+		{SourceChunkID: &chunk4.ID, TargetChunkID: &chunk1.ID, EdgeType: model.EdgeTypeReference, Weight: 0.5, Bidirectional: true},
+	}
+	for _, edge := range testEdges {
+		err = edges.InsertEdge(ctx, db.Instance, edge)
+		require.NoError(t, err)
+	}
+
+	t.Run("BFS returns chunks by distance", func(t *testing.T) {
+		results, err := engine.BFS(context.Background(), chunk1.ID, 2, nil, true)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 4)
+		assert.Equal(t, chunk1.ID, results[0].Chunk.ID)
+		assert.Equal(t, 0, results[0].Distance)
+		assert.Equal(t, chunk3.ID, results[3].Chunk.ID)
+		assert.Equal(t, 2, results[3].Distance)
+		assert.Equal(t, []uuid.UUID{chunk1.ID, chunk2.ID, chunk3.ID}, results[3].Path)
+	})
+
+	t.Run("BFS follows bidirectional edges only if requested", func(t *testing.T) {
+		results, err := engine.BFS(context.Background(), chunk1.ID, 1, nil, false)
+
+		assert.NoError(t, err)
+		for _, result := range results {
+			assert.NotEqual(t, chunk4.ID, result.Chunk.ID)
+		}
+	})
+
+	t.Run("BFS from missing chunk", func(t *testing.T) {
+		_, err := engine.BFS(context.Background(), uuid.New(), 2, nil, true)
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Traverse groups results by source", func(t *testing.T) {
+		traversals, err := engine.Traverse(context.Background(), []uuid.UUID{chunk2.ID, chunk4.ID}, 1, []model.EdgeType{model.EdgeTypeSemantic}, true, 0)
+
+		assert.NoError(t, err)
+		require.Len(t, traversals[chunk2.ID], 2)
+		assert.Equal(t, chunk3.ID, traversals[chunk2.ID][1].Chunk.ID)
+		require.Len(t, traversals[chunk4.ID], 1, "Expected reference edge to be filtered")
+		assert.Equal(t, chunk4.ID, traversals[chunk4.ID][0].Chunk.ID)
+	})
+
+	// Cleanup
+	for _, edge := range testEdges {
+		edges.DeleteEdge(ctx, edge.ID)
+	}
+	for _, chunk := range testChunks {
+		chunks.DeleteChunk(ctx, chunk.ID)
+	}
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestShortestPath(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+
+	// Create test document
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	// Create test chunks and an entity mentioned by both of them
+	chunk1 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 1", Path: "doc.section1", Metadata: map[string]interface{}{}}
+	chunk2 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 2", Path: "doc.section2", Metadata: map[string]interface{}{}}
+	require.NoError(t, chunks.InsertChunk(ctx, db.Instance, chunk1))
+	require.NoError(t, chunks.InsertChunk(ctx, db.Instance, chunk2))
+
+	entity := &model.Entity{Name: "Path Entity", Type: "Concept", Metadata: map[string]interface{}{}}
+	require.NoError(t, entities.InsertEntity(ctx, db.Instance, entity))
+
+	testEdges := []*model.Edge{
+		{SourceChunkID: &chunk1.ID, TargetEntityID: &entity.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0},
+		{SourceChunkID: &chunk2.ID, TargetEntityID: &entity.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0},
+		{SourceChunkID: &chunk1.ID, TargetChunkID: &chunk2.ID, EdgeType: model.EdgeTypeSemantic, Weight: 0.25},
+	}
+	for _, edge := range testEdges {
+		require.NoError(t, edges.InsertEdge(ctx, db.Instance, edge))
+	}
+
+	t.Run("Shortest path prefers strong edges", func(t *testing.T) {
+		opts := model.DefaultPathOptions()
+		opts.IgnoreDirection = true
+
+		path, err := engine.ShortestPath(context.Background(), model.ChunkNode(chunk1.ID), model.ChunkNode(chunk2.ID), opts)
+
+		assert.NoError(t, err)
+		require.NotNil(t, path)
+		assert.Equal(t, []model.GraphNode{model.ChunkNode(chunk1.ID), model.EntityNode(entity.ID), model.ChunkNode(chunk2.ID)}, path.Nodes)
+		assert.Equal(t, model.EdgeTypeEntityMention, path.Steps[0].Edge.EdgeType)
+	})
+
+	t.Run("K shortest paths", func(t *testing.T) {
+		opts := model.DefaultPathOptions()
+		opts.IgnoreDirection = true
+
+		paths, err := engine.KShortestPaths(context.Background(), model.ChunkNode(chunk1.ID), model.ChunkNode(chunk2.ID), 2, opts)
+
+		assert.NoError(t, err)
+		require.Len(t, paths, 2)
+		assert.Equal(t, []model.GraphNode{model.ChunkNode(chunk1.ID), model.ChunkNode(chunk2.ID)}, paths[1].Nodes)
+		assert.Equal(t, 4.0, paths[1].Cost)
+	})
+
+	// Cleanup
+	for _, edge := range testEdges {
+		edges.DeleteEdge(ctx, edge.ID)
+	}
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	entities.DeleteEntity(ctx, entity.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestTraverseThroughEntities(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+
+	// Create test document
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	// Two chunks mentioning the same entity: chunk1 -> entity <- chunk2
+	chunk1 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 1", Path: "doc.section1", Metadata: map[string]interface{}{}}
+	chunk2 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 2", Path: "doc.section2", Metadata: map[string]interface{}{}}
+	require.NoError(t, chunks.InsertChunk(ctx, db.Instance, chunk1))
+	require.NoError(t, chunks.InsertChunk(ctx, db.Instance, chunk2))
+
+	entity := &model.Entity{Name: "Traversal Entity", Type: "Concept", Metadata: map[string]interface{}{}}
+	require.NoError(t, entities.InsertEntity(ctx, db.Instance, entity))
+
+	testEdges := []*model.Edge{
+		{SourceChunkID: &chunk1.ID, TargetEntityID: &entity.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0},
+		{SourceChunkID: &chunk2.ID, TargetEntityID: &entity.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0},
+	}
+	for _, edge := range testEdges {
+		require.NoError(t, edges.InsertEdge(ctx, db.Instance, edge))
+	}
+
+	expectedPath := []model.GraphNode{model.ChunkNode(chunk1.ID), model.EntityNode(entity.ID), model.ChunkNode(chunk2.ID)}
+
+	t.Run("BFS moves from chunk to entity to chunk", func(t *testing.T) {
+		results, err := engine.BFS(context.Background(), chunk1.ID, 2, nil, false)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, model.EntityNode(entity.ID), results[1].Node)
+		assert.Nil(t, results[1].Chunk)
+		assert.Equal(t, entity.Name, results[1].Entity.Name)
+		assert.Equal(t, chunk2.ID, results[2].Chunk.ID)
+		assert.Equal(t, 2, results[2].Distance)
+		assert.Equal(t, expectedPath, results[2].PathNodes)
+	})
+
+	t.Run("BFS from an entity", func(t *testing.T) {
+		results, err := engine.BFS(context.Background(), entity.ID, 1, nil, false)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3, "Expected the entity and both chunks mentioning it")
+		assert.NotNil(t, results[0].Entity)
+	})
+
+	t.Run("DFS moves from chunk to entity to chunk", func(t *testing.T) {
+		results, err := engine.DFS(context.Background(), chunk1.ID, 2, nil, false)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, expectedPath, results[2].PathNodes)
+	})
+
+	t.Run("DFS from an entity", func(t *testing.T) {
+		results, err := engine.DFS(context.Background(), entity.ID, 1, nil, false)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3, "Expected the entity and both chunks mentioning it")
+		assert.Equal(t, model.EntityNode(entity.ID), results[0].Node)
+		assert.Equal(t, entity.Name, results[0].Entity.Name)
+		assert.Nil(t, results[0].Chunk)
+	})
+
+	t.Run("DFS from an unknown node", func(t *testing.T) {
+		_, err := engine.DFS(context.Background(), uuid.New(), 1, nil, false)
+
+		assert.ErrorIs(t, err, sql.ErrNoRows)
+	})
+
 	// Cleanup
-	edges.DeleteEdge(edge1.ID)
-	edges.DeleteEdge(edge2.ID)
-	chunks.DeleteChunk(chunk1.ID)
-	chunks.DeleteChunk(chunk2.ID)
-	chunks.DeleteChunk(chunk3.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	for _, edge := range testEdges {
+		edges.DeleteEdge(ctx, edge.ID)
+	}
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	entities.DeleteEntity(ctx, entity.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
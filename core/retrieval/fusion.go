@@ -0,0 +1,262 @@
+package retrieval
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// Fusion source names, also used as keys for QueryConfig.FusionWeights
+const (
+	FusionSourceKeyword = "keyword"
+	FusionSourceVector  = "vector"
+	FusionSourceGraph   = "graph"
+)
+
+// DefaultFusionK is the RRF constant used if QueryConfig.FusionK is not set
+const DefaultFusionK = 60
+
+// FusionSourceFunc produces a ranked result list for the fusion strategy
+type FusionSourceFunc func(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error)
+
+// fusionListFunc is a source that also gets the lists of the sources that ran before it
+type fusionListFunc func(ctx context.Context, query string, embedding []float32, config *model.QueryConfig, lists map[string][]*model.RetrievalResult) ([]*model.RetrievalResult, error)
+
+// fusionSource is a named ranked list producer
+type fusionSource struct {
+	name     string
+	retrieve fusionListFunc
+}
+
+// FusionStrategy runs several sub-strategies and merges their ranked lists.
+// Unlike HybridStrategy it doesn't add raw scores from different scales,
+// it either uses the ranks (Reciprocal Rank Fusion) or min-max normalized scores.
+type FusionStrategy struct {
+	engine  *Engine
+	sources []fusionSource
+}
+
+// NewFusionStrategy creates a new fusion strategy with the keyword, vector and graph sources
+func NewFusionStrategy(engine *Engine) *FusionStrategy {
+	s := &FusionStrategy{engine: engine}
+	s.AddSource(FusionSourceKeyword, s.keywordSource)
+	s.AddSource(FusionSourceVector, s.vectorSource)
+	s.addSource(FusionSourceGraph, s.graphSource)
+	return s
+}
+
+// AddSource adds a named ranked list to the fusion.
+// A source with the same name replaces the existing one.
+func (s *FusionStrategy) AddSource(name string, retrieve FusionSourceFunc) {
+	s.addSource(name, func(ctx context.Context, query string, embedding []float32, config *model.QueryConfig, lists map[string][]*model.RetrievalResult) ([]*model.RetrievalResult, error) {
+		return retrieve(ctx, query, embedding, config)
+	})
+}
+
+// addSource adds a named source that gets the lists of the sources before it
+func (s *FusionStrategy) addSource(name string, retrieve fusionListFunc) {
+	for i, source := range s.sources {
+		if source.name == name {
+			s.sources[i].retrieve = retrieve
+			return
+		}
+	}
+	s.sources = append(s.sources, fusionSource{name: name, retrieve: retrieve})
+}
+
+// Retrieve runs all sources and fuses their results
+func (s *FusionStrategy) Retrieve(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	lists := make(map[string][]*model.RetrievalResult)
+	for _, source := range s.sources {
+		if fusionWeight(config, source.name) == 0 {
+			continue
+		}
+
+		results, err := source.retrieve(ctx, query, embedding, config, lists)
+		if err != nil {
+			return nil, helper.NewError(fmt.Sprintf("%s source", source.name), err)
+		}
+		lists[source.name] = results
+	}
+
+	return s.fuse(lists, config)
+}
+
+// fuse merges the ranked lists of all sources in source order
+func (s *FusionStrategy) fuse(lists map[string][]*model.RetrievalResult, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	method := config.FusionMethod
+	if method == "" {
+		method = model.FusionMethodRRF
+	}
+
+	k := config.FusionK
+	if k <= 0 {
+		k = DefaultFusionK
+	}
+
+	resultMap := make(map[string]*model.RetrievalResult)
+	var order []string
+
+	for _, source := range s.sources {
+		results, ok := lists[source.name]
+		if !ok || len(results) == 0 {
+			continue
+		}
+		weight := fusionWeight(config, source.name)
+
+		var contributions []float64
+		switch method {
+		case model.FusionMethodRRF:
+			contributions = rrfContributions(results, k)
+		case model.FusionMethodScore:
+			contributions = normalizedScores(results)
+		default:
+			return nil, helper.NewError("fuse results", fmt.Errorf("unsupported fusion method: %s (use 'rrf' or 'score')", method))
+		}
+
+		for i, result := range results {
+			chunkIDStr := result.Chunk.ID.String()
+			fused, exists := resultMap[chunkIDStr]
+			if !exists {
+				fused = &model.RetrievalResult{
+					Chunk:           result.Chunk,
+					GraphDistance:   result.GraphDistance,
+					RetrievalMethod: "fusion",
+				}
+				resultMap[chunkIDStr] = fused
+				order = append(order, chunkIDStr)
+			}
+
+			fused.Score += weight * contributions[i]
+			if result.SimilarityScore > fused.SimilarityScore {
+				fused.SimilarityScore = result.SimilarityScore
+			}
+			if result.GraphDistance > 0 && (fused.GraphDistance == 0 || result.GraphDistance < fused.GraphDistance) {
+				fused.GraphDistance = result.GraphDistance
+			}
+			if !containsSource(fused.Sources, source.name) {
+				fused.Sources = append(fused.Sources, source.name)
+			}
+		}
+	}
+
+	// Convert map to slice, keeping first-seen order for equal scores
+	results := make([]*model.RetrievalResult, 0, len(order))
+	for _, chunkIDStr := range order {
+		results = append(results, resultMap[chunkIDStr])
+	}
+
+	// Sort by fused score
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	// Limit to top-k if specified
+	if config.TopK > 0 && len(results) > config.TopK {
+		results = results[:config.TopK]
+	}
+
+	return results, nil
+}
+
+// keywordSource retrieves the full-text ranked list
+func (s *FusionStrategy) keywordSource(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if query == "" {
+		return nil, nil
+	}
+	return s.engine.KeywordRetrieve(ctx, query, config)
+}
+
+// vectorSource retrieves the similarity ranked list
+func (s *FusionStrategy) vectorSource(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if len(embedding) == 0 {
+		return nil, nil
+	}
+	return s.engine.VectorRetrieve(ctx, embedding, config)
+}
+
+// graphSource retrieves chunks reached by traversal from the hits of the vector source, scored like MultiHopStrategy.
+// The vector hits themselves are left out, they are already ranked by the vector source.
+// The vector search only runs again if the vector source is disabled.
+func (s *FusionStrategy) graphSource(ctx context.Context, query string, embedding []float32, config *model.QueryConfig, lists map[string][]*model.RetrievalResult) ([]*model.RetrievalResult, error) {
+	if len(embedding) == 0 || config.MaxHops <= 0 {
+		return nil, nil
+	}
+
+	seeds, ok := lists[FusionSourceVector]
+	if !ok {
+		var err error
+		seeds, err = s.engine.VectorRetrieve(ctx, embedding, config)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(seeds) == 0 {
+		return nil, nil
+	}
+
+	results, err := s.engine.graphExpansion(ctx, seeds, config)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	return results, nil
+}
+
+// rrfContributions returns 1 / (k + rank) for each result, ranks start at 1
+func rrfContributions(results []*model.RetrievalResult, k int) []float64 {
+	contributions := make([]float64, len(results))
+	for i := range results {
+		contributions[i] = 1.0 / float64(k+i+1)
+	}
+	return contributions
+}
+
+// normalizedScores min-max normalizes the scores of a result list to [0, 1].
+// If all scores are equal, every result gets 1.
+func normalizedScores(results []*model.RetrievalResult) []float64 {
+	minScore, maxScore := results[0].Score, results[0].Score
+	for _, result := range results {
+		if result.Score < minScore {
+			minScore = result.Score
+		}
+		if result.Score > maxScore {
+			maxScore = result.Score
+		}
+	}
+
+	contributions := make([]float64, len(results))
+	for i, result := range results {
+		if maxScore == minScore {
+			contributions[i] = 1.0
+		} else {
+			contributions[i] = (result.Score - minScore) / (maxScore - minScore)
+		}
+	}
+	return contributions
+}
+
+// fusionWeight returns the configured weight of a source, defaulting to 1.0
+func fusionWeight(config *model.QueryConfig, source string) float64 {
+	if weight, ok := config.FusionWeights[source]; ok {
+		return weight
+	}
+	return 1.0
+}
+
+// containsSource checks if a source name is already in the list
+func containsSource(sources []string, source string) bool {
+	for _, s := range sources {
+		if s == source {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,287 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/database"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticSource returns a fusion source that always returns the given results
+func staticSource(results ...*model.RetrievalResult) FusionSourceFunc {
+	return func(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return results, nil
+	}
+}
+
+// testResult creates a retrieval result for a new chunk with the given score
+func testResult(score float64) *model.RetrievalResult {
+	return &model.RetrievalResult{
+		Chunk: &model.Chunk{ID: uuid.New()},
+		Score: score,
+	}
+}
+
+func TestNewFusionStrategy(t *testing.T) {
+	t.Run("Create fusion strategy with default sources", func(t *testing.T) {
+		strategy := NewFusionStrategy(nil)
+
+		require.NotNil(t, strategy)
+		require.Len(t, strategy.sources, 3)
+		assert.Equal(t, FusionSourceKeyword, strategy.sources[0].name)
+		assert.Equal(t, FusionSourceVector, strategy.sources[1].name)
+		assert.Equal(t, FusionSourceGraph, strategy.sources[2].name)
+	})
+
+	t.Run("AddSource replaces source with same name", func(t *testing.T) {
+		strategy := NewFusionStrategy(nil)
+		strategy.AddSource(FusionSourceVector, staticSource())
+		strategy.AddSource("custom", staticSource())
+
+		require.Len(t, strategy.sources, 4)
+		assert.Equal(t, FusionSourceVector, strategy.sources[1].name)
+		assert.Equal(t, "custom", strategy.sources[3].name)
+	})
+}
+
+func TestFusionStrategyGraphSource(t *testing.T) {
+	t.Run("Graph source traverses from the vector source hits", func(t *testing.T) {
+		// Without an engine a second vector search would fail, the empty vector list leaves nothing to traverse
+		strategy := NewFusionStrategy(nil)
+		strategy.AddSource(FusionSourceKeyword, staticSource())
+		strategy.AddSource(FusionSourceVector, staticSource())
+		config := &model.QueryConfig{MaxHops: 2}
+
+		results, err := strategy.Retrieve(context.Background(), "query", []float32{0.1, 0.2}, config)
+
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+}
+
+func TestFusionStrategyFuse(t *testing.T) {
+	shared := testResult(0.9)
+	keywordOnly := testResult(0.5)
+	vectorOnly := testResult(0.95)
+
+	newStrategy := func() *FusionStrategy {
+		strategy := &FusionStrategy{}
+		strategy.AddSource("keyword", staticSource(shared, keywordOnly))
+		strategy.AddSource("vector", staticSource(vectorOnly, &model.RetrievalResult{
+			Chunk:           shared.Chunk,
+			Score:           0.8,
+			SimilarityScore: 0.8,
+		}))
+		return strategy
+	}
+
+	t.Run("RRF ranks results found by several sources first", func(t *testing.T) {
+		config := &model.QueryConfig{FusionMethod: model.FusionMethodRRF, FusionK: 60}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, shared.Chunk.ID, results[0].Chunk.ID, "Expected chunk found by both sources to rank first")
+		assert.InDelta(t, 1.0/61+1.0/62, results[0].Score, 1e-9, "Expected RRF score to be the sum of reciprocal ranks")
+		assert.Equal(t, []string{"keyword", "vector"}, results[0].Sources, "Expected both sources to be recorded")
+		assert.Equal(t, 0.8, results[0].SimilarityScore, "Expected similarity score to be kept")
+		assert.Equal(t, "fusion", results[0].RetrievalMethod)
+	})
+
+	t.Run("RRF uses default k", func(t *testing.T) {
+		config := &model.QueryConfig{}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.InDelta(t, 1.0/float64(DefaultFusionK+1)+1.0/float64(DefaultFusionK+2), results[0].Score, 1e-9)
+	})
+
+	t.Run("Weights change the ranking", func(t *testing.T) {
+		config := &model.QueryConfig{
+			FusionMethod:  model.FusionMethodRRF,
+			FusionWeights: map[string]float64{"keyword": 0.01, "vector": 5.0},
+		}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.Equal(t, vectorOnly.Chunk.ID, results[0].Chunk.ID, "Expected heavily weighted source to dominate")
+	})
+
+	t.Run("Zero weight skips source", func(t *testing.T) {
+		config := &model.QueryConfig{
+			FusionWeights: map[string]float64{"keyword": 0},
+		}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 2)
+		for _, result := range results {
+			assert.Equal(t, []string{"vector"}, result.Sources)
+		}
+	})
+
+	t.Run("Score fusion normalizes each source", func(t *testing.T) {
+		config := &model.QueryConfig{FusionMethod: model.FusionMethodScore}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 3)
+		// shared: keyword max (1.0) + vector min (0.0), vectorOnly: vector max (1.0), keywordOnly: keyword min (0.0)
+		assert.InDelta(t, 1.0, results[0].Score, 1e-9)
+		assert.InDelta(t, 1.0, results[1].Score, 1e-9)
+		assert.Equal(t, shared.Chunk.ID, results[0].Chunk.ID, "Expected first-seen order for equal scores")
+		assert.Equal(t, keywordOnly.Chunk.ID, results[2].Chunk.ID)
+		assert.InDelta(t, 0.0, results[2].Score, 1e-9)
+	})
+
+	t.Run("Score fusion with equal scores", func(t *testing.T) {
+		strategy := &FusionStrategy{}
+		strategy.AddSource("keyword", staticSource(testResult(0.3), testResult(0.3)))
+		config := &model.QueryConfig{FusionMethod: model.FusionMethodScore}
+
+		results, err := strategy.Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 2)
+		assert.Equal(t, 1.0, results[0].Score)
+		assert.Equal(t, 1.0, results[1].Score)
+	})
+
+	t.Run("TopK limits fused results", func(t *testing.T) {
+		config := &model.QueryConfig{TopK: 2}
+
+		results, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("Unsupported fusion method returns error", func(t *testing.T) {
+		config := &model.QueryConfig{FusionMethod: "invalid"}
+
+		_, err := newStrategy().Retrieve(context.Background(), "query", nil, config)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "unsupported fusion method")
+	})
+
+	t.Run("Source error is returned", func(t *testing.T) {
+		strategy := newStrategy()
+		strategy.AddSource("failing", func(ctx context.Context, query string, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+			return nil, errors.New("source failed")
+		})
+
+		_, err := strategy.Retrieve(context.Background(), "query", nil, &model.QueryConfig{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "failing source")
+	})
+}
+
+func TestFusionStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+	strategy := NewFusionStrategy(engine)
+
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	embedding1 := make([]float32, 384)
+	embedding2 := make([]float32, 384)
+	for i := range embedding1 {
+		embedding1[i] = 0.5
+		embedding2[i] = float32(i%2) * 0.5
+	}
+
+	chunk1 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Sensor model TX500 measures temperature",
+		Path:       "doc.s1",
+		Embedding:  embedding1,
+		Metadata:   map[string]interface{}{},
+	}
+	chunk2 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Calibration steps for all sensors",
+		Path:       "doc.s2",
+		Embedding:  embedding2,
+		Metadata:   map[string]interface{}{},
+	}
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
+	require.NoError(t, err)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk2)
+	require.NoError(t, err)
+
+	edge := &model.Edge{
+		SourceChunkID: &chunk1.ID,
+		TargetChunkID: &chunk2.ID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      map[string]interface{}{},
+	}
+	err = edges.InsertEdge(ctx, db.Instance, edge)
+	require.NoError(t, err)
+
+	t.Run("Fusion retrieve combines sources", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 1
+		config.SimilarityThreshold = 0.9
+		config.MaxHops = 1
+
+		results, err := strategy.Retrieve(context.Background(), "TX500", embedding1, &config)
+
+		assert.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, chunk1.ID, results[0].Chunk.ID)
+		assert.Contains(t, results[0].Sources, FusionSourceKeyword)
+		assert.Contains(t, results[0].Sources, FusionSourceVector)
+	})
+
+	t.Run("Fusion retrieve includes graph source", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 10
+		config.SimilarityThreshold = 0.9
+		config.MaxHops = 1
+
+		results, err := strategy.Retrieve(context.Background(), "TX500", embedding1, &config)
+
+		assert.NoError(t, err)
+		found := false
+		for _, result := range results {
+			if result.Chunk.ID == chunk2.ID {
+				found = true
+				assert.Equal(t, []string{FusionSourceGraph}, result.Sources)
+				assert.Equal(t, 1, result.GraphDistance)
+			}
+		}
+		assert.True(t, found, "Expected chunk reached via graph to be in results")
+	})
+
+	// Cleanup
+	edges.DeleteEdge(ctx, edge.ID)
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	chunks.DeleteChunk(ctx, chunk2.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
@@ -54,7 +54,7 @@ func initHandlers(t *testing.T) (*database.ChunksDBHandler, *database.EdgesDBHan
 	chunks, err := database.NewChunksDBHandler(db, edges, 384, true)
 	require.NoError(t, err)
 
-	entities, err := database.NewEntitiesDBHandler(db, true)
+	entities, err := database.NewEntitiesDBHandler(db, 384, true)
 	require.NoError(t, err)
 
 	// Note: We don't close the db here as tests will use these handlers
@@ -0,0 +1,108 @@
+package retrieval
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/core/graph"
+	"github.com/siherrmann/grapher/model"
+)
+
+// PageRankStrategy ranks chunks by personalized PageRank over the local subgraph (HippoRAG-style).
+// The walk restarts at the top vector hits and the entities matching the query, weighted by their similarity,
+// and follows the edges in proportion to their weight. Unlike MultiHopStrategy it scores a chunk by all
+// weighted paths leading to it instead of only its distance to the closest seed.
+type PageRankStrategy struct {
+	engine *Engine
+}
+
+// NewPageRankStrategy creates a new PageRank strategy
+func NewPageRankStrategy(engine *Engine) *PageRankStrategy {
+	return &PageRankStrategy{engine: engine}
+}
+
+// Retrieve performs PageRank retrieval
+func (s *PageRankStrategy) Retrieve(ctx context.Context, embedding []float32, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	// Get top-k similar chunks and entities as seeds
+	vectorResults, err := s.engine.VectorRetrieve(ctx, embedding, config)
+	if err != nil {
+		return nil, err
+	}
+
+	entities, err := s.engine.EntityRetrieve(ctx, embedding, config)
+	if err != nil {
+		return nil, err
+	}
+
+	seeds := make(map[model.GraphNode]float64)
+	seedIDs := make([]uuid.UUID, 0, len(vectorResults)+len(entities))
+	similarities := make(map[uuid.UUID]float64)
+	for _, result := range vectorResults {
+		seeds[model.ChunkNode(result.Chunk.ID)] = result.SimilarityScore
+		seedIDs = append(seedIDs, result.Chunk.ID)
+		similarities[result.Chunk.ID] = result.SimilarityScore
+	}
+	for _, entity := range entities {
+		if entity.Similarity == nil {
+			continue
+		}
+		seeds[model.EntityNode(entity.ID)] = *entity.Similarity
+		seedIDs = append(seedIDs, entity.ID)
+	}
+
+	if len(seedIDs) == 0 {
+		return []*model.RetrievalResult{}, nil
+	}
+
+	// Collect the local subgraph around the seeds
+	subgraph, err := s.engine.Subgraph(
+		ctx,
+		seedIDs,
+		config.MaxHops,
+		config.EdgeTypes,
+		config.FollowBidirectional,
+		config.MaxFanout,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]model.GraphNode, 0, len(subgraph.Nodes))
+	for node := range subgraph.Nodes {
+		nodes = append(nodes, node)
+	}
+
+	ranks := graph.PersonalizedPageRank(nodes, subgraph.Edges, seeds, graph.PageRankOptions{
+		Damping:             config.PageRankDamping,
+		FollowBidirectional: config.FollowBidirectional,
+	})
+
+	// Rank the chunks by their stationary probability
+	results := make([]*model.RetrievalResult, 0, len(subgraph.Nodes))
+	for node, tResult := range subgraph.Nodes {
+		if tResult.Chunk == nil || ranks[node] <= 0 {
+			continue // Entities only connect chunks
+		}
+
+		results = append(results, &model.RetrievalResult{
+			Chunk:           tResult.Chunk,
+			Score:           ranks[node],
+			SimilarityScore: similarities[node.ID],
+			GraphDistance:   tResult.Distance,
+			RetrievalMethod: "pagerank",
+		})
+	}
+
+	// Sort by probability
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	// Limit to top-k
+	if config.TopK > 0 && len(results) > config.TopK {
+		results = results[:config.TopK]
+	}
+
+	return results, nil
+}
@@ -0,0 +1,136 @@
+package retrieval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/database"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewPageRankStrategy(t *testing.T) {
+	t.Run("Create PageRank strategy", func(t *testing.T) {
+		chunks, edges, entities := initHandlers(t)
+		engine := NewEngine(chunks, edges, entities)
+		strategy := NewPageRankStrategy(engine)
+
+		require.NotNil(t, strategy)
+		assert.NotNil(t, strategy.engine)
+	})
+}
+
+func TestPageRankStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+	strategy := NewPageRankStrategy(engine)
+
+	// Create test document
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	// The query matches the source chunk and the entity, the other chunks are orthogonal to it
+	queryEmbedding := make([]float32, 384)
+	orthogonal := make([]float32, 384)
+	for i := range queryEmbedding {
+		queryEmbedding[i] = 0.5
+		orthogonal[i] = 0.5
+		if i%2 == 1 {
+			orthogonal[i] = -0.5
+		}
+	}
+
+	sourceChunk := &model.Chunk{DocumentID: doc.ID, Content: "Source", Path: "doc.s1", Embedding: queryEmbedding, Metadata: map[string]interface{}{}}
+	neighborChunk := &model.Chunk{DocumentID: doc.ID, Content: "Neighbor", Path: "doc.s2", Embedding: orthogonal, Metadata: map[string]interface{}{}}
+	mentionChunk := &model.Chunk{DocumentID: doc.ID, Content: "Mention", Path: "doc.s3", Embedding: orthogonal, Metadata: map[string]interface{}{}}
+	for _, chunk := range []*model.Chunk{sourceChunk, neighborChunk, mentionChunk} {
+		require.NoError(t, chunks.InsertChunk(ctx, db.Instance, chunk))
+	}
+
+	entity := &model.Entity{Name: "PageRank Entity", Type: "Concept", Embedding: queryEmbedding, Metadata: map[string]interface{}{}}
+	require.NoError(t, entities.InsertEntity(ctx, db.Instance, entity))
+
+	reference := &model.Edge{
+		SourceChunkID: &sourceChunk.ID,
+		TargetChunkID: &neighborChunk.ID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      map[string]interface{}{},
+	}
+	mention := &model.Edge{
+		SourceChunkID:  &mentionChunk.ID,
+		TargetEntityID: &entity.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         1.0,
+		Metadata:       map[string]interface{}{},
+	}
+	require.NoError(t, edges.InsertEdge(ctx, db.Instance, reference))
+	require.NoError(t, edges.InsertEdge(ctx, db.Instance, mention))
+
+	t.Run("PageRank retrieve from chunk and entity seeds", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 10
+		config.SimilarityThreshold = 0.9
+
+		results, err := strategy.Retrieve(context.Background(), queryEmbedding, &config)
+
+		assert.NoError(t, err, "Expected Retrieve to not return an error")
+		require.NotEmpty(t, results)
+		assert.Equal(t, sourceChunk.ID, results[0].Chunk.ID, "Expected the seed chunk to rank first")
+
+		found := make(map[uuid.UUID]*model.RetrievalResult)
+		for _, result := range results {
+			assert.Equal(t, "pagerank", result.RetrievalMethod)
+			found[result.Chunk.ID] = result
+		}
+		require.Contains(t, found, neighborChunk.ID, "Expected the referenced chunk")
+		require.Contains(t, found, mentionChunk.ID, "Expected the chunk mentioning the matched entity")
+		assert.Equal(t, 1, found[neighborChunk.ID].GraphDistance)
+		assert.Equal(t, 1, found[mentionChunk.ID].GraphDistance)
+		for i := 1; i < len(results); i++ {
+			assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score, "Expected results ordered by probability")
+		}
+	})
+
+	t.Run("PageRank retrieve respects TopK limit", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 1
+		config.SimilarityThreshold = 0.9
+
+		results, err := strategy.Retrieve(context.Background(), queryEmbedding, &config)
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+	})
+
+	t.Run("PageRank retrieve without seeds", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.SimilarityThreshold = 1.1
+
+		results, err := strategy.Retrieve(context.Background(), queryEmbedding, &config)
+
+		assert.NoError(t, err)
+		assert.Empty(t, results)
+	})
+
+	// Cleanup
+	edges.DeleteEdge(ctx, reference.ID)
+	edges.DeleteEdge(ctx, mention.ID)
+	entities.DeleteEntity(ctx, entity.ID)
+	for _, chunk := range []*model.Chunk{sourceChunk, neighborChunk, mentionChunk} {
+		chunks.DeleteChunk(ctx, chunk.ID)
+	}
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
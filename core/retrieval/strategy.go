@@ -28,6 +28,22 @@ func (s *VectorOnlyStrategy) Retrieve(ctx context.Context, embedding []float32,
 	return s.engine.VectorRetrieve(ctx, embedding, config)
 }
 
+// KeywordStrategy performs full-text keyword search.
+// It finds exact terms like identifiers, error codes or names that embed badly.
+type KeywordStrategy struct {
+	engine *Engine
+}
+
+// NewKeywordStrategy creates a new keyword strategy
+func NewKeywordStrategy(engine *Engine) *KeywordStrategy {
+	return &KeywordStrategy{engine: engine}
+}
+
+// Retrieve performs keyword retrieval
+func (s *KeywordStrategy) Retrieve(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	return s.engine.KeywordRetrieve(ctx, query, config)
+}
+
 // ContextualStrategy combines vector search with immediate neighbors and hierarchical context
 type ContextualStrategy struct {
 	engine *Engine
@@ -126,58 +142,63 @@ func (s *MultiHopStrategy) Retrieve(ctx context.Context, embedding []float32, co
 		return nil, err
 	}
 
-	resultMap := make(map[string]*model.RetrievalResult)
-
-	// Add vector results
-	for _, result := range vectorResults {
-		resultMap[result.Chunk.ID.String()] = result
+	// Traverse from all starting points in one query
+	graphResults, err := s.engine.graphExpansion(ctx, vectorResults, config)
+	if err != nil {
+		return nil, err
 	}
 
-	// For each starting point, perform BFS/DFS
-	for _, result := range vectorResults {
-		traversalResults, err := s.engine.BFS(
-			ctx,
-			result.Chunk.ID,
-			config.MaxHops,
-			config.EdgeTypes,
-			config.FollowBidirectional,
-		)
-		if err != nil {
-			continue
-		}
+	results := make([]*model.RetrievalResult, 0, len(vectorResults)+len(graphResults))
+	results = append(results, vectorResults...)
+	results = append(results, graphResults...)
 
-		for _, tResult := range traversalResults {
-			// Skip the source chunk (already in results)
-			if tResult.Distance == 0 {
-				continue
-			}
+	// Sort by score
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
 
-			chunkIDStr := tResult.Chunk.ID.String()
-			if _, exists := resultMap[chunkIDStr]; !exists {
-				// Calculate score based on distance and original similarity
-				score := result.Score * config.GraphWeight / float64(tResult.Distance+1)
+	return results, nil
+}
 
-				resultMap[chunkIDStr] = &model.RetrievalResult{
-					Chunk:           tResult.Chunk,
-					Score:           score,
-					SimilarityScore: 0,
-					GraphDistance:   tResult.Distance,
-					RetrievalMethod: "multi_hop",
-				}
-			}
-		}
+// graphExpansion traverses from the seed results in one query and returns the reached chunks that aren't seeds.
+// A chunk is scored by the score of the first seed reaching it, config.GraphWeight and its distance.
+func (e *Engine) graphExpansion(ctx context.Context, seeds []*model.RetrievalResult, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	traversals, err := e.Traverse(
+		ctx,
+		resultChunkIDs(seeds),
+		config.MaxHops,
+		config.EdgeTypes,
+		config.FollowBidirectional,
+		config.MaxFanout,
+	)
+	if err != nil {
+		return nil, err
 	}
 
-	// Convert map to slice
-	results := make([]*model.RetrievalResult, 0, len(resultMap))
-	for _, result := range resultMap {
-		results = append(results, result)
+	seen := make(map[uuid.UUID]bool, len(seeds))
+	for _, seed := range seeds {
+		seen[seed.Chunk.ID] = true
 	}
 
-	// Sort by score
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].Score > results[j].Score
-	})
+	var results []*model.RetrievalResult
+	for _, seed := range seeds {
+		for _, tResult := range traversals[seed.Chunk.ID] {
+			// Skip the seed chunks and entities passed on the way
+			if tResult.Distance == 0 || tResult.Chunk == nil || seen[tResult.Chunk.ID] {
+				continue
+			}
+			seen[tResult.Chunk.ID] = true
+
+			// Calculate score based on distance and original similarity
+			results = append(results, &model.RetrievalResult{
+				Chunk:           tResult.Chunk,
+				Score:           seed.Score * config.GraphWeight / float64(tResult.Distance+1),
+				SimilarityScore: 0,
+				GraphDistance:   tResult.Distance,
+				RetrievalMethod: "multi_hop",
+			})
+		}
+	}
 
 	return results, nil
 }
@@ -202,6 +223,22 @@ func (s *HybridStrategy) Retrieve(ctx context.Context, embedding []float32, conf
 		return nil, err
 	}
 
+	// Traverse from all vector results in one query
+	var traversals map[uuid.UUID][]*TraversalResult
+	if config.MaxHops > 0 {
+		traversals, err = s.engine.Traverse(
+			ctx,
+			resultChunkIDs(vectorResults),
+			config.MaxHops,
+			config.EdgeTypes,
+			config.FollowBidirectional,
+			config.MaxFanout,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	resultMap := make(map[string]*model.RetrievalResult)
 
 	// Process each vector result
@@ -220,35 +257,27 @@ func (s *HybridStrategy) Retrieve(ctx context.Context, embedding []float32, conf
 		}
 
 		// Add graph neighbors
-		if config.MaxHops > 0 {
-			traversalResults, err := s.engine.BFS(
-				ctx,
-				vResult.Chunk.ID,
-				config.MaxHops,
-				config.EdgeTypes,
-				config.FollowBidirectional,
-			)
-			if err == nil {
-				for _, tResult := range traversalResults {
-					tChunkIDStr := tResult.Chunk.ID.String()
-
-					if existing, exists := resultMap[tChunkIDStr]; exists {
-						// Update score with graph component
-						if tResult.Distance > 0 {
-							graphScore := config.GraphWeight / float64(tResult.Distance)
-							existing.Score += graphScore
-						}
-					} else if tResult.Distance > 0 {
-						// New chunk from graph traversal
-						graphScore := config.GraphWeight / float64(tResult.Distance)
-						resultMap[tChunkIDStr] = &model.RetrievalResult{
-							Chunk:           tResult.Chunk,
-							Score:           graphScore,
-							SimilarityScore: 0,
-							GraphDistance:   tResult.Distance,
-							RetrievalMethod: "hybrid",
-						}
-					}
+		for _, tResult := range traversals[vResult.Chunk.ID] {
+			if tResult.Chunk == nil {
+				continue // Entities only connect chunks
+			}
+			tChunkIDStr := tResult.Chunk.ID.String()
+
+			if existing, exists := resultMap[tChunkIDStr]; exists {
+				// Update score with graph component
+				if tResult.Distance > 0 {
+					graphScore := config.GraphWeight / float64(tResult.Distance)
+					existing.Score += graphScore
+				}
+			} else if tResult.Distance > 0 {
+				// New chunk from graph traversal
+				graphScore := config.GraphWeight / float64(tResult.Distance)
+				resultMap[tChunkIDStr] = &model.RetrievalResult{
+					Chunk:           tResult.Chunk,
+					Score:           graphScore,
+					SimilarityScore: 0,
+					GraphDistance:   tResult.Distance,
+					RetrievalMethod: "hybrid",
 				}
 			}
 		}
@@ -340,21 +369,27 @@ func (s *EntityCentricStrategy) Retrieve(ctx context.Context, entityID uuid.UUID
 
 	// Optionally expand via graph traversal
 	if config.MaxHops > 0 {
-		for _, chunk := range chunks {
-			traversalResults, err := s.engine.BFS(
-				ctx,
-				chunk.ID,
-				config.MaxHops,
-				config.EdgeTypes,
-				config.FollowBidirectional,
-			)
-			if err != nil {
-				continue
-			}
+		chunkIDs := make([]uuid.UUID, len(chunks))
+		for i, chunk := range chunks {
+			chunkIDs[i] = chunk.ID
+		}
+
+		traversals, err := s.engine.Traverse(
+			ctx,
+			chunkIDs,
+			config.MaxHops,
+			config.EdgeTypes,
+			config.FollowBidirectional,
+			config.MaxFanout,
+		)
+		if err != nil {
+			return nil, err
+		}
 
-			for _, tResult := range traversalResults {
-				if tResult.Distance == 0 {
-					continue // Skip source
+		for _, chunk := range chunks {
+			for _, tResult := range traversals[chunk.ID] {
+				if tResult.Distance == 0 || tResult.Chunk == nil {
+					continue // Skip source and entities
 				}
 
 				tChunkIDStr := tResult.Chunk.ID.String()
@@ -390,3 +425,12 @@ func (s *EntityCentricStrategy) Retrieve(ctx context.Context, entityID uuid.UUID
 
 	return results, nil
 }
+
+// resultChunkIDs returns the chunk IDs of the results in order
+func resultChunkIDs(results []*model.RetrievalResult) []uuid.UUID {
+	ids := make([]uuid.UUID, len(results))
+	for i, result := range results {
+		ids[i] = result.Chunk.ID
+	}
+	return ids
+}
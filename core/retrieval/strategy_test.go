@@ -22,6 +22,7 @@ func TestNewVectorOnlyStrategy(t *testing.T) {
 }
 
 func TestVectorOnlyStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 	strategy := NewVectorOnlyStrategy(engine)
@@ -36,7 +37,7 @@ func TestVectorOnlyStrategyRetrieve(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunk with embedding
@@ -53,7 +54,7 @@ func TestVectorOnlyStrategyRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(chunk1)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
 	require.NoError(t, err)
 
 	t.Run("Vector-only retrieve", func(t *testing.T) {
@@ -75,8 +76,63 @@ func TestVectorOnlyStrategyRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	chunks.DeleteChunk(chunk1.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestNewKeywordStrategy(t *testing.T) {
+	t.Run("Create keyword strategy", func(t *testing.T) {
+		chunks, edges, entities := initHandlers(t)
+		engine := NewEngine(chunks, edges, entities)
+		strategy := NewKeywordStrategy(engine)
+
+		require.NotNil(t, strategy)
+		assert.NotNil(t, strategy.engine)
+	})
+}
+
+func TestKeywordStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
+	chunks, edges, entities := initHandlers(t)
+	engine := NewEngine(chunks, edges, entities)
+	strategy := NewKeywordStrategy(engine)
+
+	db := initDB(t)
+	documentsHandler, err := database.NewDocumentsDBHandler(db, false)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Firmware version FW2024B fixes the boot loop",
+		Path:       "doc.s1",
+		Metadata:   map[string]interface{}{},
+	}
+	err = chunks.InsertChunk(ctx, db.Instance, chunk1)
+	require.NoError(t, err)
+
+	t.Run("Keyword retrieve", func(t *testing.T) {
+		config := &model.QueryConfig{
+			TopK: 5,
+		}
+
+		results, err := strategy.Retrieve(context.Background(), "FW2024B", config)
+		assert.NoError(t, err)
+		require.Len(t, results, 1)
+		assert.Equal(t, chunk1.ID, results[0].Chunk.ID)
+		assert.Equal(t, "keyword", results[0].RetrievalMethod)
+	})
+
+	// Cleanup
+	chunks.DeleteChunk(ctx, chunk1.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestNewContextualStrategy(t *testing.T) {
@@ -91,6 +147,7 @@ func TestNewContextualStrategy(t *testing.T) {
 }
 
 func TestContextualStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 	strategy := NewContextualStrategy(engine)
@@ -105,7 +162,7 @@ func TestContextualStrategyRetrieve(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks
@@ -131,9 +188,9 @@ func TestContextualStrategyRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(sourceChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, sourceChunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(neighborChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, neighborChunk)
 	require.NoError(t, err)
 
 	// Create edge
@@ -143,7 +200,7 @@ func TestContextualStrategyRetrieve(t *testing.T) {
 		EdgeType:      model.EdgeTypeReference,
 		Metadata:      map[string]interface{}{},
 	}
-	err = edges.InsertEdge(edge)
+	err = edges.InsertEdge(ctx, db.Instance, edge)
 	require.NoError(t, err)
 
 	t.Run("Contextual retrieve with neighbors and hierarchy", func(t *testing.T) {
@@ -188,10 +245,10 @@ func TestContextualStrategyRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	edges.DeleteEdge(edge.ID)
-	chunks.DeleteChunk(sourceChunk.ID)
-	chunks.DeleteChunk(neighborChunk.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	edges.DeleteEdge(ctx, edge.ID)
+	chunks.DeleteChunk(ctx, sourceChunk.ID)
+	chunks.DeleteChunk(ctx, neighborChunk.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestNewMultiHopStrategy(t *testing.T) {
@@ -206,6 +263,7 @@ func TestNewMultiHopStrategy(t *testing.T) {
 }
 
 func TestMultiHopStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 	strategy := NewMultiHopStrategy(engine)
@@ -220,7 +278,7 @@ func TestMultiHopStrategyRetrieve(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks
@@ -255,11 +313,11 @@ func TestMultiHopStrategyRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(sourceChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, sourceChunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(hop1Chunk)
+	err = chunks.InsertChunk(ctx, db.Instance, hop1Chunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(hop2Chunk)
+	err = chunks.InsertChunk(ctx, db.Instance, hop2Chunk)
 	require.NoError(t, err)
 
 	// Create edges
@@ -276,9 +334,9 @@ func TestMultiHopStrategyRetrieve(t *testing.T) {
 		Metadata:      map[string]interface{}{},
 	}
 
-	err = edges.InsertEdge(edge1)
+	err = edges.InsertEdge(ctx, db.Instance, edge1)
 	require.NoError(t, err)
-	err = edges.InsertEdge(edge2)
+	err = edges.InsertEdge(ctx, db.Instance, edge2)
 	require.NoError(t, err)
 
 	t.Run("Multi-hop retrieve", func(t *testing.T) {
@@ -320,12 +378,12 @@ func TestMultiHopStrategyRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	edges.DeleteEdge(edge1.ID)
-	edges.DeleteEdge(edge2.ID)
-	chunks.DeleteChunk(sourceChunk.ID)
-	chunks.DeleteChunk(hop1Chunk.ID)
-	chunks.DeleteChunk(hop2Chunk.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	edges.DeleteEdge(ctx, edge1.ID)
+	edges.DeleteEdge(ctx, edge2.ID)
+	chunks.DeleteChunk(ctx, sourceChunk.ID)
+	chunks.DeleteChunk(ctx, hop1Chunk.ID)
+	chunks.DeleteChunk(ctx, hop2Chunk.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestNewHybridStrategy(t *testing.T) {
@@ -340,6 +398,7 @@ func TestNewHybridStrategy(t *testing.T) {
 }
 
 func TestHybridStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 	strategy := NewHybridStrategy(engine)
@@ -354,7 +413,7 @@ func TestHybridStrategyRetrieve(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test chunks
@@ -380,9 +439,9 @@ func TestHybridStrategyRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	err = chunks.InsertChunk(sourceChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, sourceChunk)
 	require.NoError(t, err)
-	err = chunks.InsertChunk(neighborChunk)
+	err = chunks.InsertChunk(ctx, db.Instance, neighborChunk)
 	require.NoError(t, err)
 
 	// Create edge
@@ -392,7 +451,7 @@ func TestHybridStrategyRetrieve(t *testing.T) {
 		EdgeType:      model.EdgeTypeReference,
 		Metadata:      map[string]interface{}{},
 	}
-	err = edges.InsertEdge(edge)
+	err = edges.InsertEdge(ctx, db.Instance, edge)
 	require.NoError(t, err)
 
 	t.Run("Hybrid retrieve with all components", func(t *testing.T) {
@@ -459,10 +518,10 @@ func TestHybridStrategyRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	edges.DeleteEdge(edge.ID)
-	chunks.DeleteChunk(sourceChunk.ID)
-	chunks.DeleteChunk(neighborChunk.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	edges.DeleteEdge(ctx, edge.ID)
+	chunks.DeleteChunk(ctx, sourceChunk.ID)
+	chunks.DeleteChunk(ctx, neighborChunk.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestNewEntityCentricStrategy(t *testing.T) {
@@ -477,6 +536,7 @@ func TestNewEntityCentricStrategy(t *testing.T) {
 }
 
 func TestEntityCentricStrategyRetrieve(t *testing.T) {
+	ctx := context.Background()
 	chunks, edges, entities := initHandlers(t)
 	engine := NewEngine(chunks, edges, entities)
 	strategy := NewEntityCentricStrategy(engine, entities)
@@ -491,7 +551,7 @@ func TestEntityCentricStrategyRetrieve(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsHandler.InsertDocument(doc)
+	err = documentsHandler.InsertDocument(ctx, db.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test entity
@@ -500,7 +560,7 @@ func TestEntityCentricStrategyRetrieve(t *testing.T) {
 		Type:     "Person",
 		Metadata: map[string]interface{}{},
 	}
-	err = entities.InsertEntity(entity)
+	err = entities.InsertEntity(ctx, db.Instance, entity)
 	require.NoError(t, err)
 
 	// Create test chunk with embedding
@@ -516,7 +576,7 @@ func TestEntityCentricStrategyRetrieve(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 		Embedding:  embedding,
 	}
-	err = chunks.InsertChunk(chunk)
+	err = chunks.InsertChunk(ctx, db.Instance, chunk)
 	require.NoError(t, err)
 
 	// Create edge from entity to chunk
@@ -527,7 +587,7 @@ func TestEntityCentricStrategyRetrieve(t *testing.T) {
 		Weight:         1.0,
 		Bidirectional:  false,
 	}
-	err = edges.InsertEdge(edge)
+	err = edges.InsertEdge(ctx, db.Instance, edge)
 	require.NoError(t, err)
 
 	t.Run("EntityCentric strategy retrieves entities and connected chunks", func(t *testing.T) {
@@ -559,8 +619,8 @@ func TestEntityCentricStrategyRetrieve(t *testing.T) {
 	})
 
 	// Cleanup
-	edges.DeleteEdge(edge.ID)
-	chunks.DeleteChunk(chunk.ID)
-	entities.DeleteEntity(entity.ID)
-	documentsHandler.DeleteDocument(doc.RID)
+	edges.DeleteEdge(ctx, edge.ID)
+	chunks.DeleteChunk(ctx, chunk.ID)
+	entities.DeleteEntity(ctx, entity.ID)
+	documentsHandler.DeleteDocument(ctx, doc.RID)
 }
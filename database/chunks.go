@@ -18,15 +18,19 @@ import (
 
 // ChunksDBHandlerFunctions defines the interface for Chunks database operations.
 type ChunksDBHandlerFunctions interface {
-	InsertChunk(chunk *model.Chunk) error
-	SelectChunk(id uuid.UUID) (*model.Chunk, error)
-	SelectAllChunksByDocument(documentRID uuid.UUID) ([]*model.Chunk, error)
-	SelectAllChunksByPathDescendant(path string) ([]*model.Chunk, error)
-	SelectAllChunksByPathAncestor(path string) ([]*model.Chunk, error)
-	SelectChunksBySimilarity(embedding []float32, limit int, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error)
-	SelectChunksBySimilarityWithContext(embedding []float32, limit int, includeAncestors bool, includeDescendants bool, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error)
-	DeleteChunk(id uuid.UUID) error
-	UpdateChunkEmbedding(id uuid.UUID, embedding []float32) error
+	InsertChunk(ctx context.Context, q Querier, chunk *model.Chunk) error
+	InsertChunksBatch(ctx context.Context, q Querier, chunks []*model.Chunk) error
+	SelectChunk(ctx context.Context, id uuid.UUID) (*model.Chunk, error)
+	SelectAllChunksByDocument(ctx context.Context, q Querier, documentRID uuid.UUID) ([]*model.Chunk, error)
+	SelectAllChunksByPathDescendant(ctx context.Context, path string) ([]*model.Chunk, error)
+	SelectAllChunksByPathAncestor(ctx context.Context, path string) ([]*model.Chunk, error)
+	SelectChunksBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error)
+	SelectChunksBySimilarityWithContext(ctx context.Context, embedding []float32, limit int, includeAncestors bool, includeDescendants bool, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error)
+	SelectChunksByKeyword(ctx context.Context, query string, limit int, documentRIDs []uuid.UUID) ([]*model.Chunk, error)
+	DeleteChunk(ctx context.Context, id uuid.UUID) error
+	DeleteChunks(ctx context.Context, q Querier, ids []uuid.UUID) (int, error)
+	UpdateChunkEmbedding(ctx context.Context, id uuid.UUID, embedding []float32) error
+	UpdateChunkPosition(ctx context.Context, q Querier, chunk *model.Chunk) error
 }
 
 // ChunksDBHandler handles chunk-related database operations
@@ -35,10 +39,21 @@ type ChunksDBHandler struct {
 	edgesHandler *EdgesDBHandler // For graph operations
 }
 
+// DefaultTextSearchConfig is the language used for keyword search if no text search config is set
+const DefaultTextSearchConfig = "english"
+
 // NewChunksDBHandler creates a new chunks database handler.
 // It initializes the database connection and loads chunk-related SQL functions.
+// New chunks tables use DefaultTextSearchConfig for keyword search.
 // If force is true, it will reload the SQL functions even if they already exist.
 func NewChunksDBHandler(db *helper.Database, edgesHandler *EdgesDBHandler, embeddingDim int, force bool) (*ChunksDBHandler, error) {
+	return NewChunksDBHandlerWithTextSearchConfig(db, edgesHandler, embeddingDim, DefaultTextSearchConfig, force)
+}
+
+// NewChunksDBHandlerWithTextSearchConfig creates a new chunks database handler like NewChunksDBHandler.
+// A new chunks table uses the textSearchConfig (e.g. "english", "german" or "simple") for keyword search,
+// an existing table keeps its config (see ChangeTextSearchConfig). An empty config uses DefaultTextSearchConfig.
+func NewChunksDBHandlerWithTextSearchConfig(db *helper.Database, edgesHandler *EdgesDBHandler, embeddingDim int, textSearchConfig string, force bool) (*ChunksDBHandler, error) {
 	if db == nil {
 		return nil, helper.NewError("database connection validation", fmt.Errorf("database connection is nil"))
 	}
@@ -53,7 +68,7 @@ func NewChunksDBHandler(db *helper.Database, edgesHandler *EdgesDBHandler, embed
 		return nil, helper.NewError("load chunks sql", err)
 	}
 
-	err = chunksDbHandler.CreateTable(embeddingDim)
+	err = chunksDbHandler.CreateTable(embeddingDim, textSearchConfig)
 	if err != nil {
 		return nil, helper.NewError("create table", err)
 	}
@@ -66,12 +81,17 @@ func NewChunksDBHandler(db *helper.Database, edgesHandler *EdgesDBHandler, embed
 // CreateTable creates the 'chunks' table in the database.
 // If the table already exists, it does not create it again.
 // It also creates all necessary extensions, indexes, and triggers.
-func (h *ChunksDBHandler) CreateTable(embeddingDim int) error {
+// The textSearchConfig is only used for a new table, an empty config uses DefaultTextSearchConfig.
+func (h *ChunksDBHandler) CreateTable(embeddingDim int, textSearchConfig string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	if textSearchConfig == "" {
+		textSearchConfig = DefaultTextSearchConfig
+	}
+
 	// Use the SQL init() function to create all tables, triggers, and indexes
-	_, err := h.db.Instance.ExecContext(ctx, `SELECT init_chunks($1);`, embeddingDim)
+	_, err := h.db.Instance.ExecContext(ctx, `SELECT init_chunks($1, $2);`, embeddingDim, textSearchConfig)
 	if err != nil {
 		log.Panicf("error initializing chunks table: %#v", err)
 	}
@@ -82,7 +102,7 @@ func (h *ChunksDBHandler) CreateTable(embeddingDim int) error {
 }
 
 // InsertChunk inserts a new chunk
-func (h *ChunksDBHandler) InsertChunk(chunk *model.Chunk) error {
+func (h *ChunksDBHandler) InsertChunk(ctx context.Context, q Querier, chunk *model.Chunk) error {
 	var embeddingParam interface{}
 	if len(chunk.Embedding) > 0 {
 		embeddingVector := pgvector.NewVector(chunk.Embedding)
@@ -91,7 +111,7 @@ func (h *ChunksDBHandler) InsertChunk(chunk *model.Chunk) error {
 		embeddingParam = nil
 	}
 
-	row := h.db.Instance.QueryRow(
+	row := q.QueryRowContext(ctx,
 		`SELECT * FROM insert_chunk($1, $2, $3, $4, $5, $6, $7, $8)`,
 		chunk.DocumentID,
 		chunk.Content,
@@ -128,9 +148,87 @@ func (h *ChunksDBHandler) InsertChunk(chunk *model.Chunk) error {
 	return nil
 }
 
+// InsertChunksBatch inserts multiple chunks with a single COPY.
+// COPY can't return rows, so IDs and creation times are generated client side
+// and written back to the chunks once the copy succeeded.
+// If q is the connection pool, the chunks are copied in a new transaction.
+func (h *ChunksDBHandler) InsertChunksBatch(ctx context.Context, q Querier, chunks []*model.Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+
+	return inTx(ctx, q, func(tx *sql.Tx) error {
+		return h.copyChunks(ctx, tx, chunks)
+	})
+}
+
+// copyChunks copies the chunks as part of the given transaction
+func (h *ChunksDBHandler) copyChunks(ctx context.Context, tx *sql.Tx, chunks []*model.Chunk) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"chunks",
+		"id",
+		"document_id",
+		"content",
+		"path",
+		"embedding",
+		"start_pos",
+		"end_pos",
+		"chunk_index",
+		"metadata",
+		"created_at",
+	))
+	if err != nil {
+		return helper.NewError("prepare copy", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]uuid.UUID, len(chunks))
+	createdAt := time.Now()
+	for i, chunk := range chunks {
+		var embeddingParam interface{}
+		if len(chunk.Embedding) > 0 {
+			embeddingParam = pgvector.NewVector(chunk.Embedding)
+		}
+
+		metadata, err := copyMetadata(chunk.Metadata)
+		if err != nil {
+			return helper.NewError(fmt.Sprintf("chunk %d metadata", i), err)
+		}
+
+		ids[i] = uuid.New()
+		_, err = stmt.ExecContext(ctx,
+			ids[i],
+			chunk.DocumentID,
+			chunk.Content,
+			chunk.Path,
+			embeddingParam,
+			chunk.StartPos,
+			chunk.EndPos,
+			chunk.ChunkIndex,
+			metadata,
+			createdAt,
+		)
+		if err != nil {
+			return helper.NewError(fmt.Sprintf("copy chunk %d", i), err)
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		return helper.NewError("flush copy", err)
+	}
+
+	for i, chunk := range chunks {
+		chunk.ID = ids[i]
+		chunk.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
 // SelectChunk retrieves a chunk by ID
-func (h *ChunksDBHandler) SelectChunk(id uuid.UUID) (*model.Chunk, error) {
-	row := h.db.Instance.QueryRow(
+func (h *ChunksDBHandler) SelectChunk(ctx context.Context, id uuid.UUID) (*model.Chunk, error) {
+	row := h.db.Instance.QueryRowContext(ctx,
 		`SELECT * FROM select_chunk($1)`,
 		id,
 	)
@@ -162,8 +260,8 @@ func (h *ChunksDBHandler) SelectChunk(id uuid.UUID) (*model.Chunk, error) {
 }
 
 // SelectAllChunksByDocument retrieves all chunks for a document
-func (h *ChunksDBHandler) SelectAllChunksByDocument(documentRID uuid.UUID) ([]*model.Chunk, error) {
-	rows, err := h.db.Instance.Query(
+func (h *ChunksDBHandler) SelectAllChunksByDocument(ctx context.Context, q Querier, documentRID uuid.UUID) ([]*model.Chunk, error) {
+	rows, err := q.QueryContext(ctx,
 		`SELECT * FROM select_chunks_by_document($1)`,
 		documentRID,
 	)
@@ -213,8 +311,8 @@ func (h *ChunksDBHandler) SelectAllChunksByDocument(documentRID uuid.UUID) ([]*m
 }
 
 // SelectAllChunksByPathDescendant retrieves chunks that are descendants of the given path
-func (h *ChunksDBHandler) SelectAllChunksByPathDescendant(path string) ([]*model.Chunk, error) {
-	rows, err := h.db.Instance.Query(
+func (h *ChunksDBHandler) SelectAllChunksByPathDescendant(ctx context.Context, path string) ([]*model.Chunk, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_chunks_by_path_descendant($1)`,
 		path,
 	)
@@ -260,8 +358,8 @@ func (h *ChunksDBHandler) SelectAllChunksByPathDescendant(path string) ([]*model
 }
 
 // SelectAllChunksByPathAncestor retrieves chunks that are ancestors of the given path
-func (h *ChunksDBHandler) SelectAllChunksByPathAncestor(path string) ([]*model.Chunk, error) {
-	rows, err := h.db.Instance.Query(
+func (h *ChunksDBHandler) SelectAllChunksByPathAncestor(ctx context.Context, path string) ([]*model.Chunk, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_chunks_by_path_ancestor($1)`,
 		path,
 	)
@@ -307,8 +405,8 @@ func (h *ChunksDBHandler) SelectAllChunksByPathAncestor(path string) ([]*model.C
 }
 
 // SelectSiblingChunks retrieves chunks that are siblings of the given path (same parent, same level)
-func (h *ChunksDBHandler) SelectSiblingChunks(path string) ([]*model.Chunk, error) {
-	rows, err := h.db.Instance.Query(
+func (h *ChunksDBHandler) SelectSiblingChunks(ctx context.Context, path string) ([]*model.Chunk, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_sibling_chunks($1)`,
 		path,
 	)
@@ -355,7 +453,7 @@ func (h *ChunksDBHandler) SelectSiblingChunks(path string) ([]*model.Chunk, erro
 
 // SelectChunksBySimilarity performs vector similarity search
 // If documentRIDs is nil or empty, searches across all documents
-func (h *ChunksDBHandler) SelectChunksBySimilarity(embedding []float32, limit int, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error) {
+func (h *ChunksDBHandler) SelectChunksBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64, documentRIDs []uuid.UUID) ([]*model.Chunk, error) {
 	embeddingVector := pgvector.NewVector(embedding)
 
 	// Convert documentRIDs to PostgreSQL UUID array format
@@ -366,7 +464,7 @@ func (h *ChunksDBHandler) SelectChunksBySimilarity(embedding []float32, limit in
 		documentRIDsParam = nil
 	}
 
-	rows, err := h.db.Instance.Query(
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_chunks_by_similarity($1, $2, $3, $4)`,
 		embeddingVector,
 		limit,
@@ -427,6 +525,7 @@ func (h *ChunksDBHandler) SelectChunksBySimilarity(embedding []float32, limit in
 // SelectChunksBySimilarityWithContext performs vector similarity search with hierarchical context
 // If documentRIDs is nil or empty, searches across all documents
 func (h *ChunksDBHandler) SelectChunksBySimilarityWithContext(
+	ctx context.Context,
 	embedding []float32,
 	limit int,
 	includeAncestors bool,
@@ -444,7 +543,7 @@ func (h *ChunksDBHandler) SelectChunksBySimilarityWithContext(
 		documentRIDsParam = nil
 	}
 
-	rows, err := h.db.Instance.Query(
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_chunks_by_similarity_with_context($1, $2, $3, $4, $5, $6)`,
 		embeddingVector,
 		limit,
@@ -501,9 +600,71 @@ func (h *ChunksDBHandler) SelectChunksBySimilarityWithContext(
 	return results, nil
 }
 
+// SelectChunksByKeyword performs full-text search on the chunk content.
+// The query supports web search syntax ("quoted phrases", or, -excluded).
+// If documentRIDs is nil or empty, searches across all documents
+func (h *ChunksDBHandler) SelectChunksByKeyword(ctx context.Context, query string, limit int, documentRIDs []uuid.UUID) ([]*model.Chunk, error) {
+	// Convert documentRIDs to PostgreSQL UUID array format
+	var documentRIDsParam interface{}
+	if len(documentRIDs) > 0 {
+		documentRIDsParam = pq.Array(documentRIDs)
+	} else {
+		documentRIDsParam = nil
+	}
+
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_chunks_by_keyword($1, $2, $3)`,
+		query,
+		limit,
+		documentRIDsParam,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var results []*model.Chunk
+	for rows.Next() {
+		chunk := &model.Chunk{}
+		var embeddingVec *pgvector.Vector
+		var rank float64
+		err := rows.Scan(
+			&chunk.ID,
+			&chunk.DocumentID,
+			&chunk.DocumentRID,
+			&chunk.Content,
+			&chunk.Path,
+			&embeddingVec,
+			&chunk.StartPos,
+			&chunk.EndPos,
+			&chunk.ChunkIndex,
+			&chunk.Metadata,
+			&chunk.CreatedAt,
+			&rank,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		if embeddingVec != nil {
+			chunk.Embedding = embeddingVec.Slice()
+		}
+		chunk.KeywordRank = &rank
+
+		results = append(results, chunk)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return results, nil
+}
+
 // DeleteChunk deletes a chunk by ID
-func (h *ChunksDBHandler) DeleteChunk(id uuid.UUID) error {
-	_, err := h.db.Instance.Exec(
+func (h *ChunksDBHandler) DeleteChunk(ctx context.Context, id uuid.UUID) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT delete_chunk($1)`,
 		id,
 	)
@@ -513,10 +674,43 @@ func (h *ChunksDBHandler) DeleteChunk(id uuid.UUID) error {
 	return nil
 }
 
+// DeleteChunks deletes chunks with their edges.
+// Mentioned entities lose the mentions of the chunks and are deleted if nothing mentions them anymore.
+// Returns the number of deleted chunks.
+func (h *ChunksDBHandler) DeleteChunks(ctx context.Context, q Querier, ids []uuid.UUID) (int, error) {
+	var deleted int
+	err := q.QueryRowContext(ctx,
+		`SELECT delete_chunks($1)`,
+		pq.Array(ids),
+	).Scan(&deleted)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+	return deleted, nil
+}
+
+// UpdateChunkPosition updates path, positions, index and metadata of a chunk,
+// content and embedding are kept
+func (h *ChunksDBHandler) UpdateChunkPosition(ctx context.Context, q Querier, chunk *model.Chunk) error {
+	_, err := q.ExecContext(ctx,
+		`SELECT update_chunk_position($1, $2, $3, $4, $5, $6)`,
+		chunk.ID,
+		chunk.Path,
+		chunk.StartPos,
+		chunk.EndPos,
+		chunk.ChunkIndex,
+		chunk.Metadata,
+	)
+	if err != nil {
+		return helper.NewError("exec", err)
+	}
+	return nil
+}
+
 // UpdateChunkEmbedding updates the embedding of a chunk
-func (h *ChunksDBHandler) UpdateChunkEmbedding(id uuid.UUID, embedding []float32) error {
+func (h *ChunksDBHandler) UpdateChunkEmbedding(ctx context.Context, id uuid.UUID, embedding []float32) error {
 	embeddingVector := pgvector.NewVector(embedding)
-	_, err := h.db.Instance.Exec(
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT * FROM update_chunk_embedding($1, $2)`,
 		id,
 		embeddingVector,
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -32,6 +34,7 @@ func TestChunksNewChunksDBHandler(t *testing.T) {
 }
 
 func TestChunksInsert(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -46,7 +49,7 @@ func TestChunksInsert(t *testing.T) {
 		Source:   "test_source.txt",
 		Metadata: map[string]interface{}{"author": "Test Author"},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err, "Expected Insert document to not return an error")
 
 	t.Run("Insert chunk without embedding", func(t *testing.T) {
@@ -63,7 +66,7 @@ func TestChunksInsert(t *testing.T) {
 			Metadata:   map[string]interface{}{"type": "paragraph"},
 		}
 
-		err := chunksDbHandler.InsertChunk(chunk)
+		err := chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		assert.NoError(t, err, "Expected Insert to not return an error")
 		assert.NotEmpty(t, chunk.ID, "Expected inserted chunk to have an ID")
 		assert.WithinDuration(t, chunk.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
@@ -89,17 +92,123 @@ func TestChunksInsert(t *testing.T) {
 			Metadata:   map[string]interface{}{"type": "paragraph"},
 		}
 
-		err := chunksDbHandler.InsertChunk(chunk)
+		err := chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		assert.NoError(t, err, "Expected Insert to not return an error")
 		assert.NotEmpty(t, chunk.ID, "Expected inserted chunk to have an ID")
 		assert.Equal(t, 384, len(chunk.Embedding), "Expected embedding to be preserved")
 	})
 
+	t.Run("Insert chunk in rolled back transaction", func(t *testing.T) {
+		tx, err := database.Instance.Begin()
+		require.NoError(t, err)
+
+		chunk := &model.Chunk{
+			DocumentID: doc.ID,
+			Content:    "This chunk is rolled back",
+			Path:       "root.section3",
+			Metadata:   map[string]interface{}{},
+		}
+
+		err = chunksDbHandler.InsertChunk(ctx, tx, chunk)
+		assert.NoError(t, err, "Expected InsertTx to not return an error")
+		assert.NotEmpty(t, chunk.ID, "Expected inserted chunk to have an ID")
+		require.NoError(t, tx.Rollback())
+
+		_, err = chunksDbHandler.SelectChunk(ctx, chunk.ID)
+		assert.Error(t, err, "Expected rolled back chunk to not exist")
+	})
+
+	// Cleanup
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestChunksInsertBatch(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err, "Expected NewDocumentsDBHandler to not return an error")
+
+	chunksDbHandler, err := NewChunksDBHandler(database, nil, 384, true)
+	require.NoError(t, err, "Expected NewChunksDBHandler to not return an error")
+
+	doc := &model.Document{
+		Title:    "Batch Document",
+		Source:   "batch.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	t.Run("Insert chunks batch", func(t *testing.T) {
+		embedding := make([]float32, 384)
+		for i := range embedding {
+			embedding[i] = 0.1
+		}
+		chunkIndex := 1
+
+		chunks := []*model.Chunk{
+			{
+				DocumentID: doc.ID,
+				Content:    "First batch chunk",
+				Path:       "root.batch.chunk0",
+				Embedding:  embedding,
+				Metadata:   map[string]interface{}{"type": "text"},
+			},
+			{
+				DocumentID: doc.ID,
+				Content:    "Second batch chunk",
+				Path:       "root.batch.chunk1",
+				ChunkIndex: &chunkIndex,
+			},
+		}
+
+		err := chunksDbHandler.InsertChunksBatch(ctx, database.Instance, chunks)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		for _, chunk := range chunks {
+			assert.NotEmpty(t, chunk.ID, "Expected inserted chunk to have an ID")
+			assert.WithinDuration(t, chunk.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
+		}
+
+		retrieved, err := chunksDbHandler.SelectChunk(ctx, chunks[0].ID)
+		require.NoError(t, err, "Expected batch inserted chunk to be retrievable")
+		assert.Equal(t, "First batch chunk", retrieved.Content)
+		assert.Equal(t, "text", retrieved.Metadata["type"], "Expected metadata to be stored as JSON")
+		assert.Equal(t, 384, len(retrieved.Embedding), "Expected embedding to be stored")
+
+		retrieved, err = chunksDbHandler.SelectChunk(ctx, chunks[1].ID)
+		require.NoError(t, err, "Expected batch inserted chunk to be retrievable")
+		assert.Empty(t, retrieved.Embedding, "Expected missing embedding to be stored as NULL")
+		require.NotNil(t, retrieved.ChunkIndex)
+		assert.Equal(t, 1, *retrieved.ChunkIndex)
+	})
+
+	t.Run("Insert empty chunks batch", func(t *testing.T) {
+		err := chunksDbHandler.InsertChunksBatch(ctx, database.Instance, nil)
+		assert.NoError(t, err, "Expected InsertBatch with no chunks to not return an error")
+	})
+
+	t.Run("Invalid chunk fails the whole batch", func(t *testing.T) {
+		chunks := []*model.Chunk{
+			{DocumentID: doc.ID, Content: "Valid chunk", Path: "root.invalid_batch.chunk0"},
+			{DocumentID: doc.ID, Content: "Invalid chunk", Path: "not a valid path!"},
+		}
+
+		err := chunksDbHandler.InsertChunksBatch(ctx, database.Instance, chunks)
+		assert.Error(t, err, "Expected InsertBatch to return an error for an invalid path")
+		assert.Equal(t, uuid.Nil, chunks[0].ID, "Expected IDs to not be set when the batch fails")
+
+		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant(ctx, "root.invalid_batch")
+		assert.NoError(t, err)
+		assert.Empty(t, descendants, "Expected no chunk of the failed batch to be stored")
+	})
+
 	// Cleanup
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestChunksGet(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -114,7 +223,7 @@ func TestChunksGet(t *testing.T) {
 		Source:   "test_source.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	chunk := &model.Chunk{
@@ -123,22 +232,29 @@ func TestChunksGet(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 	require.NoError(t, err)
 
 	// Test Get
-	retrievedChunk, err := chunksDbHandler.SelectChunk(chunk.ID)
+	retrievedChunk, err := chunksDbHandler.SelectChunk(ctx, chunk.ID)
 	assert.NoError(t, err, "Expected Get to not return an error")
 	assert.NotNil(t, retrievedChunk, "Expected Get to return a non-nil chunk")
 	assert.Equal(t, chunk.ID, retrievedChunk.ID, "Expected chunk IDs to match")
 	assert.Equal(t, chunk.Content, retrievedChunk.Content, "Expected chunk content to match")
 
+	// Test Get with canceled context
+	canceledCtx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = chunksDbHandler.SelectChunk(canceledCtx, chunk.ID)
+	assert.ErrorIs(t, err, context.Canceled, "Expected Get to return the context error")
+
 	// Cleanup
-	chunksDbHandler.DeleteChunk(chunk.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestChunksGetByDocument(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -153,7 +269,7 @@ func TestChunksGetByDocument(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create multiple chunks for the document
@@ -168,23 +284,24 @@ func TestChunksGetByDocument(t *testing.T) {
 			ChunkIndex: &index,
 			Metadata:   map[string]interface{}{},
 		}
-		err = chunksDbHandler.InsertChunk(chunks[i])
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunks[i])
 		require.NoError(t, err)
 	}
 
 	// Test GetByDocument
-	retrievedChunks, err := chunksDbHandler.SelectAllChunksByDocument(doc.RID)
+	retrievedChunks, err := chunksDbHandler.SelectAllChunksByDocument(ctx, database.Instance, doc.RID)
 	assert.NoError(t, err, "Expected GetByDocument to not return an error")
 	assert.Len(t, retrievedChunks, chunkCount, "Expected to retrieve all chunks")
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestChunksSearchBySimilarity(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -199,7 +316,7 @@ func TestChunksSearchBySimilarity(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create chunks with 384-dimension embeddings
@@ -219,7 +336,7 @@ func TestChunksSearchBySimilarity(t *testing.T) {
 			Embedding:  emb,
 			Metadata:   map[string]interface{}{},
 		}
-		err = chunksDbHandler.InsertChunk(chunks[i])
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunks[i])
 		require.NoError(t, err)
 	}
 
@@ -227,19 +344,117 @@ func TestChunksSearchBySimilarity(t *testing.T) {
 	queryEmbedding := make([]float32, 384)
 	queryEmbedding[0] = 0.9
 	queryEmbedding[1] = 0.1
-	results, err := chunksDbHandler.SelectChunksBySimilarity(queryEmbedding, 2, 0.0, nil)
+	results, err := chunksDbHandler.SelectChunksBySimilarity(ctx, queryEmbedding, 2, 0.0, nil)
 	assert.NoError(t, err, "Expected SearchBySimilarity to not return an error")
 	assert.NotEmpty(t, results, "Expected to find similar chunks")
 	assert.LessOrEqual(t, len(results), 2, "Expected at most 2 results")
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	}
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestChunksSearchByKeyword(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, nil, 384, true)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Error Codes",
+		Source:   "errors.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	otherDoc := &model.Document{
+		Title:    "Products",
+		Source:   "products.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, otherDoc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "The server returns ERR4021 when the connection pool is exhausted.",
+		Path:       "root.errors.chunk0",
+		Metadata:   map[string]interface{}{},
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	chunk2 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Restarting the database resolves most connection problems.",
+		Path:       "root.errors.chunk1",
+		Metadata:   map[string]interface{}{},
+	}
+	chunk3 := &model.Chunk{
+		DocumentID: otherDoc.ID,
+		Content:    "Product SKU4711 ships with a connection cable.",
+		Path:       "root.products.chunk0",
+		Metadata:   map[string]interface{}{},
+	}
+	for _, chunk := range []*model.Chunk{chunk1, chunk2, chunk3} {
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
+		require.NoError(t, err)
+	}
+
+	t.Run("Search by exact identifier", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "ERR4021", 10, nil)
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		require.Len(t, results, 1, "Expected exactly one chunk to contain the identifier")
+		assert.Equal(t, chunk1.ID, results[0].ID)
+		require.NotNil(t, results[0].KeywordRank, "Expected keyword rank to be set")
+		assert.Greater(t, *results[0].KeywordRank, 0.0, "Expected keyword rank to be positive")
+		assert.Less(t, *results[0].KeywordRank, 1.0, "Expected keyword rank to be normalized")
+	})
+
+	t.Run("Search matches stemmed words", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "connections", 10, nil)
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		assert.Len(t, results, 3, "Expected all chunks mentioning connection to match")
+	})
+
+	t.Run("Search with document filter", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "connection", 10, []uuid.UUID{otherDoc.RID})
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		require.Len(t, results, 1, "Expected only chunks of the filtered document")
+		assert.Equal(t, otherDoc.RID, results[0].DocumentRID)
+	})
+
+	t.Run("Search with excluded term", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "connection -database", 10, nil)
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		for _, result := range results {
+			assert.NotEqual(t, chunk2.ID, result.ID, "Expected excluded term to filter out chunk")
+		}
+	})
+
+	t.Run("Search with limit", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "connection", 2, nil)
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		assert.Len(t, results, 2, "Expected results to be limited")
+	})
+
+	t.Run("Search without match", func(t *testing.T) {
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "nonexistentterm", 10, nil)
+		assert.NoError(t, err, "Expected SearchByKeyword to not return an error")
+		assert.Empty(t, results, "Expected no results for unknown term")
+	})
+
+	// Cleanup
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, otherDoc.RID)
 }
 
 func TestChunksDelete(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -254,7 +469,7 @@ func TestChunksDelete(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	chunk := &model.Chunk{
@@ -263,22 +478,23 @@ func TestChunksDelete(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 	require.NoError(t, err)
 
 	// Delete the chunk
-	err = chunksDbHandler.DeleteChunk(chunk.ID)
+	err = chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	assert.NoError(t, err, "Expected Delete to not return an error")
 
 	// Verify deletion
-	_, err = chunksDbHandler.SelectChunk(chunk.ID)
+	_, err = chunksDbHandler.SelectChunk(ctx, chunk.ID)
 	assert.Error(t, err, "Expected Get to return an error for deleted chunk")
 
 	// Cleanup
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestChunksUpdateEmbedding(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -293,7 +509,7 @@ func TestChunksUpdateEmbedding(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create 384-dimension embedding
@@ -308,7 +524,7 @@ func TestChunksUpdateEmbedding(t *testing.T) {
 		Embedding:  embedding,
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 	require.NoError(t, err)
 
 	// Update embedding - create new 384-dimension embedding
@@ -316,20 +532,21 @@ func TestChunksUpdateEmbedding(t *testing.T) {
 	for i := range newEmbedding {
 		newEmbedding[i] = 0.5
 	}
-	err = chunksDbHandler.UpdateChunkEmbedding(chunk.ID, newEmbedding)
+	err = chunksDbHandler.UpdateChunkEmbedding(ctx, chunk.ID, newEmbedding)
 	assert.NoError(t, err, "Expected UpdateEmbedding to not return an error")
 
 	// Verify update
-	retrievedChunk, err := chunksDbHandler.SelectChunk(chunk.ID)
+	retrievedChunk, err := chunksDbHandler.SelectChunk(ctx, chunk.ID)
 	require.NoError(t, err)
 	assert.Equal(t, newEmbedding, retrievedChunk.Embedding, "Expected embedding to be updated")
 
 	// Cleanup
-	chunksDbHandler.DeleteChunk(chunk.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestChunksSelectSiblingChunks(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -344,7 +561,7 @@ func TestChunksSelectSiblingChunks(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create a hierarchical structure:
@@ -368,13 +585,13 @@ func TestChunksSelectSiblingChunks(t *testing.T) {
 	}
 
 	for _, chunk := range chunks {
-		err = chunksDbHandler.InsertChunk(chunk)
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		require.NoError(t, err)
 	}
 
 	t.Run("Siblings at section level", func(t *testing.T) {
 		// Find siblings of section1 (should be section2 and section3)
-		siblings, err := chunksDbHandler.SelectSiblingChunks("root.section1")
+		siblings, err := chunksDbHandler.SelectSiblingChunks(ctx, "root.section1")
 		assert.NoError(t, err, "Expected SelectSiblingChunks to not return an error")
 		assert.Len(t, siblings, 2, "Expected to find 2 siblings")
 
@@ -389,7 +606,7 @@ func TestChunksSelectSiblingChunks(t *testing.T) {
 
 	t.Run("Siblings at paragraph level", func(t *testing.T) {
 		// Find siblings of section1.para1 (should be section1.para2)
-		siblings, err := chunksDbHandler.SelectSiblingChunks("root.section1.para1")
+		siblings, err := chunksDbHandler.SelectSiblingChunks(ctx, "root.section1.para1")
 		assert.NoError(t, err, "Expected SelectSiblingChunks to not return an error")
 		assert.Len(t, siblings, 1, "Expected to find 1 sibling")
 		assert.Equal(t, "root.section1.para2", siblings[0].Path)
@@ -397,14 +614,14 @@ func TestChunksSelectSiblingChunks(t *testing.T) {
 
 	t.Run("No siblings (only child)", func(t *testing.T) {
 		// Find siblings of root (has no siblings)
-		siblings, err := chunksDbHandler.SelectSiblingChunks("root")
+		siblings, err := chunksDbHandler.SelectSiblingChunks(ctx, "root")
 		assert.NoError(t, err, "Expected SelectSiblingChunks to not return an error")
 		assert.Empty(t, siblings, "Expected no siblings for root")
 	})
 
 	t.Run("Siblings at different parent", func(t *testing.T) {
 		// Find siblings of section2.para1 (should be section2.para2, not section1.para1)
-		siblings, err := chunksDbHandler.SelectSiblingChunks("root.section2.para1")
+		siblings, err := chunksDbHandler.SelectSiblingChunks(ctx, "root.section2.para1")
 		assert.NoError(t, err, "Expected SelectSiblingChunks to not return an error")
 		assert.Len(t, siblings, 1, "Expected to find 1 sibling")
 		assert.Equal(t, "root.section2.para2", siblings[0].Path)
@@ -417,12 +634,13 @@ func TestChunksSelectSiblingChunks(t *testing.T) {
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectAllChunksByPathDescendant(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -437,7 +655,7 @@ func TestSelectAllChunksByPathDescendant(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create hierarchical chunks
@@ -469,18 +687,18 @@ func TestSelectAllChunksByPathDescendant(t *testing.T) {
 	}
 
 	for _, chunk := range chunks {
-		err = chunksDbHandler.InsertChunk(chunk)
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		require.NoError(t, err)
 	}
 
 	t.Run("Get all descendants", func(t *testing.T) {
-		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant("root")
+		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant(ctx, "root")
 		assert.NoError(t, err)
 		assert.Len(t, descendants, 4, "Expected 4 nodes (root + 3 descendants)")
 	})
 
 	t.Run("Get descendants of section", func(t *testing.T) {
-		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant("root.section1")
+		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant(ctx, "root.section1")
 		assert.NoError(t, err)
 		assert.Len(t, descendants, 2, "Expected 2 nodes (section1 + para1)")
 		// Should include section1 and its child
@@ -493,7 +711,7 @@ func TestSelectAllChunksByPathDescendant(t *testing.T) {
 	})
 
 	t.Run("Get descendants of leaf", func(t *testing.T) {
-		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant("root.section1.para1")
+		descendants, err := chunksDbHandler.SelectAllChunksByPathDescendant(ctx, "root.section1.para1")
 		assert.NoError(t, err)
 		assert.Len(t, descendants, 1, "Expected 1 node (the leaf itself)")
 		assert.Equal(t, "root.section1.para1", descendants[0].Path)
@@ -501,12 +719,13 @@ func TestSelectAllChunksByPathDescendant(t *testing.T) {
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectAllChunksByPathAncestor(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -521,7 +740,7 @@ func TestSelectAllChunksByPathAncestor(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create hierarchical chunks
@@ -547,12 +766,12 @@ func TestSelectAllChunksByPathAncestor(t *testing.T) {
 	}
 
 	for _, chunk := range chunks {
-		err = chunksDbHandler.InsertChunk(chunk)
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		require.NoError(t, err)
 	}
 
 	t.Run("Get all ancestors of leaf", func(t *testing.T) {
-		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor("root.section1.para1")
+		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor(ctx, "root.section1.para1")
 		assert.NoError(t, err)
 		assert.Len(t, ancestors, 3, "Expected 3 nodes (self + 2 ancestors)")
 
@@ -566,7 +785,7 @@ func TestSelectAllChunksByPathAncestor(t *testing.T) {
 	})
 
 	t.Run("Get ancestors of section", func(t *testing.T) {
-		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor("root.section1")
+		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor(ctx, "root.section1")
 		assert.NoError(t, err)
 		assert.Len(t, ancestors, 2, "Expected 2 nodes (self + root)")
 		paths := make(map[string]bool)
@@ -578,7 +797,7 @@ func TestSelectAllChunksByPathAncestor(t *testing.T) {
 	})
 
 	t.Run("Get ancestors of root", func(t *testing.T) {
-		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor("root")
+		ancestors, err := chunksDbHandler.SelectAllChunksByPathAncestor(ctx, "root")
 		assert.NoError(t, err)
 		assert.Len(t, ancestors, 1, "Expected 1 node (root itself)")
 		assert.Equal(t, "root", ancestors[0].Path)
@@ -586,12 +805,13 @@ func TestSelectAllChunksByPathAncestor(t *testing.T) {
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectChunksBySimilarityWithContext(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -606,7 +826,7 @@ func TestSelectChunksBySimilarityWithContext(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create test embedding
@@ -634,7 +854,7 @@ func TestSelectChunksBySimilarityWithContext(t *testing.T) {
 	}
 
 	for _, chunk := range chunks {
-		err = chunksDbHandler.InsertChunk(chunk)
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 		require.NoError(t, err)
 	}
 
@@ -644,14 +864,14 @@ func TestSelectChunksBySimilarityWithContext(t *testing.T) {
 			queryEmbedding[i] = 0.5
 		}
 
-		results, err := chunksDbHandler.SelectChunksBySimilarityWithContext(queryEmbedding, 10, true, true, 0.0, nil)
+		results, err := chunksDbHandler.SelectChunksBySimilarityWithContext(ctx, queryEmbedding, 10, true, true, 0.0, nil)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, results)
 	})
 
 	// Cleanup
 	for _, chunk := range chunks {
-		chunksDbHandler.DeleteChunk(chunk.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
 	}
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
@@ -0,0 +1,244 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+	loadSql "github.com/siherrmann/grapher/sql"
+)
+
+// CommunitiesDBHandlerFunctions defines the interface for Communities database operations.
+type CommunitiesDBHandlerFunctions interface {
+	InsertCommunity(ctx context.Context, q Querier, community *model.Community) error
+	SelectCommunity(ctx context.Context, id uuid.UUID) (*model.Community, error)
+	SelectAllCommunities(ctx context.Context) ([]*model.Community, error)
+	SelectCommunitiesBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64) ([]*model.Community, error)
+	UpdateCommunitySummary(ctx context.Context, id uuid.UUID, summary string, embedding []float32) error
+	DeleteAllCommunities(ctx context.Context, q Querier) (int, error)
+}
+
+// CommunitiesDBHandler handles community-related database operations
+type CommunitiesDBHandler struct {
+	db *helper.Database
+}
+
+// NewCommunitiesDBHandler creates a new communities database handler.
+// It initializes the database connection and loads community-related SQL functions.
+// The communities reference the entities table, so it has to be created after the entities handler.
+// The embeddingDim sets the dimension of the summary embedding column.
+// If force is true, it will reload the SQL functions even if they already exist.
+func NewCommunitiesDBHandler(db *helper.Database, embeddingDim int, force bool) (*CommunitiesDBHandler, error) {
+	if db == nil {
+		return nil, helper.NewError("database connection validation", fmt.Errorf("database connection is nil"))
+	}
+
+	communitiesDbHandler := &CommunitiesDBHandler{
+		db: db,
+	}
+
+	err := loadSql.LoadCommunitiesSql(communitiesDbHandler.db.Instance, force)
+	if err != nil {
+		return nil, helper.NewError("load communities sql", err)
+	}
+
+	err = communitiesDbHandler.CreateTable(embeddingDim)
+	if err != nil {
+		return nil, helper.NewError("create table", err)
+	}
+
+	db.Logger.Info("Initialized CommunitiesDBHandler")
+
+	return communitiesDbHandler, nil
+}
+
+// CreateTable creates the 'communities' and 'community_members' tables in the database.
+// If the tables already exist, it does not create them again.
+// It also creates all necessary extensions and indexes.
+func (h *CommunitiesDBHandler) CreateTable(embeddingDim int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Use the SQL init() function to create all tables and indexes
+	_, err := h.db.Instance.ExecContext(ctx, `SELECT init_communities($1);`, embeddingDim)
+	if err != nil {
+		log.Panicf("error initializing communities table: %#v", err)
+	}
+
+	h.db.Logger.Info("Checked/created table communities")
+
+	return nil
+}
+
+// InsertCommunity inserts a new community with its member entities, the order of EntityIDs is kept
+func (h *CommunitiesDBHandler) InsertCommunity(ctx context.Context, q Querier, community *model.Community) error {
+	var embeddingParam interface{}
+	if len(community.Embedding) > 0 {
+		embeddingVector := pgvector.NewVector(community.Embedding)
+		embeddingParam = &embeddingVector
+	}
+
+	row := q.QueryRowContext(ctx,
+		`SELECT * FROM insert_community($1, $2, $3, $4, $5, $6)`,
+		community.Title,
+		community.Summary,
+		community.Weight,
+		community.Metadata,
+		embeddingParam,
+		pq.Array(community.EntityIDs),
+	)
+
+	err := row.Scan(
+		&community.ID,
+		&community.Title,
+		&community.Summary,
+		&community.Weight,
+		&community.Metadata,
+		&community.CreatedAt,
+	)
+	if err != nil {
+		return helper.NewError("scan", err)
+	}
+
+	return nil
+}
+
+// SelectCommunity retrieves a community with its member entity IDs
+func (h *CommunitiesDBHandler) SelectCommunity(ctx context.Context, id uuid.UUID) (*model.Community, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_community($1)`,
+		id,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+
+	communities, err := scanCommunities(rows, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(communities) == 0 {
+		return nil, helper.NewError("scan", sql.ErrNoRows)
+	}
+
+	return communities[0], nil
+}
+
+// SelectAllCommunities retrieves all communities, the ones with the highest weight first
+func (h *CommunitiesDBHandler) SelectAllCommunities(ctx context.Context) ([]*model.Community, error) {
+	rows, err := h.db.Instance.QueryContext(ctx, `SELECT * FROM select_all_communities()`)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+
+	return scanCommunities(rows, false)
+}
+
+// SelectCommunitiesBySimilarity performs vector similarity search over the community summary embeddings.
+// Communities without a summary embedding are skipped.
+func (h *CommunitiesDBHandler) SelectCommunitiesBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64) ([]*model.Community, error) {
+	embeddingVector := pgvector.NewVector(embedding)
+
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_communities_by_similarity($1, $2, $3)`,
+		embeddingVector,
+		limit,
+		threshold,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+
+	return scanCommunities(rows, true)
+}
+
+// UpdateCommunitySummary replaces the summary and summary embedding of a community
+func (h *CommunitiesDBHandler) UpdateCommunitySummary(ctx context.Context, id uuid.UUID, summary string, embedding []float32) error {
+	var embeddingParam interface{}
+	if len(embedding) > 0 {
+		embeddingVector := pgvector.NewVector(embedding)
+		embeddingParam = &embeddingVector
+	}
+
+	_, err := h.db.Instance.ExecContext(ctx,
+		`SELECT update_community_summary($1, $2, $3)`,
+		id,
+		summary,
+		embeddingParam,
+	)
+	if err != nil {
+		return helper.NewError("exec", err)
+	}
+	return nil
+}
+
+// DeleteAllCommunities deletes all communities and their members, returns the number of deleted communities
+func (h *CommunitiesDBHandler) DeleteAllCommunities(ctx context.Context, q Querier) (int, error) {
+	var deleted int
+	err := q.QueryRowContext(ctx, `SELECT delete_all_communities()`).Scan(&deleted)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+	return deleted, nil
+}
+
+// scanCommunities scans and closes community rows, optionally with a trailing similarity column
+func scanCommunities(rows *sql.Rows, withSimilarity bool) ([]*model.Community, error) {
+	defer rows.Close()
+
+	var communities []*model.Community
+	for rows.Next() {
+		community := &model.Community{}
+		var embeddingVec *pgvector.Vector
+		var entityIDs []byte
+		var similarity sql.NullFloat64
+		dest := []interface{}{
+			&community.ID,
+			&community.Title,
+			&community.Summary,
+			&community.Weight,
+			&community.Metadata,
+			&community.CreatedAt,
+			&embeddingVec,
+			&entityIDs,
+		}
+		if withSimilarity {
+			dest = append(dest, &similarity)
+		}
+
+		err := rows.Scan(dest...)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		if embeddingVec != nil {
+			community.Embedding = embeddingVec.Slice()
+		}
+		if err := parseUUIDArray(entityIDs, &community.EntityIDs); err != nil {
+			return nil, helper.NewError("parsing entity ids", err)
+		}
+		if withSimilarity {
+			value := 0.0
+			if similarity.Valid {
+				value = similarity.Float64
+			}
+			community.Similarity = &value
+		}
+
+		communities = append(communities, community)
+	}
+
+	err := rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return communities, nil
+}
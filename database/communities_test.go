@@ -0,0 +1,157 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommunitiesNewCommunitiesDBHandler(t *testing.T) {
+	database := initDB(t)
+
+	_, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	t.Run("Valid call NewCommunitiesDBHandler", func(t *testing.T) {
+		communitiesDbHandler, err := NewCommunitiesDBHandler(database, 384, true)
+		assert.NoError(t, err, "Expected NewCommunitiesDBHandler to not return an error")
+		require.NotNil(t, communitiesDbHandler, "Expected NewCommunitiesDBHandler to return a non-nil instance")
+		require.NotNil(t, communitiesDbHandler.db, "Expected NewCommunitiesDBHandler to have a non-nil database instance")
+	})
+
+	t.Run("Invalid call NewCommunitiesDBHandler with nil database", func(t *testing.T) {
+		_, err := NewCommunitiesDBHandler(nil, 384, false)
+		assert.Error(t, err, "Expected error when creating CommunitiesDBHandler with nil database")
+		assert.Contains(t, err.Error(), "database connection is nil")
+	})
+}
+
+func TestCommunities(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	communitiesDbHandler, err := NewCommunitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	_, err = communitiesDbHandler.DeleteAllCommunities(ctx, database.Instance)
+	require.NoError(t, err)
+
+	// Setup
+	entity1 := &model.Entity{Name: "Community Member One", Type: "Person", Metadata: map[string]interface{}{}}
+	entity2 := &model.Entity{Name: "Community Member Two", Type: "Person", Metadata: map[string]interface{}{}}
+	entity3 := &model.Entity{Name: "Community Member Three", Type: "Organization", Metadata: map[string]interface{}{}}
+	for _, entity := range []*model.Entity{entity1, entity2, entity3} {
+		require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+	}
+
+	embedding := make([]float32, 384)
+	for i := range embedding {
+		embedding[i] = 0.5
+	}
+
+	strong := &model.Community{
+		Title:     "Community Member Two, Community Member One",
+		Summary:   "Two people mentioned together.",
+		Weight:    2.5,
+		EntityIDs: []uuid.UUID{entity2.ID, entity1.ID},
+		Metadata:  map[string]interface{}{"resolution": 1.0},
+		Embedding: embedding,
+	}
+	weak := &model.Community{
+		Title:     "Community Member Three",
+		Weight:    0.5,
+		EntityIDs: []uuid.UUID{entity3.ID},
+		Metadata:  map[string]interface{}{},
+	}
+
+	t.Run("Insert communities", func(t *testing.T) {
+		err := communitiesDbHandler.InsertCommunity(ctx, database.Instance, strong)
+		assert.NoError(t, err, "Expected InsertCommunity to not return an error")
+		assert.NotEqual(t, uuid.Nil, strong.ID)
+
+		err = communitiesDbHandler.InsertCommunity(ctx, database.Instance, weak)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Select community keeps the member order", func(t *testing.T) {
+		community, err := communitiesDbHandler.SelectCommunity(ctx, strong.ID)
+
+		assert.NoError(t, err, "Expected SelectCommunity to not return an error")
+		require.NotNil(t, community)
+		assert.Equal(t, strong.Title, community.Title)
+		assert.Equal(t, strong.Summary, community.Summary)
+		assert.Equal(t, 2.5, community.Weight)
+		assert.Equal(t, []uuid.UUID{entity2.ID, entity1.ID}, community.EntityIDs)
+		assert.Len(t, community.Embedding, 384)
+	})
+
+	t.Run("Select missing community", func(t *testing.T) {
+		_, err := communitiesDbHandler.SelectCommunity(ctx, uuid.New())
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Select all communities by weight", func(t *testing.T) {
+		communities, err := communitiesDbHandler.SelectAllCommunities(ctx)
+
+		assert.NoError(t, err)
+		require.Len(t, communities, 2)
+		assert.Equal(t, strong.ID, communities[0].ID)
+		assert.Equal(t, weak.ID, communities[1].ID)
+		assert.Empty(t, communities[1].Embedding, "Expected no embedding without summary")
+	})
+
+	t.Run("Select communities by similarity", func(t *testing.T) {
+		communities, err := communitiesDbHandler.SelectCommunitiesBySimilarity(ctx, embedding, 10, 0.5)
+
+		assert.NoError(t, err)
+		require.Len(t, communities, 1, "Expected communities without embedding to be skipped")
+		assert.Equal(t, strong.ID, communities[0].ID)
+		require.NotNil(t, communities[0].Similarity)
+		assert.InDelta(t, 1.0, *communities[0].Similarity, 0.001)
+	})
+
+	t.Run("Update community summary", func(t *testing.T) {
+		err := communitiesDbHandler.UpdateCommunitySummary(ctx, weak.ID, "An organization.", embedding)
+		assert.NoError(t, err, "Expected UpdateCommunitySummary to not return an error")
+
+		community, err := communitiesDbHandler.SelectCommunity(ctx, weak.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "An organization.", community.Summary)
+		assert.Len(t, community.Embedding, 384)
+
+		err = communitiesDbHandler.UpdateCommunitySummary(ctx, uuid.New(), "Missing", nil)
+		assert.Error(t, err, "Expected error for missing community")
+	})
+
+	t.Run("Members are removed with their entity", func(t *testing.T) {
+		require.NoError(t, entitiesDbHandler.DeleteEntity(ctx, entity1.ID))
+
+		community, err := communitiesDbHandler.SelectCommunity(ctx, strong.ID)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{entity2.ID}, community.EntityIDs)
+	})
+
+	t.Run("Delete all communities", func(t *testing.T) {
+		deleted, err := communitiesDbHandler.DeleteAllCommunities(ctx, database.Instance)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, deleted)
+
+		communities, err := communitiesDbHandler.SelectAllCommunities(ctx)
+		assert.NoError(t, err)
+		assert.Empty(t, communities)
+	})
+
+	// Cleanup
+	entitiesDbHandler.DeleteEntity(ctx, entity2.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity3.ID)
+}
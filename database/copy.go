@@ -0,0 +1,22 @@
+package database
+
+import (
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// copyMetadata converts metadata into the text representation expected by COPY.
+// COPY encodes []byte values as bytea, so JSONB columns have to be passed as strings.
+// Nil metadata is stored as an empty object to match the column default.
+func copyMetadata(metadata model.Metadata) (string, error) {
+	if metadata == nil {
+		return "{}", nil
+	}
+
+	b, err := metadata.Marshal()
+	if err != nil {
+		return "", helper.NewError("marshal metadata", err)
+	}
+
+	return string(b), nil
+}
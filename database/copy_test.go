@@ -0,0 +1,27 @@
+package database
+
+import (
+	"testing"
+
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyMetadata(t *testing.T) {
+	t.Run("Nil metadata becomes empty object", func(t *testing.T) {
+		text, err := copyMetadata(nil)
+		assert.NoError(t, err, "Expected copyMetadata to not return an error")
+		assert.Equal(t, "{}", text, "Expected nil metadata to be stored as empty object")
+	})
+
+	t.Run("Metadata is encoded as JSON text", func(t *testing.T) {
+		text, err := copyMetadata(model.Metadata{"key": "value"})
+		assert.NoError(t, err, "Expected copyMetadata to not return an error")
+		assert.JSONEq(t, `{"key":"value"}`, text, "Expected metadata to be encoded as JSON")
+	})
+
+	t.Run("Unsupported value returns error", func(t *testing.T) {
+		_, err := copyMetadata(model.Metadata{"invalid": make(chan int)})
+		assert.Error(t, err, "Expected error for metadata that cannot be marshaled")
+	})
+}
@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
@@ -9,17 +10,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
-	"github.com/siherrmann/grapher/sql"
+	loadSql "github.com/siherrmann/grapher/sql"
 )
 
 // DocumentsDBHandlerFunctions defines the interface for Documents database operations.
 type DocumentsDBHandlerFunctions interface {
-	InsertDocument(doc *model.Document) error
-	SelectDocument(rid uuid.UUID) (*model.Document, error)
-	SelectAllDocuments(lastCreatedAt *time.Time, limit int) ([]*model.Document, error)
-	SelectDocumentsBySearch(searchTerm string, limit int) ([]*model.Document, error)
-	UpdateDocument(doc *model.Document) error
-	DeleteDocument(rid uuid.UUID) error
+	InsertDocument(ctx context.Context, q Querier, doc *model.Document) error
+	SelectDocument(ctx context.Context, rid uuid.UUID) (*model.Document, error)
+	SelectAllDocuments(ctx context.Context, lastCreatedAt *time.Time, limit int) ([]*model.Document, error)
+	SelectDocumentsBySearch(ctx context.Context, searchTerm string, limit int) ([]*model.Document, error)
+	UpdateDocument(ctx context.Context, q Querier, doc *model.Document) error
+	SelectDocumentBySource(ctx context.Context, q Querier, source string) (*model.Document, error)
+	LockDocumentSource(ctx context.Context, tx *sql.Tx, source string) error
+	DeleteDocumentContent(ctx context.Context, q Querier, rid uuid.UUID) (int, error)
+	DeleteDocument(ctx context.Context, rid uuid.UUID) error
 }
 
 // DocumentsDBHandler handles document-related database operations
@@ -39,7 +43,7 @@ func NewDocumentsDBHandler(db *helper.Database, force bool) (*DocumentsDBHandler
 		db: db,
 	}
 
-	err := sql.LoadDocumentsSql(documentsDbHandler.db.Instance, force)
+	err := loadSql.LoadDocumentsSql(documentsDbHandler.db.Instance, force)
 	if err != nil {
 		return nil, helper.NewError("load documents sql", err)
 	}
@@ -72,13 +76,20 @@ func (h *DocumentsDBHandler) CreateTable() error {
 	return nil
 }
 
-// InsertDocument inserts a new document
-func (h *DocumentsDBHandler) InsertDocument(doc *model.Document) error {
-	row := h.db.Instance.QueryRow(
-		`SELECT * FROM insert_document($1, $2, $3)`,
+// InsertDocument inserts a new document, it gets a new RID unless doc.RID is set
+func (h *DocumentsDBHandler) InsertDocument(ctx context.Context, q Querier, doc *model.Document) error {
+	var rid *uuid.UUID
+	if doc.RID != uuid.Nil {
+		rid = &doc.RID
+	}
+
+	row := q.QueryRowContext(ctx,
+		`SELECT * FROM insert_document($1, $2, $3, $4, $5)`,
 		doc.Title,
 		doc.Source,
 		doc.Metadata,
+		doc.ContentHash,
+		rid,
 	)
 
 	err := row.Scan(
@@ -87,6 +98,7 @@ func (h *DocumentsDBHandler) InsertDocument(doc *model.Document) error {
 		&doc.Title,
 		&doc.Source,
 		&doc.Metadata,
+		&doc.ContentHash,
 		&doc.CreatedAt,
 		&doc.UpdatedAt,
 	)
@@ -98,9 +110,9 @@ func (h *DocumentsDBHandler) InsertDocument(doc *model.Document) error {
 }
 
 // SelectDocument retrieves a document by RID
-func (h *DocumentsDBHandler) SelectDocument(rid uuid.UUID) (*model.Document, error) {
+func (h *DocumentsDBHandler) SelectDocument(ctx context.Context, rid uuid.UUID) (*model.Document, error) {
 	doc := &model.Document{}
-	row := h.db.Instance.QueryRow(
+	row := h.db.Instance.QueryRowContext(ctx,
 		`SELECT * FROM select_document($1)`,
 		rid,
 	)
@@ -111,6 +123,7 @@ func (h *DocumentsDBHandler) SelectDocument(rid uuid.UUID) (*model.Document, err
 		&doc.Title,
 		&doc.Source,
 		&doc.Metadata,
+		&doc.ContentHash,
 		&doc.CreatedAt,
 		&doc.UpdatedAt,
 	)
@@ -122,8 +135,8 @@ func (h *DocumentsDBHandler) SelectDocument(rid uuid.UUID) (*model.Document, err
 }
 
 // SelectAllDocuments retrieves all documents with pagination
-func (h *DocumentsDBHandler) SelectAllDocuments(lastCreatedAt *time.Time, limit int) ([]*model.Document, error) {
-	rows, err := h.db.Instance.Query(
+func (h *DocumentsDBHandler) SelectAllDocuments(ctx context.Context, lastCreatedAt *time.Time, limit int) ([]*model.Document, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_all_documents($1, $2)`,
 		lastCreatedAt,
 		limit,
@@ -142,6 +155,7 @@ func (h *DocumentsDBHandler) SelectAllDocuments(lastCreatedAt *time.Time, limit
 			&doc.Title,
 			&doc.Source,
 			&doc.Metadata,
+			&doc.ContentHash,
 			&doc.CreatedAt,
 			&doc.UpdatedAt,
 		)
@@ -161,8 +175,8 @@ func (h *DocumentsDBHandler) SelectAllDocuments(lastCreatedAt *time.Time, limit
 }
 
 // SelectDocumentsBySearch searches documents by title or source
-func (h *DocumentsDBHandler) SelectDocumentsBySearch(searchTerm string, limit int) ([]*model.Document, error) {
-	rows, err := h.db.Instance.Query(
+func (h *DocumentsDBHandler) SelectDocumentsBySearch(ctx context.Context, searchTerm string, limit int) ([]*model.Document, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM search_documents($1, $2)`,
 		searchTerm,
 		limit,
@@ -181,6 +195,7 @@ func (h *DocumentsDBHandler) SelectDocumentsBySearch(searchTerm string, limit in
 			&doc.Title,
 			&doc.Source,
 			&doc.Metadata,
+			&doc.ContentHash,
 			&doc.CreatedAt,
 			&doc.UpdatedAt,
 		)
@@ -199,14 +214,15 @@ func (h *DocumentsDBHandler) SelectDocumentsBySearch(searchTerm string, limit in
 	return documents, nil
 }
 
-// UpdateDocument updates a document
-func (h *DocumentsDBHandler) UpdateDocument(doc *model.Document) error {
-	row := h.db.Instance.QueryRow(
-		`SELECT * FROM update_document($1, $2, $3, $4)`,
+// UpdateDocument updates a document, an empty content hash keeps the stored hash
+func (h *DocumentsDBHandler) UpdateDocument(ctx context.Context, q Querier, doc *model.Document) error {
+	row := q.QueryRowContext(ctx,
+		`SELECT * FROM update_document($1, $2, $3, $4, $5)`,
 		doc.RID,
 		doc.Title,
 		doc.Source,
 		doc.Metadata,
+		doc.ContentHash,
 	)
 
 	err := row.Scan(
@@ -215,6 +231,7 @@ func (h *DocumentsDBHandler) UpdateDocument(doc *model.Document) error {
 		&doc.Title,
 		&doc.Source,
 		&doc.Metadata,
+		&doc.ContentHash,
 		&doc.CreatedAt,
 		&doc.UpdatedAt,
 	)
@@ -225,9 +242,66 @@ func (h *DocumentsDBHandler) UpdateDocument(doc *model.Document) error {
 	return nil
 }
 
+// SelectDocumentBySource retrieves the latest document with the given source.
+// Returns nil without error if no document has the source.
+func (h *DocumentsDBHandler) SelectDocumentBySource(ctx context.Context, q Querier, source string) (*model.Document, error) {
+	doc := &model.Document{}
+	row := q.QueryRowContext(ctx,
+		`SELECT * FROM select_document_by_source($1)`,
+		source,
+	)
+
+	err := row.Scan(
+		&doc.ID,
+		&doc.RID,
+		&doc.Title,
+		&doc.Source,
+		&doc.Metadata,
+		&doc.ContentHash,
+		&doc.CreatedAt,
+		&doc.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, helper.NewError("scan", err)
+	}
+
+	return doc, nil
+}
+
+// LockDocumentSource locks a source until the transaction ends,
+// so concurrent upserts of the same source run one after another
+func (h *DocumentsDBHandler) LockDocumentSource(ctx context.Context, tx *sql.Tx, source string) error {
+	_, err := tx.ExecContext(ctx,
+		`SELECT lock_document_source($1)`,
+		source,
+	)
+	if err != nil {
+		return helper.NewError("exec", err)
+	}
+	return nil
+}
+
+// DeleteDocumentContent deletes the chunks of a document with their edges.
+// Entities lose the mentions of the document and are deleted if no chunk mentions them anymore.
+// The document itself is kept. Returns the number of deleted chunks.
+func (h *DocumentsDBHandler) DeleteDocumentContent(ctx context.Context, q Querier, rid uuid.UUID) (int, error) {
+	var deleted int
+	err := q.QueryRowContext(ctx,
+		`SELECT delete_document_content($1)`,
+		rid,
+	).Scan(&deleted)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+	return deleted, nil
+}
+
 // DeleteDocument deletes a document by RID
-func (h *DocumentsDBHandler) DeleteDocument(rid uuid.UUID) error {
-	_, err := h.db.Instance.Exec(
+func (h *DocumentsDBHandler) DeleteDocument(ctx context.Context, rid uuid.UUID) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT delete_document($1)`,
 		rid,
 	)
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +30,7 @@ func TestDocumentsNewDocumentsDBHandler(t *testing.T) {
 }
 
 func TestDocumentsInsert(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -40,7 +43,7 @@ func TestDocumentsInsert(t *testing.T) {
 			Metadata: map[string]interface{}{"author": "Test Author", "year": 2024},
 		}
 
-		err := documentsDbHandler.InsertDocument(doc)
+		err := documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 		assert.NoError(t, err, "Expected Insert to not return an error")
 		assert.NotEmpty(t, doc.RID, "Expected inserted document to have a RID")
 		assert.WithinDuration(t, doc.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
@@ -48,11 +51,70 @@ func TestDocumentsInsert(t *testing.T) {
 		assert.Equal(t, "Test Document", doc.Title, "Expected title to match")
 
 		// Cleanup
-		documentsDbHandler.DeleteDocument(doc.RID)
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
+	})
+
+	t.Run("Insert document with RID", func(t *testing.T) {
+		rid := uuid.New()
+		doc := &model.Document{
+			RID:      rid,
+			Title:    "Document With RID",
+			Source:   "test_rid.txt",
+			Metadata: map[string]interface{}{},
+		}
+
+		err := documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+		assert.NoError(t, err, "Expected Insert to not return an error")
+		assert.Equal(t, rid, doc.RID, "Expected the given RID to be kept")
+
+		// Cleanup
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
+	})
+
+	t.Run("Insert document in committed transaction", func(t *testing.T) {
+		tx, err := database.Instance.Begin()
+		require.NoError(t, err)
+
+		doc := &model.Document{
+			Title:    "Committed Document",
+			Source:   "committed.txt",
+			Metadata: map[string]interface{}{},
+		}
+
+		err = documentsDbHandler.InsertDocument(ctx, tx, doc)
+		assert.NoError(t, err, "Expected InsertTx to not return an error")
+		assert.NotEmpty(t, doc.RID, "Expected inserted document to have a RID")
+		require.NoError(t, tx.Commit())
+
+		retrievedDoc, err := documentsDbHandler.SelectDocument(ctx, doc.RID)
+		assert.NoError(t, err, "Expected committed document to be retrievable")
+		assert.Equal(t, doc.Title, retrievedDoc.Title)
+
+		// Cleanup
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
+	})
+
+	t.Run("Insert document in rolled back transaction", func(t *testing.T) {
+		tx, err := database.Instance.Begin()
+		require.NoError(t, err)
+
+		doc := &model.Document{
+			Title:    "Rolled Back Document",
+			Source:   "rolled_back.txt",
+			Metadata: map[string]interface{}{},
+		}
+
+		err = documentsDbHandler.InsertDocument(ctx, tx, doc)
+		assert.NoError(t, err, "Expected InsertTx to not return an error")
+		require.NoError(t, tx.Rollback())
+
+		_, err = documentsDbHandler.SelectDocument(ctx, doc.RID)
+		assert.Error(t, err, "Expected rolled back document to not exist")
 	})
 }
 
 func TestDocumentsGet(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -64,11 +126,11 @@ func TestDocumentsGet(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{"key": "value"},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Test Get
-	retrievedDoc, err := documentsDbHandler.SelectDocument(doc.RID)
+	retrievedDoc, err := documentsDbHandler.SelectDocument(ctx, doc.RID)
 	assert.NoError(t, err, "Expected Get to not return an error")
 	assert.NotNil(t, retrievedDoc, "Expected Get to return a non-nil document")
 	assert.Equal(t, doc.RID, retrievedDoc.RID, "Expected document RIDs to match")
@@ -76,10 +138,11 @@ func TestDocumentsGet(t *testing.T) {
 	assert.Equal(t, doc.Source, retrievedDoc.Source, "Expected sources to match")
 
 	// Cleanup
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestDocumentsGetAll(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -94,28 +157,29 @@ func TestDocumentsGetAll(t *testing.T) {
 			Source:   "test.txt",
 			Metadata: map[string]interface{}{},
 		}
-		err = documentsDbHandler.InsertDocument(docs[i])
+		err = documentsDbHandler.InsertDocument(ctx, database.Instance, docs[i])
 		require.NoError(t, err)
 	}
 
 	// Test SelectAllDocuments
-	retrievedDocs, err := documentsDbHandler.SelectAllDocuments(nil, 10)
+	retrievedDocs, err := documentsDbHandler.SelectAllDocuments(ctx, nil, 10)
 	assert.NoError(t, err, "Expected SelectAllDocuments to not return an error")
 	assert.GreaterOrEqual(t, len(retrievedDocs), docCount, "Expected to retrieve at least the inserted documents")
 
 	// Test pagination
 	pageLength := 3
-	paginatedDocs, err := documentsDbHandler.SelectAllDocuments(nil, pageLength)
+	paginatedDocs, err := documentsDbHandler.SelectAllDocuments(ctx, nil, pageLength)
 	assert.NoError(t, err, "Expected SelectAllDocuments to not return an error")
 	assert.LessOrEqual(t, len(paginatedDocs), pageLength, "Expected at most pageLength documents")
 
 	// Cleanup
 	for _, doc := range docs {
-		documentsDbHandler.DeleteDocument(doc.RID)
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
 	}
 }
 
 func TestDocumentsSearch(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -134,7 +198,7 @@ func TestDocumentsSearch(t *testing.T) {
 			Source:   "test.txt",
 			Metadata: map[string]interface{}{},
 		}
-		err = documentsDbHandler.InsertDocument(doc)
+		err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 		require.NoError(t, err)
 		docs = append(docs, doc)
 	}
@@ -145,23 +209,24 @@ func TestDocumentsSearch(t *testing.T) {
 			Source:   "test.txt",
 			Metadata: map[string]interface{}{},
 		}
-		err = documentsDbHandler.InsertDocument(doc)
+		err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 		require.NoError(t, err)
 		docs = append(docs, doc)
 	}
 
 	// Test Search
-	results, err := documentsDbHandler.SelectDocumentsBySearch(searchTerm, 10)
+	results, err := documentsDbHandler.SelectDocumentsBySearch(ctx, searchTerm, 10)
 	assert.NoError(t, err, "Expected SelectDocumentsBySearch to not return an error")
 	assert.Len(t, results, matchingDocs, "Expected to find only matching documents")
 
 	// Cleanup
 	for _, doc := range docs {
-		documentsDbHandler.DeleteDocument(doc.RID)
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
 	}
 }
 
 func TestDocumentsUpdate(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -173,7 +238,7 @@ func TestDocumentsUpdate(t *testing.T) {
 		Source:   "original.txt",
 		Metadata: map[string]interface{}{"version": 1},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Update the document
@@ -181,21 +246,115 @@ func TestDocumentsUpdate(t *testing.T) {
 	doc.Source = "updated.txt"
 	doc.Metadata = map[string]interface{}{"version": 2}
 
-	err = documentsDbHandler.UpdateDocument(doc)
+	err = documentsDbHandler.UpdateDocument(ctx, database.Instance, doc)
 	assert.NoError(t, err, "Expected UpdateDocument to not return an error")
 
 	// Verify update
-	retrievedDoc, err := documentsDbHandler.SelectDocument(doc.RID)
+	retrievedDoc, err := documentsDbHandler.SelectDocument(ctx, doc.RID)
 	require.NoError(t, err)
 	assert.Equal(t, "Updated Title", retrievedDoc.Title, "Expected title to be updated")
 	assert.Equal(t, "updated.txt", retrievedDoc.Source, "Expected source to be updated")
 	assert.Equal(t, float64(2), retrievedDoc.Metadata["version"], "Expected metadata to be updated")
 
 	// Cleanup
-	documentsDbHandler.DeleteDocument(doc.RID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestDocumentsContentHash(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:       "Hashed Document",
+		Source:      "hashed.txt",
+		Metadata:    map[string]interface{}{},
+		ContentHash: "hash1",
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	t.Run("Content hash is stored", func(t *testing.T) {
+		retrievedDoc, err := documentsDbHandler.SelectDocument(ctx, doc.RID)
+		require.NoError(t, err)
+		assert.Equal(t, "hash1", retrievedDoc.ContentHash, "Expected content hash to be stored")
+	})
+
+	t.Run("Update without content hash keeps the stored hash", func(t *testing.T) {
+		doc.ContentHash = ""
+		err := documentsDbHandler.UpdateDocument(ctx, database.Instance, doc)
+		require.NoError(t, err)
+		assert.Equal(t, "hash1", doc.ContentHash, "Expected content hash to be kept")
+	})
+
+	t.Run("Update content hash in transaction", func(t *testing.T) {
+		tx, err := database.Instance.Begin()
+		require.NoError(t, err)
+
+		doc.ContentHash = "hash2"
+		err = documentsDbHandler.UpdateDocument(ctx, tx, doc)
+		require.NoError(t, err)
+		require.NoError(t, tx.Commit())
+
+		retrievedDoc, err := documentsDbHandler.SelectDocument(ctx, doc.RID)
+		require.NoError(t, err)
+		assert.Equal(t, "hash2", retrievedDoc.ContentHash, "Expected content hash to be updated")
+	})
+
+	// Cleanup
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestDocumentsSelectBySource(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Source Document",
+		Source:   "select_by_source.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	t.Run("Select document by source", func(t *testing.T) {
+		retrievedDoc, err := documentsDbHandler.SelectDocumentBySource(ctx, database.Instance, "select_by_source.txt")
+		assert.NoError(t, err, "Expected SelectDocumentBySource to not return an error")
+		require.NotNil(t, retrievedDoc, "Expected document to be found")
+		assert.Equal(t, doc.RID, retrievedDoc.RID)
+	})
+
+	t.Run("Select missing source returns nil", func(t *testing.T) {
+		retrievedDoc, err := documentsDbHandler.SelectDocumentBySource(ctx, database.Instance, "missing_source.txt")
+		assert.NoError(t, err, "Expected no error for a missing source")
+		assert.Nil(t, retrievedDoc, "Expected no document for a missing source")
+	})
+
+	t.Run("Lock source and select in transaction", func(t *testing.T) {
+		tx, err := database.Instance.Begin()
+		require.NoError(t, err)
+		defer tx.Rollback()
+
+		err = documentsDbHandler.LockDocumentSource(ctx, tx, "select_by_source.txt")
+		assert.NoError(t, err, "Expected LockDocumentSourceTx to not return an error")
+
+		retrievedDoc, err := documentsDbHandler.SelectDocumentBySource(ctx, tx, "select_by_source.txt")
+		assert.NoError(t, err)
+		require.NotNil(t, retrievedDoc)
+		assert.Equal(t, doc.RID, retrievedDoc.RID)
+	})
+
+	// Cleanup
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestDocumentsDelete(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -207,14 +366,14 @@ func TestDocumentsDelete(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Delete the document
-	err = documentsDbHandler.DeleteDocument(doc.RID)
+	err = documentsDbHandler.DeleteDocument(ctx, doc.RID)
 	assert.NoError(t, err, "Expected Delete to not return an error")
 
 	// Verify deletion
-	_, err = documentsDbHandler.SelectDocument(doc.RID)
+	_, err = documentsDbHandler.SelectDocument(ctx, doc.RID)
 	assert.Error(t, err, "Expected Get to return an error for deleted document")
 }
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
 	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
 	loadSql "github.com/siherrmann/grapher/sql"
@@ -15,16 +17,25 @@ import (
 
 // EdgesDBHandlerFunctions defines the interface for Edges database operations.
 type EdgesDBHandlerFunctions interface {
-	InsertEdge(edge *model.Edge) error
-	SelectEdge(id uuid.UUID) (*model.Edge, error)
-	SelectEdgesFromChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
-	SelectEdgesToChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
-	SelectEdgesConnectedToChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.EdgeConnection, error)
-	SelectEdgesFromEntity(entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
-	SelectEdgesToEntity(entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
-	DeleteEdge(id uuid.UUID) error
-	UpdateEdgeWeight(id uuid.UUID, weight float64) error
-	TraverseBFSFromChunk(startChunkID uuid.UUID, maxDepth int, edgeType *model.EdgeType) ([]*model.TraversalNode, error)
+	InsertEdge(ctx context.Context, q Querier, edge *model.Edge) error
+	InsertEdgesBatch(ctx context.Context, q Querier, edges []*model.Edge) error
+	SelectEdge(ctx context.Context, id uuid.UUID) (*model.Edge, error)
+	SelectEdgesFromChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
+	SelectEdgesToChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
+	SelectEdgesConnectedToChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.EdgeConnection, error)
+	SelectEdgesFromEntity(ctx context.Context, entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
+	SelectEdgesToEntity(ctx context.Context, entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error)
+	SelectEdgesConnectedToNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType) ([]*model.Edge, error)
+	SelectEntityEdges(ctx context.Context, edgeTypes []model.EdgeType) ([]*model.Edge, error)
+	SelectEdgesBetweenNodes(ctx context.Context, nodeIDs []uuid.UUID, edgeTypes []model.EdgeType) ([]*model.Edge, error)
+	DeleteEdge(ctx context.Context, id uuid.UUID) error
+	UpdateEdgeWeight(ctx context.Context, id uuid.UUID, weight float64) error
+	TraverseBFSFromChunk(ctx context.Context, startChunkID uuid.UUID, maxDepth int, edgeType *model.EdgeType) ([]*model.TraversalNode, error)
+	TraverseGraph(ctx context.Context, seedIDs []uuid.UUID, maxDepth int, edgeTypes []model.EdgeType, followBidirectional bool, maxFanout int) ([]*model.TraversalNode, error)
+	LinkSemanticNeighbors(ctx context.Context, q Querier, chunkIDs []uuid.UUID, config model.SemanticLinkConfig) (int, error)
+	PruneSemanticEdges(ctx context.Context, threshold float64, maxPerChunk int) (int, error)
+	RebuildSemanticEdges(ctx context.Context, config model.SemanticLinkConfig) (int, error)
+	DeleteDocumentEdges(ctx context.Context, q Querier, documentRID uuid.UUID, edgeType model.EdgeType) (int, error)
 }
 
 // EdgesDBHandler handles edge-related database operations
@@ -78,8 +89,8 @@ func (h *EdgesDBHandler) CreateTable() error {
 }
 
 // InsertEdge inserts a new edge
-func (h *EdgesDBHandler) InsertEdge(edge *model.Edge) error {
-	row := h.db.Instance.QueryRow(
+func (h *EdgesDBHandler) InsertEdge(ctx context.Context, q Querier, edge *model.Edge) error {
+	row := q.QueryRowContext(ctx,
 		`SELECT * FROM insert_edge($1, $2, $3, $4, $5, $6, $7, $8)`,
 		edge.SourceChunkID,
 		edge.TargetChunkID,
@@ -110,9 +121,82 @@ func (h *EdgesDBHandler) InsertEdge(edge *model.Edge) error {
 	return nil
 }
 
+// InsertEdgesBatch inserts multiple edges with a single COPY.
+// COPY can't return rows, so IDs and creation times are generated client side
+// and written back to the edges once the copy succeeded.
+// If q is the connection pool, the edges are copied in a new transaction.
+func (h *EdgesDBHandler) InsertEdgesBatch(ctx context.Context, q Querier, edges []*model.Edge) error {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	return inTx(ctx, q, func(tx *sql.Tx) error {
+		return h.copyEdges(ctx, tx, edges)
+	})
+}
+
+// copyEdges copies the edges as part of the given transaction
+func (h *EdgesDBHandler) copyEdges(ctx context.Context, tx *sql.Tx, edges []*model.Edge) error {
+	stmt, err := tx.PrepareContext(ctx, pq.CopyIn(
+		"edges",
+		"id",
+		"source_chunk_id",
+		"target_chunk_id",
+		"source_entity_id",
+		"target_entity_id",
+		"edge_type",
+		"weight",
+		"bidirectional",
+		"metadata",
+		"created_at",
+	))
+	if err != nil {
+		return helper.NewError("prepare copy", err)
+	}
+	defer stmt.Close()
+
+	ids := make([]uuid.UUID, len(edges))
+	createdAt := time.Now()
+	for i, edge := range edges {
+		metadata, err := copyMetadata(edge.Metadata)
+		if err != nil {
+			return helper.NewError(fmt.Sprintf("edge %d metadata", i), err)
+		}
+
+		ids[i] = uuid.New()
+		_, err = stmt.ExecContext(ctx,
+			ids[i],
+			edge.SourceChunkID,
+			edge.TargetChunkID,
+			edge.SourceEntityID,
+			edge.TargetEntityID,
+			string(edge.EdgeType),
+			edge.Weight,
+			edge.Bidirectional,
+			metadata,
+			createdAt,
+		)
+		if err != nil {
+			return helper.NewError(fmt.Sprintf("copy edge %d", i), err)
+		}
+	}
+
+	_, err = stmt.ExecContext(ctx)
+	if err != nil {
+		return helper.NewError("flush copy", err)
+	}
+
+	for i, edge := range edges {
+		edge.ID = ids[i]
+		edge.CreatedAt = createdAt
+	}
+
+	return nil
+}
+
 // SelectEdge retrieves an edge by ID
-func (h *EdgesDBHandler) SelectEdge(id uuid.UUID) (*model.Edge, error) {
-	row := h.db.Instance.QueryRow(
+func (h *EdgesDBHandler) SelectEdge(ctx context.Context, id uuid.UUID) (*model.Edge, error) {
+	row := h.db.Instance.QueryRowContext(ctx,
 		`SELECT * FROM select_edge($1)`,
 		id,
 	)
@@ -139,18 +223,18 @@ func (h *EdgesDBHandler) SelectEdge(id uuid.UUID) (*model.Edge, error) {
 }
 
 // SelectEdgesFromChunk retrieves edges originating from a chunk
-func (h *EdgesDBHandler) SelectEdgesFromChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
+func (h *EdgesDBHandler) SelectEdgesFromChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_from_chunk($1, $2)`,
 			chunkID,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_from_chunk($1, NULL)`,
 			chunkID,
 		)
@@ -193,18 +277,18 @@ func (h *EdgesDBHandler) SelectEdgesFromChunk(chunkID uuid.UUID, edgeType *model
 }
 
 // SelectEdgesToChunk retrieves edges targeting a chunk
-func (h *EdgesDBHandler) SelectEdgesToChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
+func (h *EdgesDBHandler) SelectEdgesToChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_to_chunk($1, $2)`,
 			chunkID,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_to_chunk($1, NULL)`,
 			chunkID,
 		)
@@ -246,18 +330,18 @@ func (h *EdgesDBHandler) SelectEdgesToChunk(chunkID uuid.UUID, edgeType *model.E
 }
 
 // SelectEdgesConnectedToChunk retrieves all edges connected to a chunk (both directions)
-func (h *EdgesDBHandler) SelectEdgesConnectedToChunk(chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.EdgeConnection, error) {
+func (h *EdgesDBHandler) SelectEdgesConnectedToChunk(ctx context.Context, chunkID uuid.UUID, edgeType *model.EdgeType) ([]*model.EdgeConnection, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_connected_to_chunk($1, $2)`,
 			chunkID,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_connected_to_chunk($1, NULL)`,
 			chunkID,
 		)
@@ -304,18 +388,18 @@ func (h *EdgesDBHandler) SelectEdgesConnectedToChunk(chunkID uuid.UUID, edgeType
 }
 
 // SelectEdgesFromEntity retrieves edges originating from an entity
-func (h *EdgesDBHandler) SelectEdgesFromEntity(entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
+func (h *EdgesDBHandler) SelectEdgesFromEntity(ctx context.Context, entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_from_entity($1, $2)`,
 			entityID,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_from_entity($1, NULL)`,
 			entityID,
 		)
@@ -357,18 +441,18 @@ func (h *EdgesDBHandler) SelectEdgesFromEntity(entityID uuid.UUID, edgeType *mod
 }
 
 // SelectEdgesToEntity retrieves edges targeting an entity
-func (h *EdgesDBHandler) SelectEdgesToEntity(entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
+func (h *EdgesDBHandler) SelectEdgesToEntity(ctx context.Context, entityID uuid.UUID, edgeType *model.EdgeType) ([]*model.Edge, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_to_entity($1, $2)`,
 			entityID,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM select_edges_to_entity($1, NULL)`,
 			entityID,
 		)
@@ -409,9 +493,138 @@ func (h *EdgesDBHandler) SelectEdgesToEntity(entityID uuid.UUID, edgeType *model
 	return edges, nil
 }
 
+// SelectEdgesConnectedToNode retrieves all edges starting or ending at a chunk or entity.
+// Empty edgeTypes return edges of all types.
+func (h *EdgesDBHandler) SelectEdgesConnectedToNode(ctx context.Context, node model.GraphNode, edgeTypes []model.EdgeType) ([]*model.Edge, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_edges_connected_to_node($1, $2, $3::edge_type[])`,
+		node.ID,
+		node.Type == model.NodeTypeEntity,
+		edgeTypesArray(edgeTypes),
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var edges []*model.Edge
+	for rows.Next() {
+		edge := &model.Edge{}
+		err := rows.Scan(
+			&edge.ID,
+			&edge.SourceChunkID,
+			&edge.TargetChunkID,
+			&edge.SourceEntityID,
+			&edge.TargetEntityID,
+			&edge.EdgeType,
+			&edge.Weight,
+			&edge.Bidirectional,
+			&edge.Metadata,
+			&edge.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		edges = append(edges, edge)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return edges, nil
+}
+
+// SelectEntityEdges retrieves all edges between two entities, e.g. the co-occurrence edges of the relation extractor.
+// Empty edgeTypes return edges of all types.
+func (h *EdgesDBHandler) SelectEntityEdges(ctx context.Context, edgeTypes []model.EdgeType) ([]*model.Edge, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_entity_edges($1::edge_type[])`,
+		edgeTypesArray(edgeTypes),
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var edges []*model.Edge
+	for rows.Next() {
+		edge := &model.Edge{}
+		err := rows.Scan(
+			&edge.ID,
+			&edge.SourceChunkID,
+			&edge.TargetChunkID,
+			&edge.SourceEntityID,
+			&edge.TargetEntityID,
+			&edge.EdgeType,
+			&edge.Weight,
+			&edge.Bidirectional,
+			&edge.Metadata,
+			&edge.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		edges = append(edges, edge)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return edges, nil
+}
+
+// SelectEdgesBetweenNodes retrieves all edges whose source and target are both among the given chunks and entities.
+// Empty edgeTypes return edges of all types.
+func (h *EdgesDBHandler) SelectEdgesBetweenNodes(ctx context.Context, nodeIDs []uuid.UUID, edgeTypes []model.EdgeType) ([]*model.Edge, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_edges_between_nodes($1, $2::edge_type[])`,
+		pq.Array(nodeIDs),
+		edgeTypesArray(edgeTypes),
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var edges []*model.Edge
+	for rows.Next() {
+		edge := &model.Edge{}
+		err := rows.Scan(
+			&edge.ID,
+			&edge.SourceChunkID,
+			&edge.TargetChunkID,
+			&edge.SourceEntityID,
+			&edge.TargetEntityID,
+			&edge.EdgeType,
+			&edge.Weight,
+			&edge.Bidirectional,
+			&edge.Metadata,
+			&edge.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		edges = append(edges, edge)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return edges, nil
+}
+
 // DeleteEdge deletes an edge by ID
-func (h *EdgesDBHandler) DeleteEdge(id uuid.UUID) error {
-	_, err := h.db.Instance.Exec(
+func (h *EdgesDBHandler) DeleteEdge(ctx context.Context, id uuid.UUID) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT delete_edge($1)`,
 		id,
 	)
@@ -422,8 +635,8 @@ func (h *EdgesDBHandler) DeleteEdge(id uuid.UUID) error {
 }
 
 // UpdateEdgeWeight updates the weight of an edge
-func (h *EdgesDBHandler) UpdateEdgeWeight(id uuid.UUID, weight float64) error {
-	_, err := h.db.Instance.Exec(
+func (h *EdgesDBHandler) UpdateEdgeWeight(ctx context.Context, id uuid.UUID, weight float64) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT * FROM update_edge_weight($1, $2)`,
 		id,
 		weight,
@@ -435,19 +648,19 @@ func (h *EdgesDBHandler) UpdateEdgeWeight(id uuid.UUID, weight float64) error {
 }
 
 // TraverseBFSFromChunk performs breadth-first search from a starting chunk
-func (h *EdgesDBHandler) TraverseBFSFromChunk(startChunkID uuid.UUID, maxDepth int, edgeType *model.EdgeType) ([]*model.TraversalNode, error) {
+func (h *EdgesDBHandler) TraverseBFSFromChunk(ctx context.Context, startChunkID uuid.UUID, maxDepth int, edgeType *model.EdgeType) ([]*model.TraversalNode, error) {
 	var rows *sql.Rows
 	var err error
 
 	if edgeType != nil {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM traverse_bfs_from_chunk($1, $2, $3)`,
 			startChunkID,
 			maxDepth,
 			*edgeType,
 		)
 	} else {
-		rows, err = h.db.Instance.Query(
+		rows, err = h.db.Instance.QueryContext(ctx,
 			`SELECT * FROM traverse_bfs_from_chunk($1, $2, NULL)`,
 			startChunkID,
 			maxDepth,
@@ -489,6 +702,131 @@ func (h *EdgesDBHandler) TraverseBFSFromChunk(startChunkID uuid.UUID, maxDepth i
 	return nodes, nil
 }
 
+// DeleteDocumentEdges deletes the edges of the given type starting at a chunk of the document.
+// Returns the number of deleted edges.
+func (h *EdgesDBHandler) DeleteDocumentEdges(ctx context.Context, q Querier, documentRID uuid.UUID, edgeType model.EdgeType) (int, error) {
+	var deleted int
+	err := q.QueryRowContext(ctx,
+		`SELECT delete_document_edges($1, $2)`,
+		documentRID,
+		edgeType,
+	).Scan(&deleted)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+	return deleted, nil
+}
+
+// TraverseGraph performs a breadth-first search from several seed chunks or entities in a single query.
+// Every reachable chunk and entity is returned once per seed with its shortest depth and path and the chunk or entity data.
+// Edges between a chunk and an entity are followed in both directions, so paths can pass through entities.
+// Empty edgeTypes follow all edge types, a maxFanout greater than 0 limits the neighbours followed per node and hop
+// to the ones with the highest edge weight.
+func (h *EdgesDBHandler) TraverseGraph(ctx context.Context, seedIDs []uuid.UUID, maxDepth int, edgeTypes []model.EdgeType, followBidirectional bool, maxFanout int) ([]*model.TraversalNode, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM traverse_graph($1, $2, $3::edge_type[], $4, $5)`,
+		pq.Array(seedIDs),
+		maxDepth,
+		edgeTypesArray(edgeTypes),
+		followBidirectional,
+		maxFanout,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var nodes []*model.TraversalNode
+	for rows.Next() {
+		node := &model.TraversalNode{}
+		chunk := &model.Chunk{}
+		entity := &model.Entity{}
+		var isEntity bool
+		var pathArray []byte
+		var pathIsEntity pq.BoolArray
+		var embeddingVec *pgvector.Vector
+		var metadata model.Metadata
+		var createdAt time.Time
+		err := rows.Scan(
+			&node.SeedID,
+			&node.Node.ID,
+			&isEntity,
+			&node.Depth,
+			&pathArray,
+			&pathIsEntity,
+			&chunk.DocumentID,
+			&chunk.DocumentRID,
+			&chunk.Content,
+			&chunk.Path,
+			&embeddingVec,
+			&chunk.StartPos,
+			&chunk.EndPos,
+			&chunk.ChunkIndex,
+			&entity.Name,
+			&entity.Type,
+			&metadata,
+			&createdAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		if err := parseUUIDArray(pathArray, &node.Path); err != nil {
+			return nil, helper.NewError("parsing path array", err)
+		}
+		if len(pathIsEntity) != len(node.Path) {
+			return nil, helper.NewError("parsing path array", fmt.Errorf("got %d node types for %d path nodes", len(pathIsEntity), len(node.Path)))
+		}
+		node.PathNodes = make([]model.GraphNode, len(node.Path))
+		for i, id := range node.Path {
+			if pathIsEntity[i] {
+				node.PathNodes[i] = model.EntityNode(id)
+			} else {
+				node.PathNodes[i] = model.ChunkNode(id)
+			}
+		}
+
+		if isEntity {
+			node.Node.Type = model.NodeTypeEntity
+			entity.ID = node.Node.ID
+			entity.Metadata = metadata
+			entity.CreatedAt = createdAt
+			node.Entity = entity
+		} else {
+			node.Node.Type = model.NodeTypeChunk
+			chunk.ID = node.Node.ID
+			chunk.Metadata = metadata
+			chunk.CreatedAt = createdAt
+			if embeddingVec != nil {
+				chunk.Embedding = embeddingVec.Slice()
+			}
+			node.ChunkID = chunk.ID
+			node.Chunk = chunk
+		}
+
+		nodes = append(nodes, node)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return nodes, nil
+}
+
+// edgeTypesArray converts edge types to an array parameter, NULL if there are none
+func edgeTypesArray(edgeTypes []model.EdgeType) interface{} {
+	if len(edgeTypes) == 0 {
+		return nil
+	}
+	types := make([]string, len(edgeTypes))
+	for i, edgeType := range edgeTypes {
+		types[i] = string(edgeType)
+	}
+	return pq.Array(types)
+}
+
 // parseUUIDArray parses PostgreSQL UUID array format
 func parseUUIDArray(data []byte, result *[]uuid.UUID) error {
 	// PostgreSQL array format: {uuid1,uuid2,uuid3}
@@ -1,9 +1,12 @@
 package database
 
 import (
+	"context"
+	"fmt"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -28,6 +31,7 @@ func TestEdgesNewEdgesDBHandler(t *testing.T) {
 }
 
 func TestEdgesInsert(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -45,7 +49,7 @@ func TestEdgesInsert(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	chunk1 := &model.Chunk{
@@ -54,7 +58,7 @@ func TestEdgesInsert(t *testing.T) {
 		Path:       "root.1",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk1)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
 	require.NoError(t, err)
 
 	chunk2 := &model.Chunk{
@@ -63,7 +67,7 @@ func TestEdgesInsert(t *testing.T) {
 		Path:       "root.2",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk2)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
 	require.NoError(t, err)
 
 	t.Run("Insert edge between chunks", func(t *testing.T) {
@@ -76,22 +80,124 @@ func TestEdgesInsert(t *testing.T) {
 			Metadata:      map[string]interface{}{"context": "test"},
 		}
 
-		err := edgesDbHandler.InsertEdge(edge)
+		err := edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 		assert.NoError(t, err, "Expected Insert to not return an error")
 		assert.NotEmpty(t, edge.ID, "Expected inserted edge to have an ID")
 		assert.WithinDuration(t, edge.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
 
 		// Cleanup
-		edgesDbHandler.DeleteEdge(edge.ID)
+		edgesDbHandler.DeleteEdge(ctx, edge.ID)
 	})
 
 	// Cleanup
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestEdgesInsertBatch(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Chunk 1",
+		Path:       "root.1",
+		Metadata:   map[string]interface{}{},
+	}
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
+	require.NoError(t, err)
+
+	chunk2 := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Chunk 2",
+		Path:       "root.2",
+		Metadata:   map[string]interface{}{},
+	}
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
+	require.NoError(t, err)
+
+	t.Run("Insert edges batch", func(t *testing.T) {
+		edges := []*model.Edge{
+			{
+				SourceChunkID: &chunk1.ID,
+				TargetChunkID: &chunk2.ID,
+				EdgeType:      model.EdgeTypeReference,
+				Weight:        0.5,
+				Metadata:      map[string]interface{}{"context": "batch"},
+			},
+			{
+				SourceChunkID: &chunk2.ID,
+				TargetChunkID: &chunk1.ID,
+				EdgeType:      model.EdgeTypeSemantic,
+				Weight:        0.9,
+				Bidirectional: true,
+			},
+		}
+
+		err := edgesDbHandler.InsertEdgesBatch(ctx, database.Instance, edges)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		for _, edge := range edges {
+			assert.NotEmpty(t, edge.ID, "Expected inserted edge to have an ID")
+			assert.WithinDuration(t, edge.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
+		}
+
+		retrieved, err := edgesDbHandler.SelectEdge(ctx, edges[0].ID)
+		require.NoError(t, err, "Expected batch inserted edge to be retrievable")
+		assert.Equal(t, model.EdgeTypeReference, retrieved.EdgeType)
+		assert.Equal(t, 0.5, retrieved.Weight)
+		assert.Nil(t, retrieved.SourceEntityID, "Expected missing entity ID to be stored as NULL")
+		assert.Equal(t, "batch", retrieved.Metadata["context"])
+
+		retrieved, err = edgesDbHandler.SelectEdge(ctx, edges[1].ID)
+		require.NoError(t, err, "Expected batch inserted edge to be retrievable")
+		assert.True(t, retrieved.Bidirectional)
+
+		// Cleanup
+		for _, edge := range edges {
+			edgesDbHandler.DeleteEdge(ctx, edge.ID)
+		}
+	})
+
+	t.Run("Edge without target fails the whole batch", func(t *testing.T) {
+		edges := []*model.Edge{
+			{SourceChunkID: &chunk1.ID, TargetChunkID: &chunk2.ID, EdgeType: model.EdgeTypeReference},
+			{SourceChunkID: &chunk1.ID, EdgeType: model.EdgeTypeReference},
+		}
+
+		err := edgesDbHandler.InsertEdgesBatch(ctx, database.Instance, edges)
+		assert.Error(t, err, "Expected InsertBatch to return an error for an edge without target")
+
+		fromChunk, err := edgesDbHandler.SelectEdgesFromChunk(ctx, chunk1.ID, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, fromChunk, "Expected no edge of the failed batch to be stored")
+	})
+
+	// Cleanup
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestEdgesGet(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -109,7 +215,7 @@ func TestEdgesGet(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	chunk1 := &model.Chunk{
@@ -118,7 +224,7 @@ func TestEdgesGet(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk1)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
 	require.NoError(t, err)
 
 	chunk2 := &model.Chunk{
@@ -127,7 +233,7 @@ func TestEdgesGet(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	err = chunksDbHandler.InsertChunk(chunk2)
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
 	require.NoError(t, err)
 
 	// Create an edge
@@ -138,24 +244,25 @@ func TestEdgesGet(t *testing.T) {
 		Weight:        0.8,
 		Metadata:      map[string]interface{}{},
 	}
-	err = edgesDbHandler.InsertEdge(edge)
+	err = edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 	require.NoError(t, err)
 
 	// Test Get
-	retrievedEdge, err := edgesDbHandler.SelectEdge(edge.ID)
+	retrievedEdge, err := edgesDbHandler.SelectEdge(ctx, edge.ID)
 	assert.NoError(t, err, "Expected Get to not return an error")
 	assert.NotNil(t, retrievedEdge, "Expected Get to return a non-nil edge")
 	assert.Equal(t, edge.ID, retrievedEdge.ID, "Expected edge IDs to match")
 	assert.Equal(t, edge.EdgeType, retrievedEdge.EdgeType, "Expected edge types to match")
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge.ID)
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestEdgesGetFromChunk(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -173,7 +280,7 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	documentsDbHandler.InsertDocument(doc)
+	documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 
 	sourceChunk := &model.Chunk{
 		DocumentID: doc.ID,
@@ -181,7 +288,7 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(sourceChunk)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, sourceChunk)
 
 	targetChunk1 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -189,7 +296,7 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(targetChunk1)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, targetChunk1)
 
 	targetChunk2 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -197,7 +304,7 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(targetChunk2)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, targetChunk2)
 
 	// Create edges from source chunk
 	edge1 := &model.Edge{
@@ -207,7 +314,7 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Weight:        1.0,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge1)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge1)
 
 	edge2 := &model.Edge{
 		SourceChunkID: &sourceChunk.ID,
@@ -216,29 +323,30 @@ func TestEdgesGetFromChunk(t *testing.T) {
 		Weight:        0.9,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge2)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge2)
 
 	// Test GetFromChunk
-	edges, err := edgesDbHandler.SelectEdgesFromChunk(sourceChunk.ID, nil)
+	edges, err := edgesDbHandler.SelectEdgesFromChunk(ctx, sourceChunk.ID, nil)
 	assert.NoError(t, err, "Expected GetFromChunk to not return an error")
 	assert.Len(t, edges, 2, "Expected to find 2 edges from source chunk")
 
 	// Test GetFromChunk with type filter
 	refType := model.EdgeTypeReference
-	refEdges, err := edgesDbHandler.SelectEdgesFromChunk(sourceChunk.ID, &refType)
+	refEdges, err := edgesDbHandler.SelectEdgesFromChunk(ctx, sourceChunk.ID, &refType)
 	assert.NoError(t, err, "Expected GetFromChunk with type to not return an error")
 	assert.Len(t, refEdges, 1, "Expected to find 1 reference edge")
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge1.ID)
-	edgesDbHandler.DeleteEdge(edge2.ID)
-	chunksDbHandler.DeleteChunk(sourceChunk.ID)
-	chunksDbHandler.DeleteChunk(targetChunk1.ID)
-	chunksDbHandler.DeleteChunk(targetChunk2.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge1.ID)
+	edgesDbHandler.DeleteEdge(ctx, edge2.ID)
+	chunksDbHandler.DeleteChunk(ctx, sourceChunk.ID)
+	chunksDbHandler.DeleteChunk(ctx, targetChunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, targetChunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestEdgesGetToChunk(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -256,7 +364,7 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	documentsDbHandler.InsertDocument(doc)
+	documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 
 	chunk1 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -264,7 +372,7 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk1)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
 
 	chunk2 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -272,7 +380,7 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk2)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
 
 	targetChunk := &model.Chunk{
 		DocumentID: doc.ID,
@@ -280,7 +388,7 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(targetChunk)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, targetChunk)
 
 	// Create edges to target chunk
 	edge1 := &model.Edge{
@@ -290,7 +398,7 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Weight:        1.0,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge1)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge1)
 
 	edge2 := &model.Edge{
 		SourceChunkID: &chunk2.ID,
@@ -299,23 +407,24 @@ func TestEdgesGetToChunk(t *testing.T) {
 		Weight:        0.8,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge2)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge2)
 
 	// Test GetToChunk
-	edges, err := edgesDbHandler.SelectEdgesToChunk(targetChunk.ID, nil)
+	edges, err := edgesDbHandler.SelectEdgesToChunk(ctx, targetChunk.ID, nil)
 	assert.NoError(t, err, "Expected GetToChunk to not return an error")
 	assert.Len(t, edges, 2, "Expected to find 2 edges to target chunk")
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge1.ID)
-	edgesDbHandler.DeleteEdge(edge2.ID)
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	chunksDbHandler.DeleteChunk(targetChunk.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge1.ID)
+	edgesDbHandler.DeleteEdge(ctx, edge2.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	chunksDbHandler.DeleteChunk(ctx, targetChunk.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestEdgesDelete(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -333,7 +442,7 @@ func TestEdgesDelete(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	documentsDbHandler.InsertDocument(doc)
+	documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 
 	chunk1 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -341,7 +450,7 @@ func TestEdgesDelete(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk1)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
 
 	chunk2 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -349,7 +458,7 @@ func TestEdgesDelete(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk2)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
 
 	edge := &model.Edge{
 		SourceChunkID: &chunk1.ID,
@@ -358,23 +467,24 @@ func TestEdgesDelete(t *testing.T) {
 		Weight:        1.0,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 
 	// Test Delete
-	err = edgesDbHandler.DeleteEdge(edge.ID)
+	err = edgesDbHandler.DeleteEdge(ctx, edge.ID)
 	assert.NoError(t, err, "Expected Delete to not return an error")
 
 	// Verify deletion
-	_, err = edgesDbHandler.SelectEdge(edge.ID)
+	_, err = edgesDbHandler.SelectEdge(ctx, edge.ID)
 	assert.Error(t, err, "Expected Get to return an error for deleted edge")
 
 	// Cleanup
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestEdgesUpdateWeight(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
@@ -392,7 +502,7 @@ func TestEdgesUpdateWeight(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	documentsDbHandler.InsertDocument(doc)
+	documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 
 	chunk1 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -400,7 +510,7 @@ func TestEdgesUpdateWeight(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk1)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
 
 	chunk2 := &model.Chunk{
 		DocumentID: doc.ID,
@@ -408,7 +518,7 @@ func TestEdgesUpdateWeight(t *testing.T) {
 		Path:       "root",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk2)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
 
 	edge := &model.Edge{
 		SourceChunkID: &chunk1.ID,
@@ -417,26 +527,27 @@ func TestEdgesUpdateWeight(t *testing.T) {
 		Weight:        0.5,
 		Metadata:      map[string]interface{}{},
 	}
-	edgesDbHandler.InsertEdge(edge)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 
 	// Test UpdateWeight
 	newWeight := 0.9
-	err = edgesDbHandler.UpdateEdgeWeight(edge.ID, newWeight)
+	err = edgesDbHandler.UpdateEdgeWeight(ctx, edge.ID, newWeight)
 	assert.NoError(t, err, "Expected UpdateWeight to not return an error")
 
 	// Verify update
-	retrievedEdge, err := edgesDbHandler.SelectEdge(edge.ID)
+	retrievedEdge, err := edgesDbHandler.SelectEdge(ctx, edge.ID)
 	require.NoError(t, err)
 	assert.Equal(t, newWeight, retrievedEdge.Weight, "Expected weight to be updated")
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge.ID)
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectEdgesConnectedToChunk(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -454,7 +565,7 @@ func TestSelectEdgesConnectedToChunk(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create chunks
@@ -477,9 +588,9 @@ func TestSelectEdgesConnectedToChunk(t *testing.T) {
 		Metadata:   map[string]interface{}{},
 	}
 
-	chunksDbHandler.InsertChunk(chunk1)
-	chunksDbHandler.InsertChunk(chunk2)
-	chunksDbHandler.InsertChunk(chunk3)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk3)
 
 	// Create edges
 	edge1 := &model.Edge{
@@ -497,32 +608,33 @@ func TestSelectEdgesConnectedToChunk(t *testing.T) {
 		Bidirectional: true,
 	}
 
-	edgesDbHandler.InsertEdge(edge1)
-	edgesDbHandler.InsertEdge(edge2)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge1)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge2)
 
 	t.Run("Get edges connected to chunk", func(t *testing.T) {
-		edges, err := edgesDbHandler.SelectEdgesConnectedToChunk(chunk2.ID, nil)
+		edges, err := edgesDbHandler.SelectEdgesConnectedToChunk(ctx, chunk2.ID, nil)
 		assert.NoError(t, err)
 		assert.GreaterOrEqual(t, len(edges), 1, "Expected at least 1 edge connected to chunk2")
 	})
 
 	t.Run("Filter by edge type", func(t *testing.T) {
 		edgeType := model.EdgeTypeSemantic
-		edges, err := edgesDbHandler.SelectEdgesConnectedToChunk(chunk2.ID, &edgeType)
+		edges, err := edgesDbHandler.SelectEdgesConnectedToChunk(ctx, chunk2.ID, &edgeType)
 		assert.NoError(t, err)
 		assert.NotEmpty(t, edges)
 	})
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge1.ID)
-	edgesDbHandler.DeleteEdge(edge2.ID)
-	chunksDbHandler.DeleteChunk(chunk1.ID)
-	chunksDbHandler.DeleteChunk(chunk2.ID)
-	chunksDbHandler.DeleteChunk(chunk3.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge1.ID)
+	edgesDbHandler.DeleteEdge(ctx, edge2.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk3.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectEdgesFromEntity(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -534,7 +646,7 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
 	require.NoError(t, err)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create a document
@@ -543,7 +655,7 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create entity
@@ -552,7 +664,7 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 		Type:     "Person",
 		Metadata: map[string]interface{}{},
 	}
-	entitiesDbHandler.InsertEntity(entity)
+	entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 
 	// Create chunk
 	chunk := &model.Chunk{
@@ -561,7 +673,7 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 		Path:       "root.chunk1",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 
 	// Create edge from entity to chunk
 	edge := &model.Edge{
@@ -571,10 +683,10 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 		Weight:         1.0,
 		Bidirectional:  false,
 	}
-	edgesDbHandler.InsertEdge(edge)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 
 	t.Run("Get edges from entity", func(t *testing.T) {
-		edges, err := edgesDbHandler.SelectEdgesFromEntity(entity.ID, nil)
+		edges, err := edgesDbHandler.SelectEdgesFromEntity(ctx, entity.ID, nil)
 		assert.NoError(t, err)
 		assert.Len(t, edges, 1, "Expected 1 edge from entity")
 		assert.Equal(t, edge.ID, edges[0].ID)
@@ -582,19 +694,20 @@ func TestSelectEdgesFromEntity(t *testing.T) {
 
 	t.Run("Filter by edge type", func(t *testing.T) {
 		edgeType := model.EdgeTypeEntityMention
-		edges, err := edgesDbHandler.SelectEdgesFromEntity(entity.ID, &edgeType)
+		edges, err := edgesDbHandler.SelectEdgesFromEntity(ctx, entity.ID, &edgeType)
 		assert.NoError(t, err)
 		assert.Len(t, edges, 1)
 	})
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge.ID)
-	chunksDbHandler.DeleteChunk(chunk.ID)
-	entitiesDbHandler.DeleteEntity(entity.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
 
 func TestSelectEdgesToEntity(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
 	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
@@ -606,7 +719,7 @@ func TestSelectEdgesToEntity(t *testing.T) {
 	edgesDbHandler, err := NewEdgesDBHandler(database, true)
 	require.NoError(t, err)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create a document
@@ -615,7 +728,7 @@ func TestSelectEdgesToEntity(t *testing.T) {
 		Source:   "test.txt",
 		Metadata: map[string]interface{}{},
 	}
-	err = documentsDbHandler.InsertDocument(doc)
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
 	require.NoError(t, err)
 
 	// Create entity
@@ -624,7 +737,7 @@ func TestSelectEdgesToEntity(t *testing.T) {
 		Type:     "Person",
 		Metadata: map[string]interface{}{},
 	}
-	entitiesDbHandler.InsertEntity(entity)
+	entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 
 	// Create chunk
 	chunk := &model.Chunk{
@@ -633,7 +746,7 @@ func TestSelectEdgesToEntity(t *testing.T) {
 		Path:       "root.chunk1",
 		Metadata:   map[string]interface{}{},
 	}
-	chunksDbHandler.InsertChunk(chunk)
+	chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
 
 	// Create edge to entity
 	edge := &model.Edge{
@@ -643,10 +756,10 @@ func TestSelectEdgesToEntity(t *testing.T) {
 		Weight:         1.0,
 		Bidirectional:  false,
 	}
-	edgesDbHandler.InsertEdge(edge)
+	edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
 
 	t.Run("Get edges to entity", func(t *testing.T) {
-		edges, err := edgesDbHandler.SelectEdgesToEntity(entity.ID, nil)
+		edges, err := edgesDbHandler.SelectEdgesToEntity(ctx, entity.ID, nil)
 		assert.NoError(t, err)
 		assert.Len(t, edges, 1, "Expected 1 edge to entity")
 		assert.Equal(t, edge.ID, edges[0].ID)
@@ -654,14 +767,443 @@ func TestSelectEdgesToEntity(t *testing.T) {
 
 	t.Run("Filter by edge type", func(t *testing.T) {
 		edgeType := model.EdgeTypeEntityMention
-		edges, err := edgesDbHandler.SelectEdgesToEntity(entity.ID, &edgeType)
+		edges, err := edgesDbHandler.SelectEdgesToEntity(ctx, entity.ID, &edgeType)
 		assert.NoError(t, err)
 		assert.Len(t, edges, 1)
 	})
 
 	// Cleanup
-	edgesDbHandler.DeleteEdge(edge.ID)
-	chunksDbHandler.DeleteChunk(chunk.ID)
-	entitiesDbHandler.DeleteEntity(entity.ID)
-	documentsDbHandler.DeleteDocument(doc.RID)
+	edgesDbHandler.DeleteEdge(ctx, edge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestSelectEdgesConnectedToNode(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	// Setup
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 1", Path: "root.chunk1", Metadata: map[string]interface{}{}}
+	chunk2 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 2", Path: "root.chunk2", Metadata: map[string]interface{}{}}
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1))
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2))
+
+	entity := &model.Entity{Name: "Test Entity", Type: "Person", Metadata: map[string]interface{}{}}
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+
+	chunkEdge := &model.Edge{
+		SourceChunkID: &chunk2.ID,
+		TargetChunkID: &chunk1.ID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      map[string]interface{}{},
+	}
+	mentionEdge := &model.Edge{
+		SourceChunkID:  &chunk1.ID,
+		TargetEntityID: &entity.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         0.9,
+		Metadata:       map[string]interface{}{},
+	}
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, chunkEdge))
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, mentionEdge))
+
+	t.Run("Edges of a chunk in both directions", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesConnectedToNode(ctx, model.ChunkNode(chunk1.ID), nil)
+
+		assert.NoError(t, err, "Expected SelectEdgesConnectedToNode to not return an error")
+		assert.Len(t, edges, 2, "Expected incoming and outgoing edge")
+	})
+
+	t.Run("Edges of a chunk with type filter", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesConnectedToNode(ctx, model.ChunkNode(chunk1.ID), []model.EdgeType{model.EdgeTypeEntityMention})
+
+		assert.NoError(t, err)
+		require.Len(t, edges, 1)
+		assert.Equal(t, mentionEdge.ID, edges[0].ID)
+	})
+
+	t.Run("Edges of an entity", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesConnectedToNode(ctx, model.EntityNode(entity.ID), nil)
+
+		assert.NoError(t, err)
+		require.Len(t, edges, 1)
+		assert.Equal(t, mentionEdge.ID, edges[0].ID)
+	})
+
+	// Cleanup
+	edgesDbHandler.DeleteEdge(ctx, chunkEdge.ID)
+	edgesDbHandler.DeleteEdge(ctx, mentionEdge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestSelectEdgesBetweenNodes(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	// Setup
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunk1 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 1", Path: "root.chunk1", Metadata: map[string]interface{}{}}
+	chunk2 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 2", Path: "root.chunk2", Metadata: map[string]interface{}{}}
+	chunk3 := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 3", Path: "root.chunk3", Metadata: map[string]interface{}{}}
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk1))
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk2))
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk3))
+
+	entity := &model.Entity{Name: "Test Entity", Type: "Person", Metadata: map[string]interface{}{}}
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+
+	chunkEdge := &model.Edge{
+		SourceChunkID: &chunk1.ID,
+		TargetChunkID: &chunk2.ID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      map[string]interface{}{},
+	}
+	mentionEdge := &model.Edge{
+		SourceChunkID:  &chunk2.ID,
+		TargetEntityID: &entity.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         0.9,
+		Metadata:       map[string]interface{}{},
+	}
+	outsideEdge := &model.Edge{
+		SourceChunkID: &chunk2.ID,
+		TargetChunkID: &chunk3.ID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      map[string]interface{}{},
+	}
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, chunkEdge))
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, mentionEdge))
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, outsideEdge))
+
+	nodeIDs := []uuid.UUID{chunk1.ID, chunk2.ID, entity.ID}
+
+	t.Run("Edges between chunks and entities", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesBetweenNodes(ctx, nodeIDs, nil)
+
+		assert.NoError(t, err, "Expected SelectEdgesBetweenNodes to not return an error")
+		require.Len(t, edges, 2, "Expected the edge leaving the set to be skipped")
+		assert.ElementsMatch(t, []uuid.UUID{chunkEdge.ID, mentionEdge.ID}, []uuid.UUID{edges[0].ID, edges[1].ID})
+	})
+
+	t.Run("Edges between nodes with type filter", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesBetweenNodes(ctx, nodeIDs, []model.EdgeType{model.EdgeTypeEntityMention})
+
+		assert.NoError(t, err)
+		require.Len(t, edges, 1)
+		assert.Equal(t, mentionEdge.ID, edges[0].ID)
+	})
+
+	t.Run("No nodes", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEdgesBetweenNodes(ctx, nil, nil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, edges)
+	})
+
+	// Cleanup
+	edgesDbHandler.DeleteEdge(ctx, chunkEdge.ID)
+	edgesDbHandler.DeleteEdge(ctx, mentionEdge.ID)
+	edgesDbHandler.DeleteEdge(ctx, outsideEdge.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk3.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestSelectEntityEdges(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	// Setup
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunk := &model.Chunk{DocumentID: doc.ID, Content: "Chunk 1", Path: "root.chunk1", Metadata: map[string]interface{}{}}
+	require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk))
+
+	entity1 := &model.Entity{Name: "Entity Edges One", Type: "Person", Metadata: map[string]interface{}{}}
+	entity2 := &model.Entity{Name: "Entity Edges Two", Type: "Person", Metadata: map[string]interface{}{}}
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity1))
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity2))
+
+	coOccurrence := &model.Edge{
+		SourceEntityID: &entity1.ID,
+		TargetEntityID: &entity2.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         0.8,
+		Bidirectional:  true,
+		Metadata:       map[string]interface{}{},
+	}
+	mention := &model.Edge{
+		SourceChunkID:  &chunk.ID,
+		TargetEntityID: &entity1.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         1.0,
+		Metadata:       map[string]interface{}{},
+	}
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, coOccurrence))
+	require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, mention))
+
+	edgeIDs := func(edges []*model.Edge) []uuid.UUID {
+		ids := make([]uuid.UUID, len(edges))
+		for i, edge := range edges {
+			ids[i] = edge.ID
+		}
+		return ids
+	}
+
+	t.Run("Edges between entities", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEntityEdges(ctx, []model.EdgeType{model.EdgeTypeEntityMention})
+
+		assert.NoError(t, err, "Expected SelectEntityEdges to not return an error")
+		assert.Contains(t, edgeIDs(edges), coOccurrence.ID)
+		assert.NotContains(t, edgeIDs(edges), mention.ID, "Expected edges from chunks to be skipped")
+	})
+
+	t.Run("Edges between entities with other type", func(t *testing.T) {
+		edges, err := edgesDbHandler.SelectEntityEdges(ctx, []model.EdgeType{model.EdgeTypeCausal})
+
+		assert.NoError(t, err)
+		assert.NotContains(t, edgeIDs(edges), coOccurrence.ID)
+	})
+
+	// Cleanup
+	edgesDbHandler.DeleteEdge(ctx, coOccurrence.ID)
+	edgesDbHandler.DeleteEdge(ctx, mention.ID)
+	chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity1.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity2.ID)
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
+
+func TestEdgesTraverseGraph(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	// Setup
+	doc := &model.Document{
+		Title:    "Test Document",
+		Source:   "test.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunks := make(map[string]*model.Chunk)
+	for _, name := range []string{"source", "a", "b", "c", "d"} {
+		chunk := &model.Chunk{
+			DocumentID: doc.ID,
+			Content:    "Chunk " + name,
+			Path:       "root." + name,
+			Metadata:   map[string]interface{}{},
+		}
+		err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
+		require.NoError(t, err)
+		chunks[name] = chunk
+	}
+
+	// source -> a -> c, source -> b, d <-> source
+	edges := []*model.Edge{
+		{SourceChunkID: &chunks["source"].ID, TargetChunkID: &chunks["a"].ID, EdgeType: model.EdgeTypeReference, Weight: 1.0},
+		{SourceChunkID: &chunks["source"].ID, TargetChunkID: &chunks["b"].ID, EdgeType: model.EdgeTypeSemantic, Weight: 0.5},
+		{SourceChunkID: &chunks["a"].ID, TargetChunkID: &chunks["c"].ID, EdgeType: model.EdgeTypeReference, Weight: 1.0},
+		{SourceChunkID: &chunks["d"].ID, TargetChunkID: &chunks["source"].ID, EdgeType: model.EdgeTypeSemantic, Weight: 0.8, Bidirectional: true},
+	}
+	for _, edge := range edges {
+		edge.Metadata = map[string]interface{}{}
+		err = edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
+		require.NoError(t, err)
+	}
+
+	// depths maps the content of the reached chunks to their depth
+	depths := func(nodes []*model.TraversalNode) map[string]int {
+		result := make(map[string]int)
+		for _, node := range nodes {
+			result[node.Chunk.Content] = node.Depth
+		}
+		return result
+	}
+
+	t.Run("Traverse all edges", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{chunks["source"].ID}, 2, nil, true, 0)
+
+		assert.NoError(t, err, "Expected TraverseGraph to not return an error")
+		assert.Equal(t, map[string]int{"Chunk source": 0, "Chunk a": 1, "Chunk b": 1, "Chunk d": 1, "Chunk c": 2}, depths(nodes))
+		require.NotEmpty(t, nodes)
+		assert.Equal(t, chunks["source"].ID, nodes[0].ChunkID, "Expected seed first")
+		for _, node := range nodes {
+			assert.Equal(t, chunks["source"].ID, node.SeedID)
+			assert.Equal(t, node.ChunkID, node.Path[len(node.Path)-1], "Expected path to end at the chunk")
+			assert.Equal(t, doc.RID, node.Chunk.DocumentRID)
+		}
+	})
+
+	t.Run("Traverse with edge type filter", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{chunks["source"].ID}, 2, []model.EdgeType{model.EdgeTypeReference}, true, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"Chunk source": 0, "Chunk a": 1, "Chunk c": 2}, depths(nodes))
+	})
+
+	t.Run("Traverse without bidirectional edges", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{chunks["source"].ID}, 1, nil, false, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"Chunk source": 0, "Chunk a": 1, "Chunk b": 1}, depths(nodes))
+	})
+
+	t.Run("Traverse with fan-out limit", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{chunks["source"].ID}, 2, nil, true, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"Chunk source": 0, "Chunk a": 1, "Chunk c": 2}, depths(nodes), "Expected only the strongest edge per hop")
+	})
+
+	t.Run("Traverse from multiple seeds", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{chunks["a"].ID, chunks["c"].ID}, 1, nil, true, 0)
+
+		assert.NoError(t, err)
+		bySeed := make(map[uuid.UUID][]uuid.UUID)
+		for _, node := range nodes {
+			bySeed[node.SeedID] = append(bySeed[node.SeedID], node.ChunkID)
+		}
+		assert.Equal(t, []uuid.UUID{chunks["a"].ID, chunks["c"].ID}, bySeed[chunks["a"].ID])
+		assert.Equal(t, []uuid.UUID{chunks["c"].ID}, bySeed[chunks["c"].ID])
+	})
+
+	t.Run("Traverse densely linked graph", func(t *testing.T) {
+		// Every pair of the clique is linked in both directions, so the number of paths grows factorially with the depth
+		clique := make([]*model.Chunk, 10)
+		for i := range clique {
+			clique[i] = &model.Chunk{
+				DocumentID: doc.ID,
+				Content:    fmt.Sprintf("Clique %d", i),
+				Path:       fmt.Sprintf("root.clique_%d", i),
+				Metadata:   map[string]interface{}{},
+			}
+			err := chunksDbHandler.InsertChunk(ctx, database.Instance, clique[i])
+			require.NoError(t, err)
+		}
+		var cliqueEdges []*model.Edge
+		for i := range clique {
+			for j := i + 1; j < len(clique); j++ {
+				edge := &model.Edge{
+					SourceChunkID: &clique[i].ID,
+					TargetChunkID: &clique[j].ID,
+					EdgeType:      model.EdgeTypeSemantic,
+					Weight:        1.0,
+					Bidirectional: true,
+					Metadata:      map[string]interface{}{},
+				}
+				err := edgesDbHandler.InsertEdge(ctx, database.Instance, edge)
+				require.NoError(t, err)
+				cliqueEdges = append(cliqueEdges, edge)
+			}
+		}
+
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{clique[0].ID}, 8, nil, true, 0)
+
+		assert.NoError(t, err)
+		assert.Len(t, nodes, len(clique), "Expected every chunk of the clique once")
+		for _, node := range nodes[1:] {
+			assert.Equal(t, 1, node.Depth, "Expected every chunk to be a direct neighbour")
+		}
+
+		// Cleanup
+		for _, edge := range cliqueEdges {
+			edgesDbHandler.DeleteEdge(ctx, edge.ID)
+		}
+		for _, chunk := range clique {
+			chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+		}
+	})
+
+	t.Run("Traverse from missing seed", func(t *testing.T) {
+		nodes, err := edgesDbHandler.TraverseGraph(ctx, []uuid.UUID{uuid.New()}, 2, nil, true, 0)
+
+		assert.NoError(t, err)
+		assert.Empty(t, nodes)
+	})
+
+	// Cleanup
+	for _, edge := range edges {
+		edgesDbHandler.DeleteEdge(ctx, edge.ID)
+	}
+	for _, chunk := range chunks {
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	}
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
 }
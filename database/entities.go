@@ -2,26 +2,36 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/pgvector/pgvector-go"
 	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
-	"github.com/siherrmann/grapher/sql"
+	loadSql "github.com/siherrmann/grapher/sql"
 )
 
 // EntitiesDBHandlerFunctions defines the interface for Entities database operations.
 type EntitiesDBHandlerFunctions interface {
-	InsertEntity(entity *model.Entity) error
-	SelectEntity(id uuid.UUID) (*model.Entity, error)
-	SelectEntityByName(name string, entityType string) (*model.Entity, error)
-	SelectEntitiesBySearch(searchTerm string, entityType *string, limit int) ([]*model.Entity, error)
-	SelectEntitiesByType(entityType string, limit int) ([]*model.Entity, error)
-	DeleteEntity(id uuid.UUID) error
-	UpdateEntityMetadata(id uuid.UUID, metadata map[string]interface{}) error
-	SelectChunksMentioningEntity(entityID uuid.UUID) ([]*model.ChunkMention, error)
+	InsertEntity(ctx context.Context, q Querier, entity *model.Entity) error
+	InsertEntitiesBatch(ctx context.Context, q Querier, entities []*model.Entity) error
+	SelectEntity(ctx context.Context, id uuid.UUID) (*model.Entity, error)
+	SelectEntityByName(ctx context.Context, name string, entityType string) (*model.Entity, error)
+	SelectEntitiesBySearch(ctx context.Context, searchTerm string, entityType *string, limit int) ([]*model.Entity, error)
+	SelectEntitiesByType(ctx context.Context, entityType string, limit int) ([]*model.Entity, error)
+	DeleteEntity(ctx context.Context, id uuid.UUID) error
+	UpdateEntityMetadata(ctx context.Context, id uuid.UUID, metadata model.Metadata) error
+	SelectChunksMentioningEntity(ctx context.Context, entityID uuid.UUID) ([]*model.ChunkMention, error)
+	InsertEntityAlias(ctx context.Context, entityID uuid.UUID, alias string) (*model.EntityAlias, error)
+	SelectEntityAliases(ctx context.Context, entityID uuid.UUID) ([]*model.EntityAlias, error)
+	SelectEntitiesByAlias(ctx context.Context, alias string, entityType *string) ([]*model.Entity, error)
+	MergeEntities(ctx context.Context, q Querier, keep uuid.UUID, drop uuid.UUID) error
+	UpdateEntityEmbedding(ctx context.Context, id uuid.UUID, embedding []float32) error
+	SelectEntitiesBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64, entityType *string) ([]*model.Entity, error)
 }
 
 // EntitiesDBHandler handles entity-related database operations
@@ -31,8 +41,9 @@ type EntitiesDBHandler struct {
 
 // NewEntitiesDBHandler creates a new entities database handler.
 // It initializes the database connection and loads entity-related SQL functions.
+// The embeddingDim sets the dimension of the entity embedding column.
 // If force is true, it will reload the SQL functions even if they already exist.
-func NewEntitiesDBHandler(db *helper.Database, force bool) (*EntitiesDBHandler, error) {
+func NewEntitiesDBHandler(db *helper.Database, embeddingDim int, force bool) (*EntitiesDBHandler, error) {
 	if db == nil {
 		return nil, helper.NewError("database connection validation", fmt.Errorf("database connection is nil"))
 	}
@@ -41,12 +52,12 @@ func NewEntitiesDBHandler(db *helper.Database, force bool) (*EntitiesDBHandler,
 		db: db,
 	}
 
-	err := sql.LoadEntitiesSql(entitiesDbHandler.db.Instance, force)
+	err := loadSql.LoadEntitiesSql(entitiesDbHandler.db.Instance, force)
 	if err != nil {
 		return nil, helper.NewError("load entities sql", err)
 	}
 
-	err = entitiesDbHandler.CreateTable()
+	err = entitiesDbHandler.CreateTable(embeddingDim)
 	if err != nil {
 		return nil, helper.NewError("create table", err)
 	}
@@ -58,13 +69,13 @@ func NewEntitiesDBHandler(db *helper.Database, force bool) (*EntitiesDBHandler,
 
 // CreateTable creates the 'entities' table in the database.
 // If the table already exists, it does not create it again.
-// It also creates all necessary indexes.
-func (h *EntitiesDBHandler) CreateTable() error {
+// It also creates all necessary extensions and indexes.
+func (h *EntitiesDBHandler) CreateTable(embeddingDim int) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
 	// Use the SQL init() function to create all tables, triggers, and indexes
-	_, err := h.db.Instance.ExecContext(ctx, `SELECT init_entities();`)
+	_, err := h.db.Instance.ExecContext(ctx, `SELECT init_entities($1);`, embeddingDim)
 	if err != nil {
 		log.Panicf("error initializing entities table: %#v", err)
 	}
@@ -74,13 +85,21 @@ func (h *EntitiesDBHandler) CreateTable() error {
 	return nil
 }
 
-// InsertEntity inserts a new entity (or updates if exists)
-func (h *EntitiesDBHandler) InsertEntity(entity *model.Entity) error {
-	row := h.db.Instance.QueryRow(
-		`SELECT * FROM insert_entity($1, $2, $3)`,
+// InsertEntity inserts a new entity (or updates if exists).
+// Names and aliases of merged entities resolve to the stored entity, whose name is written back.
+func (h *EntitiesDBHandler) InsertEntity(ctx context.Context, q Querier, entity *model.Entity) error {
+	var embeddingParam interface{}
+	if len(entity.Embedding) > 0 {
+		embeddingVector := pgvector.NewVector(entity.Embedding)
+		embeddingParam = &embeddingVector
+	}
+
+	row := q.QueryRowContext(ctx,
+		`SELECT * FROM insert_entity($1, $2, $3, $4)`,
 		entity.Name,
 		entity.Type,
 		entity.Metadata,
+		embeddingParam,
 	)
 
 	err := row.Scan(
@@ -97,10 +116,94 @@ func (h *EntitiesDBHandler) InsertEntity(entity *model.Entity) error {
 	return nil
 }
 
+// InsertEntitiesBatch inserts multiple entities (or updates if exist) in a single round trip
+// The insert_entities_batch SQL function resolves all names like InsertEntity and writes them with one update
+// and one insert statement. Entities resolving to the same stored entity (or new entities with the same
+// normalized name) merge their metadata like repeated single inserts (the last embedding wins).
+// Every entity gets the final stored row of the entity it resolved to.
+func (h *EntitiesDBHandler) InsertEntitiesBatch(ctx context.Context, q Querier, entities []*model.Entity) error {
+	if len(entities) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(entities))
+	entityTypes := make([]string, len(entities))
+	metadata := make([]string, len(entities))
+	embeddings := make([]sql.NullString, len(entities))
+	for i, entity := range entities {
+		names[i] = entity.Name
+		entityTypes[i] = entity.Type
+
+		encoded, err := copyMetadata(entity.Metadata)
+		if err != nil {
+			return helper.NewError(fmt.Sprintf("entity %s (%s) metadata", entity.Name, entity.Type), err)
+		}
+		metadata[i] = encoded
+
+		if len(entity.Embedding) > 0 {
+			embeddings[i] = sql.NullString{String: pgvector.NewVector(entity.Embedding).String(), Valid: true}
+		}
+	}
+
+	rows, err := q.QueryContext(ctx,
+		`SELECT * FROM insert_entities_batch($1, $2, $3, $4)`,
+		pq.Array(names),
+		pq.Array(entityTypes),
+		pq.Array(metadata),
+		pq.Array(embeddings),
+	)
+	if err != nil {
+		return helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	// Every input position returns the final row of its entity
+	stored := make([]*model.Entity, len(entities))
+	for rows.Next() {
+		var position int
+		entity := &model.Entity{}
+		err := rows.Scan(
+			&position,
+			&entity.ID,
+			&entity.Name,
+			&entity.Type,
+			&entity.Metadata,
+			&entity.CreatedAt,
+		)
+		if err != nil {
+			return helper.NewError("scan", err)
+		}
+
+		if position < 1 || position > len(stored) {
+			return helper.NewError("match entity", fmt.Errorf("unexpected position %d", position))
+		}
+		stored[position-1] = entity
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return helper.NewError("rows error", err)
+	}
+
+	for i, entity := range entities {
+		if stored[i] == nil {
+			return helper.NewError("match entity", fmt.Errorf("entity %s (%s) was not returned", entity.Name, entity.Type))
+		}
+		storedEntity := stored[i]
+
+		entity.ID = storedEntity.ID
+		entity.Name = storedEntity.Name
+		entity.Metadata = storedEntity.Metadata
+		entity.CreatedAt = storedEntity.CreatedAt
+	}
+
+	return nil
+}
+
 // SelectEntity retrieves an entity by ID
-func (h *EntitiesDBHandler) SelectEntity(id uuid.UUID) (*model.Entity, error) {
+func (h *EntitiesDBHandler) SelectEntity(ctx context.Context, id uuid.UUID) (*model.Entity, error) {
 	entity := &model.Entity{}
-	row := h.db.Instance.QueryRow(
+	row := h.db.Instance.QueryRowContext(ctx,
 		`SELECT * FROM select_entity($1)`,
 		id,
 	)
@@ -119,10 +222,47 @@ func (h *EntitiesDBHandler) SelectEntity(id uuid.UUID) (*model.Entity, error) {
 	return entity, nil
 }
 
+// SelectEntitiesByIDs retrieves the entities with the given IDs in one query.
+// The entities are returned in no particular order, IDs without an entity are skipped.
+func (h *EntitiesDBHandler) SelectEntitiesByIDs(ctx context.Context, ids []uuid.UUID) ([]*model.Entity, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_entities_by_ids($1)`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var entities []*model.Entity
+	for rows.Next() {
+		entity := &model.Entity{}
+		err := rows.Scan(
+			&entity.ID,
+			&entity.Name,
+			&entity.Type,
+			&entity.Metadata,
+			&entity.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		entities = append(entities, entity)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return entities, nil
+}
+
 // SelectEntityByName retrieves an entity by name and type
-func (h *EntitiesDBHandler) SelectEntityByName(name string, entityType string) (*model.Entity, error) {
+func (h *EntitiesDBHandler) SelectEntityByName(ctx context.Context, name string, entityType string) (*model.Entity, error) {
 	entity := &model.Entity{}
-	row := h.db.Instance.QueryRow(
+	row := h.db.Instance.QueryRowContext(ctx,
 		`SELECT * FROM select_entity_by_name($1, $2)`,
 		name,
 		entityType,
@@ -143,8 +283,8 @@ func (h *EntitiesDBHandler) SelectEntityByName(name string, entityType string) (
 }
 
 // SelectEntitiesBySearch searches entities by name pattern
-func (h *EntitiesDBHandler) SelectEntitiesBySearch(searchTerm string, entityType *string, limit int) ([]*model.Entity, error) {
-	rows, err := h.db.Instance.Query(
+func (h *EntitiesDBHandler) SelectEntitiesBySearch(ctx context.Context, searchTerm string, entityType *string, limit int) ([]*model.Entity, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM search_entities($1, $2, $3)`,
 		searchTerm,
 		entityType,
@@ -180,9 +320,9 @@ func (h *EntitiesDBHandler) SelectEntitiesBySearch(searchTerm string, entityType
 	return entities, nil
 }
 
-// SelectEntitiesByType retrieves entities by type
-func (h *EntitiesDBHandler) SelectEntitiesByType(entityType string, limit int) ([]*model.Entity, error) {
-	rows, err := h.db.Instance.Query(
+// SelectEntitiesByType retrieves entities by type with their embedding
+func (h *EntitiesDBHandler) SelectEntitiesByType(ctx context.Context, entityType string, limit int) ([]*model.Entity, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_entities_by_type($1, $2)`,
 		entityType,
 		limit,
@@ -195,16 +335,21 @@ func (h *EntitiesDBHandler) SelectEntitiesByType(entityType string, limit int) (
 	var entities []*model.Entity
 	for rows.Next() {
 		entity := &model.Entity{}
+		var embeddingVec *pgvector.Vector
 		err := rows.Scan(
 			&entity.ID,
 			&entity.Name,
 			&entity.Type,
 			&entity.Metadata,
 			&entity.CreatedAt,
+			&embeddingVec,
 		)
 		if err != nil {
 			return nil, helper.NewError("scan", err)
 		}
+		if embeddingVec != nil {
+			entity.Embedding = embeddingVec.Slice()
+		}
 
 		entities = append(entities, entity)
 	}
@@ -218,8 +363,8 @@ func (h *EntitiesDBHandler) SelectEntitiesByType(entityType string, limit int) (
 }
 
 // DeleteEntity deletes an entity by ID
-func (h *EntitiesDBHandler) DeleteEntity(id uuid.UUID) error {
-	_, err := h.db.Instance.Exec(
+func (h *EntitiesDBHandler) DeleteEntity(ctx context.Context, id uuid.UUID) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT delete_entity($1)`,
 		id,
 	)
@@ -230,8 +375,8 @@ func (h *EntitiesDBHandler) DeleteEntity(id uuid.UUID) error {
 }
 
 // UpdateEntityMetadata updates the metadata of an entity
-func (h *EntitiesDBHandler) UpdateEntityMetadata(id uuid.UUID, metadata model.Metadata) error {
-	_, err := h.db.Instance.Exec(
+func (h *EntitiesDBHandler) UpdateEntityMetadata(ctx context.Context, id uuid.UUID, metadata model.Metadata) error {
+	_, err := h.db.Instance.ExecContext(ctx,
 		`SELECT * FROM update_entity_metadata($1, $2)`,
 		id,
 		metadata,
@@ -243,8 +388,8 @@ func (h *EntitiesDBHandler) UpdateEntityMetadata(id uuid.UUID, metadata model.Me
 }
 
 // SelectChunksMentioningEntity retrieves chunks that mention an entity
-func (h *EntitiesDBHandler) SelectChunksMentioningEntity(entityID uuid.UUID) ([]*model.ChunkMention, error) {
-	rows, err := h.db.Instance.Query(
+func (h *EntitiesDBHandler) SelectChunksMentioningEntity(ctx context.Context, entityID uuid.UUID) ([]*model.ChunkMention, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
 		`SELECT * FROM select_chunks_mentioning_entity($1)`,
 		entityID,
 	)
@@ -276,13 +421,199 @@ func (h *EntitiesDBHandler) SelectChunksMentioningEntity(entityID uuid.UUID) ([]
 	return mentions, nil
 }
 
+// UpdateEntityEmbedding updates the embedding of an entity
+func (h *EntitiesDBHandler) UpdateEntityEmbedding(ctx context.Context, id uuid.UUID, embedding []float32) error {
+	embeddingVector := pgvector.NewVector(embedding)
+	_, err := h.db.Instance.ExecContext(ctx,
+		`SELECT * FROM update_entity_embedding($1, $2)`,
+		id,
+		embeddingVector,
+	)
+	if err != nil {
+		return helper.NewError("exec", err)
+	}
+	return nil
+}
+
+// SelectEntitiesBySimilarity performs vector similarity search over the entity embeddings.
+// Entities without an embedding are never returned. If entityType is nil, all types are searched.
+func (h *EntitiesDBHandler) SelectEntitiesBySimilarity(ctx context.Context, embedding []float32, limit int, threshold float64, entityType *string) ([]*model.Entity, error) {
+	embeddingVector := pgvector.NewVector(embedding)
+
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_entities_by_similarity($1, $2, $3, $4)`,
+		embeddingVector,
+		limit,
+		threshold,
+		entityType,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var entities []*model.Entity
+	for rows.Next() {
+		entity := &model.Entity{}
+		var embeddingVec *pgvector.Vector
+		var similarity sql.NullFloat64
+		err := rows.Scan(
+			&entity.ID,
+			&entity.Name,
+			&entity.Type,
+			&entity.Metadata,
+			&entity.CreatedAt,
+			&embeddingVec,
+			&similarity,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		if embeddingVec != nil {
+			entity.Embedding = embeddingVec.Slice()
+		}
+
+		// Ensure similarity is always set
+		if similarity.Valid {
+			entity.Similarity = &similarity.Float64
+		} else {
+			zero := 0.0
+			entity.Similarity = &zero
+		}
+
+		entities = append(entities, entity)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return entities, nil
+}
+
+// InsertEntityAlias adds an alternative name to an entity.
+// Aliases are unique per entity by their normalized form, an existing alias is returned unchanged.
+func (h *EntitiesDBHandler) InsertEntityAlias(ctx context.Context, entityID uuid.UUID, alias string) (*model.EntityAlias, error) {
+	entityAlias := &model.EntityAlias{}
+	row := h.db.Instance.QueryRowContext(ctx,
+		`SELECT * FROM insert_entity_alias($1, $2)`,
+		entityID,
+		alias,
+	)
+
+	err := row.Scan(
+		&entityAlias.ID,
+		&entityAlias.EntityID,
+		&entityAlias.Alias,
+		&entityAlias.NormalizedAlias,
+		&entityAlias.CreatedAt,
+	)
+	if err != nil {
+		return nil, helper.NewError("scan", err)
+	}
+
+	return entityAlias, nil
+}
+
+// SelectEntityAliases retrieves all aliases of an entity
+func (h *EntitiesDBHandler) SelectEntityAliases(ctx context.Context, entityID uuid.UUID) ([]*model.EntityAlias, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_entity_aliases($1)`,
+		entityID,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var aliases []*model.EntityAlias
+	for rows.Next() {
+		entityAlias := &model.EntityAlias{}
+		err := rows.Scan(
+			&entityAlias.ID,
+			&entityAlias.EntityID,
+			&entityAlias.Alias,
+			&entityAlias.NormalizedAlias,
+			&entityAlias.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		aliases = append(aliases, entityAlias)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return aliases, nil
+}
+
+// SelectEntitiesByAlias retrieves entities whose name or one of whose aliases
+// matches the given alias after normalization (e.g. "I.B.M." matches "IBM")
+func (h *EntitiesDBHandler) SelectEntitiesByAlias(ctx context.Context, alias string, entityType *string) ([]*model.Entity, error) {
+	rows, err := h.db.Instance.QueryContext(ctx,
+		`SELECT * FROM select_entities_by_alias($1, $2)`,
+		alias,
+		entityType,
+	)
+	if err != nil {
+		return nil, helper.NewError("query", err)
+	}
+	defer rows.Close()
+
+	var entities []*model.Entity
+	for rows.Next() {
+		entity := &model.Entity{}
+		err := rows.Scan(
+			&entity.ID,
+			&entity.Name,
+			&entity.Type,
+			&entity.Metadata,
+			&entity.CreatedAt,
+		)
+		if err != nil {
+			return nil, helper.NewError("scan", err)
+		}
+
+		entities = append(entities, entity)
+	}
+
+	err = rows.Err()
+	if err != nil {
+		return nil, helper.NewError("rows error", err)
+	}
+
+	return entities, nil
+}
+
+// MergeEntities merges the entity drop into the entity keep.
+// All edges of the dropped entity are rewired to the kept entity and duplicate edges are
+// collapsed, its name and aliases become aliases of the kept entity and its documents are added.
+// The mention count is recounted from the remaining mention edges. The dropped entity is deleted.
+func (h *EntitiesDBHandler) MergeEntities(ctx context.Context, q Querier, keep uuid.UUID, drop uuid.UUID) error {
+	_, err := q.ExecContext(ctx,
+		`SELECT * FROM merge_entities($1, $2)`,
+		keep,
+		drop,
+	)
+	if err != nil {
+		return helper.NewError("exec", err)
+	}
+	return nil
+}
+
 // GetEntity retrieves an entity by ID (alias for SelectEntity for interface compatibility)
 func (h *EntitiesDBHandler) GetEntity(ctx context.Context, id string) (*model.Entity, error) {
 	entityID, err := uuid.Parse(id)
 	if err != nil {
 		return nil, helper.NewError("parse uuid", err)
 	}
-	return h.SelectEntity(entityID)
+	return h.SelectEntity(ctx, entityID)
 }
 
 // GetChunksForEntity retrieves all chunks related to an entity
@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,7 +15,7 @@ func TestEntitiesNewEntitiesDBHandler(t *testing.T) {
 	database := initDB(t)
 
 	t.Run("Valid call NewEntitiesDBHandler", func(t *testing.T) {
-		entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+		entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 		assert.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
 		require.NotNil(t, entitiesDbHandler, "Expected NewEntitiesDBHandler to return a non-nil instance")
 		require.NotNil(t, entitiesDbHandler.db, "Expected NewEntitiesDBHandler to have a non-nil database instance")
@@ -21,16 +23,17 @@ func TestEntitiesNewEntitiesDBHandler(t *testing.T) {
 	})
 
 	t.Run("Invalid call NewEntitiesDBHandler with nil database", func(t *testing.T) {
-		_, err := NewEntitiesDBHandler(nil, false)
+		_, err := NewEntitiesDBHandler(nil, 384, false)
 		assert.Error(t, err, "Expected error when creating EntitiesDBHandler with nil database")
 		assert.Contains(t, err.Error(), "database connection is nil", "Expected specific error message for nil database connection")
 	})
 }
 
 func TestEntitiesInsert(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
 
 	t.Run("Insert entity", func(t *testing.T) {
@@ -40,13 +43,13 @@ func TestEntitiesInsert(t *testing.T) {
 			Metadata: map[string]interface{}{"occupation": "Engineer"},
 		}
 
-		err := entitiesDbHandler.InsertEntity(entity)
+		err := entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 		assert.NoError(t, err, "Expected Insert to not return an error")
 		assert.NotEmpty(t, entity.ID, "Expected inserted entity to have an ID")
 		assert.WithinDuration(t, entity.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
 
 		// Cleanup
-		entitiesDbHandler.DeleteEntity(entity.ID)
+		entitiesDbHandler.DeleteEntity(ctx, entity.ID)
 	})
 
 	t.Run("Insert duplicate entity (upsert)", func(t *testing.T) {
@@ -56,7 +59,7 @@ func TestEntitiesInsert(t *testing.T) {
 			Metadata: map[string]interface{}{"age": 30},
 		}
 
-		err := entitiesDbHandler.InsertEntity(entity)
+		err := entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 		require.NoError(t, err)
 		firstID := entity.ID
 
@@ -67,23 +70,144 @@ func TestEntitiesInsert(t *testing.T) {
 			Metadata: map[string]interface{}{"age": 31},
 		}
 
-		err = entitiesDbHandler.InsertEntity(entity2)
+		err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity2)
 		assert.NoError(t, err, "Expected Insert to not return an error for duplicate")
 		// Depending on implementation, this might update or create new - verify behavior
 		assert.NotEmpty(t, entity2.ID, "Expected entity to have an ID")
+		assert.Equal(t, firstID, entity2.ID, "Expected upsert to keep the stored ID")
+		assert.Equal(t, float64(31), entity2.Metadata["age"], "Expected metadata to be updated")
+		assert.Equal(t, float64(2), entity2.Metadata["mention_count"], "Expected mention count to accumulate")
 
 		// Cleanup
-		entitiesDbHandler.DeleteEntity(firstID)
+		entitiesDbHandler.DeleteEntity(ctx, firstID)
 		if entity2.ID != firstID {
-			entitiesDbHandler.DeleteEntity(entity2.ID)
+			entitiesDbHandler.DeleteEntity(ctx, entity2.ID)
 		}
 	})
 }
 
+func TestEntitiesInsertBatch(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
+
+	t.Run("Insert entities batch", func(t *testing.T) {
+		entities := []*model.Entity{
+			{Name: "Ada Lovelace", Type: "PERSON", Metadata: map[string]interface{}{"field": "math"}},
+			{Name: "London", Type: "LOCATION"},
+		}
+
+		err := entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, entities)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		for _, entity := range entities {
+			assert.NotEmpty(t, entity.ID, "Expected inserted entity to have an ID")
+			assert.WithinDuration(t, entity.CreatedAt, time.Now(), 2*time.Second, "Expected CreatedAt to be set")
+		}
+
+		retrieved, err := entitiesDbHandler.SelectEntityByName(ctx, "Ada Lovelace", "PERSON")
+		require.NoError(t, err, "Expected batch inserted entity to be retrievable")
+		assert.Equal(t, entities[0].ID, retrieved.ID)
+		assert.Equal(t, "math", retrieved.Metadata["field"])
+
+		// Cleanup
+		for _, entity := range entities {
+			entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+		}
+	})
+
+	t.Run("Duplicates in batch share the stored entity", func(t *testing.T) {
+		entities := []*model.Entity{
+			{Name: "Grace Hopper", Type: "PERSON", Metadata: map[string]interface{}{"version": 1}},
+			{Name: "Grace Hopper", Type: "PERSON", Metadata: map[string]interface{}{"version": 2}},
+			{Name: "Grace Hopper", Type: "ORG"},
+		}
+
+		err := entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, entities)
+		assert.NoError(t, err, "Expected InsertBatch with duplicates to not return an error")
+		assert.Equal(t, entities[0].ID, entities[1].ID, "Expected duplicates to get the same ID")
+		assert.NotEqual(t, entities[0].ID, entities[2].ID, "Expected different types to be different entities")
+		assert.Equal(t, float64(2), entities[0].Metadata["version"], "Expected last duplicate metadata to win")
+		assert.Equal(t, float64(2), entities[0].Metadata["mention_count"], "Expected duplicate mentions to be counted")
+		assert.Equal(t, float64(1), entities[2].Metadata["mention_count"], "Expected single mention to be counted")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, entities[0].ID)
+		entitiesDbHandler.DeleteEntity(ctx, entities[2].ID)
+	})
+
+	t.Run("New names with the same normalized form share one entity", func(t *testing.T) {
+		entities := []*model.Entity{
+			{Name: "Initech Corp", Type: "ORG", Metadata: map[string]interface{}{}},
+			{Name: "INITECH CORP.", Type: "ORG", Metadata: map[string]interface{}{}},
+			{Name: "Initrode", Type: "ORG", Metadata: map[string]interface{}{}},
+		}
+
+		err := entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, entities)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		assert.Equal(t, entities[0].ID, entities[1].ID, "Expected normalized duplicates to get the same ID")
+		assert.Equal(t, "Initech Corp", entities[1].Name, "Expected the name of the first occurrence")
+		assert.Equal(t, float64(2), entities[1].Metadata["mention_count"], "Expected both mentions to be counted")
+		assert.NotEqual(t, entities[0].ID, entities[2].ID)
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, entities[0].ID)
+		entitiesDbHandler.DeleteEntity(ctx, entities[2].ID)
+	})
+
+	t.Run("Batch upserts existing entity", func(t *testing.T) {
+		existing := &model.Entity{Name: "Alan Turing", Type: "PERSON"}
+		err := entitiesDbHandler.InsertEntity(ctx, database.Instance, existing)
+		require.NoError(t, err)
+
+		entities := []*model.Entity{
+			{Name: "Alan Turing", Type: "PERSON", Metadata: map[string]interface{}{"updated": true}},
+		}
+		err = entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, entities)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		assert.Equal(t, existing.ID, entities[0].ID, "Expected batch to return the existing entity ID")
+		assert.Equal(t, true, entities[0].Metadata["updated"], "Expected metadata to be updated")
+		assert.Equal(t, float64(2), entities[0].Metadata["mention_count"], "Expected mention count to accumulate")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, existing.ID)
+	})
+
+	t.Run("Batch accumulates documents of existing entity", func(t *testing.T) {
+		existing := &model.Entity{
+			Name:     "Katherine Johnson",
+			Type:     "PERSON",
+			Metadata: map[string]interface{}{"documents": []string{"doc-1"}},
+		}
+		err := entitiesDbHandler.InsertEntity(ctx, database.Instance, existing)
+		require.NoError(t, err)
+
+		entities := []*model.Entity{
+			{Name: "Katherine Johnson", Type: "PERSON", Metadata: map[string]interface{}{"documents": []string{"doc-2"}}},
+			{Name: "Katherine Johnson", Type: "PERSON", Metadata: map[string]interface{}{"documents": []string{"doc-1"}}},
+		}
+		err = entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, entities)
+		assert.NoError(t, err, "Expected InsertBatch to not return an error")
+		assert.Equal(t, existing.ID, entities[0].ID, "Expected batch to return the existing entity ID")
+		assert.Equal(t, float64(3), entities[0].Metadata["mention_count"], "Expected all mentions to be counted")
+		assert.Equal(t, []interface{}{"doc-1", "doc-2"}, entities[0].Metadata["documents"], "Expected documents to be unioned")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, existing.ID)
+	})
+
+	t.Run("Insert empty entities batch", func(t *testing.T) {
+		err := entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, nil)
+		assert.NoError(t, err, "Expected InsertBatch with no entities to not return an error")
+	})
+}
+
 func TestEntitiesGet(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create an entity
@@ -92,11 +216,11 @@ func TestEntitiesGet(t *testing.T) {
 		Type:     "ORGANIZATION",
 		Metadata: map[string]interface{}{"founded": 2020},
 	}
-	err = entitiesDbHandler.InsertEntity(entity)
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 	require.NoError(t, err)
 
 	// Test Get
-	retrievedEntity, err := entitiesDbHandler.SelectEntity(entity.ID)
+	retrievedEntity, err := entitiesDbHandler.SelectEntity(ctx, entity.ID)
 	assert.NoError(t, err, "Expected Get to not return an error")
 	assert.NotNil(t, retrievedEntity, "Expected Get to return a non-nil entity")
 	assert.Equal(t, entity.ID, retrievedEntity.ID, "Expected entity IDs to match")
@@ -104,13 +228,46 @@ func TestEntitiesGet(t *testing.T) {
 	assert.Equal(t, entity.Type, retrievedEntity.Type, "Expected types to match")
 
 	// Cleanup
-	entitiesDbHandler.DeleteEntity(entity.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+}
+
+func TestEntitiesGetByIDs(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err)
+
+	entity1 := &model.Entity{Name: "By IDs One", Type: "PERSON", Metadata: map[string]interface{}{}}
+	entity2 := &model.Entity{Name: "By IDs Two", Type: "PERSON", Metadata: map[string]interface{}{}}
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity1))
+	require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity2))
+
+	t.Run("Select entities by IDs", func(t *testing.T) {
+		entities, err := entitiesDbHandler.SelectEntitiesByIDs(ctx, []uuid.UUID{entity1.ID, entity2.ID, uuid.New()})
+		assert.NoError(t, err, "Expected SelectEntitiesByIDs to not return an error")
+		require.Len(t, entities, 2, "Expected unknown IDs to be skipped")
+
+		names := []string{entities[0].Name, entities[1].Name}
+		assert.ElementsMatch(t, []string{"By IDs One", "By IDs Two"}, names)
+	})
+
+	t.Run("Select entities by no IDs", func(t *testing.T) {
+		entities, err := entitiesDbHandler.SelectEntitiesByIDs(ctx, nil)
+		assert.NoError(t, err)
+		assert.Empty(t, entities)
+	})
+
+	// Cleanup
+	entitiesDbHandler.DeleteEntity(ctx, entity1.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity2.ID)
 }
 
 func TestEntitiesGetByName(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create an entity
@@ -119,23 +276,24 @@ func TestEntitiesGetByName(t *testing.T) {
 		Type:     "LOCATION",
 		Metadata: map[string]interface{}{},
 	}
-	err = entitiesDbHandler.InsertEntity(entity)
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 	require.NoError(t, err)
 
 	// Test GetByName
-	retrievedEntity, err := entitiesDbHandler.SelectEntityByName("Unique Entity Name", "LOCATION")
+	retrievedEntity, err := entitiesDbHandler.SelectEntityByName(ctx, "Unique Entity Name", "LOCATION")
 	assert.NoError(t, err, "Expected GetByName to not return an error")
 	assert.NotNil(t, retrievedEntity, "Expected GetByName to return a non-nil entity")
 	assert.Equal(t, entity.ID, retrievedEntity.ID, "Expected entity IDs to match")
 
 	// Cleanup
-	entitiesDbHandler.DeleteEntity(entity.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
 }
 
 func TestEntitiesSearch(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create entities with different names
@@ -151,7 +309,7 @@ func TestEntitiesSearch(t *testing.T) {
 			Type:     "PERSON",
 			Metadata: map[string]interface{}{},
 		}
-		err = entitiesDbHandler.InsertEntity(entity)
+		err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 		require.NoError(t, err)
 		entities = append(entities, entity)
 	}
@@ -162,32 +320,33 @@ func TestEntitiesSearch(t *testing.T) {
 			Type:     "PERSON",
 			Metadata: map[string]interface{}{},
 		}
-		err = entitiesDbHandler.InsertEntity(entity)
+		err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 		require.NoError(t, err)
 		entities = append(entities, entity)
 	}
 
 	// Test Search
-	results, err := entitiesDbHandler.SelectEntitiesBySearch(searchTerm, nil, 10)
+	results, err := entitiesDbHandler.SelectEntitiesBySearch(ctx, searchTerm, nil, 10)
 	assert.NoError(t, err, "Expected Search to not return an error")
 	assert.GreaterOrEqual(t, len(results), matchingCount, "Expected to find at least matching entities")
 
 	// Test Search with type filter
 	personType := "PERSON"
-	resultsWithType, err := entitiesDbHandler.SelectEntitiesBySearch(searchTerm, &personType, 10)
+	resultsWithType, err := entitiesDbHandler.SelectEntitiesBySearch(ctx, searchTerm, &personType, 10)
 	assert.NoError(t, err, "Expected Search with type to not return an error")
 	assert.GreaterOrEqual(t, len(resultsWithType), matchingCount, "Expected to find matching entities with type filter")
 
 	// Cleanup
 	for _, entity := range entities {
-		entitiesDbHandler.DeleteEntity(entity.ID)
+		entitiesDbHandler.DeleteEntity(ctx, entity.ID)
 	}
 }
 
 func TestEntitiesGetByType(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create entities of different types
@@ -202,26 +361,27 @@ func TestEntitiesGetByType(t *testing.T) {
 			Type:     entityType,
 			Metadata: map[string]interface{}{},
 		}
-		err = entitiesDbHandler.InsertEntity(entity)
+		err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 		require.NoError(t, err)
 		entities = append(entities, entity)
 	}
 
 	// Test GetByType
-	results, err := entitiesDbHandler.SelectEntitiesByType(entityType, 10)
+	results, err := entitiesDbHandler.SelectEntitiesByType(ctx, entityType, 10)
 	assert.NoError(t, err, "Expected GetByType to not return an error")
 	assert.GreaterOrEqual(t, len(results), entityCount, "Expected to find all entities of type")
 
 	// Cleanup
 	for _, entity := range entities {
-		entitiesDbHandler.DeleteEntity(entity.ID)
+		entitiesDbHandler.DeleteEntity(ctx, entity.ID)
 	}
 }
 
 func TestEntitiesDelete(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create an entity
@@ -230,22 +390,23 @@ func TestEntitiesDelete(t *testing.T) {
 		Type:     "TEST",
 		Metadata: map[string]interface{}{},
 	}
-	err = entitiesDbHandler.InsertEntity(entity)
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 	require.NoError(t, err)
 
 	// Delete the entity
-	err = entitiesDbHandler.DeleteEntity(entity.ID)
+	err = entitiesDbHandler.DeleteEntity(ctx, entity.ID)
 	assert.NoError(t, err, "Expected Delete to not return an error")
 
 	// Verify deletion
-	_, err = entitiesDbHandler.SelectEntity(entity.ID)
+	_, err = entitiesDbHandler.SelectEntity(ctx, entity.ID)
 	assert.Error(t, err, "Expected Get to return an error for deleted entity")
 }
 
 func TestEntitiesUpdateMetadata(t *testing.T) {
+	ctx := context.Background()
 	database := initDB(t)
 
-	entitiesDbHandler, err := NewEntitiesDBHandler(database, true)
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
 	require.NoError(t, err)
 
 	// Create an entity
@@ -254,20 +415,323 @@ func TestEntitiesUpdateMetadata(t *testing.T) {
 		Type:     "PERSON",
 		Metadata: model.Metadata{"status": "active"},
 	}
-	err = entitiesDbHandler.InsertEntity(entity)
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
 	require.NoError(t, err)
 
 	// Update metadata
 	newMetadata := model.Metadata{"status": "inactive", "reason": "test"}
-	err = entitiesDbHandler.UpdateEntityMetadata(entity.ID, newMetadata)
+	err = entitiesDbHandler.UpdateEntityMetadata(ctx, entity.ID, newMetadata)
 	assert.NoError(t, err, "Expected UpdateMetadata to not return an error")
 
 	// Verify update
-	retrievedEntity, err := entitiesDbHandler.SelectEntity(entity.ID)
+	retrievedEntity, err := entitiesDbHandler.SelectEntity(ctx, entity.ID)
 	require.NoError(t, err)
 	assert.Equal(t, "inactive", retrievedEntity.Metadata["status"], "Expected metadata to be updated")
 	assert.Equal(t, "test", retrievedEntity.Metadata["reason"], "Expected new metadata field")
 
 	// Cleanup
-	entitiesDbHandler.DeleteEntity(entity.ID)
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+}
+
+func TestEntitiesAliases(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
+
+	entity := &model.Entity{Name: "IBM", Type: "ORG", Metadata: model.Metadata{}}
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
+	require.NoError(t, err)
+
+	t.Run("Insert alias", func(t *testing.T) {
+		alias, err := entitiesDbHandler.InsertEntityAlias(ctx, entity.ID, "International Business Machines")
+
+		assert.NoError(t, err, "Expected InsertEntityAlias to not return an error")
+		assert.NotEmpty(t, alias.ID, "Expected alias to have an ID")
+		assert.Equal(t, entity.ID, alias.EntityID)
+		assert.Equal(t, "international business machines", alias.NormalizedAlias, "Expected normalized alias")
+	})
+
+	t.Run("Insert alias with same normalized form returns existing alias", func(t *testing.T) {
+		first, err := entitiesDbHandler.InsertEntityAlias(ctx, entity.ID, "Big Blue")
+		require.NoError(t, err)
+
+		second, err := entitiesDbHandler.InsertEntityAlias(ctx, entity.ID, "big-blue")
+
+		assert.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID, "Expected existing alias to be returned")
+		assert.Equal(t, "Big Blue", second.Alias, "Expected original spelling to be kept")
+	})
+
+	t.Run("Select aliases", func(t *testing.T) {
+		aliases, err := entitiesDbHandler.SelectEntityAliases(ctx, entity.ID)
+
+		assert.NoError(t, err)
+		assert.Len(t, aliases, 2)
+	})
+
+	t.Run("Select entities by alias", func(t *testing.T) {
+		entityType := "ORG"
+
+		byName, err := entitiesDbHandler.SelectEntitiesByAlias(ctx, "I.B.M.", &entityType)
+		assert.NoError(t, err)
+		require.Len(t, byName, 1, "Expected normalized name to match")
+		assert.Equal(t, entity.ID, byName[0].ID)
+
+		byAlias, err := entitiesDbHandler.SelectEntitiesByAlias(ctx, "international business machines", nil)
+		assert.NoError(t, err)
+		require.Len(t, byAlias, 1, "Expected alias to match")
+		assert.Equal(t, entity.ID, byAlias[0].ID)
+
+		otherType := "PERSON"
+		none, err := entitiesDbHandler.SelectEntitiesByAlias(ctx, "IBM", &otherType)
+		assert.NoError(t, err)
+		assert.Empty(t, none, "Expected entity type to be filtered")
+	})
+
+	// Cleanup, aliases are deleted with the entity
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+
+	aliases, err := entitiesDbHandler.SelectEntityAliases(ctx, entity.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, aliases, "Expected aliases to be deleted with their entity")
+}
+
+func TestEntitiesMerge(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err, "Expected NewEdgesDBHandler to not return an error")
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err, "Expected NewDocumentsDBHandler to not return an error")
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err, "Expected NewChunksDBHandler to not return an error")
+
+	t.Run("Merge entities rewires edges", func(t *testing.T) {
+		keep := &model.Entity{Name: "International Business Machines", Type: "ORG", Metadata: model.Metadata{"documents": []string{"doc-1"}}}
+		drop := &model.Entity{Name: "I.B.M.", Type: "ORG", Metadata: model.Metadata{"documents": []string{"doc-2"}}}
+		other := &model.Entity{Name: "Armonk", Type: "LOCATION", Metadata: model.Metadata{}}
+		for _, entity := range []*model.Entity{keep, drop, other} {
+			require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+		}
+		_, err := entitiesDbHandler.InsertEntityAlias(ctx, drop.ID, "Big Blue")
+		require.NoError(t, err)
+
+		doc := &model.Document{Title: "Merge Document", Source: "merge.txt", Metadata: model.Metadata{}}
+		require.NoError(t, documentsDbHandler.InsertDocument(ctx, database.Instance, doc))
+		chunk1 := &model.Chunk{DocumentID: doc.ID, Content: "IBM, also known as I.B.M.", Path: "root.1", Metadata: model.Metadata{}}
+		chunk2 := &model.Chunk{DocumentID: doc.ID, Content: "I.B.M. is based in Armonk", Path: "root.2", Metadata: model.Metadata{}}
+		for _, chunk := range []*model.Chunk{chunk1, chunk2} {
+			require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk))
+		}
+
+		outgoing := &model.Edge{SourceEntityID: &drop.ID, TargetEntityID: &other.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0, Metadata: model.Metadata{}}
+		incoming := &model.Edge{SourceEntityID: &other.ID, TargetEntityID: &drop.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 0.8, Metadata: model.Metadata{}}
+		parallel := &model.Edge{SourceEntityID: &other.ID, TargetEntityID: &keep.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 0.5, Metadata: model.Metadata{}}
+		between := &model.Edge{SourceEntityID: &keep.ID, TargetEntityID: &drop.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0, Metadata: model.Metadata{}}
+		mentionKeep := &model.Edge{SourceChunkID: &chunk1.ID, TargetEntityID: &keep.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0, Metadata: model.Metadata{}}
+		mentionDrop := &model.Edge{SourceChunkID: &chunk1.ID, TargetEntityID: &drop.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0, Metadata: model.Metadata{}}
+		mentionOther := &model.Edge{SourceChunkID: &chunk2.ID, TargetEntityID: &drop.ID, EdgeType: model.EdgeTypeEntityMention, Weight: 1.0, Metadata: model.Metadata{}}
+		for _, edge := range []*model.Edge{outgoing, incoming, parallel, between, mentionKeep, mentionDrop, mentionOther} {
+			require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, edge))
+		}
+
+		err = entitiesDbHandler.MergeEntities(ctx, database.Instance, keep.ID, drop.ID)
+		assert.NoError(t, err, "Expected MergeEntities to not return an error")
+
+		_, err = entitiesDbHandler.SelectEntity(ctx, drop.ID)
+		assert.Error(t, err, "Expected dropped entity to be deleted")
+
+		retrievedOutgoing, err := edgesDbHandler.SelectEdge(ctx, outgoing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, keep.ID, *retrievedOutgoing.SourceEntityID, "Expected outgoing edge to be rewired")
+
+		retrievedIncoming, err := edgesDbHandler.SelectEdge(ctx, incoming.ID)
+		require.NoError(t, err)
+		assert.Equal(t, keep.ID, *retrievedIncoming.TargetEntityID, "Expected incoming edge to be rewired")
+
+		_, err = edgesDbHandler.SelectEdge(ctx, between.ID)
+		assert.Error(t, err, "Expected edge between merged entities to be removed")
+
+		// Parallel edges collapse to the one with the highest weight
+		_, err = edgesDbHandler.SelectEdge(ctx, parallel.ID)
+		assert.Error(t, err, "Expected parallel edge with the lower weight to be removed")
+		otherEdges, err := edgesDbHandler.SelectEdgesFromEntity(ctx, other.ID, nil)
+		require.NoError(t, err)
+		require.Len(t, otherEdges, 1, "Expected a single edge from the neighbour to the kept entity")
+		assert.Equal(t, 0.8, otherEdges[0].Weight, "Expected the highest weight to be kept")
+
+		mentionType := model.EdgeTypeEntityMention
+		chunkEdges, err := edgesDbHandler.SelectEdgesFromChunk(ctx, chunk1.ID, &mentionType)
+		require.NoError(t, err)
+		assert.Len(t, chunkEdges, 1, "Expected a single mention edge from a chunk mentioning both entities")
+
+		aliases, err := entitiesDbHandler.SelectEntityAliases(ctx, keep.ID)
+		require.NoError(t, err)
+		aliasNames := []string{}
+		for _, alias := range aliases {
+			aliasNames = append(aliasNames, alias.Alias)
+		}
+		assert.ElementsMatch(t, []string{"I.B.M.", "Big Blue"}, aliasNames, "Expected name and aliases of dropped entity")
+
+		merged, err := entitiesDbHandler.SelectEntity(ctx, keep.ID)
+		require.NoError(t, err)
+		assert.Equal(t, float64(2), merged.Metadata["mention_count"], "Expected mention count to be recounted from the mention edges")
+		assert.ElementsMatch(t, []interface{}{"doc-1", "doc-2"}, merged.Metadata["documents"], "Expected documents to be unioned")
+
+		// Cleanup
+		edgesDbHandler.DeleteEdge(ctx, outgoing.ID)
+		edgesDbHandler.DeleteEdge(ctx, incoming.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk1.ID)
+		chunksDbHandler.DeleteChunk(ctx, chunk2.ID)
+		documentsDbHandler.DeleteDocument(ctx, doc.RID)
+		entitiesDbHandler.DeleteEntity(ctx, keep.ID)
+		entitiesDbHandler.DeleteEntity(ctx, other.ID)
+	})
+
+	t.Run("Reingesting merged names resolves to kept entity", func(t *testing.T) {
+		keep := &model.Entity{Name: "Acme Corporation", Type: "ORG", Metadata: model.Metadata{}}
+		drop := &model.Entity{Name: "Acme", Type: "ORG", Metadata: model.Metadata{}}
+		for _, entity := range []*model.Entity{keep, drop} {
+			require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+		}
+		_, err := entitiesDbHandler.InsertEntityAlias(ctx, drop.ID, "Acme Inc")
+		require.NoError(t, err)
+
+		err = entitiesDbHandler.MergeEntities(ctx, database.Instance, keep.ID, drop.ID)
+		require.NoError(t, err)
+
+		// The dropped name is an alias now
+		reingested := &model.Entity{Name: "Acme", Type: "ORG", Metadata: model.Metadata{}}
+		err = entitiesDbHandler.InsertEntity(ctx, database.Instance, reingested)
+		assert.NoError(t, err, "Expected InsertEntity to not return an error")
+		assert.Equal(t, keep.ID, reingested.ID, "Expected merged name to resolve to the kept entity")
+		assert.Equal(t, "Acme Corporation", reingested.Name, "Expected the name of the kept entity")
+
+		// Aliases and normalized names resolve in batches too
+		batch := []*model.Entity{
+			{Name: "ACME INC.", Type: "ORG", Metadata: model.Metadata{}},
+			{Name: "acme corporation", Type: "ORG", Metadata: model.Metadata{}},
+			{Name: "Acme", Type: "PRODUCT", Metadata: model.Metadata{}},
+		}
+		err = entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, batch)
+		assert.NoError(t, err, "Expected InsertEntitiesBatch to not return an error")
+		assert.Equal(t, keep.ID, batch[0].ID, "Expected alias to resolve to the kept entity")
+		assert.Equal(t, keep.ID, batch[1].ID, "Expected normalized name to resolve to the kept entity")
+		assert.NotEqual(t, keep.ID, batch[2].ID, "Expected entities of other types to not be resolved")
+
+		merged, err := entitiesDbHandler.SelectEntity(ctx, keep.ID)
+		require.NoError(t, err)
+		// Neither entity had mention edges, so only the mentions after the merge are counted
+		assert.Equal(t, float64(3), merged.Metadata["mention_count"], "Expected every mention after the merge to be counted on the kept entity")
+
+		entityType := "ORG"
+		matches, err := entitiesDbHandler.SelectEntitiesByAlias(ctx, "Acme", &entityType)
+		require.NoError(t, err)
+		assert.Len(t, matches, 1, "Expected the merged entity to not be recreated")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, keep.ID)
+		entitiesDbHandler.DeleteEntity(ctx, batch[2].ID)
+	})
+
+	t.Run("Merge entity into itself", func(t *testing.T) {
+		entity := &model.Entity{Name: "Self Merge", Type: "ORG", Metadata: model.Metadata{}}
+		require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+
+		err := entitiesDbHandler.MergeEntities(ctx, database.Instance, entity.ID, entity.ID)
+		assert.Error(t, err, "Expected merging an entity into itself to fail")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	})
+
+	t.Run("Merge non-existent entity", func(t *testing.T) {
+		entity := &model.Entity{Name: "Merge Target", Type: "ORG", Metadata: model.Metadata{}}
+		require.NoError(t, entitiesDbHandler.InsertEntity(ctx, database.Instance, entity))
+
+		err := entitiesDbHandler.MergeEntities(ctx, database.Instance, entity.ID, uuid.New())
+		assert.Error(t, err, "Expected merging a missing entity to fail")
+
+		// Cleanup
+		entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	})
+}
+
+func TestEntitiesEmbedding(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	entitiesDbHandler, err := NewEntitiesDBHandler(database, 384, true)
+	require.NoError(t, err, "Expected NewEntitiesDBHandler to not return an error")
+
+	embedding1 := make([]float32, 384)
+	embedding2 := make([]float32, 384)
+	for i := range embedding1 {
+		embedding1[i] = 0.5
+		embedding2[i] = float32(i%2) * 0.5
+	}
+
+	entity := &model.Entity{Name: "Embedded Person", Type: "EMBEDDING_TEST", Metadata: model.Metadata{}, Embedding: embedding1}
+	err = entitiesDbHandler.InsertEntity(ctx, database.Instance, entity)
+	require.NoError(t, err, "Expected InsertEntity with embedding to not return an error")
+
+	batch := []*model.Entity{
+		{Name: "Embedded Place", Type: "EMBEDDING_TEST", Metadata: model.Metadata{}, Embedding: embedding2},
+		{Name: "Unembedded Place", Type: "EMBEDDING_TEST", Metadata: model.Metadata{}},
+	}
+	err = entitiesDbHandler.InsertEntitiesBatch(ctx, database.Instance, batch)
+	require.NoError(t, err, "Expected InsertEntitiesBatch with embeddings to not return an error")
+
+	entityType := "EMBEDDING_TEST"
+
+	t.Run("Select entities by similarity", func(t *testing.T) {
+		entities, err := entitiesDbHandler.SelectEntitiesBySimilarity(ctx, embedding1, 10, 0.0, &entityType)
+
+		assert.NoError(t, err, "Expected SelectEntitiesBySimilarity to not return an error")
+		require.Len(t, entities, 2, "Expected only entities with embedding")
+		assert.Equal(t, entity.ID, entities[0].ID, "Expected most similar entity first")
+		require.NotNil(t, entities[0].Similarity)
+		assert.InDelta(t, 1.0, *entities[0].Similarity, 0.001)
+		assert.Len(t, entities[0].Embedding, 384, "Expected embedding to be returned")
+		assert.Equal(t, batch[0].ID, entities[1].ID)
+	})
+
+	t.Run("Select entities by similarity with threshold", func(t *testing.T) {
+		entities, err := entitiesDbHandler.SelectEntitiesBySimilarity(ctx, embedding1, 10, 0.99, &entityType)
+
+		assert.NoError(t, err)
+		require.Len(t, entities, 1)
+		assert.Equal(t, entity.ID, entities[0].ID)
+	})
+
+	t.Run("Upsert without embedding keeps embedding", func(t *testing.T) {
+		duplicate := &model.Entity{Name: "Embedded Person", Type: "EMBEDDING_TEST", Metadata: model.Metadata{}}
+		err := entitiesDbHandler.InsertEntity(ctx, database.Instance, duplicate)
+		require.NoError(t, err)
+
+		entities, err := entitiesDbHandler.SelectEntitiesBySimilarity(ctx, embedding1, 1, 0.99, &entityType)
+		assert.NoError(t, err)
+		require.Len(t, entities, 1, "Expected stored embedding to be kept")
+		assert.Equal(t, entity.ID, entities[0].ID)
+	})
+
+	t.Run("Update entity embedding", func(t *testing.T) {
+		err := entitiesDbHandler.UpdateEntityEmbedding(ctx, batch[1].ID, embedding1)
+		assert.NoError(t, err, "Expected UpdateEntityEmbedding to not return an error")
+
+		entities, err := entitiesDbHandler.SelectEntitiesBySimilarity(ctx, embedding1, 10, 0.99, &entityType)
+		assert.NoError(t, err)
+		assert.Len(t, entities, 2, "Expected updated entity to be found")
+	})
+
+	// Cleanup
+	entitiesDbHandler.DeleteEntity(ctx, entity.ID)
+	for _, e := range batch {
+		entitiesDbHandler.DeleteEntity(ctx, e.ID)
+	}
 }
@@ -70,3 +70,17 @@ func (h *ChunksDBHandler) ChangeIndexType(ctx context.Context, indexType string,
 
 	return nil
 }
+
+// ChangeTextSearchConfig changes the language used for keyword search, e.g. "english", "german" or "simple".
+// The full-text column is regenerated, which rewrites the chunks table and can take a while on large tables,
+// so the ctx should allow for that. Canceling it rolls the change back.
+func (h *ChunksDBHandler) ChangeTextSearchConfig(ctx context.Context, textSearchConfig string) error {
+	_, err := h.db.Instance.ExecContext(ctx, `SELECT change_chunks_text_search_config($1);`, textSearchConfig)
+	if err != nil {
+		return helper.NewError("change text search config", err)
+	}
+
+	h.db.Logger.Info(fmt.Sprintf("Changed text search config to %s", textSearchConfig))
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/siherrmann/grapher/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -81,3 +82,84 @@ func TestChangeIndexType(t *testing.T) {
 		assert.NoError(t, err, "Expected ChangeIndexType to hnsw for cleanup to not return an error")
 	})
 }
+
+func TestChangeTextSearchConfig(t *testing.T) {
+	database := initDB(t)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err, "Expected NewDocumentsDBHandler to not return an error")
+
+	chunksDbHandler, err := NewChunksDBHandler(database, nil, 384, true)
+	require.NoError(t, err, "Expected NewChunksDBHandler to not return an error")
+
+	ctx := context.Background()
+
+	doc := &model.Document{
+		Title:    "German Document",
+		Source:   "german.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	chunk := &model.Chunk{
+		DocumentID: doc.ID,
+		Content:    "Die Häuser stehen am Fluss.",
+		Path:       "root",
+		Metadata:   map[string]interface{}{},
+	}
+	err = chunksDbHandler.InsertChunk(ctx, database.Instance, chunk)
+	require.NoError(t, err)
+
+	t.Run("Change text search config to german", func(t *testing.T) {
+		err := chunksDbHandler.ChangeTextSearchConfig(ctx, "german")
+		assert.NoError(t, err, "Expected ChangeTextSearchConfig to not return an error")
+
+		var config string
+		err = database.Instance.QueryRow(`SELECT select_chunks_text_search_config()::TEXT;`).Scan(&config)
+		require.NoError(t, err)
+		assert.Equal(t, "german", config, "Expected text search config to be changed")
+
+		// German stemming matches the singular form against the stored plural
+		results, err := chunksDbHandler.SelectChunksByKeyword(ctx, "Haus", 10, nil)
+		assert.NoError(t, err)
+		require.Len(t, results, 1, "Expected existing chunks to be reindexed with the new config")
+		assert.Equal(t, chunk.ID, results[0].ID)
+	})
+
+	t.Run("Existing table keeps its text search config", func(t *testing.T) {
+		_, err := NewChunksDBHandlerWithTextSearchConfig(database, nil, 384, "simple", true)
+		require.NoError(t, err, "Expected NewChunksDBHandlerWithTextSearchConfig to not return an error")
+
+		var config string
+		err = database.Instance.QueryRow(`SELECT select_chunks_text_search_config()::TEXT;`).Scan(&config)
+		require.NoError(t, err)
+		assert.Equal(t, "german", config, "Expected the stored text search config to be kept")
+	})
+
+	t.Run("Change text search config with canceled context", func(t *testing.T) {
+		canceledCtx, cancel := context.WithCancel(ctx)
+		cancel()
+
+		err := chunksDbHandler.ChangeTextSearchConfig(canceledCtx, "simple")
+		assert.Error(t, err, "Expected error when the context is canceled")
+
+		var config string
+		err = database.Instance.QueryRow(`SELECT select_chunks_text_search_config()::TEXT;`).Scan(&config)
+		require.NoError(t, err)
+		assert.Equal(t, "german", config, "Expected text search config to be unchanged")
+	})
+
+	t.Run("Change text search config with invalid config", func(t *testing.T) {
+		err := chunksDbHandler.ChangeTextSearchConfig(ctx, "invalid_language")
+		assert.Error(t, err, "Expected error when using unknown text search config")
+	})
+
+	t.Run("Change text search config back to english for cleanup", func(t *testing.T) {
+		err := chunksDbHandler.ChangeTextSearchConfig(ctx, "english")
+		assert.NoError(t, err, "Expected ChangeTextSearchConfig back to english to not return an error")
+	})
+
+	// Cleanup
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
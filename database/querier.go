@@ -0,0 +1,50 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/siherrmann/grapher/helper"
+)
+
+// Querier is the subset of methods shared by *sql.DB and *sql.Tx.
+// Handler methods that accept a Querier run either directly on the
+// connection pool (e.g. g.DB.Instance) or as part of an enclosing transaction.
+type Querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// inTx runs fn in the transaction q, or in a new transaction if q is the connection pool.
+// COPY only works inside a transaction, a new transaction is committed if fn succeeds.
+func inTx(ctx context.Context, q Querier, fn func(tx *sql.Tx) error) error {
+	switch q := q.(type) {
+	case *sql.Tx:
+		return fn(q)
+	case *sql.DB:
+		tx, err := q.BeginTx(ctx, nil)
+		if err != nil {
+			return helper.NewError("begin transaction", err)
+		}
+		defer func() {
+			// Rollback is a no-op once the transaction has been committed
+			_ = tx.Rollback()
+		}()
+
+		err = fn(tx)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Commit()
+		if err != nil {
+			return helper.NewError("commit transaction", err)
+		}
+		return nil
+	default:
+		return helper.NewError("begin transaction", fmt.Errorf("unsupported querier %T", q))
+	}
+}
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// LinkSemanticNeighbors links the given chunks to their K nearest neighbours (across all documents)
+// with a similarity of at least Threshold, using bidirectional semantic edges weighted by similarity.
+// If chunkIDs is empty, all chunks are linked. Returns the number of created edges.
+func (h *EdgesDBHandler) LinkSemanticNeighbors(ctx context.Context, q Querier, chunkIDs []uuid.UUID, config model.SemanticLinkConfig) (int, error) {
+	var chunkIDsParam interface{}
+	if len(chunkIDs) > 0 {
+		chunkIDsParam = pq.Array(chunkIDs)
+	}
+
+	var created int
+	err := q.QueryRowContext(ctx,
+		`SELECT link_semantic_neighbors($1, $2, $3)`,
+		chunkIDsParam,
+		config.K,
+		config.Threshold,
+	).Scan(&created)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+
+	return created, nil
+}
+
+// PruneSemanticEdges deletes generated semantic edges with a weight below threshold and,
+// if maxPerChunk > 0, all but the strongest maxPerChunk edges of every chunk.
+// Manually created semantic edges are kept. Returns the number of deleted edges.
+func (h *EdgesDBHandler) PruneSemanticEdges(ctx context.Context, threshold float64, maxPerChunk int) (int, error) {
+	var deleted int
+	err := h.db.Instance.QueryRowContext(ctx,
+		`SELECT prune_semantic_edges($1, $2)`,
+		threshold,
+		maxPerChunk,
+	).Scan(&deleted)
+	if err != nil {
+		return 0, helper.NewError("scan", err)
+	}
+
+	return deleted, nil
+}
+
+// RebuildSemanticEdges deletes all generated semantic edges and links all chunks again
+// in one transaction. Returns the number of created edges.
+func (h *EdgesDBHandler) RebuildSemanticEdges(ctx context.Context, config model.SemanticLinkConfig) (int, error) {
+	tx, err := h.db.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
+
+	_, err = tx.ExecContext(ctx, `SELECT delete_semantic_edges()`)
+	if err != nil {
+		return 0, helper.NewError("delete semantic edges", err)
+	}
+
+	created, err := h.LinkSemanticNeighbors(ctx, tx, nil, config)
+	if err != nil {
+		return 0, helper.NewError("link semantic neighbors", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, helper.NewError("commit transaction", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSemanticEdges(t *testing.T) {
+	ctx := context.Background()
+	database := initDB(t)
+
+	edgesDbHandler, err := NewEdgesDBHandler(database, true)
+	require.NoError(t, err)
+
+	chunksDbHandler, err := NewChunksDBHandler(database, edgesDbHandler, 384, true)
+	require.NoError(t, err)
+
+	documentsDbHandler, err := NewDocumentsDBHandler(database, true)
+	require.NoError(t, err)
+
+	doc := &model.Document{
+		Title:    "Semantic Document",
+		Source:   "semantic.txt",
+		Metadata: map[string]interface{}{},
+	}
+	err = documentsDbHandler.InsertDocument(ctx, database.Instance, doc)
+	require.NoError(t, err)
+
+	// chunk1 and chunk2 point in nearly the same direction, chunk3 is orthogonal to both
+	embedding1 := make([]float32, 384)
+	embedding1[100] = 1.0
+	embedding2 := make([]float32, 384)
+	embedding2[100] = 1.0
+	embedding2[101] = 0.1
+	embedding3 := make([]float32, 384)
+	embedding3[200] = 1.0
+
+	chunks := []*model.Chunk{
+		{DocumentID: doc.ID, Content: "Semantic chunk 1", Path: "semantic.1", Embedding: embedding1, Metadata: map[string]interface{}{}},
+		{DocumentID: doc.ID, Content: "Semantic chunk 2", Path: "semantic.2", Embedding: embedding2, Metadata: map[string]interface{}{}},
+		{DocumentID: doc.ID, Content: "Semantic chunk 3", Path: "semantic.3", Embedding: embedding3, Metadata: map[string]interface{}{}},
+	}
+	for _, chunk := range chunks {
+		require.NoError(t, chunksDbHandler.InsertChunk(ctx, database.Instance, chunk))
+	}
+	chunkIDs := []uuid.UUID{chunks[0].ID, chunks[1].ID, chunks[2].ID}
+	config := model.SemanticLinkConfig{K: 2, Threshold: 0.9}
+	semantic := model.EdgeTypeSemantic
+
+	t.Run("Link semantic neighbors", func(t *testing.T) {
+		created, err := edgesDbHandler.LinkSemanticNeighbors(ctx, database.Instance, chunkIDs, config)
+
+		assert.NoError(t, err, "Expected LinkSemanticNeighbors to not return an error")
+		assert.Equal(t, 1, created, "Expected one edge for the similar pair")
+
+		edges, err := edgesDbHandler.SelectEdgesFromChunk(ctx, chunks[0].ID, &semantic)
+		require.NoError(t, err)
+		edges2, err := edgesDbHandler.SelectEdgesFromChunk(ctx, chunks[1].ID, &semantic)
+		require.NoError(t, err)
+		edges = append(edges, edges2...)
+		require.Len(t, edges, 1)
+		assert.True(t, edges[0].Bidirectional, "Expected bidirectional edge")
+		assert.InDelta(t, 0.995, edges[0].Weight, 0.001, "Expected similarity as weight")
+		assert.Equal(t, true, edges[0].Metadata["generated"])
+
+		edges3, err := edgesDbHandler.SelectEdgesFromChunk(ctx, chunks[2].ID, &semantic)
+		require.NoError(t, err)
+		assert.Empty(t, edges3, "Expected no edge for dissimilar chunk")
+	})
+
+	t.Run("Link semantic neighbors again creates no duplicates", func(t *testing.T) {
+		created, err := edgesDbHandler.LinkSemanticNeighbors(ctx, database.Instance, chunkIDs, config)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, created, "Expected existing pair to be skipped")
+	})
+
+	t.Run("Prune keeps manual semantic edges", func(t *testing.T) {
+		manual := &model.Edge{
+			SourceChunkID: &chunks[0].ID,
+			TargetChunkID: &chunks[2].ID,
+			EdgeType:      model.EdgeTypeSemantic,
+			Weight:        0.1,
+			Metadata:      map[string]interface{}{},
+		}
+		require.NoError(t, edgesDbHandler.InsertEdge(ctx, database.Instance, manual))
+
+		deleted, err := edgesDbHandler.PruneSemanticEdges(ctx, 0.999, 0)
+
+		assert.NoError(t, err, "Expected PruneSemanticEdges to not return an error")
+		assert.GreaterOrEqual(t, deleted, 1, "Expected weak generated edge to be pruned")
+
+		_, err = edgesDbHandler.SelectEdge(ctx, manual.ID)
+		assert.NoError(t, err, "Expected manual edge to be kept")
+
+		// Cleanup
+		edgesDbHandler.DeleteEdge(ctx, manual.ID)
+	})
+
+	t.Run("Rebuild semantic edges", func(t *testing.T) {
+		created, err := edgesDbHandler.RebuildSemanticEdges(ctx, config)
+
+		assert.NoError(t, err, "Expected RebuildSemanticEdges to not return an error")
+		assert.GreaterOrEqual(t, created, 1, "Expected edges to be recreated")
+
+		edges, err := edgesDbHandler.SelectEdgesConnectedToChunk(ctx, chunks[0].ID, &semantic)
+		require.NoError(t, err)
+		assert.Len(t, edges, 1, "Expected the similar pair to be linked again")
+	})
+
+	// Cleanup
+	_, _ = database.Instance.Exec(`SELECT delete_semantic_edges()`)
+	for _, chunk := range chunks {
+		chunksDbHandler.DeleteChunk(ctx, chunk.ID)
+	}
+	documentsDbHandler.DeleteDocument(ctx, doc.RID)
+}
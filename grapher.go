@@ -1,13 +1,21 @@
 package grapher
 
 import (
+	"bytes"
 	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/core/graph"
 	"github.com/siherrmann/grapher/core/pipeline"
+	"github.com/siherrmann/grapher/core/resolution"
 	"github.com/siherrmann/grapher/core/retrieval"
 	"github.com/siherrmann/grapher/database"
 	"github.com/siherrmann/grapher/helper"
@@ -17,19 +25,46 @@ import (
 
 // Grapher provides a unified interface to all database handlers
 type Grapher struct {
-	DB        *helper.Database
-	Chunks    *database.ChunksDBHandler
-	Documents *database.DocumentsDBHandler
-	Edges     *database.EdgesDBHandler
-	Entities  *database.EntitiesDBHandler
-	Pipeline  *pipeline.Pipeline // Optional chunking pipeline
-	Engine    *retrieval.Engine  // Retrieval engine for hybrid search
+	DB          *helper.Database
+	Chunks      *database.ChunksDBHandler
+	Documents   *database.DocumentsDBHandler
+	Edges       *database.EdgesDBHandler
+	Entities    *database.EntitiesDBHandler
+	Communities *database.CommunitiesDBHandler
+	Pipeline    *pipeline.Pipeline // Optional chunking pipeline
+	Engine      *retrieval.Engine  // Retrieval engine for hybrid search
+	// Models loaded by UseDefaultPipeline, loaded once and closed by Close
+	Models *pipeline.ModelRegistry
+	// Options the models are loaded with, nil reads them from the environment
+	modelOptions *helper.ModelOptions
+	// Optional semantic edges created for new chunks on ingest
+	SemanticLinks *model.SemanticLinkConfig
 	// Logging
 	log *slog.Logger
 }
 
+// GrapherOptions configures a Grapher created with NewGrapherWithOptions
+type GrapherOptions struct {
+	// Options the models of UseDefaultPipeline are loaded with (model directory, offline mode, checksums).
+	// Nil reads them from the environment, see helper.NewModelOptions.
+	Models *helper.ModelOptions
+	// Text search config (e.g. "english", "german" or "simple") of a new chunks table,
+	// defaults to database.DefaultTextSearchConfig. Use ChangeTextSearchConfig for an existing table.
+	TextSearchConfig string
+}
+
 // NewGrapher creates a new Grapher instance with all handlers initialized
 func NewGrapher(config *helper.DatabaseConfiguration, embeddingDim int) (*Grapher, error) {
+	return NewGrapherWithOptions(config, embeddingDim, nil)
+}
+
+// NewGrapherWithOptions creates a new Grapher instance like NewGrapher with the given options,
+// nil options are the defaults
+func NewGrapherWithOptions(config *helper.DatabaseConfiguration, embeddingDim int, options *GrapherOptions) (*Grapher, error) {
+	if options == nil {
+		options = &GrapherOptions{}
+	}
+
 	// Logger
 	opts := helper.PrettyHandlerOptions{
 		SlogOpts: slog.HandlerOptions{
@@ -45,7 +80,7 @@ func NewGrapher(config *helper.DatabaseConfiguration, embeddingDim int) (*Graphe
 		return nil, helper.NewError("initialize database extensions", err)
 	}
 
-	// Create all handlers in the correct order (documents first, then chunks)
+	// Create all handlers in the correct order (documents first, then chunks, communities after entities)
 	// force=false to not reload if functions already exist
 	documents, err := database.NewDocumentsDBHandler(db, false)
 	if err != nil {
@@ -57,36 +92,56 @@ func NewGrapher(config *helper.DatabaseConfiguration, embeddingDim int) (*Graphe
 		return nil, helper.NewError("create edges handler", err)
 	}
 
-	chunks, err := database.NewChunksDBHandler(db, edges, embeddingDim, false)
+	chunks, err := database.NewChunksDBHandlerWithTextSearchConfig(db, edges, embeddingDim, options.TextSearchConfig, false)
 	if err != nil {
 		return nil, helper.NewError("create chunks handler", err)
 	}
 
-	entities, err := database.NewEntitiesDBHandler(db, false)
+	entities, err := database.NewEntitiesDBHandler(db, embeddingDim, false)
 	if err != nil {
 		return nil, helper.NewError("create entities handler", err)
 	}
 
+	communities, err := database.NewCommunitiesDBHandler(db, embeddingDim, false)
+	if err != nil {
+		return nil, helper.NewError("create communities handler", err)
+	}
+
 	// Create retrieval engine with database handlers
 	engine := retrieval.NewEngine(chunks, edges, entities)
 
 	return &Grapher{
-		DB:        db,
-		Chunks:    chunks,
-		Documents: documents,
-		Edges:     edges,
-		Entities:  entities,
-		Engine:    engine,
-		log:       logger,
+		DB:           db,
+		Chunks:       chunks,
+		Documents:    documents,
+		Edges:        edges,
+		Entities:     entities,
+		Communities:  communities,
+		Engine:       engine,
+		Models:       newModelRegistry(options.Models),
+		modelOptions: options.Models,
+		log:          logger,
 	}, nil
 }
 
-// Close closes the database connection
+// newModelRegistry creates a model registry with the options, nil options are read from the environment
+func newModelRegistry(options *helper.ModelOptions) *pipeline.ModelRegistry {
+	if options == nil {
+		return pipeline.NewModelRegistry()
+	}
+	return pipeline.NewModelRegistryWithOptions(options)
+}
+
+// Close releases the loaded models and closes the database connection
 func (g *Grapher) Close() error {
+	var err error
+	if g.Models != nil {
+		err = g.Models.Close()
+	}
 	if g.DB != nil && g.DB.Instance != nil {
-		return g.DB.Instance.Close()
+		err = errors.Join(err, g.DB.Instance.Close())
 	}
-	return nil
+	return err
 }
 
 // SetPipeline sets the chunking pipeline for document processing
@@ -94,43 +149,68 @@ func (g *Grapher) SetPipeline(pipeline *pipeline.Pipeline) {
 	g.Pipeline = pipeline
 }
 
+// SetSemanticLinking enables linking every newly ingested chunk to its nearest neighbours
+// with semantic edges. A nil config disables it.
+func (g *Grapher) SetSemanticLinking(config *model.SemanticLinkConfig) {
+	g.SemanticLinks = config
+}
+
 // UseDefaultPipeline sets up the default semantic chunking and embedding pipeline
-// This uses DefaultChunker with 500 char max chunks and 0.7 similarity threshold,
-// DefaultEmbedder with the all-MiniLM-L6-v2 model (384 dimensions),
-// DefaultEntityExtractor with distilbert-NER for entity recognition,
-// and DefaultRelationExtractor with distilbert-NER for citation and reference detection
+// This uses the semantic chunker with 500 char max chunks and 0.7 similarity threshold,
+// the batch embedder with the all-MiniLM-L6-v2 model (384 dimensions) for chunks, entities and queries,
+// the entity extractor with distilbert-NER for entity recognition,
+// and the relation extractor with distilbert-NER for citation and reference detection.
+// The models are loaded once into g.Models, shared between the functions and released by Close.
 func (g *Grapher) UseDefaultPipeline() error {
-	chunker := pipeline.DefaultChunker(500, 0.7)
-	embedder, err := pipeline.DefaultEmbedder()
+	if g.Models == nil {
+		g.Models = newModelRegistry(g.modelOptions)
+	}
+
+	chunker := g.Models.Chunker(500, 0.7)
+	batchEmbedder, err := g.Models.BatchEmbedder()
 	if err != nil {
 		return helper.NewError("create default embedder", err)
 	}
+	embedder := pipeline.EmbedderFromBatch(batchEmbedder)
 
-	entityExtractor, err := pipeline.DefaultEntityExtractor()
+	entityExtractor, err := g.Models.EntityExtractor()
 	if err != nil {
 		return helper.NewError("create default entity extractor", err)
 	}
 
-	relationExtractor, err := pipeline.DefaultRelationExtractor()
+	relationExtractor, err := g.Models.RelationExtractor()
 	if err != nil {
 		return helper.NewError("create default relation extractor", err)
 	}
 
 	g.Pipeline = pipeline.NewPipeline(chunker, embedder)
+	g.Pipeline.SetBatchEmbedder(batchEmbedder, pipeline.DefaultBatchSize)
 	g.Pipeline.SetEntityExtractor(entityExtractor)
 	g.Pipeline.SetRelationExtractor(relationExtractor)
 	return nil
 }
 
 // ProcessAndInsertDocument processes a document by:
-// 1. Inserting the document metadata (without content)
-// 2. Processing the content into chunks using the pipeline
-// 3. Inserting all chunks with the document ID
-// 4. Extracting and inserting entities (if entity extractor is configured)
-// 5. Extracting and inserting relations/edges (if relation extractor is configured)
-// The document's Content field is used for processing but not stored in the database.
+// 1. Processing the content into chunks using the pipeline, embedding them and
+// extracting entities and relations (if the extractors are configured)
+// 2. Inserting the document metadata (without content)
+// 3. Inserting all chunks with the document ID and the hierarchical edges
+// (parent/child and next/previous sibling) derived from their paths
+// 4. Inserting the entities, an existing entity keeps its ID and accumulates the mention count and documents
+// 5. Inserting the relations/edges, remapped to the stored entity IDs
+// 6. Linking the new chunks to their nearest neighbours (if semantic linking is enabled)
+// The pipeline runs before the transaction is opened, so no locks are held while the models run.
+// All inserts run inside a single transaction, so a failure in any step
+// rolls back the whole document and nothing is left half-ingested.
+// The document gets a new RID, the document's Content field is used for processing but not stored in the database.
+// doc is only changed after the commit (new RID, content cleared), so a failed document can be inserted again.
 // Returns the number of chunks inserted and any error encountered.
 func (g *Grapher) ProcessAndInsertDocument(doc *model.Document) (int, error) {
+	return g.ProcessAndInsertDocumentCtx(context.Background(), doc)
+}
+
+// ProcessAndInsertDocumentCtx is ProcessAndInsertDocument with a context that cancels the processing and the transaction
+func (g *Grapher) ProcessAndInsertDocumentCtx(ctx context.Context, doc *model.Document) (int, error) {
 	if g.Pipeline == nil {
 		return 0, helper.NewError("process document", fmt.Errorf("pipeline not set, use SetPipeline() first"))
 	}
@@ -139,21 +219,205 @@ func (g *Grapher) ProcessAndInsertDocument(doc *model.Document) (int, error) {
 		return 0, helper.NewError("process document", fmt.Errorf("document content is empty"))
 	}
 
-	// Store content temporarily and clear it before DB insert
+	// Work on a copy without content, the caller's document is only updated after the commit,
+	// so it can be inserted again if processing or the transaction fails
 	content := doc.Content
-	doc.Content = ""
+	inserted := *doc
+	inserted.Content = ""
+	inserted.ContentHash = helper.HashContent(content)
+
+	// The chunk paths contain the RID, so it is chosen before processing
+	inserted.RID = uuid.New()
+	result, err := g.processContent(ctx, &inserted, content)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := g.DB.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
 
 	// Insert document metadata
-	if err := g.Documents.InsertDocument(doc); err != nil {
+	if err := g.Documents.InsertDocument(ctx, tx, &inserted); err != nil {
 		return 0, helper.NewError("insert document", err)
 	}
 
-	g.log.Info("Inserted document", slog.String("document_id", doc.RID.String()), slog.String("title", doc.Title))
+	stats, err := g.insertProcessingResult(ctx, tx, &inserted, result, result.Chunks)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, helper.NewError("commit transaction", err)
+	}
+	*doc = inserted
+
+	g.log.Info("Inserted document",
+		slog.String("document_id", doc.RID.String()),
+		slog.String("title", doc.Title),
+		slog.Int("num_chunks", stats.chunks),
+		slog.Int("num_entities", stats.entities),
+		slog.Int("num_edges", stats.edges),
+		slog.Int("num_semantic_edges", stats.semanticEdges))
+
+	return stats.chunks, nil
+}
+
+// ProcessAndUpsertDocument inserts a document like ProcessAndInsertDocument, keyed by its Source.
+// The SHA-256 hash of the content is stored with the document, re-ingesting a source is idempotent:
+// - No document with the source exists: the document is inserted
+// - The stored content hash equals the new one: nothing is written and doc is filled with the stored document
+// - The content changed: the document is updated keeping its RID and the content is updated incrementally
+// like in ProcessAndUpdateDocument, changed chunks are deleted with their edges and entity mentions
+// (entities without remaining mentions are deleted) and rebuilt
+// The content is processed before the transaction is opened. Concurrent upserts of the same source are
+// serialized with a lock on the source, an upsert fails if the document changed while it was processed.
+// Returns the upsert status and the number of chunks inserted (0 if unchanged).
+func (g *Grapher) ProcessAndUpsertDocument(doc *model.Document) (model.UpsertStatus, int, error) {
+	return g.ProcessAndUpsertDocumentCtx(context.Background(), doc)
+}
+
+// ProcessAndUpsertDocumentCtx is ProcessAndUpsertDocument with a context that cancels the processing and the transaction
+func (g *Grapher) ProcessAndUpsertDocumentCtx(ctx context.Context, doc *model.Document) (model.UpsertStatus, int, error) {
+	if g.Pipeline == nil {
+		return "", 0, helper.NewError("process document", fmt.Errorf("pipeline not set, use SetPipeline() first"))
+	}
+
+	if doc.Content == "" {
+		return "", 0, helper.NewError("process document", fmt.Errorf("document content is empty"))
+	}
+
+	if doc.Source == "" {
+		return "", 0, helper.NewError("process document", fmt.Errorf("document source is empty"))
+	}
+
+	content := doc.Content
+	contentHash := helper.HashContent(content)
+
+	existing, err := g.Documents.SelectDocumentBySource(ctx, g.DB.Instance, doc.Source)
+	if err != nil {
+		return "", 0, helper.NewError("select document", err)
+	}
+
+	if existing != nil && existing.ContentHash == contentHash {
+		return g.upsertUnchanged(doc, existing)
+	}
+
+	// Work on a copy without content, the caller's document is only updated after the commit
+	upserted := *doc
+	upserted.Content = ""
+	upserted.ContentHash = contentHash
+
+	// Process the content before opening the transaction
+	var result *pipeline.ProcessingResult
+	var prepared *preparedUpdate
+	if existing == nil {
+		upserted.RID = uuid.New()
+		result, err = g.processContent(ctx, &upserted, content)
+	} else {
+		// Only the changed chunks are rebuilt, unchanged chunks keep their IDs and edges
+		upserted.RID = existing.RID
+		prepared, err = g.prepareUpdate(ctx, &upserted, content)
+	}
+	if err != nil {
+		return "", 0, err
+	}
+
+	tx, err := g.DB.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return "", 0, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
+
+	if err := g.Documents.LockDocumentSource(ctx, tx, doc.Source); err != nil {
+		return "", 0, helper.NewError("lock source", err)
+	}
+
+	// A concurrent upsert of the source may have written it while the content was processed
+	current, err := g.Documents.SelectDocumentBySource(ctx, tx, doc.Source)
+	if err != nil {
+		return "", 0, helper.NewError("select document", err)
+	}
+	if current != nil && current.ContentHash == contentHash {
+		return g.upsertUnchanged(doc, current)
+	}
+	if (existing == nil) != (current == nil) || (current != nil && current.RID != existing.RID) {
+		return "", 0, helper.NewError("upsert document", fmt.Errorf("document with source %s changed during processing", doc.Source))
+	}
+
+	status := model.UpsertStatusInserted
+	var stats contentStats
+	if existing == nil {
+		if err := g.Documents.InsertDocument(ctx, tx, &upserted); err != nil {
+			return "", 0, helper.NewError("insert document", err)
+		}
+		stats, err = g.insertProcessingResult(ctx, tx, &upserted, result, result.Chunks)
+		if err != nil {
+			return "", 0, err
+		}
+	} else {
+		status = model.UpsertStatusUpdated
+		var update *model.DocumentUpdate
+		update, stats, err = g.writeUpdate(ctx, tx, &upserted, prepared)
+		if err != nil {
+			return "", 0, err
+		}
+		g.log.Info("Updated document content",
+			slog.String("document_id", doc.RID.String()),
+			slog.Int("num_kept", update.Kept),
+			slog.Int("num_moved", update.Moved),
+			slog.Int("num_removed", update.Removed))
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", 0, helper.NewError("commit transaction", err)
+	}
+	*doc = upserted
+
+	g.log.Info("Upserted document",
+		slog.String("document_id", doc.RID.String()),
+		slog.String("status", string(status)),
+		slog.String("title", doc.Title),
+		slog.Int("num_chunks", stats.chunks),
+		slog.Int("num_entities", stats.entities),
+		slog.Int("num_edges", stats.edges),
+		slog.Int("num_semantic_edges", stats.semanticEdges))
+
+	return status, stats.chunks, nil
+}
+
+// upsertUnchanged fills doc with the stored document whose content hash matches
+func (g *Grapher) upsertUnchanged(doc *model.Document, stored *model.Document) (model.UpsertStatus, int, error) {
+	*doc = *stored
+	g.log.Info("Document unchanged",
+		slog.String("document_id", doc.RID.String()),
+		slog.String("source", doc.Source))
+	return model.UpsertStatusUnchanged, 0, nil
+}
+
+// contentStats counts what insertProcessingResult inserted
+type contentStats struct {
+	chunks        int
+	entities      int
+	edges         int
+	semanticEdges int
+}
 
+// processContent chunks, embeds and extracts the content of a new document (step 1 of ProcessAndInsertDocument),
+// the chunk paths are built from the RID of the document
+func (g *Grapher) processContent(ctx context.Context, doc *model.Document, content string) (*pipeline.ProcessingResult, error) {
 	// Process content with entity and relation extraction
-	result, err := g.Pipeline.ProcessWithExtraction(content, fmt.Sprintf("doc_%s", doc.RID.String()))
+	result, err := g.Pipeline.ProcessWithHintsCtx(ctx, content, fmt.Sprintf("doc_%s", doc.RID.String()), doc.Hints)
 	if err != nil {
-		return 0, helper.NewError("process chunks", err)
+		return nil, helper.NewError("process chunks", err)
 	}
 
 	g.log.Info("Processed document into chunks",
@@ -162,57 +426,331 @@ func (g *Grapher) ProcessAndInsertDocument(doc *model.Document) (int, error) {
 		slog.Int("num_relations", len(result.Relations)),
 		slog.String("document_id", doc.RID.String()))
 
+	return result, nil
+}
+
+// insertProcessingResult inserts the processed chunks, entities and edges of a document in the given transaction,
+// links the chunks to their nearest neighbours and adds the hierarchical edges between hierarchyChunks
+func (g *Grapher) insertProcessingResult(ctx context.Context, tx *sql.Tx, doc *model.Document, result *pipeline.ProcessingResult, hierarchyChunks []*model.Chunk) (contentStats, error) {
 	// Insert all chunks and build a path-to-ID mapping
-	chunkPathToID := make(map[string]uuid.UUID)
-	for i, chunk := range result.Chunks {
+	for _, chunk := range result.Chunks {
 		chunk.DocumentID = doc.ID
-		if err := g.Chunks.InsertChunk(chunk); err != nil {
-			return i, helper.NewError(fmt.Sprintf("insert chunk %d", i), err)
-		}
+		chunk.DocumentRID = doc.RID
+	}
+	if err := g.Chunks.InsertChunksBatch(ctx, tx, result.Chunks); err != nil {
+		return contentStats{}, helper.NewError("insert chunks", err)
+	}
+
+	chunkPathToID := make(map[string]uuid.UUID)
+	for _, chunk := range result.Chunks {
 		chunkPathToID[chunk.Path] = chunk.ID
 	}
 
-	// Insert entities
-	if len(result.Entities) > 0 {
-		for _, entity := range result.Entities {
-			if err := g.Entities.InsertEntity(entity); err != nil {
-				g.log.Error("Failed to insert entity", slog.String("entity", entity.Name), slog.String("error", err.Error()))
-				// Continue processing other entities even if one fails
-			}
+	// Insert entities in a fixed order, so concurrent documents insert shared new entities in the same order
+	// instead of deadlocking (stored entities are locked in ID order by the batch insert). An entity that already exists keeps its stored ID,
+	// so remember the extractor IDs to remap the edges afterwards.
+	sort.SliceStable(result.Entities, func(i, j int) bool {
+		if result.Entities[i].Type != result.Entities[j].Type {
+			return result.Entities[i].Type < result.Entities[j].Type
+		}
+		return result.Entities[i].Name < result.Entities[j].Name
+	})
+	extractedIDs := make([]uuid.UUID, len(result.Entities))
+	for i, entity := range result.Entities {
+		extractedIDs[i] = entity.ID
+		if entity.Metadata == nil {
+			entity.Metadata = model.Metadata{}
 		}
-		g.log.Info("Inserted entities", slog.Int("count", len(result.Entities)))
+		entity.Metadata["documents"] = []string{doc.RID.String()}
+	}
+	if err := g.Entities.InsertEntitiesBatch(ctx, tx, result.Entities); err != nil {
+		return contentStats{}, helper.NewError("insert entities", err)
 	}
 
+	entityIDs := make(map[uuid.UUID]uuid.UUID, len(result.Entities))
+	for i, entity := range result.Entities {
+		if extractedIDs[i] != uuid.Nil {
+			entityIDs[extractedIDs[i]] = entity.ID
+		}
+	}
+	remapEntityIDs(result.Relations, entityIDs)
+
 	// Insert relations/edges
-	if len(result.Relations) > 0 {
-		for _, edge := range result.Relations {
-			// For reference edges without entity IDs, link to the source chunk
-			if edge.SourceEntityID == nil && edge.SourceChunkID == nil {
-				// Get chunk ID from extracted_from metadata
-				if extractedFrom, ok := edge.Metadata["extracted_from"].(string); ok {
-					if chunkID, found := chunkPathToID[extractedFrom]; found {
-						edge.SourceChunkID = &chunkID
-					}
+	edges := make([]*model.Edge, 0, len(result.Relations))
+	for _, edge := range result.Relations {
+		// For reference and mention edges without a source, link to the source chunk
+		if edge.SourceEntityID == nil && edge.SourceChunkID == nil {
+			// Get chunk ID from extracted_from metadata
+			if extractedFrom, ok := edge.Metadata["extracted_from"].(string); ok {
+				if chunkID, found := chunkPathToID[extractedFrom]; found {
+					edge.SourceChunkID = &chunkID
 				}
 			}
+		}
 
-			// Skip edges that don't have both source and target
-			// (e.g., citations to external documents not in our database)
-			hasSource := edge.SourceChunkID != nil || edge.SourceEntityID != nil
-			hasTarget := edge.TargetChunkID != nil || edge.TargetEntityID != nil
-			if !hasSource || !hasTarget {
-				continue // Skip this edge
-			}
+		// Skip edges that don't have both source and target
+		// (e.g., citations to external documents not in our database)
+		hasSource := edge.SourceChunkID != nil || edge.SourceEntityID != nil
+		hasTarget := edge.TargetChunkID != nil || edge.TargetEntityID != nil
+		if !hasSource || !hasTarget {
+			continue // Skip this edge
+		}
+
+		edges = append(edges, edge)
+	}
+	edges = append(edges, graph.HierarchyEdges(hierarchyChunks)...)
+	if err := g.Edges.InsertEdgesBatch(ctx, tx, edges); err != nil {
+		return contentStats{}, helper.NewError("insert edges", err)
+	}
+
+	// Link the new chunks to their nearest neighbours
+	numSemanticEdges := 0
+	if g.SemanticLinks != nil && len(result.Chunks) > 0 {
+		chunkIDs := make([]uuid.UUID, len(result.Chunks))
+		for i, chunk := range result.Chunks {
+			chunkIDs[i] = chunk.ID
+		}
+		var err error
+		numSemanticEdges, err = g.Edges.LinkSemanticNeighbors(ctx, tx, chunkIDs, *g.SemanticLinks)
+		if err != nil {
+			return contentStats{}, helper.NewError("link semantic neighbors", err)
+		}
+	}
+
+	return contentStats{
+		chunks:        len(result.Chunks),
+		entities:      len(result.Entities),
+		edges:         len(edges),
+		semanticEdges: numSemanticEdges,
+	}, nil
+}
+
+// ProcessAndUpdateDocument updates a stored document (identified by its RID) with new content
+// without reprocessing the unchanged parts. The new content is chunked and the chunks are matched
+// to the stored chunks by the hash of their content (see pipeline.DiffChunks):
+// - Matched chunks keep their ID, embedding and edges, only path and position are updated if they moved
+// - Stored chunks without a match are deleted with their edges and entity mentions
+// - New or changed chunks are embedded, their entities extracted and inserted like in ProcessAndInsertDocument
+// The hierarchical edges of the document are rebuilt. Title, source and metadata of the document are updated.
+// The new chunks are processed before the transaction is opened and all writes run in one transaction,
+// the update fails if the stored chunks changed in the meantime.
+// Returns the counts of kept, moved, added and removed chunks.
+func (g *Grapher) ProcessAndUpdateDocument(doc *model.Document) (*model.DocumentUpdate, error) {
+	return g.ProcessAndUpdateDocumentCtx(context.Background(), doc)
+}
+
+// ProcessAndUpdateDocumentCtx is ProcessAndUpdateDocument with a context that cancels the processing and the transaction
+func (g *Grapher) ProcessAndUpdateDocumentCtx(ctx context.Context, doc *model.Document) (*model.DocumentUpdate, error) {
+	if g.Pipeline == nil {
+		return nil, helper.NewError("process document", fmt.Errorf("pipeline not set, use SetPipeline() first"))
+	}
+
+	if doc.Content == "" {
+		return nil, helper.NewError("process document", fmt.Errorf("document content is empty"))
+	}
+
+	// Work on a copy without content, the caller's document is only updated after the commit
+	content := doc.Content
+	updated := *doc
+	updated.Content = ""
+	updated.ContentHash = helper.HashContent(content)
+
+	prepared, err := g.prepareUpdate(ctx, &updated, content)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := g.DB.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
+
+	update, stats, err := g.writeUpdate(ctx, tx, &updated, prepared)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, helper.NewError("commit transaction", err)
+	}
+	*doc = updated
 
-			if err := g.Edges.InsertEdge(edge); err != nil {
-				g.log.Error("Failed to insert edge", slog.String("type", string(edge.EdgeType)), slog.String("error", err.Error()))
-				// Continue processing other edges even if one fails
+	g.log.Info("Updated document",
+		slog.String("document_id", doc.RID.String()),
+		slog.String("title", doc.Title),
+		slog.Int("num_kept", update.Kept),
+		slog.Int("num_moved", update.Moved),
+		slog.Int("num_added", update.Added),
+		slog.Int("num_removed", update.Removed),
+		slog.Int("num_entities", stats.entities),
+		slog.Int("num_edges", stats.edges),
+		slog.Int("num_semantic_edges", stats.semanticEdges))
+
+	return update, nil
+}
+
+// preparedUpdate is the processed new content of a stored document, ready to be written by writeUpdate
+type preparedUpdate struct {
+	stored []*model.Chunk // Stored chunks the diff was computed against
+	diff   *pipeline.ChunkDiff
+	result *pipeline.ProcessingResult // Processed added chunks
+}
+
+// prepareUpdate chunks the new content of a stored document, matches the chunks to the stored chunks
+// and processes only the new or changed chunks. Nothing is written.
+func (g *Grapher) prepareUpdate(ctx context.Context, doc *model.Document, content string) (*preparedUpdate, error) {
+	stored, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+	if err != nil {
+		return nil, helper.NewError("select chunks", err)
+	}
+
+	// The chunker can't be interrupted, so don't start it for a canceled update
+	if err := ctx.Err(); err != nil {
+		return nil, helper.NewError("chunk content", err)
+	}
+
+	chunksWithPath, err := g.Pipeline.Chunk(content, fmt.Sprintf("doc_%s", doc.RID.String()), doc.Hints)
+	if err != nil {
+		return nil, helper.NewError("chunk content", err)
+	}
+
+	diff := pipeline.DiffChunks(stored, chunksWithPath)
+
+	// Only the new chunks are embedded and extracted
+	result, err := g.Pipeline.ProcessChunksCtx(ctx, diff.Added)
+	if err != nil {
+		return nil, helper.NewError("process chunks", err)
+	}
+
+	return &preparedUpdate{stored: stored, diff: diff, result: result}, nil
+}
+
+// writeUpdate updates the document and replaces its stored chunks with the prepared chunks in the given transaction,
+// only chunks with changed content are deleted or inserted. It fails if the stored chunks changed since prepareUpdate.
+func (g *Grapher) writeUpdate(ctx context.Context, tx *sql.Tx, doc *model.Document, prepared *preparedUpdate) (*model.DocumentUpdate, contentStats, error) {
+	// Updating the document locks it against concurrent updates until the transaction ends
+	if err := g.Documents.UpdateDocument(ctx, tx, doc); err != nil {
+		return nil, contentStats{}, helper.NewError("update document", err)
+	}
+
+	stored, err := g.Chunks.SelectAllChunksByDocument(ctx, tx, doc.RID)
+	if err != nil {
+		return nil, contentStats{}, helper.NewError("select chunks", err)
+	}
+	if !sameChunks(stored, prepared.stored) {
+		return nil, contentStats{}, helper.NewError("update document", fmt.Errorf("chunks of document %s changed during processing", doc.RID))
+	}
+
+	diff := prepared.diff
+	update := &model.DocumentUpdate{
+		Kept:    len(diff.Matched),
+		Added:   len(diff.Added),
+		Removed: len(diff.Removed),
+	}
+
+	// Delete the chunks that are gone before moving others to their paths
+	if len(diff.Removed) > 0 {
+		removedIDs := make([]uuid.UUID, len(diff.Removed))
+		for i, chunk := range diff.Removed {
+			removedIDs[i] = chunk.ID
+		}
+		if _, err := g.Chunks.DeleteChunks(ctx, tx, removedIDs); err != nil {
+			return nil, contentStats{}, helper.NewError("delete chunks", err)
+		}
+	}
+
+	kept := make([]*model.Chunk, 0, len(diff.Matched))
+	for _, match := range diff.Matched {
+		chunk := match.Stored
+		if chunkMoved(chunk, match.New) {
+			chunk.Path = match.New.Path
+			chunk.StartPos = match.New.StartPos
+			chunk.EndPos = match.New.EndPos
+			chunk.ChunkIndex = match.New.ChunkIndex
+			chunk.Metadata = match.New.Metadata
+			if err := g.Chunks.UpdateChunkPosition(ctx, tx, chunk); err != nil {
+				return nil, contentStats{}, helper.NewError("update chunk position", err)
 			}
+			update.Moved++
 		}
-		g.log.Info("Inserted relations", slog.Int("count", len(result.Relations)))
+		kept = append(kept, chunk)
 	}
 
-	return len(result.Chunks), nil
+	// Hierarchical edges depend on the paths of all chunks, so they are rebuilt
+	if _, err := g.Edges.DeleteDocumentEdges(ctx, tx, doc.RID, model.EdgeTypeHierarchical); err != nil {
+		return nil, contentStats{}, helper.NewError("delete hierarchical edges", err)
+	}
+
+	stats, err := g.insertProcessingResult(ctx, tx, doc, prepared.result, append(kept, prepared.result.Chunks...))
+	if err != nil {
+		return nil, contentStats{}, err
+	}
+
+	return update, stats, nil
+}
+
+// sameChunks reports whether two lists of stored chunks contain the same chunks at the same paths
+func sameChunks(a []*model.Chunk, b []*model.Chunk) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	paths := make(map[uuid.UUID]string, len(a))
+	for _, chunk := range a {
+		paths[chunk.ID] = chunk.Path
+	}
+	for _, chunk := range b {
+		if path, ok := paths[chunk.ID]; !ok || path != chunk.Path {
+			return false
+		}
+	}
+	return true
+}
+
+// chunkMoved reports whether the path, position or metadata of a stored chunk differ from the new chunk
+func chunkMoved(chunk *model.Chunk, cwp pipeline.ChunkWithPath) bool {
+	return chunk.Path != cwp.Path ||
+		!equalIntPtr(chunk.StartPos, cwp.StartPos) ||
+		!equalIntPtr(chunk.EndPos, cwp.EndPos) ||
+		!equalIntPtr(chunk.ChunkIndex, cwp.ChunkIndex) ||
+		!equalMetadata(chunk.Metadata, cwp.Metadata)
+}
+
+// equalIntPtr compares two optional ints
+func equalIntPtr(a *int, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// equalMetadata compares metadata by its JSON encoding, like it is stored
+func equalMetadata(a model.Metadata, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aJSON, bJSON)
+}
+
+// remapEntityIDs replaces extractor entity IDs in the edges with the stored entity IDs
+func remapEntityIDs(edges []*model.Edge, entityIDs map[uuid.UUID]uuid.UUID) {
+	for _, edge := range edges {
+		if edge.SourceEntityID != nil {
+			if storedID, ok := entityIDs[*edge.SourceEntityID]; ok {
+				edge.SourceEntityID = &storedID
+			}
+		}
+		if edge.TargetEntityID != nil {
+			if storedID, ok := entityIDs[*edge.TargetEntityID]; ok {
+				edge.TargetEntityID = &storedID
+			}
+		}
+	}
 }
 
 // Search performs vector similarity search
@@ -225,12 +763,27 @@ func (g *Grapher) Search(ctx context.Context, query string, config *model.QueryC
 	}
 
 	// Generate embedding from query
-	embedding, err := g.Pipeline.Embedder(query)
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
 	if err != nil {
 		return nil, helper.NewError("generate embedding", err)
 	}
 
-	return g.Engine.VectorRetrieve(ctx, embedding, config)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return g.Engine.VectorRetrieve(ctx, embedding, config)
+	})
+}
+
+// KeywordSearch performs full-text keyword search
+// This finds exact terms like identifiers, error codes or names and doesn't need an embedder
+func (g *Grapher) KeywordSearch(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if g.Engine == nil {
+		return nil, helper.NewError("keyword search", fmt.Errorf("retrieval engine not initialized"))
+	}
+
+	strategy := retrieval.NewKeywordStrategy(g.Engine)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, query, config)
+	})
 }
 
 // ContextualSearch performs contextual retrieval (vector + neighbors + hierarchy)
@@ -240,13 +793,15 @@ func (g *Grapher) ContextualSearch(ctx context.Context, query string, config *mo
 	}
 
 	// Generate embedding from query
-	embedding, err := g.Pipeline.Embedder(query)
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
 	if err != nil {
 		return nil, helper.NewError("generate embedding", err)
 	}
 
 	strategy := retrieval.NewContextualStrategy(g.Engine)
-	return strategy.Retrieve(ctx, embedding, config)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, embedding, config)
+	})
 }
 
 // MultiHopSearch performs multi-hop graph traversal retrieval
@@ -256,13 +811,15 @@ func (g *Grapher) MultiHopSearch(ctx context.Context, query string, config *mode
 	}
 
 	// Generate embedding from query
-	embedding, err := g.Pipeline.Embedder(query)
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
 	if err != nil {
 		return nil, helper.NewError("generate embedding", err)
 	}
 
 	strategy := retrieval.NewMultiHopStrategy(g.Engine)
-	return strategy.Retrieve(ctx, embedding, config)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, embedding, config)
+	})
 }
 
 // HybridSearch performs fully configurable hybrid retrieval
@@ -272,13 +829,53 @@ func (g *Grapher) HybridSearch(ctx context.Context, query string, config *model.
 	}
 
 	// Generate embedding from query
-	embedding, err := g.Pipeline.Embedder(query)
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
 	if err != nil {
 		return nil, helper.NewError("generate embedding", err)
 	}
 
 	strategy := retrieval.NewHybridStrategy(g.Engine)
-	return strategy.Retrieve(ctx, embedding, config)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, embedding, config)
+	})
+}
+
+// PageRankSearch ranks chunks by personalized PageRank over the subgraph around the top vector hits
+// and the entities matching the query, following the edges by weight (HippoRAG-style retrieval)
+func (g *Grapher) PageRankSearch(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if g.Pipeline == nil || g.Pipeline.Embedder == nil {
+		return nil, helper.NewError("pagerank search", fmt.Errorf("pipeline with embedder not set, use SetPipeline() first"))
+	}
+
+	// Generate embedding from query
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
+	if err != nil {
+		return nil, helper.NewError("generate embedding", err)
+	}
+
+	strategy := retrieval.NewPageRankStrategy(g.Engine)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, embedding, config)
+	})
+}
+
+// FusionSearch combines keyword, vector and graph results with rank or normalized score fusion
+// The fusion method, k constant and per-source weights are taken from the query config
+func (g *Grapher) FusionSearch(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if g.Pipeline == nil || g.Pipeline.Embedder == nil {
+		return nil, helper.NewError("fusion search", fmt.Errorf("pipeline with embedder not set, use SetPipeline() first"))
+	}
+
+	// Generate embedding from query
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
+	if err != nil {
+		return nil, helper.NewError("generate embedding", err)
+	}
+
+	strategy := retrieval.NewFusionStrategy(g.Engine)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, query, embedding, config)
+	})
 }
 
 // DocumentScopedSearch performs hybrid search within specific documents only
@@ -293,7 +890,7 @@ func (g *Grapher) DocumentScopedSearch(ctx context.Context, query string, docume
 	}
 
 	// Generate embedding from query
-	embedding, err := g.Pipeline.Embedder(query)
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
 	if err != nil {
 		return nil, helper.NewError("generate embedding", err)
 	}
@@ -305,7 +902,72 @@ func (g *Grapher) DocumentScopedSearch(ctx context.Context, query string, docume
 	config.DocumentRIDs = documentRIDs
 
 	strategy := retrieval.NewHybridStrategy(g.Engine)
-	return strategy.Retrieve(ctx, embedding, config)
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		return strategy.Retrieve(ctx, embedding, config)
+	})
+}
+
+// withRerank runs a retrieval and rescores the top candidates with the pipeline's reranker.
+// If config.RerankDepth is set, max(TopK, RerankDepth) candidates are retrieved,
+// the first RerankDepth of them are reranked and the result is truncated to TopK.
+func (g *Grapher) withRerank(ctx context.Context, query string, config *model.QueryConfig, retrieve func(config *model.QueryConfig) ([]*model.RetrievalResult, error)) ([]*model.RetrievalResult, error) {
+	if config == nil || config.RerankDepth <= 0 {
+		return retrieve(config)
+	}
+	if g.Pipeline == nil || g.Pipeline.Reranker == nil {
+		return nil, helper.NewError("rerank", fmt.Errorf("rerank depth set but pipeline has no reranker, use SetReranker() first"))
+	}
+
+	// Retrieve enough candidates for reranking
+	candidateConfig := *config
+	if candidateConfig.TopK < config.RerankDepth {
+		candidateConfig.TopK = config.RerankDepth
+	}
+
+	results, err := retrieve(&candidateConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	depth := config.RerankDepth
+	if depth > len(results) {
+		depth = len(results)
+	}
+
+	documents := make([]string, depth)
+	for i, result := range results[:depth] {
+		documents[i] = result.Chunk.Content
+	}
+
+	// The reranker can't be interrupted, so don't start it for a canceled request
+	if err := ctx.Err(); err != nil {
+		return nil, helper.NewError("rerank", err)
+	}
+
+	scores, err := g.Pipeline.Reranker(query, documents)
+	if err != nil {
+		return nil, helper.NewError("rerank", err)
+	}
+	if len(scores) != depth {
+		return nil, helper.NewError("rerank", fmt.Errorf("reranker returned %d scores for %d documents", len(scores), depth))
+	}
+
+	// Rescore the reranked candidates, the rest keeps its order behind them
+	for i, result := range results[:depth] {
+		score := float64(scores[i])
+		result.Score = score
+		result.RerankScore = &score
+	}
+	sort.SliceStable(results[:depth], func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+
+	// Limit to top-k
+	if config.TopK > 0 && len(results) > config.TopK {
+		results = results[:config.TopK]
+	}
+
+	return results, nil
 }
 
 // EntityCentricSearch performs entity-centric retrieval
@@ -314,17 +976,332 @@ func (g *Grapher) EntityCentricSearch(ctx context.Context, entityID uuid.UUID, c
 	return strategy.Retrieve(ctx, entityID, config)
 }
 
-// BFSTraversal performs breadth-first search from a chunk
+// ResolveEntities clusters stored entities of a type that refer to the same real-world entity
+// (e.g. "IBM", "I.B.M." and "International Business Machines") and merges each cluster
+// into its canonical entity. Up to limit entities are compared by name and by the similarity
+// of their stored embeddings, entities without one are embedded if the pipeline has an embedder.
+// All merges run in one transaction. Returns the merged clusters.
+func (g *Grapher) ResolveEntities(ctx context.Context, entityType string, limit int, config resolution.Config) ([]*resolution.Cluster, error) {
+	entities, err := g.Entities.SelectEntitiesByType(ctx, entityType, limit)
+	if err != nil {
+		return nil, helper.NewError("select entities", err)
+	}
+
+	// Entities without a stored embedding are embedded like entities without mentions during ingestion
+	var embedder resolution.EmbedFunc
+	if g.Pipeline != nil && (g.Pipeline.Embedder != nil || g.Pipeline.BatchEmbedder != nil) {
+		embedder = func(names []string) ([][]float32, error) {
+			texts := make([]string, len(names))
+			for i, name := range names {
+				texts[i] = pipeline.EntityEmbeddingText(name, nil)
+			}
+			return g.Pipeline.EmbedBatchCtx(ctx, texts)
+		}
+	}
+
+	clusters, err := resolution.NewResolver(config, embedder).Resolve(entities)
+	if err != nil {
+		return nil, helper.NewError("resolve entities", err)
+	}
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+
+	tx, err := g.DB.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
+
+	for _, cluster := range clusters {
+		for _, duplicate := range cluster.Duplicates {
+			if err := g.Entities.MergeEntities(ctx, tx, cluster.Canonical.ID, duplicate.ID); err != nil {
+				return nil, helper.NewError(fmt.Sprintf("merge entity %s into %s", duplicate.Name, cluster.Canonical.Name), err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, helper.NewError("commit transaction", err)
+	}
+
+	g.log.Info("Resolved entities",
+		slog.String("entity_type", entityType),
+		slog.Int("num_entities", len(entities)),
+		slog.Int("num_clusters", len(clusters)))
+
+	return clusters, nil
+}
+
+// SearchEntities finds the entities most related to a query by the similarity of their embeddings
+// Entities are embedded from their name and mention context during ingestion
+func (g *Grapher) SearchEntities(ctx context.Context, query string, limit int, threshold float64) ([]*model.Entity, error) {
+	if g.Pipeline == nil || g.Pipeline.Embedder == nil {
+		return nil, helper.NewError("entity search", fmt.Errorf("pipeline with embedder not set, use SetPipeline() first"))
+	}
+
+	// Generate embedding from query
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
+	if err != nil {
+		return nil, helper.NewError("generate embedding", err)
+	}
+
+	return g.Entities.SelectEntitiesBySimilarity(ctx, embedding, limit, threshold, nil)
+}
+
+// EntitySearch performs entity-centric retrieval starting from the entities most related to the query
+// It uses the top EntityTopK entities (by SearchEntities) as entry points for EntityCentricSearch and
+// weights the chunks of each entity by the entity similarity. A nil config uses DefaultQueryConfig.
+func (g *Grapher) EntitySearch(ctx context.Context, query string, config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+	if g.Engine == nil {
+		return nil, helper.NewError("entity search", fmt.Errorf("retrieval engine not initialized"))
+	}
+	if config == nil {
+		defaultConfig := model.DefaultQueryConfig()
+		config = &defaultConfig
+	}
+
+	entityTopK := config.EntityTopK
+	if entityTopK <= 0 {
+		entityTopK = 3
+	}
+
+	entities, err := g.SearchEntities(ctx, query, entityTopK, config.SimilarityThreshold)
+	if err != nil {
+		return nil, helper.NewError("search entities", err)
+	}
+
+	return g.withRerank(ctx, query, config, func(config *model.QueryConfig) ([]*model.RetrievalResult, error) {
+		resultMap := make(map[string]*model.RetrievalResult)
+		for _, entity := range entities {
+			// Without a similarity the chunks of the entity can't be weighted
+			if entity.Similarity == nil {
+				continue
+			}
+
+			entityResults, err := g.EntityCentricSearch(ctx, entity.ID, config)
+			if err != nil {
+				return nil, helper.NewError(fmt.Sprintf("entity centric search for %s", entity.Name), err)
+			}
+
+			for _, result := range entityResults {
+				result.Score *= *entity.Similarity
+				result.SimilarityScore = *entity.Similarity
+
+				chunkIDStr := result.Chunk.ID.String()
+				if existing, ok := resultMap[chunkIDStr]; !ok || result.Score > existing.Score {
+					resultMap[chunkIDStr] = result
+				}
+			}
+		}
+
+		results := make([]*model.RetrievalResult, 0, len(resultMap))
+		for _, result := range resultMap {
+			results = append(results, result)
+		}
+
+		// Sort by score
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Score > results[j].Score
+		})
+
+		// Limit to top-k
+		if config.TopK > 0 && len(results) > config.TopK {
+			results = results[:config.TopK]
+		}
+
+		return results, nil
+	})
+}
+
+// DetectCommunities groups the stored entities into communities of densely connected entities
+// with the Louvain method over the edges between entities, by default the co-occurrence edges
+// created by the relation extractor. Each community is summarized with the pipeline's
+// CommunitySummarizer (DefaultCommunitySummarizer if not set) and the summary is embedded
+// if the pipeline has an embedder, so GlobalSearch can find it.
+// The detected communities replace the stored ones in one transaction.
+func (g *Grapher) DetectCommunities(ctx context.Context, opts graph.CommunityOptions) ([]*model.Community, error) {
+	edgeTypes := opts.EdgeTypes
+	if len(edgeTypes) == 0 {
+		edgeTypes = []model.EdgeType{model.EdgeTypeEntityMention}
+	}
+
+	edges, err := g.Edges.SelectEntityEdges(ctx, edgeTypes)
+	if err != nil {
+		return nil, helper.NewError("select entity edges", err)
+	}
+
+	summarizer := pipeline.DefaultCommunitySummarizer()
+	if g.Pipeline != nil && g.Pipeline.CommunitySummarizer != nil {
+		summarizer = g.Pipeline.CommunitySummarizer
+	}
+
+	detected := graph.DetectCommunities(edges, opts)
+
+	// Load the members of all communities at once
+	var memberIDs []uuid.UUID
+	for _, community := range detected {
+		memberIDs = append(memberIDs, community.EntityIDs...)
+	}
+	entities, err := g.Entities.SelectEntitiesByIDs(ctx, memberIDs)
+	if err != nil {
+		return nil, helper.NewError("select entities", err)
+	}
+	entitiesByID := make(map[uuid.UUID]*model.Entity, len(entities))
+	for _, entity := range entities {
+		entitiesByID[entity.ID] = entity
+	}
+
+	communities := make([]*model.Community, 0, len(detected))
+	for _, community := range detected {
+		members := make([]*model.Entity, 0, len(community.EntityIDs))
+		for _, entityID := range community.EntityIDs {
+			entity, ok := entitiesByID[entityID]
+			if !ok {
+				return nil, helper.NewError(fmt.Sprintf("select entity %s", entityID), sql.ErrNoRows)
+			}
+			members = append(members, entity)
+		}
+
+		summary, err := summarizer(members, community.Edges)
+		if err != nil {
+			return nil, helper.NewError("summarize community", err)
+		}
+
+		// Title the community by its most connected members
+		names := make([]string, 0, 3)
+		for _, member := range members[:min(len(members), 3)] {
+			names = append(names, member.Name)
+		}
+
+		communities = append(communities, &model.Community{
+			Title:     strings.Join(names, ", "),
+			Summary:   summary,
+			Weight:    community.Weight,
+			EntityIDs: community.EntityIDs,
+			Metadata:  model.Metadata{"num_edges": len(community.Edges)},
+		})
+	}
+
+	if g.Pipeline != nil && (g.Pipeline.Embedder != nil || g.Pipeline.BatchEmbedder != nil) && len(communities) > 0 {
+		summaries := make([]string, len(communities))
+		for i, community := range communities {
+			summaries[i] = community.Summary
+		}
+
+		embeddings, err := g.Pipeline.EmbedBatchCtx(ctx, summaries)
+		if err != nil {
+			return nil, helper.NewError("generate embeddings", err)
+		}
+		for i, community := range communities {
+			community.Embedding = embeddings[i]
+		}
+	}
+
+	tx, err := g.DB.Instance.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, helper.NewError("begin transaction", err)
+	}
+	defer func() {
+		// Rollback is a no-op once the transaction has been committed
+		_ = tx.Rollback()
+	}()
+
+	if _, err := g.Communities.DeleteAllCommunities(ctx, tx); err != nil {
+		return nil, helper.NewError("delete communities", err)
+	}
+
+	for _, community := range communities {
+		if err := g.Communities.InsertCommunity(ctx, tx, community); err != nil {
+			return nil, helper.NewError(fmt.Sprintf("insert community %s", community.Title), err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, helper.NewError("commit transaction", err)
+	}
+
+	g.log.Info("Detected communities",
+		slog.Int("num_edges", len(edges)),
+		slog.Int("num_communities", len(communities)))
+
+	return communities, nil
+}
+
+// GlobalSearch retrieves the community summaries most related to the query (GraphRAG-style global search)
+// It answers questions about the whole corpus, like its main themes, that no single chunk answers.
+// The communities have to be detected with DetectCommunities first.
+func (g *Grapher) GlobalSearch(ctx context.Context, query string, config *model.QueryConfig) ([]*model.CommunityResult, error) {
+	if g.Pipeline == nil || g.Pipeline.Embedder == nil {
+		return nil, helper.NewError("global search", fmt.Errorf("pipeline with embedder not set, use SetPipeline() first"))
+	}
+
+	// Generate embedding from query
+	embedding, err := g.Pipeline.EmbedCtx(ctx, query)
+	if err != nil {
+		return nil, helper.NewError("generate embedding", err)
+	}
+
+	strategy := retrieval.NewCommunityStrategy(g.Communities)
+	return strategy.Retrieve(ctx, embedding, config)
+}
+
+// BFSTraversal performs breadth-first search from a chunk or entity
 func (g *Grapher) BFSTraversal(ctx context.Context, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*retrieval.TraversalResult, error) {
 	return g.Engine.BFS(ctx, sourceID, maxHops, edgeTypes, followBidirectional)
 }
 
-// DFSTraversal performs depth-first search from a chunk
+// DFSTraversal performs depth-first search from a chunk or entity
 func (g *Grapher) DFSTraversal(ctx context.Context, sourceID uuid.UUID, maxHops int, edgeTypes []model.EdgeType, followBidirectional bool) ([]*retrieval.TraversalResult, error) {
 	return g.Engine.DFS(ctx, sourceID, maxHops, edgeTypes, followBidirectional)
 }
 
+// ShortestPath finds the path with the lowest cost between two chunks or entities.
+// The steps of the path contain the followed edges, explaining how the nodes are connected.
+func (g *Grapher) ShortestPath(ctx context.Context, from model.GraphNode, to model.GraphNode, opts model.PathOptions) (*model.Path, error) {
+	return g.Engine.ShortestPath(ctx, from, to, opts)
+}
+
+// KShortestPaths finds up to k loopless paths between two chunks or entities ordered by cost
+func (g *Grapher) KShortestPaths(ctx context.Context, from model.GraphNode, to model.GraphNode, k int, opts model.PathOptions) ([]*model.Path, error) {
+	return g.Engine.KShortestPaths(ctx, from, to, k, opts)
+}
+
+// RebuildSemanticEdges replaces all generated semantic edges by linking every chunk
+// to its nearest neighbours. Returns the number of created edges.
+func (g *Grapher) RebuildSemanticEdges(ctx context.Context, config model.SemanticLinkConfig) (int, error) {
+	created, err := g.Edges.RebuildSemanticEdges(ctx, config)
+	if err != nil {
+		return 0, helper.NewError("rebuild semantic edges", err)
+	}
+
+	g.log.Info("Rebuilt semantic edges", slog.Int("num_semantic_edges", created))
+
+	return created, nil
+}
+
+// PruneSemanticEdges removes weak generated semantic edges, see EdgesDBHandler.PruneSemanticEdges.
+// Returns the number of deleted edges.
+func (g *Grapher) PruneSemanticEdges(ctx context.Context, threshold float64, maxPerChunk int) (int, error) {
+	deleted, err := g.Edges.PruneSemanticEdges(ctx, threshold, maxPerChunk)
+	if err != nil {
+		return 0, helper.NewError("prune semantic edges", err)
+	}
+
+	g.log.Info("Pruned semantic edges", slog.Int("num_deleted", deleted))
+
+	return deleted, nil
+}
+
 // ChangeIndexType changes the vector index type between HNSW and IVFFlat
 func (g *Grapher) ChangeIndexType(ctx context.Context, indexType string, params map[string]interface{}) error {
 	return g.Chunks.ChangeIndexType(ctx, indexType, params)
 }
+
+// ChangeTextSearchConfig changes the language used for keyword search
+func (g *Grapher) ChangeTextSearchConfig(ctx context.Context, textSearchConfig string) error {
+	return g.Chunks.ChangeTextSearchConfig(ctx, textSearchConfig)
+}
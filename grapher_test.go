@@ -2,10 +2,14 @@ package grapher
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/siherrmann/grapher/core/graph"
 	"github.com/siherrmann/grapher/core/pipeline"
+	"github.com/siherrmann/grapher/core/resolution"
 	"github.com/siherrmann/grapher/helper"
 	"github.com/siherrmann/grapher/model"
 	loadSql "github.com/siherrmann/grapher/sql"
@@ -58,7 +62,29 @@ func TestNewGrapher(t *testing.T) {
 		assert.NotNil(t, g.Documents, "Expected grapher to have documents handler")
 		assert.NotNil(t, g.Edges, "Expected grapher to have edges handler")
 		assert.NotNil(t, g.Entities, "Expected grapher to have entities handler")
+		assert.NotNil(t, g.Communities, "Expected grapher to have communities handler")
 		assert.Nil(t, g.Pipeline, "Expected pipeline to be nil initially")
+		assert.NotNil(t, g.Models, "Expected grapher to have a model registry")
+
+		// Cleanup
+		err = g.Close()
+		assert.NoError(t, err, "Expected Close to not return an error")
+	})
+
+	t.Run("Valid call NewGrapherWithOptions", func(t *testing.T) {
+		modelOptions := &helper.ModelOptions{Dir: t.TempDir(), Offline: true}
+		g, err := NewGrapherWithOptions(dbConfig, 384, &GrapherOptions{
+			Models:           modelOptions,
+			TextSearchConfig: "english",
+		})
+		require.NoError(t, err, "Expected NewGrapherWithOptions to not return an error")
+		require.NotNil(t, g.Models, "Expected grapher to have a model registry")
+		assert.Equal(t, modelOptions, g.modelOptions, "Expected the model options to be kept")
+
+		// The model directory is empty and downloads are disabled
+		err = g.UseDefaultPipeline()
+		require.Error(t, err, "Expected the default pipeline to use the model options")
+		assert.Contains(t, err.Error(), "offline mode")
 
 		// Cleanup
 		err = g.Close()
@@ -117,6 +143,7 @@ func TestSetPipeline(t *testing.T) {
 }
 
 func TestProcessAndInsertDocument(t *testing.T) {
+	ctx := context.Background()
 	g := initGrapher(t)
 
 	chunker := pipeline.SentenceChunker(5)
@@ -143,7 +170,7 @@ func TestProcessAndInsertDocument(t *testing.T) {
 		assert.Equal(t, "", doc.Content, "Expected content to be cleared after processing")
 
 		// Cleanup
-		g.Documents.DeleteDocument(doc.RID)
+		g.Documents.DeleteDocument(ctx, doc.RID)
 	})
 
 	t.Run("Error when pipeline not set", func(t *testing.T) {
@@ -194,13 +221,13 @@ func TestProcessAndInsertDocument(t *testing.T) {
 		assert.Greater(t, numChunks, 0, "Expected at least one chunk")
 
 		// Verify document was inserted with metadata
-		retrieved, err := g.Documents.SelectDocument(doc.RID)
+		retrieved, err := g.Documents.SelectDocument(ctx, doc.RID)
 		require.NoError(t, err, "Expected to retrieve document")
 		assert.Equal(t, "Test Author", retrieved.Metadata["author"], "Expected metadata to be preserved")
 		assert.Equal(t, "testing", retrieved.Metadata["topic"], "Expected metadata to be preserved")
 
 		// Cleanup
-		g.Documents.DeleteDocument(doc.RID)
+		g.Documents.DeleteDocument(ctx, doc.RID)
 	})
 
 	t.Run("Process document with long content", func(t *testing.T) {
@@ -222,7 +249,7 @@ func TestProcessAndInsertDocument(t *testing.T) {
 		assert.Greater(t, numChunks, 1, "Expected multiple chunks for long content")
 
 		// Cleanup
-		g.Documents.DeleteDocument(doc.RID)
+		g.Documents.DeleteDocument(ctx, doc.RID)
 	})
 
 	t.Run("Process multiple documents", func(t *testing.T) {
@@ -259,12 +286,604 @@ func TestProcessAndInsertDocument(t *testing.T) {
 
 		// Cleanup
 		for _, doc := range docs {
-			g.Documents.DeleteDocument(doc.RID)
+			g.Documents.DeleteDocument(ctx, doc.RID)
+		}
+	})
+}
+
+func TestProcessAndInsertDocumentRollback(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	// The second chunk has an invalid ltree path and fails on insert
+	failingChunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{
+			{Content: "Valid chunk", Path: basePath + ".chunk0"},
+			{Content: "Invalid chunk", Path: basePath + ".not a valid path!"},
+		}, nil
+	}
+	g.SetPipeline(pipeline.NewPipeline(failingChunker, testEmbedder(384)))
+
+	doc := &model.Document{
+		Title:    "Rollback Document",
+		Source:   "test_rollback",
+		Content:  "Content that fails during chunk insertion.",
+		Metadata: model.Metadata{},
+	}
+
+	numChunks, err := g.ProcessAndInsertDocument(doc)
+
+	assert.Error(t, err, "Expected error when a chunk cannot be inserted")
+	assert.Equal(t, 0, numChunks, "Expected 0 chunks when error occurs")
+
+	existing, err := g.Documents.SelectDocumentBySource(ctx, g.DB.Instance, "test_rollback")
+	assert.NoError(t, err)
+	assert.Nil(t, existing, "Expected document to be rolled back")
+
+	t.Run("Failed document can be inserted again", func(t *testing.T) {
+		assert.Equal(t, "Content that fails during chunk insertion.", doc.Content, "Expected the content to be kept")
+		assert.Equal(t, uuid.Nil, doc.RID, "Expected no RID that was never stored")
+
+		g.SetPipeline(pipeline.NewPipeline(func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+			return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+		}, testEmbedder(384)))
+
+		numChunks, err := g.ProcessAndInsertDocument(doc)
+		require.NoError(t, err, "Expected the retry to succeed")
+		assert.Equal(t, 1, numChunks)
+		assert.Empty(t, doc.Content, "Expected the content to be cleared after the commit")
+		assert.NotEqual(t, uuid.Nil, doc.RID)
+
+		// Cleanup
+		g.Documents.DeleteDocument(ctx, doc.RID)
+	})
+
+	t.Run("Canceled context rolls back the document", func(t *testing.T) {
+		g.SetPipeline(pipeline.NewPipeline(func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+			return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+		}, testEmbedder(384)))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		doc := &model.Document{Title: "Canceled Document", Source: "test_canceled", Content: "Never inserted."}
+		_, err := g.ProcessAndInsertDocumentCtx(ctx, doc)
+
+		assert.ErrorIs(t, err, context.Canceled, "Expected the context error")
+		existing, err := g.Documents.SelectDocumentBySource(ctx, g.DB.Instance, "test_canceled")
+		assert.NoError(t, err)
+		assert.Nil(t, existing, "Expected nothing to be inserted")
+	})
+}
+
+func TestProcessAndInsertDocumentMentionEdges(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{
+			{Content: text, Path: basePath + ".chunk0"},
+			{Content: text, Path: basePath + ".chunk1"},
+		}, nil
+	}
+	p := pipeline.NewPipeline(chunker, testEmbedder(384))
+	p.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+		return []*model.Entity{
+			{
+				Name:     "Mention Test Entity",
+				Type:     "TEST",
+				Metadata: model.Metadata{"start": 0, "end": 19, "confidence": 0.8},
+			},
+		}, nil
+	})
+	g.SetPipeline(p)
+
+	doc := &model.Document{
+		Title:    "Mention Document",
+		Source:   "test_mentions",
+		Content:  "Mention Test Entity is referenced in this document.",
+		Metadata: model.Metadata{},
+	}
+
+	numChunks, err := g.ProcessAndInsertDocument(doc)
+	require.NoError(t, err)
+	require.Greater(t, numChunks, 0)
+
+	entity, err := g.Entities.SelectEntityByName(ctx, "Mention Test Entity", "TEST")
+	require.NoError(t, err)
+
+	chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+	require.NoError(t, err)
+
+	mentions, err := g.Entities.SelectChunksMentioningEntity(ctx, entity.ID)
+	require.NoError(t, err)
+	require.Len(t, mentions, len(chunks), "Expected one mention edge per chunk")
+
+	chunkIDs := make(map[string]bool)
+	for _, chunk := range chunks {
+		chunkIDs[chunk.ID.String()] = true
+	}
+	for _, mention := range mentions {
+		assert.True(t, chunkIDs[mention.ChunkID.String()], "Expected mention to be resolved to an inserted chunk")
+		assert.EqualValues(t, 0, mention.EdgeMetadata["start"], "Expected start offset")
+		assert.EqualValues(t, 19, mention.EdgeMetadata["end"], "Expected end offset")
+		assert.Equal(t, 0.8, mention.EdgeMetadata["confidence"], "Expected confidence")
+	}
+
+	// Cleanup
+	for _, mention := range mentions {
+		g.Edges.DeleteEdge(ctx, mention.EdgeID)
+	}
+	g.Entities.DeleteEntity(ctx, entity.ID)
+	g.Documents.DeleteDocument(ctx, doc.RID)
+}
+
+func TestProcessAndInsertDocumentEntityRemap(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	p := pipeline.NewPipeline(chunker, testEmbedder(384))
+	p.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+		return []*model.Entity{
+			{ID: uuid.New(), Name: "Remap Person", Type: "TEST"},
+			{ID: uuid.New(), Name: "Remap Place", Type: "TEST"},
+		}, nil
+	})
+	// Copy the IDs so the edges don't alias the entities
+	p.SetRelationExtractor(func(text string, chunkID string, entities []*model.Entity) ([]*model.Edge, error) {
+		sourceID := entities[0].ID
+		targetID := entities[1].ID
+		return []*model.Edge{
+			{
+				SourceEntityID: &sourceID,
+				TargetEntityID: &targetID,
+				EdgeType:       model.EdgeTypeEntityMention,
+				Weight:         1.0,
+				Metadata:       model.Metadata{},
+			},
+		}, nil
+	})
+	g.SetPipeline(p)
+
+	doc1 := &model.Document{Title: "Remap 1", Source: "test_remap", Content: "Remap Person lives in Remap Place.", Metadata: model.Metadata{}}
+	doc2 := &model.Document{Title: "Remap 2", Source: "test_remap", Content: "Remap Person visited Remap Place.", Metadata: model.Metadata{}}
+
+	_, err := g.ProcessAndInsertDocument(doc1)
+	require.NoError(t, err)
+	_, err = g.ProcessAndInsertDocument(doc2)
+	require.NoError(t, err)
+
+	person, err := g.Entities.SelectEntityByName(ctx, "Remap Person", "TEST")
+	require.NoError(t, err)
+	place, err := g.Entities.SelectEntityByName(ctx, "Remap Place", "TEST")
+	require.NoError(t, err)
+
+	t.Run("Edges point to the stored entities", func(t *testing.T) {
+		edges, err := g.Edges.SelectEdgesFromEntity(ctx, person.ID, nil)
+		require.NoError(t, err)
+
+		targets := 0
+		for _, edge := range edges {
+			if edge.TargetEntityID != nil && *edge.TargetEntityID == place.ID {
+				targets++
+			}
 		}
+		assert.Equal(t, 2, targets, "Expected edges of both documents to use the stored entity IDs")
 	})
+
+	t.Run("Entity accumulates mentions and documents", func(t *testing.T) {
+		assert.Equal(t, float64(2), person.Metadata["mention_count"], "Expected mentions of both documents")
+		assert.Equal(t, []interface{}{doc1.RID.String(), doc2.RID.String()}, person.Metadata["documents"], "Expected both documents")
+	})
+
+	// Cleanup
+	edges, _ := g.Edges.SelectEdgesFromEntity(ctx, person.ID, nil)
+	for _, edge := range edges {
+		g.Edges.DeleteEdge(ctx, edge.ID)
+	}
+	g.Entities.DeleteEntity(ctx, person.ID)
+	g.Entities.DeleteEntity(ctx, place.ID)
+	g.Documents.DeleteDocument(ctx, doc1.RID)
+	g.Documents.DeleteDocument(ctx, doc2.RID)
+}
+
+func TestProcessAndUpsertDocument(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	p := pipeline.NewPipeline(chunker, testEmbedder(384))
+	p.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+		if strings.Contains(text, "Upsert Person") {
+			return []*model.Entity{{ID: uuid.New(), Name: "Upsert Person", Type: "TEST"}}, nil
+		}
+		return nil, nil
+	})
+	g.SetPipeline(p)
+
+	doc := &model.Document{Title: "Upsert", Source: "test_upsert.txt", Content: "Upsert Person wrote this.", Metadata: model.Metadata{}}
+
+	status, numChunks, err := g.ProcessAndUpsertDocument(doc)
+	require.NoError(t, err, "Expected ProcessAndUpsertDocument to not return an error")
+	rid := doc.RID
+
+	t.Run("New source is inserted", func(t *testing.T) {
+		assert.Equal(t, model.UpsertStatusInserted, status)
+		assert.Equal(t, 1, numChunks)
+		assert.Equal(t, helper.HashContent("Upsert Person wrote this."), doc.ContentHash, "Expected content hash to be set")
+	})
+
+	t.Run("Same content is unchanged", func(t *testing.T) {
+		again := &model.Document{Title: "Upsert", Source: "test_upsert.txt", Content: "Upsert Person wrote this.", Metadata: model.Metadata{}}
+
+		status, numChunks, err := g.ProcessAndUpsertDocument(again)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.UpsertStatusUnchanged, status)
+		assert.Equal(t, 0, numChunks)
+		assert.Equal(t, rid, again.RID, "Expected the stored document")
+
+		chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, rid)
+		require.NoError(t, err)
+		assert.Len(t, chunks, 1, "Expected no duplicate chunks")
+	})
+
+	t.Run("Changed content is rebuilt", func(t *testing.T) {
+		oldChunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, rid)
+		require.NoError(t, err)
+		require.Len(t, oldChunks, 1)
+
+		changed := &model.Document{Title: "Upsert v2", Source: "test_upsert.txt", Content: "Nobody wrote this.", Metadata: model.Metadata{}}
+
+		status, numChunks, err := g.ProcessAndUpsertDocument(changed)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.UpsertStatusUpdated, status)
+		assert.Equal(t, 1, numChunks)
+		assert.Equal(t, rid, changed.RID, "Expected the document to keep its RID")
+
+		chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, rid)
+		require.NoError(t, err)
+		require.Len(t, chunks, 1, "Expected the old chunks to be replaced")
+		assert.Equal(t, "Nobody wrote this.", chunks[0].Content)
+		assert.NotEqual(t, oldChunks[0].ID, chunks[0].ID)
+
+		edges, err := g.Edges.SelectEdgesFromChunk(ctx, oldChunks[0].ID, nil)
+		require.NoError(t, err)
+		assert.Empty(t, edges, "Expected the edges of the old chunks to be deleted")
+
+		_, err = g.Entities.SelectEntityByName(ctx, "Upsert Person", "TEST")
+		assert.Error(t, err, "Expected the orphaned entity to be deleted")
+
+		stored, err := g.Documents.SelectDocument(ctx, rid)
+		require.NoError(t, err)
+		assert.Equal(t, "Upsert v2", stored.Title)
+		assert.Equal(t, helper.HashContent("Nobody wrote this."), stored.ContentHash)
+	})
+
+	t.Run("Error when source is empty", func(t *testing.T) {
+		_, _, err := g.ProcessAndUpsertDocument(&model.Document{Title: "No Source", Content: "Some content"})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "source is empty")
+	})
+
+	// Cleanup
+	g.Documents.DeleteDocument(ctx, rid)
+}
+
+func TestProcessAndUpdateDocument(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	// One chunk per paragraph, counting the embedded chunks
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		var chunks []pipeline.ChunkWithPath
+		for i, paragraph := range strings.Split(text, "\n\n") {
+			index := i
+			chunks = append(chunks, pipeline.ChunkWithPath{
+				Content:    paragraph,
+				Path:       fmt.Sprintf("%s.chunk%d", basePath, i),
+				ChunkIndex: &index,
+			})
+		}
+		return chunks, nil
+	}
+	embedded := 0
+	embedder := testEmbedder(384)
+	g.SetPipeline(pipeline.NewPipeline(chunker, func(text string) ([]float32, error) {
+		embedded++
+		return embedder(text)
+	}))
+
+	doc := &model.Document{
+		Title:    "Incremental",
+		Source:   "test_incremental",
+		Content:  "First paragraph.\n\nSecond paragraph.\n\nThird paragraph.",
+		Metadata: model.Metadata{},
+	}
+	_, err := g.ProcessAndInsertDocument(doc)
+	require.NoError(t, err)
+
+	before, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+	require.NoError(t, err)
+	require.Len(t, before, 3)
+	chunkIDs := make(map[string]uuid.UUID)
+	for _, chunk := range before {
+		chunkIDs[chunk.Content] = chunk.ID
+	}
+
+	// Incoming edge from another document to an unchanged chunk
+	other := &model.Document{Title: "Other", Source: "test_incremental_other", Content: "Other paragraph.", Metadata: model.Metadata{}}
+	_, err = g.ProcessAndInsertDocument(other)
+	require.NoError(t, err)
+	otherChunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, other.RID)
+	require.NoError(t, err)
+	sourceID := otherChunks[0].ID
+	targetID := chunkIDs["Third paragraph."]
+	err = g.Edges.InsertEdge(ctx, g.DB.Instance, &model.Edge{
+		SourceChunkID: &sourceID,
+		TargetChunkID: &targetID,
+		EdgeType:      model.EdgeTypeReference,
+		Weight:        1.0,
+		Metadata:      model.Metadata{},
+	})
+	require.NoError(t, err)
+
+	t.Run("Only changed chunks are embedded", func(t *testing.T) {
+		embedded = 0
+		doc.Content = "Inserted paragraph.\n\nFirst paragraph.\n\nSecond paragraph, edited.\n\nThird paragraph."
+
+		update, err := g.ProcessAndUpdateDocument(doc)
+
+		require.NoError(t, err, "Expected ProcessAndUpdateDocument to not return an error")
+		assert.Equal(t, &model.DocumentUpdate{Kept: 2, Moved: 2, Added: 2, Removed: 1}, update)
+		assert.Equal(t, 2, embedded, "Expected only the new and the edited paragraph to be embedded")
+		assert.Equal(t, helper.HashContent("Inserted paragraph.\n\nFirst paragraph.\n\nSecond paragraph, edited.\n\nThird paragraph."), doc.ContentHash)
+	})
+
+	t.Run("Unchanged chunks keep their IDs and incoming edges", func(t *testing.T) {
+		after, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+		require.NoError(t, err)
+		require.Len(t, after, 4)
+
+		for _, chunk := range after {
+			switch chunk.Content {
+			case "First paragraph.":
+				assert.Equal(t, chunkIDs["First paragraph."], chunk.ID)
+				assert.Equal(t, fmt.Sprintf("doc_%s.chunk1", doc.RID.String()), chunk.Path, "Expected the moved chunk to get the new path")
+			case "Third paragraph.":
+				assert.Equal(t, chunkIDs["Third paragraph."], chunk.ID)
+			}
+		}
+
+		reference := model.EdgeTypeReference
+		edges, err := g.Edges.SelectEdgesToChunk(ctx, targetID, &reference)
+		require.NoError(t, err)
+		assert.Len(t, edges, 1, "Expected the incoming edge to be kept")
+
+		_, err = g.Chunks.SelectChunk(ctx, chunkIDs["Second paragraph."])
+		assert.Error(t, err, "Expected the edited chunk to be replaced")
+	})
+
+	t.Run("Hierarchical edges are rebuilt", func(t *testing.T) {
+		hierarchical := model.EdgeTypeHierarchical
+		edges, err := g.Edges.SelectEdgesFromChunk(ctx, chunkIDs["First paragraph."], &hierarchical)
+		require.NoError(t, err)
+		assert.Len(t, edges, 2, "Expected previous and next sibling edges")
+	})
+
+	t.Run("Update fails if the chunks change during processing", func(t *testing.T) {
+		original := g.Pipeline
+		defer g.SetPipeline(original)
+
+		// The pipeline runs before the transaction, so a concurrent update isn't blocked by it
+		concurrent := true
+		g.SetPipeline(pipeline.NewPipeline(func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+			if concurrent {
+				concurrent = false
+				_, err := g.ProcessAndUpdateDocument(&model.Document{
+					RID:      doc.RID,
+					Title:    doc.Title,
+					Source:   doc.Source,
+					Content:  "Concurrent paragraph.\n\nThird paragraph.",
+					Metadata: model.Metadata{},
+				})
+				require.NoError(t, err, "Expected the concurrent update to not be blocked")
+			}
+			return chunker(text, basePath)
+		}, testEmbedder(384)))
+
+		doc.Content = "First paragraph.\n\nThird paragraph."
+		_, err := g.ProcessAndUpdateDocument(doc)
+
+		require.Error(t, err, "Expected error for chunks changed during processing")
+		assert.Contains(t, err.Error(), "changed during processing")
+
+		after, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+		require.NoError(t, err)
+		assert.Len(t, after, 2, "Expected the chunks of the concurrent update")
+	})
+
+	t.Run("Error for unknown document", func(t *testing.T) {
+		_, err := g.ProcessAndUpdateDocument(&model.Document{RID: uuid.New(), Title: "Unknown", Content: "Content"})
+
+		assert.Error(t, err)
+	})
+
+	// Cleanup
+	g.Documents.DeleteDocument(ctx, doc.RID)
+	g.Documents.DeleteDocument(ctx, other.RID)
+}
+
+func TestProcessAndInsertDocumentSemanticLinking(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	g.SetPipeline(pipeline.NewPipeline(chunker, testEmbedder(384)))
+	g.SetSemanticLinking(&model.SemanticLinkConfig{K: 5, Threshold: 0.99})
+
+	// The test embedder only depends on the text length, so both chunks get the same embedding
+	doc1 := &model.Document{Title: "Semantic 1", Source: "test_semantic", Content: "Semantic linking content A", Metadata: model.Metadata{}}
+	doc2 := &model.Document{Title: "Semantic 2", Source: "test_semantic", Content: "Semantic linking content B", Metadata: model.Metadata{}}
+
+	_, err := g.ProcessAndInsertDocument(doc1)
+	require.NoError(t, err)
+	_, err = g.ProcessAndInsertDocument(doc2)
+	require.NoError(t, err)
+
+	chunks1, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc1.RID)
+	require.NoError(t, err)
+	require.Len(t, chunks1, 1)
+	chunks2, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc2.RID)
+	require.NoError(t, err)
+	require.Len(t, chunks2, 1)
+
+	semantic := model.EdgeTypeSemantic
+
+	t.Run("New chunk is linked to similar chunk of other document", func(t *testing.T) {
+		connections, err := g.Edges.SelectEdgesConnectedToChunk(ctx, chunks2[0].ID, &semantic)
+		require.NoError(t, err)
+
+		linked := false
+		for _, connection := range connections {
+			edge := connection.Edge
+			if edge.SourceChunkID != nil && edge.TargetChunkID != nil &&
+				(*edge.SourceChunkID == chunks1[0].ID || *edge.TargetChunkID == chunks1[0].ID) {
+				linked = true
+				assert.True(t, edge.Bidirectional, "Expected bidirectional semantic edge")
+				assert.InDelta(t, 1.0, edge.Weight, 0.001, "Expected similarity as weight")
+			}
+		}
+		assert.True(t, linked, "Expected semantic edge between the chunks")
+	})
+
+	t.Run("Prune semantic edges", func(t *testing.T) {
+		_, err := g.PruneSemanticEdges(ctx, 1.1, 0)
+		assert.NoError(t, err, "Expected PruneSemanticEdges to not return an error")
+
+		connections, err := g.Edges.SelectEdgesConnectedToChunk(ctx, chunks2[0].ID, &semantic)
+		require.NoError(t, err)
+		assert.Empty(t, connections, "Expected generated semantic edges to be pruned")
+	})
+
+	t.Run("Rebuild semantic edges", func(t *testing.T) {
+		created, err := g.RebuildSemanticEdges(ctx, model.SemanticLinkConfig{K: 5, Threshold: 0.99})
+		assert.NoError(t, err, "Expected RebuildSemanticEdges to not return an error")
+		assert.GreaterOrEqual(t, created, 1, "Expected semantic edges to be recreated")
+
+		connections, err := g.Edges.SelectEdgesConnectedToChunk(ctx, chunks2[0].ID, &semantic)
+		require.NoError(t, err)
+		assert.NotEmpty(t, connections, "Expected semantic edge between the chunks")
+	})
+
+	// Cleanup
+	_, _ = g.PruneSemanticEdges(ctx, 1.1, 0)
+	g.Documents.DeleteDocument(ctx, doc1.RID)
+	g.Documents.DeleteDocument(ctx, doc2.RID)
+}
+
+func TestProcessAndInsertDocumentHierarchyEdges(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+	g.SetPipeline(pipeline.NewPipeline(pipeline.MarkdownChunker(500), testEmbedder(384)))
+
+	doc := &model.Document{
+		Title:    "Hierarchy Document",
+		Source:   "test_hierarchy",
+		Content:  "# Section\n\nFirst paragraph.\n\n## Subsection\n\nSecond paragraph.",
+		Metadata: model.Metadata{},
+	}
+	_, err := g.ProcessAndInsertDocument(doc)
+	require.NoError(t, err)
+
+	chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
+	require.NoError(t, err)
+	byPath := make(map[string]*model.Chunk)
+	for _, chunk := range chunks {
+		byPath[strings.TrimPrefix(chunk.Path, fmt.Sprintf("doc_%s.", doc.RID))] = chunk
+	}
+	section := byPath["sec0"]
+	require.NotNil(t, section, "Expected section summary chunk")
+
+	hierarchical := model.EdgeTypeHierarchical
+
+	t.Run("Section links to its content and subsection", func(t *testing.T) {
+		edges, err := g.Edges.SelectEdgesFromChunk(ctx, section.ID, &hierarchical)
+		require.NoError(t, err)
+
+		targets := make(map[uuid.UUID]interface{})
+		for _, edge := range edges {
+			targets[*edge.TargetChunkID] = edge.Metadata["relation"]
+		}
+		assert.Equal(t, "child", targets[byPath["sec0.chunk0"].ID], "Expected child edge to the content")
+		assert.Equal(t, "child", targets[byPath["sec0.sub0"].ID], "Expected child edge to the subsection")
+	})
+
+	t.Run("BFS moves from content to the next section", func(t *testing.T) {
+		results, err := g.BFSTraversal(context.Background(), byPath["sec0.chunk0"].ID, 1, []model.EdgeType{hierarchical}, false)
+		require.NoError(t, err)
+
+		reached := make(map[uuid.UUID]bool)
+		for _, result := range results {
+			reached[result.Chunk.ID] = true
+		}
+		assert.True(t, reached[section.ID], "Expected parent section to be reached")
+		assert.True(t, reached[byPath["sec0.sub0"].ID], "Expected next sibling to be reached")
+	})
+
+	// Cleanup
+	g.Documents.DeleteDocument(ctx, doc.RID)
+}
+
+func TestChunkMoved(t *testing.T) {
+	index := 1
+	otherIndex := 2
+	chunk := &model.Chunk{Path: "doc.chunk1", ChunkIndex: &index, Metadata: model.Metadata{"level": float64(2)}}
+
+	t.Run("Same position", func(t *testing.T) {
+		assert.False(t, chunkMoved(chunk, pipeline.ChunkWithPath{Path: "doc.chunk1", ChunkIndex: &index, Metadata: map[string]interface{}{"level": 2}}))
+	})
+
+	t.Run("Changed path or index", func(t *testing.T) {
+		assert.True(t, chunkMoved(chunk, pipeline.ChunkWithPath{Path: "doc.chunk2", ChunkIndex: &index, Metadata: map[string]interface{}{"level": 2}}))
+		assert.True(t, chunkMoved(chunk, pipeline.ChunkWithPath{Path: "doc.chunk1", ChunkIndex: &otherIndex, Metadata: map[string]interface{}{"level": 2}}))
+		assert.True(t, chunkMoved(chunk, pipeline.ChunkWithPath{Path: "doc.chunk1", Metadata: map[string]interface{}{"level": 2}}))
+	})
+
+	t.Run("Changed metadata", func(t *testing.T) {
+		assert.True(t, chunkMoved(chunk, pipeline.ChunkWithPath{Path: "doc.chunk1", ChunkIndex: &index, Metadata: map[string]interface{}{"level": 3}}))
+	})
+}
+
+func TestRemapEntityIDs(t *testing.T) {
+	extractedID := uuid.New()
+	storedID := uuid.New()
+	otherID := uuid.New()
+	chunkID := uuid.New()
+
+	edges := []*model.Edge{
+		{SourceEntityID: &extractedID, TargetEntityID: &otherID},
+		{SourceChunkID: &chunkID, TargetEntityID: &extractedID},
+	}
+
+	remapEntityIDs(edges, map[uuid.UUID]uuid.UUID{extractedID: storedID})
+
+	assert.Equal(t, storedID, *edges[0].SourceEntityID, "Expected source to be remapped")
+	assert.Equal(t, otherID, *edges[0].TargetEntityID, "Expected unknown ID to be kept")
+	assert.Equal(t, chunkID, *edges[1].SourceChunkID, "Expected chunk ID to be kept")
+	assert.Equal(t, storedID, *edges[1].TargetEntityID, "Expected target to be remapped")
 }
 
 func TestUseDefaultPipeline(t *testing.T) {
+	ctx := context.Background()
 	g := initGrapher(t)
 
 	t.Run("Sets up default pipeline successfully", func(t *testing.T) {
@@ -276,6 +895,14 @@ func TestUseDefaultPipeline(t *testing.T) {
 		assert.NotNil(t, g.Pipeline.Chunker, "Chunker should be set")
 	})
 
+	t.Run("Models are loaded once and shared", func(t *testing.T) {
+		err := g.UseDefaultPipeline()
+		require.NoError(t, err)
+
+		// One sentence model for chunker and embedder, one NER model for both extractors
+		assert.Equal(t, 2, g.Models.Loaded(), "Expected every model to be loaded once")
+	})
+
 	t.Run("Can process document after setting default pipeline", func(t *testing.T) {
 		err := g.UseDefaultPipeline()
 		require.NoError(t, err)
@@ -292,7 +919,7 @@ func TestUseDefaultPipeline(t *testing.T) {
 		assert.Greater(t, numChunks, 0)
 
 		// Cleanup
-		g.Documents.DeleteDocument(doc.RID)
+		g.Documents.DeleteDocument(ctx, doc.RID)
 	})
 }
 
@@ -331,6 +958,20 @@ func TestSearchMethods(t *testing.T) {
 		assert.LessOrEqual(t, len(results), 5)
 	})
 
+	t.Run("KeywordSearch finds exact terms", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 5
+
+		results, err := g.KeywordSearch(ctx, "Stanford", &config)
+
+		assert.NoError(t, err)
+		require.NotEmpty(t, results)
+		for _, result := range results {
+			assert.Contains(t, result.Chunk.Content, "Stanford")
+			assert.Equal(t, "keyword", result.RetrievalMethod)
+		}
+	})
+
 	t.Run("ContextualSearch includes context", func(t *testing.T) {
 		config := model.DefaultQueryConfig()
 		config.TopK = 3
@@ -379,6 +1020,21 @@ func TestSearchMethods(t *testing.T) {
 		}
 	})
 
+	t.Run("FusionSearch records contributing sources", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 5
+
+		results, err := g.FusionSearch(ctx, "Stanford University", &config)
+
+		assert.NoError(t, err)
+		require.NotEmpty(t, results)
+		assert.LessOrEqual(t, len(results), 5)
+		for _, result := range results {
+			assert.Equal(t, "fusion", result.RetrievalMethod)
+			assert.NotEmpty(t, result.Sources, "Expected fused result to record its sources")
+		}
+	})
+
 	t.Run("DocumentScopedSearch filters by document", func(t *testing.T) {
 		config := model.DefaultQueryConfig()
 		config.TopK = 5
@@ -401,12 +1057,112 @@ func TestSearchMethods(t *testing.T) {
 		assert.Nil(t, results)
 	})
 
+	t.Run("Canceled context stops search and traversal", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+		config := model.DefaultQueryConfig()
+
+		_, err := g.HybridSearch(canceled, "What is artificial intelligence?", &config)
+		assert.ErrorIs(t, err, context.Canceled, "Expected search to return the context error")
+
+		chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc1.RID)
+		require.NoError(t, err)
+		require.NotEmpty(t, chunks)
+
+		_, err = g.BFSTraversal(canceled, chunks[0].ID, 3, nil, true)
+		assert.ErrorIs(t, err, context.Canceled, "Expected traversal to return the context error")
+	})
+
 	// Cleanup
-	g.Documents.DeleteDocument(doc1.RID)
-	g.Documents.DeleteDocument(doc2.RID)
+	g.Documents.DeleteDocument(ctx, doc1.RID)
+	g.Documents.DeleteDocument(ctx, doc2.RID)
+}
+
+func TestSearchWithRerank(t *testing.T) {
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{
+			{Content: "Rerank alpha mentions the widget once.", Path: basePath + ".chunk0"},
+			{Content: "Rerank beta is about the widget and its widget housing.", Path: basePath + ".chunk1"},
+			{Content: "Rerank gamma describes the widget, the preferred answer.", Path: basePath + ".chunk2"},
+		}, nil
+	}
+	p := pipeline.NewPipeline(chunker, testEmbedder(384))
+	g.SetPipeline(p)
+
+	doc := &model.Document{
+		Title:    "Rerank Document",
+		Source:   "test_rerank",
+		Content:  "Content is replaced by the chunker.",
+		Metadata: model.Metadata{},
+	}
+	_, err := g.ProcessAndInsertDocument(doc)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("Error when rerank depth set without reranker", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.RerankDepth = 3
+
+		_, err := g.KeywordSearch(ctx, "widget", &config)
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "no reranker")
+	})
+
+	// Reranker that prefers the chunk containing "preferred"
+	var rerankedDocuments []string
+	p.SetReranker(func(query string, documents []string) ([]float32, error) {
+		rerankedDocuments = documents
+		scores := make([]float32, len(documents))
+		for i, document := range documents {
+			if strings.Contains(document, "preferred") {
+				scores[i] = 10
+			}
+		}
+		return scores, nil
+	})
+
+	t.Run("Rerank rescores candidates before truncating", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.TopK = 1
+		config.RerankDepth = 3
+		config.DocumentRIDs = []uuid.UUID{doc.RID}
+
+		results, err := g.KeywordSearch(ctx, "widget", &config)
+
+		assert.NoError(t, err)
+		assert.Len(t, rerankedDocuments, 3, "Expected rerank depth candidates to be reranked")
+		require.Len(t, results, 1, "Expected results to be truncated to TopK")
+		assert.Contains(t, results[0].Chunk.Content, "preferred")
+		require.NotNil(t, results[0].RerankScore)
+		assert.Equal(t, 10.0, *results[0].RerankScore)
+		assert.Equal(t, 1, config.TopK, "Expected caller config to be unchanged")
+	})
+
+	t.Run("Zero rerank depth skips reranking", func(t *testing.T) {
+		rerankedDocuments = nil
+		config := model.DefaultQueryConfig()
+		config.TopK = 3
+		config.DocumentRIDs = []uuid.UUID{doc.RID}
+
+		results, err := g.KeywordSearch(ctx, "widget", &config)
+
+		assert.NoError(t, err)
+		assert.Nil(t, rerankedDocuments, "Expected reranker to not be called")
+		for _, result := range results {
+			assert.Nil(t, result.RerankScore)
+		}
+	})
+
+	// Cleanup
+	g.Documents.DeleteDocument(ctx, doc.RID)
 }
 
 func TestTraversal(t *testing.T) {
+	ctx := context.Background()
 	g := initGrapher(t)
 	err := g.UseDefaultPipeline()
 	require.NoError(t, err)
@@ -430,11 +1186,10 @@ func TestTraversal(t *testing.T) {
 	require.GreaterOrEqual(t, numChunks, 1) // At least 1 chunk (changed from >1)
 
 	// Get chunks to use as source
-	chunks, err := g.Chunks.SelectAllChunksByDocument(doc.RID)
+	chunks, err := g.Chunks.SelectAllChunksByDocument(ctx, g.DB.Instance, doc.RID)
 	require.NoError(t, err)
 	require.NotEmpty(t, chunks)
 
-	ctx := context.Background()
 	sourceID := chunks[0].ID
 
 	t.Run("BFSTraversal explores graph breadth-first", func(t *testing.T) {
@@ -456,5 +1211,258 @@ func TestTraversal(t *testing.T) {
 	})
 
 	// Cleanup
-	g.Documents.DeleteDocument(doc.RID)
+	g.Documents.DeleteDocument(ctx, doc.RID)
+}
+
+func TestResolveEntities(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	entityType := "RESOLVE_TEST"
+	ibm := &model.Entity{Name: "IBM", Type: entityType, Metadata: model.Metadata{"mention_count": 5}}
+	dotted := &model.Entity{Name: "I.B.M.", Type: entityType, Metadata: model.Metadata{}}
+	long := &model.Entity{Name: "International Business Machines", Type: entityType, Metadata: model.Metadata{}}
+	other := &model.Entity{Name: "Microsoft", Type: entityType, Metadata: model.Metadata{}}
+	for _, entity := range []*model.Entity{ibm, dotted, long, other} {
+		require.NoError(t, g.Entities.InsertEntity(ctx, g.DB.Instance, entity))
+	}
+
+	edge := &model.Edge{
+		SourceEntityID: &dotted.ID,
+		TargetEntityID: &other.ID,
+		EdgeType:       model.EdgeTypeEntityMention,
+		Weight:         1.0,
+		Metadata:       model.Metadata{},
+	}
+	require.NoError(t, g.Edges.InsertEdge(ctx, g.DB.Instance, edge))
+
+	t.Run("Resolve merges aliases into canonical entity", func(t *testing.T) {
+		clusters, err := g.ResolveEntities(ctx, entityType, 100, resolution.DefaultConfig())
+
+		require.NoError(t, err)
+		require.Len(t, clusters, 1, "Expected one cluster")
+		assert.Equal(t, ibm.ID, clusters[0].Canonical.ID, "Expected entity with most mentions to be canonical")
+		assert.Len(t, clusters[0].Duplicates, 2)
+
+		entities, err := g.Entities.SelectEntitiesByType(ctx, entityType, 100)
+		require.NoError(t, err)
+		assert.Len(t, entities, 2, "Expected duplicates to be merged")
+
+		retrievedEdge, err := g.Edges.SelectEdge(ctx, edge.ID)
+		require.NoError(t, err)
+		assert.Equal(t, ibm.ID, *retrievedEdge.SourceEntityID, "Expected edge to be rewired to canonical entity")
+
+		byAlias, err := g.Entities.SelectEntitiesByAlias(ctx, "International Business Machines", &entityType)
+		require.NoError(t, err)
+		require.Len(t, byAlias, 1)
+		assert.Equal(t, ibm.ID, byAlias[0].ID, "Expected merged name to be an alias")
+	})
+
+	t.Run("Resolve without duplicates", func(t *testing.T) {
+		clusters, err := g.ResolveEntities(ctx, entityType, 100, resolution.DefaultConfig())
+
+		assert.NoError(t, err)
+		assert.Empty(t, clusters)
+	})
+
+	// Cleanup
+	g.Edges.DeleteEdge(ctx, edge.ID)
+	g.Entities.DeleteEntity(ctx, ibm.ID)
+	g.Entities.DeleteEntity(ctx, other.ID)
+}
+
+func TestEntitySearch(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	p := pipeline.NewPipeline(chunker, testEmbedder(384))
+	p.SetEntityExtractor(func(text string) ([]*model.Entity, error) {
+		return []*model.Entity{{Name: "Entity Search Person", Type: "ENTITY_SEARCH_TEST"}}, nil
+	})
+	g.SetPipeline(p)
+
+	doc := &model.Document{
+		Title:    "Entity Search Document",
+		Source:   "test_entity_search",
+		Content:  "Entity Search Person wrote this document.",
+		Metadata: model.Metadata{},
+	}
+	_, err := g.ProcessAndInsertDocument(doc)
+	require.NoError(t, err)
+
+	entity, err := g.Entities.SelectEntityByName(ctx, "Entity Search Person", "ENTITY_SEARCH_TEST")
+	require.NoError(t, err)
+
+	// The test embedder only depends on the text length
+	query := pipeline.EntityEmbeddingText(entity.Name, []string{"Entity Search Person wrote this document."})
+
+	t.Run("Search entities", func(t *testing.T) {
+		entities, err := g.SearchEntities(context.Background(), query, 5, 0.99)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, entities, "Expected entity to be found by its embedding")
+		assert.Equal(t, entity.ID, entities[0].ID)
+	})
+
+	t.Run("Entity search returns chunks of related entities", func(t *testing.T) {
+		config := model.DefaultQueryConfig()
+		config.SimilarityThreshold = 0.99
+		config.MaxHops = 0
+
+		results, err := g.EntitySearch(context.Background(), query, &config)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, results, "Expected chunks mentioning the entity")
+		assert.Equal(t, "Entity Search Person wrote this document.", results[0].Chunk.Content)
+	})
+
+	t.Run("Entity search with nil config uses default config", func(t *testing.T) {
+		results, err := g.EntitySearch(context.Background(), query, nil)
+
+		assert.NoError(t, err, "Expected EntitySearch to not return an error")
+		assert.NotEmpty(t, results, "Expected chunks mentioning the entity")
+	})
+
+	t.Run("Search entities with canceled context", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		_, err := g.SearchEntities(canceled, query, 5, 0.99)
+
+		assert.ErrorIs(t, err, context.Canceled, "Expected search to return the context error")
+	})
+
+	t.Run("Search entities without pipeline", func(t *testing.T) {
+		g2 := &Grapher{}
+
+		_, err := g2.SearchEntities(context.Background(), query, 5, 0.5)
+
+		assert.Error(t, err)
+	})
+
+	// Cleanup
+	mentions, _ := g.Entities.SelectChunksMentioningEntity(ctx, entity.ID)
+	for _, mention := range mentions {
+		g.Edges.DeleteEdge(ctx, mention.EdgeID)
+	}
+	g.Entities.DeleteEntity(ctx, entity.ID)
+	g.Documents.DeleteDocument(ctx, doc.RID)
+}
+
+func TestDetectCommunities(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	g.SetPipeline(pipeline.NewPipeline(chunker, testEmbedder(384)))
+
+	// Two groups of co-occurring entities
+	entityType := "COMMUNITY_TEST"
+	var entities []*model.Entity
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave", "Eve"} {
+		entity := &model.Entity{Name: name, Type: entityType, Metadata: model.Metadata{}}
+		require.NoError(t, g.Entities.InsertEntity(ctx, g.DB.Instance, entity))
+		entities = append(entities, entity)
+	}
+
+	var edges []*model.Edge
+	for _, pair := range [][2]int{{0, 1}, {1, 2}, {0, 2}, {3, 4}} {
+		edge := &model.Edge{
+			SourceEntityID: &entities[pair[0]].ID,
+			TargetEntityID: &entities[pair[1]].ID,
+			EdgeType:       model.EdgeTypeEntityMention,
+			Weight:         1.0,
+			Bidirectional:  true,
+			Metadata:       model.Metadata{},
+		}
+		require.NoError(t, g.Edges.InsertEdge(ctx, g.DB.Instance, edge))
+		edges = append(edges, edge)
+	}
+
+	// communityOf finds the community containing the entity
+	communityOf := func(communities []*model.Community, entityID uuid.UUID) *model.Community {
+		for _, community := range communities {
+			for _, memberID := range community.EntityIDs {
+				if memberID == entityID {
+					return community
+				}
+			}
+		}
+		return nil
+	}
+
+	t.Run("Detect and store communities", func(t *testing.T) {
+		communities, err := g.DetectCommunities(context.Background(), graph.DefaultCommunityOptions())
+
+		require.NoError(t, err, "Expected DetectCommunities to not return an error")
+		first := communityOf(communities, entities[0].ID)
+		second := communityOf(communities, entities[3].ID)
+		require.NotNil(t, first)
+		require.NotNil(t, second)
+		assert.ElementsMatch(t, []uuid.UUID{entities[0].ID, entities[1].ID, entities[2].ID}, first.EntityIDs)
+		assert.ElementsMatch(t, []uuid.UUID{entities[3].ID, entities[4].ID}, second.EntityIDs)
+		assert.Contains(t, first.Summary, "Community of 3 entities")
+		assert.NotEmpty(t, first.Embedding, "Expected summary to be embedded")
+
+		stored, err := g.Communities.SelectCommunity(ctx, first.ID)
+		require.NoError(t, err)
+		assert.Equal(t, first.Summary, stored.Summary)
+		assert.ElementsMatch(t, first.EntityIDs, stored.EntityIDs)
+	})
+
+	t.Run("Custom summarizer", func(t *testing.T) {
+		g.Pipeline.SetCommunitySummarizer(func(entities []*model.Entity, relations []*model.Edge) (string, error) {
+			return fmt.Sprintf("%d members", len(entities)), nil
+		})
+		defer g.Pipeline.SetCommunitySummarizer(nil)
+
+		communities, err := g.DetectCommunities(context.Background(), graph.DefaultCommunityOptions())
+
+		require.NoError(t, err)
+		second := communityOf(communities, entities[3].ID)
+		require.NotNil(t, second)
+		assert.Equal(t, "2 members", second.Summary)
+
+		all, err := g.Communities.SelectAllCommunities(ctx)
+		require.NoError(t, err)
+		assert.Len(t, all, len(communities), "Expected the previous communities to be replaced")
+	})
+
+	t.Run("Global search returns community summaries", func(t *testing.T) {
+		communities, err := g.DetectCommunities(context.Background(), graph.DefaultCommunityOptions())
+		require.NoError(t, err)
+		first := communityOf(communities, entities[0].ID)
+		require.NotNil(t, first)
+
+		// The test embedder only depends on the text length
+		config := model.DefaultQueryConfig()
+		results, err := g.GlobalSearch(context.Background(), first.Summary, &config)
+
+		require.NoError(t, err)
+		require.NotEmpty(t, results, "Expected community summaries")
+		assert.Equal(t, "community", results[0].RetrievalMethod)
+		assert.InDelta(t, 1.0, results[0].Score, 0.001)
+	})
+
+	t.Run("Global search without pipeline", func(t *testing.T) {
+		g2 := &Grapher{}
+
+		_, err := g2.GlobalSearch(context.Background(), "main themes", &model.QueryConfig{})
+
+		assert.Error(t, err)
+	})
+
+	// Cleanup
+	g.Communities.DeleteAllCommunities(ctx, g.DB.Instance)
+	for _, edge := range edges {
+		g.Edges.DeleteEdge(ctx, edge.ID)
+	}
+	for _, entity := range entities {
+		g.Entities.DeleteEntity(ctx, entity.ID)
+	}
 }
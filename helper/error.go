@@ -16,6 +16,12 @@ func (e Error) Error() string {
 	return e.Original.Error() + " | Trace: " + fmt.Sprint(strings.Join(e.Trace, ", "))
 }
 
+// Unwrap returns the original error, so errors.Is and errors.As see through the trace
+// (e.g. errors.Is(err, context.Canceled) for a canceled query)
+func (e Error) Unwrap() error {
+	return e.Original
+}
+
 func NewError(trace string, original error) Error {
 	pc, _, _, ok := runtime.Caller(1)
 	details := runtime.FuncForPC(pc)
@@ -1,6 +1,7 @@
 package helper
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"testing"
@@ -96,6 +97,13 @@ func TestNewError(t *testing.T) {
 		assert.Contains(t, result.Trace[2], "starting application", "third trace should contain third message")
 	})
 
+	t.Run("Unwrap original error", func(t *testing.T) {
+		result := NewError("querying chunks", NewError("scan", context.Canceled))
+
+		assert.ErrorIs(t, result, context.Canceled, "errors.Is should find the original error")
+		assert.Equal(t, context.Canceled, errors.Unwrap(result))
+	})
+
 	t.Run("Function name extraction", func(t *testing.T) {
 		originalErr := errors.New("test error")
 		trace := "test operation"
@@ -0,0 +1,19 @@
+package helper
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashContent(t *testing.T) {
+	t.Run("Known hash", func(t *testing.T) {
+		assert.Equal(t, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", HashContent(""))
+	})
+
+	t.Run("Same content same hash", func(t *testing.T) {
+		assert.Equal(t, HashContent("some content"), HashContent("some content"))
+		assert.NotEqual(t, HashContent("some content"), HashContent("other content"))
+		assert.Len(t, HashContent("some content"), 64)
+	})
+}
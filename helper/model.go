@@ -1,16 +1,99 @@
 package helper
 
 import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/knights-analytics/hugot"
 )
 
-// PrepareModel downloads the model if it doesn't exist and returns the model path
+// DefaultModelDir is the directory models are stored in if no directory is configured
+const DefaultModelDir = "./models"
+
+// ModelManifestFile is the name of the manifest with the SHA-256 checksums of the model files.
+// It uses the sha256sum format ("<hex checksum>  <path relative to the model directory>").
+// The manifest is written from the downloaded files (trust on first use), so it detects files
+// that changed or got corrupted after the download, but not a download that was tampered with.
+// Only checksums configured in ModelOptions.Checksums pin the expected content.
+const ModelManifestFile = "checksums.sha256"
+
+// verifiedFile is the state of a model file when its checksum was verified
+type verifiedFile struct {
+	checksum string
+	size     int64
+	modTime  time.Time
+}
+
+// verifiedFiles caches the verified model files by path, so a file is only hashed
+// again in the same process if its size or modification time changed
+var verifiedFiles sync.Map
+
+// ModelOptions configures where models are stored and how they are loaded
+type ModelOptions struct {
+	// Directory the models are stored in, defaults to DefaultModelDir
+	Dir string
+	// Offline fails with an error instead of downloading missing models
+	Offline bool
+	// Expected SHA-256 checksums (hex) of model files by model name and file path
+	// relative to the model directory, e.g. {"org/model": {"model.onnx": "ab12..."}}.
+	// Unlike the manifest written on download, they also detect a tampered download.
+	Checksums map[string]map[string]string
+	// RequireChecksums fails loading a model without manifest or configured checksums
+	RequireChecksums bool
+}
+
+// NewModelOptions creates model options from the environment variables GRAPHER_MODEL_DIR
+// (default "./models") and GRAPHER_MODEL_OFFLINE (a boolean like "true" or "1" disables downloads)
+func NewModelOptions() (*ModelOptions, error) {
+	dir := strings.TrimSpace(os.Getenv("GRAPHER_MODEL_DIR"))
+	if dir == "" {
+		dir = DefaultModelDir
+	}
+
+	offline := false
+	if value := strings.TrimSpace(os.Getenv("GRAPHER_MODEL_OFFLINE")); value != "" {
+		var err error
+		offline, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GRAPHER_MODEL_OFFLINE value %q: %w", value, err)
+		}
+	}
+
+	return &ModelOptions{
+		Dir:     dir,
+		Offline: offline,
+	}, nil
+}
+
+// PrepareModel downloads the model if it doesn't exist and returns the model path.
+// It uses the options from the environment, see NewModelOptions.
 func PrepareModel(modelName string, onnxFilePath string) (string, error) {
-	modelDir := "./models"
+	options, err := NewModelOptions()
+	if err != nil {
+		return "", err
+	}
+	return options.PrepareModel(modelName, onnxFilePath)
+}
+
+// PrepareModel returns the path of the model in the model directory and downloads it if it doesn't exist
+// (unless offline). A downloaded model gets a manifest with the checksum of its ONNX file.
+// The files listed in the manifest and the configured checksums are verified, a file is only
+// hashed once per process unless its size or modification time changes.
+func (o *ModelOptions) PrepareModel(modelName string, onnxFilePath string) (string, error) {
+	modelDir := o.Dir
+	if modelDir == "" {
+		modelDir = DefaultModelDir
+	}
 
 	// Sanitize model name for directory (replace / with _)
 	sanitizedName := filepath.Base(modelName)
@@ -21,6 +104,9 @@ func PrepareModel(modelName string, onnxFilePath string) (string, error) {
 
 	// Check if model exists, if not download it
 	if _, err := os.Stat(modelPath); os.IsNotExist(err) {
+		if o.Offline {
+			return "", fmt.Errorf("model %s not found in %s and offline mode is enabled, copy the model there or disable GRAPHER_MODEL_OFFLINE", modelName, modelDir)
+		}
 		if err := os.MkdirAll(modelDir, 0750); err != nil {
 			return "", fmt.Errorf("failed to create model directory: %w", err)
 		}
@@ -33,7 +119,150 @@ func PrepareModel(modelName string, onnxFilePath string) (string, error) {
 			return "", fmt.Errorf("failed to download model: %w", err)
 		}
 		modelPath = downloadedPath
+
+		if onnxFilePath != "" {
+			if err := WriteModelManifest(modelPath, []string{onnxFilePath}); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	if err := o.verifyModel(modelName, modelPath); err != nil {
+		return "", err
 	}
 
 	return modelPath, nil
 }
+
+// verifyModel checks the model files against the manifest and the configured checksums
+func (o *ModelOptions) verifyModel(modelName string, modelPath string) error {
+	manifest, err := ReadModelManifest(modelPath)
+	if err != nil {
+		return err
+	}
+
+	expected := make(map[string]string, len(manifest))
+	for file, checksum := range manifest {
+		expected[file] = checksum
+	}
+	for file, checksum := range o.Checksums[modelName] {
+		if manifestChecksum, ok := expected[file]; ok && !strings.EqualFold(manifestChecksum, checksum) {
+			return fmt.Errorf("checksum of %s in the manifest of model %s doesn't match the configured checksum", file, modelName)
+		}
+		expected[file] = checksum
+	}
+
+	if len(expected) == 0 {
+		if o.RequireChecksums {
+			return fmt.Errorf("model %s has no %s manifest and no configured checksums", modelName, ModelManifestFile)
+		}
+		return nil
+	}
+
+	files := make([]string, 0, len(expected))
+	for file := range expected {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		err := verifyFile(filepath.Join(modelPath, filepath.FromSlash(file)), expected[file])
+		if err != nil {
+			return fmt.Errorf("failed to verify %s of model %s: %w", file, modelName, err)
+		}
+	}
+
+	return nil
+}
+
+// verifyFile compares the checksum of a file with the expected checksum,
+// skipping the hashing if the unchanged file was verified before
+func verifyFile(filePath string, expected string) error {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return err
+	}
+
+	if cached, ok := verifiedFiles.Load(filePath); ok {
+		verified := cached.(verifiedFile)
+		if strings.EqualFold(verified.checksum, expected) && verified.size == info.Size() && verified.modTime.Equal(info.ModTime()) {
+			return nil
+		}
+	}
+
+	checksum, err := FileChecksum(filePath)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(checksum, expected) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, checksum)
+	}
+
+	verifiedFiles.Store(filePath, verifiedFile{checksum: checksum, size: info.Size(), modTime: info.ModTime()})
+	return nil
+}
+
+// FileChecksum returns the hex encoded SHA-256 checksum of a file
+func FileChecksum(filePath string) (string, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// WriteModelManifest writes the checksums of the given files (relative to the model directory) to the manifest
+func WriteModelManifest(modelPath string, files []string) error {
+	var manifest strings.Builder
+	for _, file := range files {
+		checksum, err := FileChecksum(filepath.Join(modelPath, filepath.FromSlash(file)))
+		if err != nil {
+			return fmt.Errorf("failed to compute checksum of %s: %w", file, err)
+		}
+		fmt.Fprintf(&manifest, "%s  %s\n", checksum, filepath.ToSlash(file))
+	}
+
+	if err := os.WriteFile(filepath.Join(modelPath, ModelManifestFile), []byte(manifest.String()), 0640); err != nil {
+		return fmt.Errorf("failed to write model manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadModelManifest reads the checksums by file path from the manifest of a model.
+// Returns an empty map if the model has no manifest.
+func ReadModelManifest(modelPath string) (map[string]string, error) {
+	file, err := os.Open(filepath.Join(modelPath, ModelManifestFile))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open model manifest: %w", err)
+	}
+	defer file.Close()
+
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		checksum, file, ok := strings.Cut(text, " ")
+		file = strings.TrimPrefix(strings.TrimSpace(file), "*")
+		if !ok || file == "" || len(checksum) != sha256.Size*2 {
+			return nil, fmt.Errorf("invalid model manifest line %d: %q", line, text)
+		}
+		checksums[file] = checksum
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read model manifest: %w", err)
+	}
+
+	return checksums, nil
+}
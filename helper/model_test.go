@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -146,3 +147,176 @@ func TestPrepareModel(t *testing.T) {
 		assert.NotEmpty(t, path, "Expected model path to be returned")
 	})
 }
+
+func TestNewModelOptions(t *testing.T) {
+	t.Run("Default options", func(t *testing.T) {
+		t.Setenv("GRAPHER_MODEL_DIR", "")
+		t.Setenv("GRAPHER_MODEL_OFFLINE", "")
+
+		options, err := NewModelOptions()
+		require.NoError(t, err)
+		assert.Equal(t, DefaultModelDir, options.Dir)
+		assert.False(t, options.Offline)
+	})
+
+	t.Run("Options from environment", func(t *testing.T) {
+		t.Setenv("GRAPHER_MODEL_DIR", "/opt/models")
+		t.Setenv("GRAPHER_MODEL_OFFLINE", "true")
+
+		options, err := NewModelOptions()
+		require.NoError(t, err)
+		assert.Equal(t, "/opt/models", options.Dir)
+		assert.True(t, options.Offline)
+	})
+
+	t.Run("Offline values are parsed as booleans", func(t *testing.T) {
+		for value, offline := range map[string]bool{"1": true, "TRUE": true, "false": false, "0": false} {
+			t.Setenv("GRAPHER_MODEL_OFFLINE", value)
+
+			options, err := NewModelOptions()
+			require.NoError(t, err, "Expected %q to be parsed", value)
+			assert.Equal(t, offline, options.Offline, "Expected offline for %q to be %v", value, offline)
+		}
+	})
+
+	t.Run("Invalid offline value", func(t *testing.T) {
+		t.Setenv("GRAPHER_MODEL_OFFLINE", "yes please")
+
+		_, err := NewModelOptions()
+		require.Error(t, err, "Expected error for invalid offline value")
+		assert.Contains(t, err.Error(), "GRAPHER_MODEL_OFFLINE")
+	})
+}
+
+func TestModelOptionsPrepareModel(t *testing.T) {
+	// writeModel creates a mock model with an onnx file in the directory
+	writeModel := func(t *testing.T, dir string, sanitizedName string) string {
+		modelPath := filepath.Join(dir, sanitizedName)
+		require.NoError(t, os.MkdirAll(filepath.Join(modelPath, "onnx"), 0750))
+		require.NoError(t, os.WriteFile(filepath.Join(modelPath, "onnx", "model.onnx"), []byte("onnx model"), 0640))
+		return modelPath
+	}
+
+	t.Run("Use configured model directory", func(t *testing.T) {
+		dir := t.TempDir()
+		modelPath := writeModel(t, dir, "test_custom-dir")
+
+		path, err := (&ModelOptions{Dir: dir}).PrepareModel("test/custom-dir", "onnx/model.onnx")
+		assert.NoError(t, err, "Expected PrepareModel to not return an error")
+		assert.Equal(t, modelPath, path, "Expected path in the configured directory")
+	})
+
+	t.Run("Offline mode fails for missing model", func(t *testing.T) {
+		dir := t.TempDir()
+
+		_, err := (&ModelOptions{Dir: dir, Offline: true}).PrepareModel("test/missing-model", "model.onnx")
+		require.Error(t, err, "Expected error for missing model in offline mode")
+		assert.Contains(t, err.Error(), "offline mode")
+		assert.Contains(t, err.Error(), dir)
+		assert.NoDirExists(t, filepath.Join(dir, "test_missing-model"), "Expected nothing to be downloaded")
+	})
+
+	t.Run("Offline mode uses existing model", func(t *testing.T) {
+		dir := t.TempDir()
+		modelPath := writeModel(t, dir, "test_offline-model")
+
+		path, err := (&ModelOptions{Dir: dir, Offline: true}).PrepareModel("test/offline-model", "onnx/model.onnx")
+		assert.NoError(t, err, "Expected existing model to load in offline mode")
+		assert.Equal(t, modelPath, path)
+	})
+
+	t.Run("Verify manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		modelPath := writeModel(t, dir, "test_manifest-model")
+		require.NoError(t, WriteModelManifest(modelPath, []string{"onnx/model.onnx"}))
+
+		options := &ModelOptions{Dir: dir, Offline: true}
+		_, err := options.PrepareModel("test/manifest-model", "onnx/model.onnx")
+		assert.NoError(t, err, "Expected unchanged model to match the manifest")
+
+		require.NoError(t, os.WriteFile(filepath.Join(modelPath, "onnx", "model.onnx"), []byte("corrupted"), 0640))
+		_, err = options.PrepareModel("test/manifest-model", "onnx/model.onnx")
+		require.Error(t, err, "Expected error for corrupted model")
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("Unchanged files are only hashed once", func(t *testing.T) {
+		dir := t.TempDir()
+		modelPath := writeModel(t, dir, "test_cached-model")
+		onnxPath := filepath.Join(modelPath, "onnx", "model.onnx")
+		require.NoError(t, WriteModelManifest(modelPath, []string{"onnx/model.onnx"}))
+
+		options := &ModelOptions{Dir: dir, Offline: true}
+		_, err := options.PrepareModel("test/cached-model", "onnx/model.onnx")
+		require.NoError(t, err)
+
+		// Same size and modification time, so the verified checksum is reused
+		info, err := os.Stat(onnxPath)
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(onnxPath, []byte("onnx other"), 0640))
+		require.NoError(t, os.Chtimes(onnxPath, info.ModTime(), info.ModTime()))
+		_, err = options.PrepareModel("test/cached-model", "onnx/model.onnx")
+		assert.NoError(t, err, "Expected the unchanged file not to be hashed again")
+
+		// A new modification time hashes the file again
+		later := info.ModTime().Add(time.Minute)
+		require.NoError(t, os.Chtimes(onnxPath, later, later))
+		_, err = options.PrepareModel("test/cached-model", "onnx/model.onnx")
+		require.Error(t, err, "Expected the changed file to be hashed again")
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("Verify configured checksums", func(t *testing.T) {
+		dir := t.TempDir()
+		modelPath := writeModel(t, dir, "test_checksum-model")
+		checksum, err := FileChecksum(filepath.Join(modelPath, "onnx", "model.onnx"))
+		require.NoError(t, err)
+
+		options := &ModelOptions{Dir: dir, Checksums: map[string]map[string]string{
+			"test/checksum-model": {"onnx/model.onnx": checksum},
+		}}
+		_, err = options.PrepareModel("test/checksum-model", "onnx/model.onnx")
+		assert.NoError(t, err, "Expected model to match the configured checksum")
+
+		options.Checksums["test/checksum-model"]["onnx/model.onnx"] = HashContent("other")
+		_, err = options.PrepareModel("test/checksum-model", "onnx/model.onnx")
+		require.Error(t, err, "Expected error for wrong checksum")
+		assert.Contains(t, err.Error(), "checksum mismatch")
+	})
+
+	t.Run("Require checksums", func(t *testing.T) {
+		dir := t.TempDir()
+		writeModel(t, dir, "test_unverified-model")
+
+		_, err := (&ModelOptions{Dir: dir, RequireChecksums: true}).PrepareModel("test/unverified-model", "onnx/model.onnx")
+		require.Error(t, err, "Expected error for model without checksums")
+		assert.Contains(t, err.Error(), ModelManifestFile)
+	})
+}
+
+func TestReadModelManifest(t *testing.T) {
+	t.Run("Missing manifest", func(t *testing.T) {
+		checksums, err := ReadModelManifest(t.TempDir())
+		assert.NoError(t, err)
+		assert.Empty(t, checksums)
+	})
+
+	t.Run("Parse sha256sum format", func(t *testing.T) {
+		dir := t.TempDir()
+		checksum := HashContent("model")
+		manifest := "# comment\n" + checksum + "  onnx/model.onnx\n" + checksum + " *tokenizer.json\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ModelManifestFile), []byte(manifest), 0640))
+
+		checksums, err := ReadModelManifest(dir)
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{"onnx/model.onnx": checksum, "tokenizer.json": checksum}, checksums)
+	})
+
+	t.Run("Invalid line", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, ModelManifestFile), []byte("abc model.onnx\n"), 0640))
+
+		_, err := ReadModelManifest(dir)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,287 @@
+package grapher
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/siherrmann/grapher/core/loader"
+	"github.com/siherrmann/grapher/helper"
+	"github.com/siherrmann/grapher/model"
+)
+
+// DefaultIngestWorkers is the number of documents processed in parallel if IngestOptions.Workers is not set
+const DefaultIngestWorkers = 4
+
+// IngestOptions configures IngestDirectory
+type IngestOptions struct {
+	// Include and Exclude are glob patterns matched against the slash separated path relative to the root.
+	// Patterns without a slash match the file name in any directory, "**" matches any number of directories.
+	// Without Include patterns all files with a registered loader are ingested.
+	// Excluded directories are not walked.
+	Include []string
+	Exclude []string
+	// Number of documents processed in parallel, defaults to DefaultIngestWorkers.
+	// The pipeline functions must be safe for concurrent use if it is greater than 1.
+	Workers int
+	// Metadata added to every document
+	Metadata model.Metadata
+	// Optional callback called after every file, calls are never concurrent
+	Progress func(IngestProgress)
+}
+
+// IngestProgress reports the result of one file
+type IngestProgress struct {
+	Path    string             // Path of the file
+	Done    int                // Number of processed files including this one
+	Total   int                // Number of files to process
+	Chunks  int                // Number of inserted chunks
+	Status  model.UpsertStatus // Whether the document was inserted, updated or unchanged
+	Skipped bool               // True if the file has no text content
+	Err     error              // Error if the file failed
+	Doc     *model.Document    // Upserted document, nil if the file failed or was skipped
+}
+
+// IngestSummary summarizes an IngestDirectory run
+type IngestSummary struct {
+	Total     int              `json:"total"`
+	Succeeded int              `json:"succeeded"` // Inserted, updated and unchanged documents
+	Updated   int              `json:"updated"`
+	Unchanged int              `json:"unchanged"`
+	Skipped   int              `json:"skipped"`
+	Failed    int              `json:"failed"`
+	Chunks    int              `json:"chunks"`
+	Errors    map[string]error `json:"-"` // Errors by file path
+	Duration  time.Duration    `json:"duration"`
+}
+
+// IngestDirectory loads and ingests all files below root matching the options with a bounded worker pool.
+// Every file is loaded with the loader registered for its extension and upserted with ProcessAndUpsertDocument,
+// so a failing file is rolled back on its own and doesn't stop the others.
+// Ingesting a directory again only rebuilds the files whose content changed.
+// Canceling the context stops starting new files, cancels the files in progress (they are rolled back and
+// reported as failed) and returns the summary so far with the context error.
+func (g *Grapher) IngestDirectory(ctx context.Context, root string, opts IngestOptions) (*IngestSummary, error) {
+	start := time.Now()
+
+	if g.Pipeline == nil {
+		return nil, helper.NewError("ingest directory", fmt.Errorf("pipeline not set, use SetPipeline() first"))
+	}
+	for _, pattern := range append(append([]string{}, opts.Include...), opts.Exclude...) {
+		if err := validateGlob(pattern); err != nil {
+			return nil, helper.NewError("validate pattern", err)
+		}
+	}
+
+	files, err := collectFiles(root, opts.Include, opts.Exclude)
+	if err != nil {
+		return nil, helper.NewError("walk directory", err)
+	}
+
+	summary := &IngestSummary{
+		Total:  len(files),
+		Errors: make(map[string]error),
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = DefaultIngestWorkers
+	}
+	workers = min(workers, max(len(files), 1))
+
+	var mu sync.Mutex
+	report := func(progress IngestProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case progress.Err != nil:
+			summary.Failed++
+			summary.Errors[progress.Path] = progress.Err
+		case progress.Skipped:
+			summary.Skipped++
+		default:
+			summary.Succeeded++
+			summary.Chunks += progress.Chunks
+			switch progress.Status {
+			case model.UpsertStatusUpdated:
+				summary.Updated++
+			case model.UpsertStatusUnchanged:
+				summary.Unchanged++
+			}
+		}
+		progress.Done = summary.Succeeded + summary.Skipped + summary.Failed
+		progress.Total = summary.Total
+
+		if opts.Progress != nil {
+			opts.Progress(progress)
+		}
+	}
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for filePath := range jobs {
+				report(g.ingestFile(ctx, filePath, opts.Metadata))
+			}
+		}()
+	}
+
+dispatch:
+	for _, filePath := range files {
+		if ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break dispatch
+		case jobs <- filePath:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary.Duration = time.Since(start)
+
+	g.log.Info("Ingested directory",
+		slog.String("root", root),
+		slog.Int("total", summary.Total),
+		slog.Int("succeeded", summary.Succeeded),
+		slog.Int("updated", summary.Updated),
+		slog.Int("unchanged", summary.Unchanged),
+		slog.Int("skipped", summary.Skipped),
+		slog.Int("failed", summary.Failed),
+		slog.Int("num_chunks", summary.Chunks),
+		slog.Duration("duration", summary.Duration))
+
+	if err := ctx.Err(); err != nil {
+		return summary, helper.NewError("ingest directory", err)
+	}
+
+	return summary, nil
+}
+
+// ingestFile loads and upserts one file
+func (g *Grapher) ingestFile(ctx context.Context, filePath string, metadata model.Metadata) IngestProgress {
+	progress := IngestProgress{Path: filePath}
+
+	doc, err := loader.LoadFile(filePath, metadata)
+	if err != nil {
+		progress.Err = err
+		return progress
+	}
+	if strings.TrimSpace(doc.Content) == "" {
+		progress.Skipped = true
+		return progress
+	}
+
+	progress.Status, progress.Chunks, err = g.ProcessAndUpsertDocumentCtx(ctx, doc)
+	if err != nil {
+		progress.Err = err
+		return progress
+	}
+
+	progress.Doc = doc
+	return progress
+}
+
+// collectFiles walks root and returns the files to ingest in lexical order
+func collectFiles(root string, include []string, exclude []string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if matchAnyGlob(exclude, rel) {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if entry.IsDir() || !entry.Type().IsRegular() {
+			return nil
+		}
+
+		if len(include) > 0 {
+			if matchAnyGlob(include, rel) {
+				files = append(files, filePath)
+			}
+		} else if _, ok := loader.ForExtension(filepath.Ext(filePath)); ok {
+			files = append(files, filePath)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// matchAnyGlob reports whether the slash separated relative path matches one of the patterns
+func matchAnyGlob(patterns []string, rel string) bool {
+	for _, pattern := range patterns {
+		if matchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchGlob matches a relative path against a glob pattern. Patterns without a slash match
+// the last path element, "**" matches zero or more path elements.
+func matchGlob(pattern string, rel string) bool {
+	pattern = strings.Trim(pattern, "/")
+	if !strings.Contains(pattern, "/") {
+		matched, _ := path.Match(pattern, path.Base(rel))
+		return matched
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// matchSegments matches path elements against pattern elements
+func matchSegments(pattern []string, elements []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(elements); i++ {
+				if matchSegments(pattern[1:], elements[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elements) == 0 {
+			return false
+		}
+		if matched, _ := path.Match(pattern[0], elements[0]); !matched {
+			return false
+		}
+		pattern, elements = pattern[1:], elements[1:]
+	}
+	return len(elements) == 0
+}
+
+// validateGlob checks the syntax of a glob pattern
+func validateGlob(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package grapher
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/siherrmann/grapher/core/pipeline"
+	"github.com/siherrmann/grapher/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFiles creates the files (relative path to content) below a temporary directory
+func writeTestFiles(t *testing.T, files map[string]string) string {
+	root := t.TempDir()
+	for rel, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(rel))
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+	return root
+}
+
+func TestMatchGlob(t *testing.T) {
+	t.Run("Pattern without slash matches file name", func(t *testing.T) {
+		assert.True(t, matchGlob("*.md", "README.md"))
+		assert.True(t, matchGlob("*.md", "docs/guide/intro.md"))
+		assert.False(t, matchGlob("*.md", "docs/intro.txt"))
+	})
+
+	t.Run("Pattern with slash matches relative path", func(t *testing.T) {
+		assert.True(t, matchGlob("docs/*.md", "docs/intro.md"))
+		assert.False(t, matchGlob("docs/*.md", "docs/guide/intro.md"))
+	})
+
+	t.Run("Double star matches any number of directories", func(t *testing.T) {
+		assert.True(t, matchGlob("docs/**/*.md", "docs/intro.md"))
+		assert.True(t, matchGlob("docs/**/*.md", "docs/a/b/intro.md"))
+		assert.True(t, matchGlob("vendor/**", "vendor"))
+		assert.True(t, matchGlob("vendor/**", "vendor/pkg/file.txt"))
+		assert.False(t, matchGlob("docs/**/*.md", "other/intro.md"))
+	})
+}
+
+func TestCollectFiles(t *testing.T) {
+	root := writeTestFiles(t, map[string]string{
+		"a.txt":              "a",
+		"b.md":               "b",
+		"image.png":          "png",
+		"docs/c.html":        "<p>c</p>",
+		"docs/draft/d.md":    "d",
+		"node_modules/e.txt": "e",
+	})
+
+	t.Run("Files with a registered loader", func(t *testing.T) {
+		files, err := collectFiles(root, nil, nil)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(root, "a.txt"),
+			filepath.Join(root, "b.md"),
+			filepath.Join(root, "docs", "c.html"),
+			filepath.Join(root, "docs", "draft", "d.md"),
+			filepath.Join(root, "node_modules", "e.txt"),
+		}, files)
+	})
+
+	t.Run("Include and exclude patterns", func(t *testing.T) {
+		files, err := collectFiles(root, []string{"*.md", "*.txt"}, []string{"node_modules", "docs/draft/**"})
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			filepath.Join(root, "a.txt"),
+			filepath.Join(root, "b.md"),
+		}, files)
+	})
+
+	t.Run("Missing root", func(t *testing.T) {
+		_, err := collectFiles(filepath.Join(root, "missing"), nil, nil)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestIngestDirectory(t *testing.T) {
+	ctx := context.Background()
+	g := initGrapher(t)
+
+	chunker := func(text string, basePath string) ([]pipeline.ChunkWithPath, error) {
+		return []pipeline.ChunkWithPath{{Content: text, Path: basePath + ".chunk0"}}, nil
+	}
+	g.SetPipeline(pipeline.NewPipeline(chunker, testEmbedder(384)))
+
+	root := writeTestFiles(t, map[string]string{
+		"one.txt":          "First ingested file.",
+		"two.md":           "# Second\n\nSecond ingested file.",
+		"empty.txt":        "   ",
+		"broken.pdf":       "not a pdf",
+		"skip/three.txt":   "Excluded file.",
+		"notes/readme.xyz": "No loader.",
+	})
+
+	t.Run("Ingest directory with progress", func(t *testing.T) {
+		var progress []IngestProgress
+
+		summary, err := g.IngestDirectory(context.Background(), root, IngestOptions{
+			Exclude:  []string{"skip"},
+			Workers:  2,
+			Metadata: model.Metadata{"batch": "ingest_test"},
+			Progress: func(p IngestProgress) {
+				progress = append(progress, p)
+			},
+		})
+
+		require.NoError(t, err, "Expected IngestDirectory to not return an error")
+		assert.Equal(t, 4, summary.Total)
+		assert.Equal(t, 2, summary.Succeeded)
+		assert.Equal(t, 1, summary.Skipped)
+		assert.Equal(t, 1, summary.Failed)
+		assert.Equal(t, 2, summary.Chunks)
+		assert.Contains(t, summary.Errors, filepath.Join(root, "broken.pdf"))
+
+		require.Len(t, progress, 4, "Expected one progress report per file")
+		for i, p := range progress {
+			assert.Equal(t, i+1, p.Done)
+			assert.Equal(t, 4, p.Total)
+			if p.Doc != nil {
+				assert.Equal(t, "ingest_test", p.Doc.Metadata["batch"])
+				// Cleanup
+				g.Documents.DeleteDocument(ctx, p.Doc.RID)
+			}
+		}
+	})
+
+	t.Run("Re-ingest only rebuilds changed files", func(t *testing.T) {
+		opts := IngestOptions{Include: []string{"*.txt", "*.md"}, Exclude: []string{"skip"}}
+
+		_, err := g.IngestDirectory(context.Background(), root, opts)
+		require.NoError(t, err)
+
+		summary, err := g.IngestDirectory(context.Background(), root, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Unchanged, "Expected unchanged files to be skipped")
+		assert.Equal(t, 0, summary.Chunks)
+
+		// The same files below a differently written root are the same documents
+		cwd, err := os.Getwd()
+		require.NoError(t, err)
+		relativeRoot, err := filepath.Rel(cwd, root)
+		require.NoError(t, err)
+		summary, err = g.IngestDirectory(context.Background(), "."+string(filepath.Separator)+relativeRoot, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 2, summary.Unchanged, "Expected files below a relative root to be unchanged")
+		assert.Equal(t, 0, summary.Chunks, "Expected no duplicate documents")
+
+		require.NoError(t, os.WriteFile(filepath.Join(root, "one.txt"), []byte("First file changed."), 0644))
+		var docs []*model.Document
+		opts.Progress = func(p IngestProgress) {
+			if p.Doc != nil {
+				docs = append(docs, p.Doc)
+			}
+		}
+
+		summary, err = g.IngestDirectory(context.Background(), root, opts)
+		require.NoError(t, err)
+		assert.Equal(t, 1, summary.Updated, "Expected the changed file to be updated")
+		assert.Equal(t, 1, summary.Unchanged)
+		assert.Equal(t, 1, summary.Chunks)
+
+		// Cleanup
+		for _, doc := range docs {
+			g.Documents.DeleteDocument(ctx, doc.RID)
+		}
+	})
+
+	t.Run("Canceled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		summary, err := g.IngestDirectory(ctx, root, IngestOptions{Include: []string{"*.txt"}})
+
+		assert.Error(t, err, "Expected context error")
+		require.NotNil(t, summary)
+		assert.Equal(t, 0, summary.Succeeded)
+	})
+
+	t.Run("Invalid pattern", func(t *testing.T) {
+		_, err := g.IngestDirectory(context.Background(), root, IngestOptions{Include: []string{"[a"}})
+
+		assert.Error(t, err)
+	})
+
+	t.Run("Error when pipeline not set", func(t *testing.T) {
+		gNoPipeline := initGrapher(t)
+
+		_, err := gNoPipeline.IngestDirectory(context.Background(), root, IngestOptions{})
+
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "pipeline not set")
+	})
+}
@@ -20,6 +20,7 @@ type Chunk struct {
 	Metadata    Metadata  `json:"metadata,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	// Results
-	Similarity *float64 `json:"similarity,omitempty"`
-	IsMatch    *bool    `json:"is_match,omitempty"`
+	Similarity  *float64 `json:"similarity,omitempty"`
+	KeywordRank *float64 `json:"keyword_rank,omitempty"`
+	IsMatch     *bool    `json:"is_match,omitempty"`
 }
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Community is a group of densely connected entities found by community detection
+type Community struct {
+	ID        uuid.UUID   `json:"id"`
+	Title     string      `json:"title"`   // Names of the most connected members
+	Summary   string      `json:"summary"` // Summary of the members and their relations
+	Weight    float64     `json:"weight"`  // Total weight of the edges between the members
+	EntityIDs []uuid.UUID `json:"entity_ids"`
+	Metadata  Metadata    `json:"metadata,omitempty"`
+	Embedding []float32   `json:"embedding,omitempty"` // Embedding of the summary
+	CreatedAt time.Time   `json:"created_at"`
+	// Results
+	Similarity *float64 `json:"similarity,omitempty"`
+}
+
+// CommunityResult represents a community retrieved by a query
+type CommunityResult struct {
+	Community       *Community `json:"community"`
+	Score           float64    `json:"score"`
+	SimilarityScore float64    `json:"similarity_score"` // Cosine similarity of the query and the summary
+	RetrievalMethod string     `json:"retrieval_method"`
+}
@@ -2,6 +2,14 @@ package model
 
 import "github.com/google/uuid"
 
+// FusionMethod defines how ranked result lists of several sources are merged
+type FusionMethod string
+
+const (
+	FusionMethodRRF   FusionMethod = "rrf"   // Reciprocal Rank Fusion, only uses the rank in each list
+	FusionMethodScore FusionMethod = "score" // Min-max normalized score fusion
+)
+
 // QueryConfig represents configuration for a retrieval query
 type QueryConfig struct {
 	// Vector search parameters
@@ -15,6 +23,7 @@ type QueryConfig struct {
 	MaxHops             int        `json:"max_hops,omitempty"`
 	EdgeTypes           []EdgeType `json:"edge_types,omitempty"` // Filter by edge types
 	FollowBidirectional bool       `json:"follow_bidirectional"`
+	MaxFanout           int        `json:"max_fanout,omitempty"` // Neighbours followed per chunk and hop, strongest edges first (0 follows all)
 
 	// Ltree parameters
 	IncludeAncestors   bool `json:"include_ancestors"`
@@ -25,6 +34,20 @@ type QueryConfig struct {
 	VectorWeight    float64 `json:"vector_weight"`    // Weight for similarity score
 	GraphWeight     float64 `json:"graph_weight"`     // Weight for graph distance
 	HierarchyWeight float64 `json:"hierarchy_weight"` // Weight for hierarchy distance
+
+	// Fusion parameters
+	FusionMethod  FusionMethod       `json:"fusion_method,omitempty"`  // How source lists are merged (default rrf)
+	FusionK       int                `json:"fusion_k,omitempty"`       // RRF constant damping top ranks (default 60)
+	FusionWeights map[string]float64 `json:"fusion_weights,omitempty"` // Weight per source (keyword, vector, graph), default 1.0
+
+	// Entity parameters
+	EntityTopK int `json:"entity_top_k,omitempty"` // Number of entities related to the query used as entry points by entity search
+
+	// PageRank parameters
+	PageRankDamping float64 `json:"pagerank_damping,omitempty"` // Probability of following an edge instead of jumping back to a seed (default 0.85)
+
+	// Reranking parameters
+	RerankDepth int `json:"rerank_depth,omitempty"` // Number of top candidates rescored by the reranker (0 disables reranking)
 }
 
 // DefaultQueryConfig returns a sensible default configuration
@@ -35,11 +58,31 @@ func DefaultQueryConfig() QueryConfig {
 		MaxHops:             2,
 		EdgeTypes:           nil, // All types
 		FollowBidirectional: true,
+		MaxFanout:           20,
 		IncludeAncestors:    false,
 		IncludeDescendants:  false,
 		IncludeSiblings:     true,
 		VectorWeight:        0.6,
 		GraphWeight:         0.3,
 		HierarchyWeight:     0.1,
+		FusionMethod:        FusionMethodRRF,
+		FusionK:             60,
+		FusionWeights:       nil, // All sources weighted equally
+		EntityTopK:          3,
+		PageRankDamping:     0.85,
+	}
+}
+
+// SemanticLinkConfig configures the automatic semantic edges between similar chunks
+type SemanticLinkConfig struct {
+	K         int     `json:"k"`         // Number of nearest neighbours linked per chunk
+	Threshold float64 `json:"threshold"` // Minimum cosine similarity of linked chunks
+}
+
+// DefaultSemanticLinkConfig returns a sensible default configuration
+func DefaultSemanticLinkConfig() SemanticLinkConfig {
+	return SemanticLinkConfig{
+		K:         5,
+		Threshold: 0.8,
 	}
 }
@@ -17,12 +17,18 @@ func TestDefaultQueryConfig(t *testing.T) {
 		assert.Equal(t, 2, config.MaxHops, "Default MaxHops should be 2")
 		assert.Nil(t, config.EdgeTypes, "Default EdgeTypes should be nil (all types)")
 		assert.True(t, config.FollowBidirectional, "Default FollowBidirectional should be true")
+		assert.Equal(t, 20, config.MaxFanout, "Default MaxFanout should be 20")
 		assert.False(t, config.IncludeAncestors, "Default IncludeAncestors should be false")
 		assert.False(t, config.IncludeDescendants, "Default IncludeDescendants should be false")
 		assert.True(t, config.IncludeSiblings, "Default IncludeSiblings should be true")
 		assert.Equal(t, 0.6, config.VectorWeight, "Default VectorWeight should be 0.6")
 		assert.Equal(t, 0.3, config.GraphWeight, "Default GraphWeight should be 0.3")
 		assert.Equal(t, 0.1, config.HierarchyWeight, "Default HierarchyWeight should be 0.1")
+		assert.Equal(t, FusionMethodRRF, config.FusionMethod, "Default FusionMethod should be rrf")
+		assert.Equal(t, 60, config.FusionK, "Default FusionK should be 60")
+		assert.Nil(t, config.FusionWeights, "Default FusionWeights should be nil (equal weights)")
+		assert.Equal(t, 3, config.EntityTopK, "Default EntityTopK should be 3")
+		assert.Equal(t, 0.85, config.PageRankDamping, "Default PageRankDamping should be 0.85")
 	})
 
 	t.Run("Default weights sum to 1.0", func(t *testing.T) {
@@ -68,3 +74,12 @@ func TestDefaultQueryConfig(t *testing.T) {
 		assert.Equal(t, EdgeTypeReference, config.EdgeTypes[1])
 	})
 }
+
+func TestDefaultSemanticLinkConfig(t *testing.T) {
+	t.Run("Returns correct default values", func(t *testing.T) {
+		config := DefaultSemanticLinkConfig()
+
+		assert.Equal(t, 5, config.K, "Default K should be 5")
+		assert.Equal(t, 0.8, config.Threshold, "Default Threshold should be 0.8")
+	})
+}
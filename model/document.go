@@ -10,18 +10,59 @@ import (
 
 // Document represents a source document
 type Document struct {
-	ID        int64     `json:"id"`
-	RID       uuid.UUID `json:"rid"`
-	Title     string    `json:"title"`
-	Source    string    `json:"source,omitempty"`
-	Content   string    `json:"content,omitempty" db:"-"` // Temporary field for processing, not stored in DB
-	Metadata  Metadata  `json:"metadata,omitempty"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       int64     `json:"id"`
+	RID      uuid.UUID `json:"rid"`
+	Title    string    `json:"title"`
+	Source   string    `json:"source,omitempty"`
+	Content  string    `json:"content,omitempty" db:"-"` // Temporary field for processing, not stored in DB
+	Hints    []Hint    `json:"hints,omitempty" db:"-"`   // Structural hints from the loader, not stored in DB
+	Metadata Metadata  `json:"metadata,omitempty"`
+	// Hash of the processed content, used to detect changes on re-ingestion
+	ContentHash string    `json:"content_hash,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// UpsertStatus tells what an upsert did with a document
+type UpsertStatus string
+
+const (
+	UpsertStatusInserted  UpsertStatus = "inserted"  // No document with the source existed
+	UpsertStatusUpdated   UpsertStatus = "updated"   // The content changed and the document was rebuilt
+	UpsertStatusUnchanged UpsertStatus = "unchanged" // The content hash matched, nothing was written
+)
+
+// DocumentUpdate counts the chunk changes of an incremental document update
+type DocumentUpdate struct {
+	Kept    int `json:"kept"`    // Chunks with unchanged content, including the moved ones
+	Moved   int `json:"moved"`   // Kept chunks whose path or position changed
+	Added   int `json:"added"`   // New or changed chunks that were embedded and inserted
+	Removed int `json:"removed"` // Stored chunks that were deleted with their edges
+}
+
+// HintType represents the kind of a structural hint
+type HintType string
+
+const (
+	HintTypeHeading HintType = "heading"
+	HintTypePage    HintType = "page"
+)
+
+// Hint marks a structural element (like a heading or the start of a page) in the document content
+type Hint struct {
+	Type   HintType `json:"type"`
+	Text   string   `json:"text,omitempty"`  // Heading text
+	Level  int      `json:"level,omitempty"` // Heading level, 1 is the top level
+	Page   int      `json:"page,omitempty"`  // Page number, starting at 1
+	Offset int      `json:"offset"`          // Byte offset in the content
 }
 
 // NewDocumentFromFile reads a file and creates a Document with the file content
-// The title defaults to the filename, and source to the file path
+// The title defaults to the filename, and source to the file path.
+// The content is stored as is and the document has no hints.
+//
+// Deprecated: Use loader.LoadFile, which also converts PDF, HTML and DOCX files
+// and sets the heading and page hints.
 func NewDocumentFromFile(filePath string, metadata Metadata) (*Document, error) {
 	// Clean the path to prevent directory traversal
 	cleanPath := filepath.Clean(filePath)
@@ -41,7 +41,60 @@ type EdgeConnection struct {
 
 // TraversalNode represents a node in a graph traversal
 type TraversalNode struct {
-	ChunkID uuid.UUID   `json:"chunk_id"`
-	Depth   int         `json:"depth"`
-	Path    []uuid.UUID `json:"path"`
+	ChunkID   uuid.UUID   `json:"chunk_id"`
+	Depth     int         `json:"depth"`
+	Path      []uuid.UUID `json:"path"`                 // IDs of the chunks and entities from the seed to the node
+	SeedID    uuid.UUID   `json:"seed_id,omitempty"`    // Seed the node was reached from in a multi-seed traversal
+	Node      GraphNode   `json:"node"`                 // Reached chunk or entity
+	PathNodes []GraphNode `json:"path_nodes,omitempty"` // Typed nodes of the path if the traversal returned them
+	Chunk     *Chunk      `json:"chunk,omitempty"`      // Chunk data if the traversal joined it, nil for entities
+	Entity    *Entity     `json:"entity,omitempty"`     // Entity data if the traversal joined it, nil for chunks
+}
+
+// SourceNode returns the chunk or entity the edge starts at
+func (e *Edge) SourceNode() (GraphNode, bool) {
+	if e.SourceChunkID != nil {
+		return ChunkNode(*e.SourceChunkID), true
+	}
+	if e.SourceEntityID != nil {
+		return EntityNode(*e.SourceEntityID), true
+	}
+	return GraphNode{}, false
+}
+
+// TargetNode returns the chunk or entity the edge ends at
+func (e *Edge) TargetNode() (GraphNode, bool) {
+	if e.TargetChunkID != nil {
+		return ChunkNode(*e.TargetChunkID), true
+	}
+	if e.TargetEntityID != nil {
+		return EntityNode(*e.TargetEntityID), true
+	}
+	return GraphNode{}, false
+}
+
+// LinksChunkAndEntity reports whether the edge connects a chunk with an entity, like a mention.
+// Traversals follow these edges in both directions, so they can move from a chunk to the entities
+// it mentions and on to the other chunks mentioning them.
+func (e *Edge) LinksChunkAndEntity() bool {
+	return (e.SourceChunkID != nil && e.TargetEntityID != nil) || (e.SourceEntityID != nil && e.TargetChunkID != nil)
+}
+
+// Neighbor returns the node reached by following the edge from the given node.
+// Edges are followed from source to target, bidirectional edges (if followBidirectional is set)
+// and edges between a chunk and an entity also from target to source.
+func (e *Edge) Neighbor(node GraphNode, followBidirectional bool) (GraphNode, bool) {
+	source, okSource := e.SourceNode()
+	target, okTarget := e.TargetNode()
+	if !okSource || !okTarget || source == target {
+		return GraphNode{}, false
+	}
+
+	if source == node {
+		return target, true
+	}
+	if target == node && ((followBidirectional && e.Bidirectional) || e.LinksChunkAndEntity()) {
+		return source, true
+	}
+	return GraphNode{}, false
 }
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEdgeNeighbor(t *testing.T) {
+	chunkID1 := uuid.New()
+	chunkID2 := uuid.New()
+	entityID := uuid.New()
+
+	t.Run("Directed edge between chunks", func(t *testing.T) {
+		edge := &Edge{SourceChunkID: &chunkID1, TargetChunkID: &chunkID2}
+
+		neighbor, ok := edge.Neighbor(ChunkNode(chunkID1), true)
+		assert.True(t, ok)
+		assert.Equal(t, ChunkNode(chunkID2), neighbor)
+
+		_, ok = edge.Neighbor(ChunkNode(chunkID2), true)
+		assert.False(t, ok, "Expected directed edge to not be followed backwards")
+		assert.False(t, edge.LinksChunkAndEntity())
+	})
+
+	t.Run("Bidirectional edge between chunks", func(t *testing.T) {
+		edge := &Edge{SourceChunkID: &chunkID1, TargetChunkID: &chunkID2, Bidirectional: true}
+
+		neighbor, ok := edge.Neighbor(ChunkNode(chunkID2), true)
+		assert.True(t, ok)
+		assert.Equal(t, ChunkNode(chunkID1), neighbor)
+
+		_, ok = edge.Neighbor(ChunkNode(chunkID2), false)
+		assert.False(t, ok, "Expected bidirectional edge to be followed backwards only if requested")
+	})
+
+	t.Run("Edge between chunk and entity", func(t *testing.T) {
+		edge := &Edge{SourceChunkID: &chunkID1, TargetEntityID: &entityID}
+
+		neighbor, ok := edge.Neighbor(ChunkNode(chunkID1), false)
+		assert.True(t, ok)
+		assert.Equal(t, EntityNode(entityID), neighbor)
+
+		neighbor, ok = edge.Neighbor(EntityNode(entityID), false)
+		assert.True(t, ok, "Expected mention to be followed from the entity")
+		assert.Equal(t, ChunkNode(chunkID1), neighbor)
+		assert.True(t, edge.LinksChunkAndEntity())
+	})
+
+	t.Run("Node types are distinguished", func(t *testing.T) {
+		edge := &Edge{SourceChunkID: &chunkID1, TargetEntityID: &entityID}
+
+		_, ok := edge.Neighbor(EntityNode(chunkID1), false)
+		assert.False(t, ok, "Expected an entity with the chunk ID to not match")
+	})
+
+	t.Run("Invalid edge", func(t *testing.T) {
+		edge := &Edge{SourceChunkID: &chunkID1}
+
+		_, ok := edge.Neighbor(ChunkNode(chunkID1), true)
+		assert.False(t, ok)
+	})
+}
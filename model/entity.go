@@ -12,7 +12,10 @@ type Entity struct {
 	Name      string    `json:"name"`
 	Type      string    `json:"entity_type"`
 	Metadata  Metadata  `json:"metadata,omitempty"`
+	Embedding []float32 `json:"embedding,omitempty"` // Embedding of the name and context snippets
 	CreatedAt time.Time `json:"created_at"`
+	// Results
+	Similarity *float64 `json:"similarity,omitempty"`
 }
 
 // ChunkMention represents a chunk that mentions an entity
@@ -21,3 +24,12 @@ type ChunkMention struct {
 	EdgeID       uuid.UUID `json:"edge_id"`
 	EdgeMetadata Metadata  `json:"edge_metadata,omitempty"`
 }
+
+// EntityAlias represents an alternative name of an entity
+type EntityAlias struct {
+	ID              uuid.UUID `json:"id"`
+	EntityID        uuid.UUID `json:"entity_id"`
+	Alias           string    `json:"alias"`
+	NormalizedAlias string    `json:"normalized_alias"`
+	CreatedAt       time.Time `json:"created_at"`
+}
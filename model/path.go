@@ -0,0 +1,68 @@
+package model
+
+import "github.com/google/uuid"
+
+// NodeType distinguishes the chunk and entity nodes of the graph
+type NodeType string
+
+const (
+	NodeTypeChunk  NodeType = "chunk"
+	NodeTypeEntity NodeType = "entity"
+)
+
+// DefaultPathMaxVisited is the number of nodes a path search expands before giving up
+const DefaultPathMaxVisited = 10000
+
+// GraphNode identifies a chunk or an entity in the graph
+type GraphNode struct {
+	ID   uuid.UUID `json:"id"`
+	Type NodeType  `json:"type"`
+}
+
+// ChunkNode returns the graph node of a chunk
+func ChunkNode(id uuid.UUID) GraphNode {
+	return GraphNode{ID: id, Type: NodeTypeChunk}
+}
+
+// EntityNode returns the graph node of an entity
+func EntityNode(id uuid.UUID) GraphNode {
+	return GraphNode{ID: id, Type: NodeTypeEntity}
+}
+
+// PathStep is one edge of a path
+type PathStep struct {
+	From     GraphNode `json:"from"`
+	To       GraphNode `json:"to"`
+	Edge     *Edge     `json:"edge"`
+	Reversed bool      `json:"reversed"` // True if the edge was followed from its target to its source
+	Cost     float64   `json:"cost"`
+}
+
+// Path is a connection between two nodes, the steps contain the edges explaining it
+type Path struct {
+	Nodes []GraphNode `json:"nodes"`
+	Steps []PathStep  `json:"steps"`
+	Cost  float64     `json:"cost"`
+}
+
+// PathOptions configures shortest path queries
+type PathOptions struct {
+	EdgeTypes           []EdgeType `json:"edge_types,omitempty"` // Filter by edge types
+	FollowBidirectional bool       `json:"follow_bidirectional"` // Follow bidirectional edges from target to source
+	IgnoreDirection     bool       `json:"ignore_direction"`     // Follow all edges in both directions, edges between a chunk and an entity always are
+	MaxVisited          int        `json:"max_visited,omitempty"`
+
+	// Cost of following an edge, defaults to the inverse of the edge weight so strong edges are preferred.
+	// Edges with a negative, infinite or NaN cost are not followed.
+	Cost func(edge *Edge) float64 `json:"-"`
+}
+
+// DefaultPathOptions returns a sensible default configuration
+func DefaultPathOptions() PathOptions {
+	return PathOptions{
+		EdgeTypes:           nil, // All types
+		FollowBidirectional: true,
+		IgnoreDirection:     false,
+		MaxVisited:          DefaultPathMaxVisited,
+	}
+}
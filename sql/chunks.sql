@@ -1,7 +1,14 @@
 -- Chunks SQL Functions
 
+-- Drop the previous init_chunks signature, otherwise calls with one argument would be ambiguous
+DROP FUNCTION IF EXISTS init_chunks(INT);
+
 -- Initialize chunks table and related objects
-CREATE OR REPLACE FUNCTION init_chunks(embedding_dim INT DEFAULT 384) RETURNS VOID AS $$
+-- text_search_config sets the language used for the full-text search column (e.g. 'english', 'german', 'simple')
+CREATE OR REPLACE FUNCTION init_chunks(
+    embedding_dim INT DEFAULT 384,
+    text_search_config REGCONFIG DEFAULT 'english'
+) RETURNS VOID AS $$
 BEGIN
     -- Create required extensions
     CREATE EXTENSION IF NOT EXISTS vector;
@@ -22,11 +29,28 @@ BEGIN
             created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
         )', embedding_dim);
     
+    -- Create the settings table storing the text search config of the content_tsv column
+    CREATE TABLE IF NOT EXISTS chunks_settings (
+        id BOOLEAN PRIMARY KEY DEFAULT TRUE CHECK (id),
+        text_search_config REGCONFIG NOT NULL
+    );
+
+    -- Add full-text search column (also for tables created before it existed)
+    -- An existing column keeps its config, so the stored config isn't overwritten either
+    EXECUTE format('
+        ALTER TABLE chunks ADD COLUMN IF NOT EXISTS content_tsv TSVECTOR
+            GENERATED ALWAYS AS (to_tsvector(%L::regconfig, content)) STORED', text_search_config);
+
+    INSERT INTO chunks_settings (text_search_config)
+    VALUES (text_search_config)
+    ON CONFLICT (id) DO NOTHING;
+    
     -- Create indexes
     CREATE INDEX IF NOT EXISTS idx_chunks_path ON chunks USING GIST (path);
     CREATE INDEX IF NOT EXISTS idx_chunks_path_btree ON chunks USING BTREE (path);
     CREATE INDEX IF NOT EXISTS idx_chunks_document ON chunks(document_id);
     CREATE INDEX IF NOT EXISTS idx_chunks_metadata ON chunks USING GIN (metadata);
+    CREATE INDEX IF NOT EXISTS idx_chunks_content_tsv ON chunks USING GIN (content_tsv);
     
     -- Create HNSW index for vector similarity if it doesn't exist
     IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_chunks_embedding') THEN
@@ -36,6 +60,43 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Get the text search config the content_tsv column was generated with
+-- Falls back to 'english' if no config is stored yet
+CREATE OR REPLACE FUNCTION select_chunks_text_search_config()
+RETURNS REGCONFIG
+AS $$
+BEGIN
+    RETURN COALESCE(
+        (SELECT s.text_search_config FROM chunks_settings s),
+        'english'::regconfig
+    );
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Change the text search config of the content_tsv column
+-- Recreating the generated column recomputes the vectors of all existing chunks
+CREATE OR REPLACE FUNCTION change_chunks_text_search_config(
+    text_search_config REGCONFIG
+)
+RETURNS VOID
+AS $$
+BEGIN
+    DROP INDEX IF EXISTS idx_chunks_content_tsv;
+    ALTER TABLE chunks DROP COLUMN IF EXISTS content_tsv;
+
+    EXECUTE format('
+        ALTER TABLE chunks ADD COLUMN content_tsv TSVECTOR
+            GENERATED ALWAYS AS (to_tsvector(%L::regconfig, content)) STORED', text_search_config);
+
+    CREATE INDEX idx_chunks_content_tsv ON chunks USING GIN (content_tsv);
+
+    INSERT INTO chunks_settings (text_search_config)
+    VALUES (text_search_config)
+    ON CONFLICT (id) DO UPDATE
+        SET text_search_config = EXCLUDED.text_search_config;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Insert a new chunk
 CREATE OR REPLACE FUNCTION insert_chunk(
     input_document_id BIGINT,
@@ -318,6 +379,55 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Full-text keyword search
+-- Uses websearch syntax ("quoted phrases", OR, -excluded) and ranks by cover density.
+-- The rank is normalized to [0, 1) with rank / (rank + 1).
+CREATE OR REPLACE FUNCTION select_chunks_by_keyword(
+    input_query TEXT,
+    input_limit INT,
+    input_document_rids UUID[] DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_document_id BIGINT,
+    output_document_rid UUID,
+    output_content TEXT,
+    output_path LTREE,
+    output_embedding VECTOR,
+    output_start_pos INT,
+    output_end_pos INT,
+    output_chunk_index INT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_rank FLOAT
+)
+AS $$
+DECLARE
+    search_query TSQUERY := websearch_to_tsquery(select_chunks_text_search_config(), input_query);
+BEGIN
+    RETURN QUERY
+    SELECT 
+        c.id,
+        c.document_id,
+        d.rid,
+        c.content,
+        c.path,
+        c.embedding,
+        c.start_pos,
+        c.end_pos,
+        c.chunk_index,
+        c.metadata,
+        c.created_at,
+        ts_rank_cd(c.content_tsv, search_query, 32)::FLOAT AS rank
+    FROM chunks c
+    LEFT JOIN documents d ON c.document_id = d.id
+    WHERE c.content_tsv @@ search_query
+        AND (input_document_rids IS NULL OR d.rid = ANY(input_document_rids))
+    ORDER BY rank DESC
+    LIMIT input_limit;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Hybrid search: vector similarity + ltree hierarchy
 -- Finds similar chunks and also includes their ancestors/descendants
 CREATE OR REPLACE FUNCTION select_chunks_by_similarity_with_context(
@@ -419,6 +529,116 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Update the position of a chunk whose content didn't change
+CREATE OR REPLACE FUNCTION update_chunk_position(
+    input_id UUID,
+    input_path TEXT,
+    input_start_pos INT,
+    input_end_pos INT,
+    input_chunk_index INT,
+    input_metadata JSONB
+)
+RETURNS VOID
+AS $$
+BEGIN
+    UPDATE chunks
+    SET path = input_path::LTREE,
+        start_pos = input_start_pos,
+        end_pos = input_end_pos,
+        chunk_index = input_chunk_index,
+        metadata = COALESCE(input_metadata, '{}'::JSONB)
+    WHERE id = input_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Delete chunks together with their edges (edges have no foreign keys).
+-- Entities lose the mention counts of the deleted chunks and a document is removed
+-- from the entity documents if none of its remaining chunks mentions the entity.
+-- Entities without any remaining mention are deleted with their edges.
+-- Returns the number of deleted chunks.
+CREATE OR REPLACE FUNCTION delete_chunks(input_ids UUID[])
+RETURNS INT
+AS $$
+DECLARE
+    mentioned UUID[];
+    orphans UUID[];
+    num_chunks INT;
+BEGIN
+    WITH mention_edges AS (
+        SELECT e.target_entity_id AS entity_id, c.document_id
+        FROM edges e
+        INNER JOIN chunks c ON c.id = e.source_chunk_id
+        WHERE e.source_chunk_id = ANY(input_ids)
+            AND e.edge_type = 'entity_mention'
+            AND e.target_entity_id IS NOT NULL
+    ),
+    mentions AS (
+        SELECT entity_id, COUNT(*) AS num_mentions
+        FROM mention_edges
+        GROUP BY entity_id
+    ),
+    removed_documents AS (
+        SELECT me.entity_id, jsonb_agg(DISTINCT d.rid::TEXT) AS document_rids
+        FROM mention_edges me
+        INNER JOIN documents d ON d.id = me.document_id
+        WHERE NOT EXISTS (
+            SELECT 1 FROM edges e2
+            INNER JOIN chunks c2 ON c2.id = e2.source_chunk_id
+            WHERE e2.target_entity_id = me.entity_id
+                AND e2.edge_type = 'entity_mention'
+                AND c2.document_id = me.document_id
+                AND NOT (c2.id = ANY(input_ids))
+        )
+        GROUP BY me.entity_id
+    ),
+    updated AS (
+        UPDATE entities en
+        SET metadata = COALESCE(en.metadata, '{}'::JSONB)
+            || jsonb_build_object(
+                'mention_count',
+                GREATEST(COALESCE((en.metadata->>'mention_count')::NUMERIC::BIGINT, 0) - m.num_mentions, 0),
+                'documents',
+                COALESCE((
+                    SELECT jsonb_agg(d.value ORDER BY d.ordinality)
+                    FROM jsonb_array_elements(
+                        CASE WHEN jsonb_typeof(en.metadata->'documents') = 'array'
+                            THEN en.metadata->'documents' ELSE '[]'::JSONB END
+                    ) WITH ORDINALITY AS d(value, ordinality)
+                    WHERE NOT COALESCE(r.document_rids, '[]'::JSONB) @> jsonb_build_array(d.value)
+                ), '[]'::JSONB)
+            )
+        FROM mentions m
+        LEFT JOIN removed_documents r ON r.entity_id = m.entity_id
+        WHERE en.id = m.entity_id
+        RETURNING en.id
+    )
+    SELECT ARRAY_AGG(id) INTO mentioned FROM updated;
+
+    DELETE FROM edges
+    WHERE source_chunk_id = ANY(input_ids)
+        OR target_chunk_id = ANY(input_ids);
+
+    DELETE FROM chunks WHERE id = ANY(input_ids);
+    GET DIAGNOSTICS num_chunks = ROW_COUNT;
+
+    -- Delete the entities that lost their last mention
+    SELECT ARRAY_AGG(m.id) INTO orphans
+    FROM unnest(COALESCE(mentioned, '{}'::UUID[])) AS m(id)
+    WHERE NOT EXISTS (
+        SELECT 1 FROM edges e
+        WHERE e.target_entity_id = m.id AND e.edge_type = 'entity_mention'
+    );
+
+    IF orphans IS NOT NULL THEN
+        DELETE FROM edges
+        WHERE source_entity_id = ANY(orphans) OR target_entity_id = ANY(orphans);
+        DELETE FROM entities WHERE id = ANY(orphans);
+    END IF;
+
+    RETURN num_chunks;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Update chunk embedding
 CREATE OR REPLACE FUNCTION update_chunk_embedding(
     input_id UUID,
@@ -0,0 +1,221 @@
+-- Communities SQL Functions
+
+-- Initialize communities tables and related objects
+-- Requires the entities table, members are removed together with their entity
+CREATE OR REPLACE FUNCTION init_communities(embedding_dim INT DEFAULT 384) RETURNS VOID AS $$
+BEGIN
+    -- Create required extensions
+    CREATE EXTENSION IF NOT EXISTS vector;
+
+    -- Create communities table
+    CREATE TABLE IF NOT EXISTS communities (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        title TEXT NOT NULL,
+        summary TEXT NOT NULL DEFAULT '',
+        weight FLOAT NOT NULL DEFAULT 0,
+        metadata JSONB DEFAULT '{}',
+        created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    );
+
+    -- Add embedding column
+    EXECUTE format('ALTER TABLE communities ADD COLUMN IF NOT EXISTS embedding VECTOR(%s)', embedding_dim);
+
+    -- Create HNSW index for vector similarity if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_communities_embedding') THEN
+        CREATE INDEX idx_communities_embedding ON communities USING hnsw (embedding vector_cosine_ops)
+        WITH (m = 16, ef_construction = 64);
+    END IF;
+
+    -- Create community members table, the rank orders the members by their connections
+    CREATE TABLE IF NOT EXISTS community_members (
+        community_id UUID NOT NULL REFERENCES communities(id) ON DELETE CASCADE,
+        entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+        member_rank INT NOT NULL,
+
+        PRIMARY KEY (community_id, entity_id)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_community_members_entity ON community_members(entity_id);
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select the member entity IDs of a community ordered by rank
+CREATE OR REPLACE FUNCTION select_community_entity_ids(input_community_id UUID)
+RETURNS UUID[]
+AS $$
+BEGIN
+    RETURN ARRAY(
+        SELECT entity_id
+        FROM community_members
+        WHERE community_id = input_community_id
+        ORDER BY member_rank
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+-- Insert a community with its member entities, the entities keep their order as rank
+CREATE OR REPLACE FUNCTION insert_community(
+    input_title TEXT,
+    input_summary TEXT,
+    input_weight FLOAT,
+    input_metadata JSONB,
+    input_embedding VECTOR,
+    input_entity_ids UUID[]
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_title TEXT,
+    output_summary TEXT,
+    output_weight FLOAT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+DECLARE
+    new_id UUID;
+BEGIN
+    INSERT INTO communities (title, summary, weight, metadata, embedding)
+    VALUES (input_title, COALESCE(input_summary, ''), input_weight, COALESCE(input_metadata, '{}'), input_embedding)
+    RETURNING id INTO new_id;
+
+    INSERT INTO community_members (community_id, entity_id, member_rank)
+    SELECT new_id, m.entity_id, MIN(m.member_rank)
+    FROM unnest(input_entity_ids) WITH ORDINALITY AS m(entity_id, member_rank)
+    GROUP BY m.entity_id;
+
+    RETURN QUERY
+    SELECT id, title, summary, weight, metadata, created_at
+    FROM communities
+    WHERE id = new_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select a community by ID
+CREATE OR REPLACE FUNCTION select_community(input_id UUID)
+RETURNS TABLE (
+    output_id UUID,
+    output_title TEXT,
+    output_summary TEXT,
+    output_weight FLOAT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_embedding VECTOR,
+    output_entity_ids UUID[]
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT
+        c.id,
+        c.title,
+        c.summary,
+        c.weight,
+        c.metadata,
+        c.created_at,
+        c.embedding,
+        select_community_entity_ids(c.id)
+    FROM communities c
+    WHERE c.id = input_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select all communities, the most connected first
+CREATE OR REPLACE FUNCTION select_all_communities()
+RETURNS TABLE (
+    output_id UUID,
+    output_title TEXT,
+    output_summary TEXT,
+    output_weight FLOAT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_embedding VECTOR,
+    output_entity_ids UUID[]
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT
+        c.id,
+        c.title,
+        c.summary,
+        c.weight,
+        c.metadata,
+        c.created_at,
+        c.embedding,
+        select_community_entity_ids(c.id)
+    FROM communities c
+    ORDER BY c.weight DESC, c.created_at;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Vector similarity search over the community summary embeddings
+CREATE OR REPLACE FUNCTION select_communities_by_similarity(
+    input_embedding VECTOR,
+    input_limit INT,
+    input_threshold FLOAT DEFAULT 0.0
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_title TEXT,
+    output_summary TEXT,
+    output_weight FLOAT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_embedding VECTOR,
+    output_entity_ids UUID[],
+    output_similarity FLOAT
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT
+        c.id,
+        c.title,
+        c.summary,
+        c.weight,
+        c.metadata,
+        c.created_at,
+        c.embedding,
+        select_community_entity_ids(c.id),
+        1 - (c.embedding <=> input_embedding) AS similarity
+    FROM communities c
+    WHERE c.embedding IS NOT NULL
+        AND (1 - (c.embedding <=> input_embedding)) >= input_threshold
+    ORDER BY c.embedding <=> input_embedding
+    LIMIT input_limit;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Update the summary and summary embedding of a community
+CREATE OR REPLACE FUNCTION update_community_summary(
+    input_id UUID,
+    input_summary TEXT,
+    input_embedding VECTOR
+)
+RETURNS VOID
+AS $$
+BEGIN
+    UPDATE communities
+    SET summary = input_summary,
+        embedding = input_embedding
+    WHERE id = input_id;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'community % not found', input_id;
+    END IF;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Delete all communities before storing a new detection result
+-- Returns the number of deleted communities
+CREATE OR REPLACE FUNCTION delete_all_communities()
+RETURNS INT
+AS $$
+DECLARE
+    deleted_count INT;
+BEGIN
+    DELETE FROM communities;
+    GET DIAGNOSTICS deleted_count = ROW_COUNT;
+    RETURN deleted_count;
+END;
+$$ LANGUAGE plpgsql;
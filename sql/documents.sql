@@ -1,5 +1,14 @@
 -- Documents SQL Functions
 
+-- Drop the previous signatures and the functions whose return type changed,
+-- CREATE OR REPLACE can't change the return type of a function
+DROP FUNCTION IF EXISTS insert_document(TEXT, TEXT, JSONB);
+DROP FUNCTION IF EXISTS insert_document(TEXT, TEXT, JSONB, TEXT);
+DROP FUNCTION IF EXISTS update_document(UUID, TEXT, TEXT, JSONB);
+DROP FUNCTION IF EXISTS select_document(UUID);
+DROP FUNCTION IF EXISTS select_all_documents(TIMESTAMP WITH TIME ZONE, INT);
+DROP FUNCTION IF EXISTS search_documents(TEXT, INT);
+
 -- Initialize documents table and related objects
 CREATE OR REPLACE FUNCTION init_documents() RETURNS VOID AS $$
 BEGIN
@@ -14,8 +23,12 @@ BEGIN
         updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
     );
     
+    -- Hash of the processed content to detect changes on re-ingestion
+    ALTER TABLE documents ADD COLUMN IF NOT EXISTS content_hash TEXT;
+
     -- Create indexes
     CREATE INDEX IF NOT EXISTS idx_documents_metadata ON documents USING GIN (metadata);
+    CREATE INDEX IF NOT EXISTS idx_documents_source ON documents(source);
     
     -- Create trigger function for updated_at
     CREATE OR REPLACE FUNCTION update_updated_at_column()
@@ -33,11 +46,13 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
--- Insert a new document
+-- Insert a new document, a NULL RID generates a new one
 CREATE OR REPLACE FUNCTION insert_document(
     input_title TEXT,
     input_source TEXT,
-    input_metadata JSONB
+    input_metadata JSONB,
+    input_content_hash TEXT DEFAULT NULL,
+    input_rid UUID DEFAULT NULL
 )
 RETURNS TABLE (
     output_id BIGINT,
@@ -45,20 +60,22 @@ RETURNS TABLE (
     output_title TEXT,
     output_source TEXT,
     output_metadata JSONB,
+    output_content_hash TEXT,
     output_created_at TIMESTAMP WITH TIME ZONE,
     output_updated_at TIMESTAMP WITH TIME ZONE
 )
 AS $$
 BEGIN
     RETURN QUERY
-    INSERT INTO documents (title, source, metadata)
-    VALUES (input_title, input_source, input_metadata)
+    INSERT INTO documents (rid, title, source, metadata, content_hash)
+    VALUES (COALESCE(input_rid, gen_random_uuid()), input_title, input_source, input_metadata, NULLIF(input_content_hash, ''))
     RETURNING 
         id,
         rid,
         title, 
         source, 
         metadata, 
+        COALESCE(content_hash, ''), 
         created_at, 
         updated_at;
 END;
@@ -72,6 +89,7 @@ RETURNS TABLE (
     output_title TEXT,
     output_source TEXT,
     output_metadata JSONB,
+    output_content_hash TEXT,
     output_created_at TIMESTAMP WITH TIME ZONE,
     output_updated_at TIMESTAMP WITH TIME ZONE
 )
@@ -84,6 +102,7 @@ BEGIN
         title, 
         source, 
         metadata, 
+        COALESCE(content_hash, ''), 
         created_at, 
         updated_at
     FROM documents
@@ -102,6 +121,7 @@ RETURNS TABLE (
     output_title TEXT,
     output_source TEXT,
     output_metadata JSONB,
+    output_content_hash TEXT,
     output_created_at TIMESTAMP WITH TIME ZONE,
     output_updated_at TIMESTAMP WITH TIME ZONE
 )
@@ -114,6 +134,7 @@ BEGIN
         title, 
         source, 
         metadata, 
+        COALESCE(content_hash, ''), 
         created_at, 
         updated_at
     FROM documents
@@ -134,6 +155,7 @@ RETURNS TABLE (
     output_title TEXT,
     output_source TEXT,
     output_metadata JSONB,
+    output_content_hash TEXT,
     output_created_at TIMESTAMP WITH TIME ZONE,
     output_updated_at TIMESTAMP WITH TIME ZONE
 )
@@ -146,6 +168,7 @@ BEGIN
         title, 
         source, 
         metadata, 
+        COALESCE(content_hash, ''), 
         created_at, 
         updated_at
     FROM documents
@@ -162,7 +185,8 @@ CREATE OR REPLACE FUNCTION update_document(
     input_rid UUID,
     input_title TEXT,
     input_source TEXT,
-    input_metadata JSONB
+    input_metadata JSONB,
+    input_content_hash TEXT DEFAULT NULL
 )
 RETURNS TABLE (
     output_id BIGINT,
@@ -170,6 +194,7 @@ RETURNS TABLE (
     output_title TEXT,
     output_source TEXT,
     output_metadata JSONB,
+    output_content_hash TEXT,
     output_created_at TIMESTAMP WITH TIME ZONE,
     output_updated_at TIMESTAMP WITH TIME ZONE
 )
@@ -180,7 +205,8 @@ BEGIN
     SET 
         title = input_title,
         source = input_source,
-        metadata = input_metadata
+        metadata = input_metadata,
+        content_hash = COALESCE(NULLIF(input_content_hash, ''), content_hash)
     WHERE rid = input_rid
     RETURNING 
         id,
@@ -188,6 +214,7 @@ BEGIN
         title, 
         source, 
         metadata, 
+        COALESCE(content_hash, ''), 
         created_at, 
         updated_at;
 END;
@@ -201,3 +228,60 @@ BEGIN
     DELETE FROM documents WHERE rid = input_rid;
 END;
 $$ LANGUAGE plpgsql;
+
+-- Select the latest document with the given source
+CREATE OR REPLACE FUNCTION select_document_by_source(input_source TEXT)
+RETURNS TABLE (
+    output_id BIGINT,
+    output_rid UUID,
+    output_title TEXT,
+    output_source TEXT,
+    output_metadata JSONB,
+    output_content_hash TEXT,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_updated_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id,
+        rid,
+        title, 
+        source, 
+        metadata, 
+        COALESCE(content_hash, ''), 
+        created_at, 
+        updated_at
+    FROM documents
+    WHERE source = input_source
+    ORDER BY created_at DESC, id DESC
+    LIMIT 1;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Lock a source until the end of the transaction, so concurrent upserts
+-- of the same source don't both insert a new document
+CREATE OR REPLACE FUNCTION lock_document_source(input_source TEXT)
+RETURNS VOID
+AS $$
+BEGIN
+    PERFORM pg_advisory_xact_lock(hashtext('documents:' || input_source));
+END;
+$$ LANGUAGE plpgsql;
+
+-- Delete the chunks of a document together with their edges, keeping the document itself.
+-- Mentioned entities lose the mention count and the document RID, entities that are
+-- not mentioned by any chunk anymore are deleted. Returns the number of deleted chunks.
+CREATE OR REPLACE FUNCTION delete_document_content(input_rid UUID)
+RETURNS INT
+AS $$
+BEGIN
+    RETURN delete_chunks(ARRAY(
+        SELECT c.id
+        FROM chunks c
+        INNER JOIN documents d ON d.id = c.document_id
+        WHERE d.rid = input_rid
+    ));
+END;
+$$ LANGUAGE plpgsql;
@@ -1,5 +1,23 @@
 -- Edges SQL Functions
 
+-- Drop the previous traverse_graph, its result columns changed to include entity nodes
+DROP FUNCTION IF EXISTS traverse_graph(UUID[], INT, edge_type[], BOOLEAN, INT);
+
+-- Create the traversal_step type holding the nodes visited by traverse_graph if it doesn't exist
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_type WHERE typname = 'traversal_step') THEN
+        CREATE TYPE traversal_step AS (
+            seed_id UUID,
+            node_id UUID,
+            is_entity BOOLEAN,
+            depth INT,
+            path UUID[],
+            path_is_entity BOOLEAN[]
+        );
+    END IF;
+END $$;
+
 -- Initialize edges table and related objects
 CREATE OR REPLACE FUNCTION init_edges() RETURNS VOID AS $$
 BEGIN
@@ -336,6 +354,129 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Select edges connected to a chunk or entity in either direction
+-- If input_edge_types is NULL or empty, edges of all types are returned.
+CREATE OR REPLACE FUNCTION select_edges_connected_to_node(
+    input_node_id UUID,
+    input_is_entity BOOLEAN,
+    input_edge_types edge_type[] DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_source_chunk_id UUID,
+    output_target_chunk_id UUID,
+    output_source_entity_id UUID,
+    output_target_entity_id UUID,
+    output_edge_type edge_type,
+    output_weight FLOAT,
+    output_bidirectional BOOLEAN,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id,
+        source_chunk_id,
+        target_chunk_id,
+        source_entity_id,
+        target_entity_id,
+        edge_type,
+        weight,
+        bidirectional,
+        metadata,
+        created_at
+    FROM edges
+    WHERE (
+            (NOT input_is_entity AND (source_chunk_id = input_node_id OR target_chunk_id = input_node_id))
+            OR (input_is_entity AND (source_entity_id = input_node_id OR target_entity_id = input_node_id))
+        )
+        AND (input_edge_types IS NULL OR cardinality(input_edge_types) = 0 OR edge_type = ANY(input_edge_types))
+    ORDER BY weight DESC, created_at;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select the edges between two entities, e.g. the co-occurrence edges of the relation extractor
+-- If input_edge_types is NULL or empty, edges of all types are returned.
+CREATE OR REPLACE FUNCTION select_entity_edges(
+    input_edge_types edge_type[] DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_source_chunk_id UUID,
+    output_target_chunk_id UUID,
+    output_source_entity_id UUID,
+    output_target_entity_id UUID,
+    output_edge_type edge_type,
+    output_weight FLOAT,
+    output_bidirectional BOOLEAN,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id,
+        source_chunk_id,
+        target_chunk_id,
+        source_entity_id,
+        target_entity_id,
+        edge_type,
+        weight,
+        bidirectional,
+        metadata,
+        created_at
+    FROM edges
+    WHERE source_entity_id IS NOT NULL
+        AND target_entity_id IS NOT NULL
+        AND (input_edge_types IS NULL OR cardinality(input_edge_types) = 0 OR edge_type = ANY(input_edge_types))
+    ORDER BY created_at, id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select the edges between a set of chunks and entities
+-- Only edges whose source and target are both in input_node_ids are returned.
+-- If input_edge_types is NULL or empty, edges of all types are returned.
+CREATE OR REPLACE FUNCTION select_edges_between_nodes(
+    input_node_ids UUID[],
+    input_edge_types edge_type[] DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_source_chunk_id UUID,
+    output_target_chunk_id UUID,
+    output_source_entity_id UUID,
+    output_target_entity_id UUID,
+    output_edge_type edge_type,
+    output_weight FLOAT,
+    output_bidirectional BOOLEAN,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id,
+        source_chunk_id,
+        target_chunk_id,
+        source_entity_id,
+        target_entity_id,
+        edge_type,
+        weight,
+        bidirectional,
+        metadata,
+        created_at
+    FROM edges
+    WHERE COALESCE(source_chunk_id, source_entity_id) = ANY(input_node_ids)
+        AND COALESCE(target_chunk_id, target_entity_id) = ANY(input_node_ids)
+        AND (input_edge_types IS NULL OR cardinality(input_edge_types) = 0 OR edge_type = ANY(input_edge_types))
+    ORDER BY weight DESC, created_at;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Delete edge
 CREATE OR REPLACE FUNCTION delete_edge(input_id UUID)
 RETURNS VOID
@@ -365,7 +506,7 @@ END;
 $$ LANGUAGE plpgsql;
 
 -- BFS traversal from a chunk
--- Returns chunks reachable within max_depth hops
+-- Returns chunks reachable within max_depth hops, paths may pass through entities (see traverse_graph)
 CREATE OR REPLACE FUNCTION traverse_bfs_from_chunk(
     input_start_chunk_id UUID,
     input_max_depth INT,
@@ -379,44 +520,323 @@ RETURNS TABLE (
 AS $$
 BEGIN
     RETURN QUERY
-    WITH RECURSIVE traversal AS (
-        -- Base case: start chunk
+    SELECT
+        g.output_node_id,
+        g.output_depth,
+        g.output_path
+    FROM traverse_graph(
+        ARRAY[input_start_chunk_id],
+        input_max_depth,
+        CASE WHEN input_edge_type IS NULL THEN NULL ELSE ARRAY[input_edge_type] END,
+        TRUE,
+        0
+    ) g
+    WHERE NOT g.output_is_entity
+    ORDER BY g.output_depth, g.output_node_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Graph traversal from several seed chunks or entities in one round trip
+-- Follows outgoing edges, bidirectional edges backwards if input_follow_bidirectional is set
+-- and edges between a chunk and an entity in both directions, so the traversal can move
+-- from a chunk to the entities it mentions and on to the other chunks mentioning them.
+-- If input_edge_types is NULL or empty, all edge types are followed.
+-- If input_max_fanout is greater than 0, only the neighbours with the highest edge weight are followed per node and hop.
+-- The graph is expanded one depth at a time and every node is visited only once per seed, so the work grows
+-- with the number of reachable nodes and edges instead of the number of paths through entity hubs.
+-- Returns every node reachable from a seed once per seed with its shortest depth and path, joined with the
+-- chunk or entity data. Chunk columns are empty for entities and entity columns are empty for chunks.
+CREATE OR REPLACE FUNCTION traverse_graph(
+    input_seed_ids UUID[],
+    input_max_depth INT,
+    input_edge_types edge_type[] DEFAULT NULL,
+    input_follow_bidirectional BOOLEAN DEFAULT TRUE,
+    input_max_fanout INT DEFAULT 20
+)
+RETURNS TABLE (
+    output_seed_id UUID,
+    output_node_id UUID,
+    output_is_entity BOOLEAN,
+    output_depth INT,
+    output_path UUID[],
+    output_path_is_entity BOOLEAN[],
+    output_document_id BIGINT,
+    output_document_rid UUID,
+    output_content TEXT,
+    output_chunk_path LTREE,
+    output_embedding VECTOR,
+    output_start_pos INT,
+    output_end_pos INT,
+    output_chunk_index INT,
+    output_entity_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+DECLARE
+    visited traversal_step[];
+    frontier traversal_step[];
+    current_depth INT := 0;
+BEGIN
+    -- Depth 0: seed chunks and entities
+    frontier := ARRAY(
+        SELECT ROW(s.seed_id, s.seed_id, s.is_entity, 0, ARRAY[s.seed_id], ARRAY[s.is_entity])::traversal_step
+        FROM (
+            SELECT
+                u.seed_id,
+                EXISTS (SELECT 1 FROM entities en WHERE en.id = u.seed_id) AS is_entity
+            FROM (SELECT DISTINCT unnest(input_seed_ids) AS seed_id) u
+        ) s
+    );
+    visited := frontier;
+
+    -- Expand the nodes found at the previous depth by their strongest edges to nodes not visited yet
+    WHILE current_depth < input_max_depth AND cardinality(frontier) > 0 LOOP
+        frontier := ARRAY(
+            SELECT DISTINCT ON (c.seed_id, c.neighbor_id)
+                ROW(
+                    c.seed_id,
+                    c.neighbor_id,
+                    c.neighbor_is_entity,
+                    current_depth + 1,
+                    c.path || c.neighbor_id,
+                    c.path_is_entity || c.neighbor_is_entity
+                )::traversal_step
+            FROM (
+                SELECT
+                    t.seed_id,
+                    t.path,
+                    t.path_is_entity,
+                    n.neighbor_id,
+                    n.neighbor_is_entity,
+                    row_number() OVER (
+                        PARTITION BY t.seed_id, t.node_id
+                        ORDER BY n.weight DESC, n.neighbor_id
+                    ) AS neighbor_rank
+                FROM unnest(frontier) t
+                CROSS JOIN LATERAL (
+                    SELECT DISTINCT ON (neighbors.neighbor_id)
+                        neighbors.neighbor_id,
+                        neighbors.neighbor_is_entity,
+                        neighbors.weight
+                    FROM (
+                        -- Outgoing edges
+                        SELECT
+                            COALESCE(e.target_chunk_id, e.target_entity_id) AS neighbor_id,
+                            e.target_chunk_id IS NULL AS neighbor_is_entity,
+                            e.weight
+                        FROM edges e
+                        WHERE ((NOT t.is_entity AND e.source_chunk_id = t.node_id)
+                                OR (t.is_entity AND e.source_entity_id = t.node_id))
+                            AND (input_edge_types IS NULL OR cardinality(input_edge_types) = 0 OR e.edge_type = ANY(input_edge_types))
+
+                        UNION ALL
+
+                        -- Incoming bidirectional edges and edges between a chunk and an entity
+                        SELECT
+                            COALESCE(e.source_chunk_id, e.source_entity_id) AS neighbor_id,
+                            e.source_chunk_id IS NULL AS neighbor_is_entity,
+                            e.weight
+                        FROM edges e
+                        WHERE ((NOT t.is_entity AND e.target_chunk_id = t.node_id)
+                                OR (t.is_entity AND e.target_entity_id = t.node_id))
+                            AND ((input_follow_bidirectional AND e.bidirectional)
+                                OR ((e.source_chunk_id IS NULL) <> (e.target_chunk_id IS NULL)))
+                            AND (input_edge_types IS NULL OR cardinality(input_edge_types) = 0 OR e.edge_type = ANY(input_edge_types))
+                    ) neighbors
+                    ORDER BY neighbors.neighbor_id, neighbors.weight DESC
+                ) n
+                WHERE NOT EXISTS (
+                    SELECT 1
+                    FROM unnest(visited) v
+                    WHERE v.seed_id = t.seed_id
+                        AND v.node_id = n.neighbor_id
+                )
+            ) c
+            WHERE COALESCE(input_max_fanout, 0) <= 0 OR c.neighbor_rank <= input_max_fanout
+            -- A node reached from several nodes of the previous depth keeps the smallest path
+            ORDER BY c.seed_id, c.neighbor_id, c.path
+        );
+
+        visited := visited || frontier;
+        current_depth := current_depth + 1;
+    END LOOP;
+
+    RETURN QUERY
+    SELECT
+        s.seed_id,
+        s.node_id,
+        s.is_entity,
+        s.depth,
+        s.path,
+        s.path_is_entity,
+        COALESCE(c.document_id, 0),
+        d.rid,
+        COALESCE(c.content, ''),
+        COALESCE(c.path, ''::ltree),
+        c.embedding,
+        c.start_pos,
+        c.end_pos,
+        c.chunk_index,
+        COALESCE(en.name, ''),
+        COALESCE(en.entity_type, ''),
+        COALESCE(c.metadata, en.metadata),
+        COALESCE(c.created_at, en.created_at)
+    FROM unnest(visited) s
+    LEFT JOIN chunks c ON NOT s.is_entity AND c.id = s.node_id
+    LEFT JOIN documents d ON c.document_id = d.id
+    LEFT JOIN entities en ON s.is_entity AND en.id = s.node_id
+    WHERE c.id IS NOT NULL OR en.id IS NOT NULL
+    ORDER BY array_position(input_seed_ids, s.seed_id), s.depth, s.path;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Link chunks to their k nearest neighbours by embedding similarity
+-- Creates one bidirectional semantic edge per chunk pair with the similarity as weight,
+-- marked with "generated" in the metadata. Pairs that already have a semantic edge are skipped.
+-- If input_chunk_ids is NULL, all chunks are linked.
+-- Returns the number of created edges.
+CREATE OR REPLACE FUNCTION link_semantic_neighbors(
+    input_chunk_ids UUID[],
+    input_k INT,
+    input_threshold FLOAT
+)
+RETURNS INT
+AS $$
+DECLARE
+    inserted INT;
+BEGIN
+    WITH candidates AS (
         SELECT 
-            input_start_chunk_id as chunk_id,
-            0 as depth,
-            ARRAY[input_start_chunk_id] as path
-        
-        UNION
-        
-        -- Recursive case: follow edges
-        SELECT DISTINCT
-            CASE 
-                WHEN e.source_chunk_id = t.chunk_id THEN e.target_chunk_id
-                WHEN e.target_chunk_id = t.chunk_id AND e.bidirectional THEN e.source_chunk_id
-            END as chunk_id,
-            t.depth + 1 as depth,
-            t.path || CASE 
-                WHEN e.source_chunk_id = t.chunk_id THEN e.target_chunk_id
-                WHEN e.target_chunk_id = t.chunk_id AND e.bidirectional THEN e.source_chunk_id
-            END as path
-        FROM traversal t
-        JOIN edges e ON (
-            (e.source_chunk_id = t.chunk_id)
-            OR (e.target_chunk_id = t.chunk_id AND e.bidirectional)
-        )
-        WHERE t.depth < input_max_depth
-            AND (input_edge_type IS NULL OR e.edge_type = input_edge_type)
-            AND CASE 
-                WHEN e.source_chunk_id = t.chunk_id THEN e.target_chunk_id
-                WHEN e.target_chunk_id = t.chunk_id AND e.bidirectional THEN e.source_chunk_id
-            END IS NOT NULL
-            AND NOT (CASE 
-                WHEN e.source_chunk_id = t.chunk_id THEN e.target_chunk_id
-                WHEN e.target_chunk_id = t.chunk_id AND e.bidirectional THEN e.source_chunk_id
-            END = ANY(t.path))  -- Prevent cycles
+            c.id AS source_id,
+            n.id AS target_id,
+            n.similarity
+        FROM chunks c
+        CROSS JOIN LATERAL (
+            -- Uses the HNSW index of the chunk embeddings
+            SELECT 
+                o.id,
+                1 - (o.embedding <=> c.embedding) AS similarity
+            FROM chunks o
+            WHERE o.id <> c.id
+                AND o.embedding IS NOT NULL
+            ORDER BY o.embedding <=> c.embedding
+            LIMIT input_k
+        ) n
+        WHERE c.embedding IS NOT NULL
+            AND (input_chunk_ids IS NULL OR c.id = ANY(input_chunk_ids))
+            AND n.similarity >= input_threshold
+    ),
+    pairs AS (
+        -- A pair found from both sides gets one edge
+        SELECT DISTINCT ON (LEAST(source_id, target_id), GREATEST(source_id, target_id))
+            LEAST(source_id, target_id) AS first_id,
+            GREATEST(source_id, target_id) AS second_id,
+            similarity
+        FROM candidates
+        ORDER BY LEAST(source_id, target_id), GREATEST(source_id, target_id), similarity DESC
     )
-    SELECT chunk_id, depth, path
-    FROM traversal
-    ORDER BY depth, chunk_id;
+    INSERT INTO edges (source_chunk_id, target_chunk_id, edge_type, weight, bidirectional, metadata)
+    SELECT 
+        p.first_id,
+        p.second_id,
+        'semantic',
+        p.similarity,
+        TRUE,
+        jsonb_build_object('similarity', p.similarity, 'generated', TRUE)
+    FROM pairs p
+    WHERE NOT EXISTS (
+        SELECT 1 FROM edges e
+        WHERE e.edge_type = 'semantic'
+            AND (
+                (e.source_chunk_id = p.first_id AND e.target_chunk_id = p.second_id)
+                OR (e.source_chunk_id = p.second_id AND e.target_chunk_id = p.first_id)
+            )
+    );
+
+    GET DIAGNOSTICS inserted = ROW_COUNT;
+    RETURN inserted;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Prune generated semantic edges
+-- Removes edges with a weight below input_threshold and, if input_max_per_chunk > 0,
+-- keeps only the strongest input_max_per_chunk edges of every chunk.
+-- Manually created semantic edges are never touched.
+-- Returns the number of deleted edges.
+CREATE OR REPLACE FUNCTION prune_semantic_edges(
+    input_threshold FLOAT,
+    input_max_per_chunk INT DEFAULT 0
+)
+RETURNS INT
+AS $$
+DECLARE
+    deleted INT;
+    deleted_ranked INT := 0;
+BEGIN
+    DELETE FROM edges
+    WHERE edge_type = 'semantic'
+        AND (metadata->>'generated')::BOOLEAN IS TRUE
+        AND weight < input_threshold;
+    GET DIAGNOSTICS deleted = ROW_COUNT;
+
+    IF input_max_per_chunk > 0 THEN
+        WITH ranked AS (
+            SELECT 
+                id,
+                ROW_NUMBER() OVER (PARTITION BY chunk_id ORDER BY weight DESC, id) AS edge_rank
+            FROM (
+                SELECT id, source_chunk_id AS chunk_id, weight FROM edges
+                WHERE edge_type = 'semantic' AND (metadata->>'generated')::BOOLEAN IS TRUE
+                UNION ALL
+                SELECT id, target_chunk_id AS chunk_id, weight FROM edges
+                WHERE edge_type = 'semantic' AND (metadata->>'generated')::BOOLEAN IS TRUE
+            ) chunk_edges
+        )
+        DELETE FROM edges
+        WHERE id IN (SELECT id FROM ranked WHERE edge_rank > input_max_per_chunk);
+        GET DIAGNOSTICS deleted_ranked = ROW_COUNT;
+    END IF;
+
+    RETURN deleted + deleted_ranked;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Delete all generated semantic edges
+-- Returns the number of deleted edges.
+CREATE OR REPLACE FUNCTION delete_semantic_edges()
+RETURNS INT
+AS $$
+DECLARE
+    deleted INT;
+BEGIN
+    DELETE FROM edges
+    WHERE edge_type = 'semantic'
+        AND (metadata->>'generated')::BOOLEAN IS TRUE;
+    GET DIAGNOSTICS deleted = ROW_COUNT;
+    RETURN deleted;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Delete the edges of the given type starting at a chunk of the document
+-- Returns the number of deleted edges.
+CREATE OR REPLACE FUNCTION delete_document_edges(
+    input_document_rid UUID,
+    input_edge_type edge_type
+)
+RETURNS INT
+AS $$
+DECLARE
+    deleted INT;
+BEGIN
+    DELETE FROM edges e
+    USING chunks c, documents d
+    WHERE e.source_chunk_id = c.id
+        AND c.document_id = d.id
+        AND d.rid = input_document_rid
+        AND e.edge_type = input_edge_type;
+    GET DIAGNOSTICS deleted = ROW_COUNT;
+    RETURN deleted;
 END;
 $$ LANGUAGE plpgsql;
@@ -1,8 +1,20 @@
 -- Entities SQL Functions
 
+-- Drop the previous signatures, otherwise calls with fewer arguments would be ambiguous
+DROP FUNCTION IF EXISTS init_entities();
+DROP FUNCTION IF EXISTS insert_entity(TEXT, TEXT, JSONB);
+DROP FUNCTION IF EXISTS insert_entities_batch(TEXT[], TEXT[], JSONB[]);
+-- Drop the previous insert_entities_batch, its result columns changed to include the input position
+DROP FUNCTION IF EXISTS insert_entities_batch(TEXT[], TEXT[], JSONB[], TEXT[]);
+-- Drop the previous select_entities_by_type, its result columns changed to include the embedding
+DROP FUNCTION IF EXISTS select_entities_by_type(TEXT, INT);
+
 -- Initialize entities table and related objects
-CREATE OR REPLACE FUNCTION init_entities() RETURNS VOID AS $$
+CREATE OR REPLACE FUNCTION init_entities(embedding_dim INT DEFAULT 384) RETURNS VOID AS $$
 BEGIN
+    -- Create required extensions
+    CREATE EXTENSION IF NOT EXISTS vector;
+
     -- Create entities table
     CREATE TABLE IF NOT EXISTS entities (
         id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
@@ -14,17 +26,176 @@ BEGIN
         UNIQUE(name, entity_type)
     );
     
+    -- Add embedding column (also for tables created before it existed)
+    EXECUTE format('ALTER TABLE entities ADD COLUMN IF NOT EXISTS embedding VECTOR(%s)', embedding_dim);
+    
     -- Create indexes
     CREATE INDEX IF NOT EXISTS idx_entities_name ON entities(name);
     CREATE INDEX IF NOT EXISTS idx_entities_type ON entities(entity_type);
+    
+    -- Create HNSW index for vector similarity if it doesn't exist
+    IF NOT EXISTS (SELECT 1 FROM pg_indexes WHERE indexname = 'idx_entities_embedding') THEN
+        CREATE INDEX idx_entities_embedding ON entities USING hnsw (embedding vector_cosine_ops)
+        WITH (m = 16, ef_construction = 64);
+    END IF;
+
+    -- Create entity aliases table, aliases are removed together with their entity
+    CREATE TABLE IF NOT EXISTS entity_aliases (
+        id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+        entity_id UUID NOT NULL REFERENCES entities(id) ON DELETE CASCADE,
+        alias TEXT NOT NULL,
+        normalized_alias TEXT NOT NULL,
+        created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+        UNIQUE(entity_id, normalized_alias)
+    );
+
+    CREATE INDEX IF NOT EXISTS idx_entity_aliases_normalized ON entity_aliases(normalized_alias);
+
+    -- Index for resolving incoming names by their normalized form
+    CREATE INDEX IF NOT EXISTS idx_entities_normalized_name ON entities(entity_type, normalize_entity_name(name));
 END;
 $$ LANGUAGE plpgsql;
 
--- Insert a new entity
+-- Normalize an entity name for alias matching.
+-- Dots and apostrophes are removed ("I.B.M." becomes "ibm"), all other runs of
+-- non-alphanumeric characters become a single space and the result is lowercased.
+CREATE OR REPLACE FUNCTION normalize_entity_name(input_name TEXT)
+RETURNS TEXT
+AS $$
+BEGIN
+    RETURN btrim(regexp_replace(
+        regexp_replace(lower(input_name), '[.'']', '', 'g'),
+        '[^[:alnum:]]+', ' ', 'g'
+    ));
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Merge the metadata of a new mention into the stored entity metadata.
+-- Other keys are overwritten by the incoming metadata, but mention_count
+-- (defaulting to 1 per mention) is summed and the documents arrays are unioned.
+-- With NULL existing metadata only mention_count is set on the incoming metadata.
+CREATE OR REPLACE FUNCTION merge_entity_metadata(
+    existing_metadata JSONB,
+    incoming_metadata JSONB
+)
+RETURNS JSONB
+AS $$
+DECLARE
+    merged JSONB;
+    mention_count BIGINT;
+BEGIN
+    incoming_metadata := COALESCE(incoming_metadata, '{}'::JSONB);
+    mention_count := COALESCE((incoming_metadata->>'mention_count')::NUMERIC::BIGINT, 1);
+
+    IF existing_metadata IS NULL THEN
+        RETURN jsonb_set(incoming_metadata, '{mention_count}', to_jsonb(mention_count));
+    END IF;
+
+    merged := existing_metadata || incoming_metadata;
+    mention_count := mention_count + COALESCE((existing_metadata->>'mention_count')::NUMERIC::BIGINT, 1);
+    merged := jsonb_set(merged, '{mention_count}', to_jsonb(mention_count));
+
+    IF jsonb_typeof(existing_metadata->'documents') = 'array'
+        AND jsonb_typeof(incoming_metadata->'documents') = 'array' THEN
+        merged := jsonb_set(merged, '{documents}', (
+            SELECT COALESCE(jsonb_agg(d.document ORDER BY d.position), '[]'::JSONB)
+            FROM (
+                SELECT DISTINCT ON (a.document) a.document, a.position
+                FROM (
+                    SELECT e.value AS document, e.ordinality AS position
+                    FROM jsonb_array_elements(existing_metadata->'documents') WITH ORDINALITY AS e
+                    UNION ALL
+                    SELECT i.value, jsonb_array_length(existing_metadata->'documents') + i.ordinality
+                    FROM jsonb_array_elements(incoming_metadata->'documents') WITH ORDINALITY AS i
+                ) a
+                ORDER BY a.document, a.position
+            ) d
+        ));
+    END IF;
+
+    RETURN merged;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+-- Merge the metadata of several mentions in order, like repeated merge_entity_metadata calls
+CREATE OR REPLACE AGGREGATE merge_entity_metadata_agg(JSONB) (
+    SFUNC = merge_entity_metadata,
+    STYPE = JSONB
+);
+
+-- Resolve an incoming entity name to a stored entity of the same type.
+-- An entity with exactly the name wins, then an entity with the name as alias
+-- (e.g. the name of an entity merged into it), then an entity with the same normalized name.
+-- Returns NULL if the name doesn't resolve to a stored entity.
+CREATE OR REPLACE FUNCTION resolve_entity(
+    input_name TEXT,
+    input_entity_type TEXT
+)
+RETURNS UUID
+AS $$
+BEGIN
+    RETURN (
+        SELECT output_id
+        FROM resolve_entities(ARRAY[input_name], ARRAY[input_entity_type])
+    );
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Resolve multiple incoming entity names at once like resolve_entity.
+-- Input arrays must have the same length, output_position is the 1-based position of the input
+-- and output_id is NULL for names that don't resolve to a stored entity.
+CREATE OR REPLACE FUNCTION resolve_entities(
+    input_names TEXT[],
+    input_entity_types TEXT[]
+)
+RETURNS TABLE (
+    output_position INT,
+    output_id UUID
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT i.position::INT, r.id
+    FROM unnest(input_names, input_entity_types) WITH ORDINALITY AS i(name, entity_type, position)
+    CROSS JOIN LATERAL normalize_entity_name(i.name) AS n(normalized)
+    LEFT JOIN LATERAL (
+        SELECT c.id
+        FROM (
+            SELECT e.id, 0 AS priority, e.created_at
+            FROM entities e
+            WHERE e.name = i.name AND e.entity_type = i.entity_type
+
+            UNION ALL
+
+            SELECT e.id, 1 AS priority, e.created_at
+            FROM entity_aliases a
+            JOIN entities e ON e.id = a.entity_id
+            WHERE a.normalized_alias = n.normalized AND e.entity_type = i.entity_type
+
+            UNION ALL
+
+            SELECT e.id, 2 AS priority, e.created_at
+            FROM entities e
+            WHERE normalize_entity_name(e.name) = n.normalized AND e.entity_type = i.entity_type
+        ) c
+        WHERE n.normalized <> '' OR c.priority = 0
+        ORDER BY c.priority, c.created_at, c.id
+        LIMIT 1
+    ) r ON TRUE
+    ORDER BY i.position;
+END;
+$$ LANGUAGE plpgsql STABLE;
+
+-- Insert a new entity (or merge the metadata if it exists)
+-- The name is resolved with resolve_entity first, so names and aliases of merged
+-- entities are merged into the stored entity instead of creating it again.
+-- A NULL embedding keeps the stored embedding
 CREATE OR REPLACE FUNCTION insert_entity(
     input_name TEXT,
     input_entity_type TEXT,
-    input_metadata JSONB
+    input_metadata JSONB,
+    input_embedding VECTOR DEFAULT NULL
 )
 RETURNS TABLE (
     output_id UUID,
@@ -34,12 +205,31 @@ RETURNS TABLE (
     output_created_at TIMESTAMP WITH TIME ZONE
 )
 AS $$
+DECLARE
+    resolved_id UUID := resolve_entity(input_name, input_entity_type);
 BEGIN
+    IF resolved_id IS NOT NULL THEN
+        RETURN QUERY
+        UPDATE entities
+        SET metadata = merge_entity_metadata(entities.metadata, input_metadata),
+            embedding = COALESCE(input_embedding, entities.embedding)
+        WHERE id = resolved_id
+        RETURNING 
+            id, 
+            name, 
+            entity_type, 
+            metadata, 
+            created_at;
+        RETURN;
+    END IF;
+
+    -- A concurrent insert of the same name is merged by the unique constraint
     RETURN QUERY
-    INSERT INTO entities (name, entity_type, metadata)
-    VALUES (input_name, input_entity_type, input_metadata)
+    INSERT INTO entities (name, entity_type, metadata, embedding)
+    VALUES (input_name, input_entity_type, merge_entity_metadata(NULL, input_metadata), input_embedding)
     ON CONFLICT (name, entity_type) DO UPDATE
-        SET metadata = EXCLUDED.metadata
+        SET metadata = merge_entity_metadata(entities.metadata, EXCLUDED.metadata),
+            embedding = COALESCE(EXCLUDED.embedding, entities.embedding)
     RETURNING 
         id, 
         name, 
@@ -49,6 +239,108 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Insert multiple entities at once (or merge the metadata if they exist)
+-- All names are resolved like in insert_entity with one query. Entries resolving to the same stored entity,
+-- and new names with the same normalized form, are merged in input order like repeated single inserts
+-- (a new entity gets the name of its first entry). The stored entities are locked in ID order and then
+-- updated with one statement, the new entities are inserted with another one.
+-- Input arrays must have the same length, output_position is the 1-based position of the input
+-- and every position returns the entity row after the whole batch.
+-- Embeddings are passed in their text form ('[0.1,0.2,...]'), a NULL embedding keeps the stored embedding
+CREATE OR REPLACE FUNCTION insert_entities_batch(
+    input_names TEXT[],
+    input_entity_types TEXT[],
+    input_metadata JSONB[],
+    input_embeddings TEXT[] DEFAULT NULL
+)
+RETURNS TABLE (
+    output_position INT,
+    output_id UUID,
+    output_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+DECLARE
+    resolved_ids UUID[];
+BEGIN
+    SELECT array_agg(r.output_id ORDER BY r.output_position) INTO resolved_ids
+    FROM resolve_entities(input_names, input_entity_types) r;
+
+    -- Lock in a fixed order, so concurrent batches sharing entities don't deadlock
+    PERFORM 1 FROM entities WHERE id = ANY(resolved_ids) ORDER BY id FOR UPDATE;
+
+    RETURN QUERY
+    WITH entries AS (
+        SELECT
+            i.position::INT AS position,
+            i.name,
+            i.entity_type,
+            i.metadata,
+            i.embedding::VECTOR AS embedding,
+            resolved_ids[i.position::INT] AS resolved_id,
+            -- New names are merged by their normalized form, names without one only by exact name
+            CASE WHEN resolved_ids[i.position::INT] IS NULL
+                THEN COALESCE(NULLIF(normalize_entity_name(i.name), ''), i.name)
+            END AS new_key
+        FROM unnest(input_names, input_entity_types, input_metadata, input_embeddings)
+            WITH ORDINALITY AS i(name, entity_type, metadata, embedding, position)
+    ),
+    merged AS (
+        SELECT
+            entries.resolved_id,
+            entries.entity_type,
+            (array_agg(entries.name ORDER BY entries.position))[1] AS name,
+            merge_entity_metadata_agg(entries.metadata ORDER BY entries.position) AS metadata,
+            (array_agg(entries.embedding ORDER BY entries.position DESC) FILTER (WHERE entries.embedding IS NOT NULL))[1] AS embedding,
+            array_agg(entries.position ORDER BY entries.position) AS positions
+        FROM entries
+        GROUP BY entries.resolved_id, entries.entity_type, entries.new_key
+    ),
+    updated AS (
+        UPDATE entities
+        SET metadata = merge_entity_metadata(entities.metadata, m.metadata),
+            embedding = COALESCE(m.embedding, entities.embedding)
+        FROM merged m
+        WHERE entities.id = m.resolved_id
+        RETURNING 
+            entities.id, 
+            entities.name, 
+            entities.entity_type, 
+            entities.metadata, 
+            entities.created_at,
+            m.positions
+    ),
+    -- A concurrent insert of the same name is merged by the unique constraint
+    inserted AS (
+        INSERT INTO entities (name, entity_type, metadata, embedding)
+        SELECT m.name, m.entity_type, m.metadata, m.embedding
+        FROM merged m
+        WHERE m.resolved_id IS NULL
+        ORDER BY m.positions[1]
+        ON CONFLICT (name, entity_type) DO UPDATE
+            SET metadata = merge_entity_metadata(entities.metadata, EXCLUDED.metadata),
+                embedding = COALESCE(EXCLUDED.embedding, entities.embedding)
+        RETURNING 
+            id, 
+            name, 
+            entity_type, 
+            metadata, 
+            created_at
+    )
+    SELECT p.position, u.id, u.name, u.entity_type, u.metadata, u.created_at
+    FROM updated u
+    CROSS JOIN LATERAL unnest(u.positions) AS p(position)
+    UNION ALL
+    SELECT p.position, ins.id, ins.name, ins.entity_type, ins.metadata, ins.created_at
+    FROM inserted ins
+    JOIN merged m ON m.resolved_id IS NULL AND m.name = ins.name AND m.entity_type = ins.entity_type
+    CROSS JOIN LATERAL unnest(m.positions) AS p(position)
+    ORDER BY 1;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Select entity by ID
 CREATE OR REPLACE FUNCTION select_entity(input_id UUID)
 RETURNS TABLE (
@@ -72,6 +364,29 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Select entities by their IDs, unknown IDs are skipped
+CREATE OR REPLACE FUNCTION select_entities_by_ids(input_ids UUID[])
+RETURNS TABLE (
+    output_id UUID,
+    output_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id, 
+        name, 
+        entity_type, 
+        metadata, 
+        created_at
+    FROM entities
+    WHERE id = ANY(input_ids);
+END;
+$$ LANGUAGE plpgsql;
+
 -- Select entity by name and type
 CREATE OR REPLACE FUNCTION select_entity_by_name(
     input_name TEXT,
@@ -128,7 +443,7 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
--- Select all entities by type
+-- Select all entities by type with their embedding
 CREATE OR REPLACE FUNCTION select_entities_by_type(
     input_entity_type TEXT,
     input_limit INT DEFAULT 100
@@ -138,7 +453,8 @@ RETURNS TABLE (
     output_name TEXT,
     output_entity_type TEXT,
     output_metadata JSONB,
-    output_created_at TIMESTAMP WITH TIME ZONE
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_embedding VECTOR
 )
 AS $$
 BEGIN
@@ -148,7 +464,8 @@ BEGIN
         name, 
         entity_type, 
         metadata, 
-        created_at
+        created_at,
+        embedding
     FROM entities
     WHERE entity_type = input_entity_type
     ORDER BY name
@@ -192,6 +509,237 @@ BEGIN
 END;
 $$ LANGUAGE plpgsql;
 
+-- Insert an alias for an entity (or return the existing one)
+CREATE OR REPLACE FUNCTION insert_entity_alias(
+    input_entity_id UUID,
+    input_alias TEXT
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_entity_id UUID,
+    output_alias TEXT,
+    output_normalized_alias TEXT,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    INSERT INTO entity_aliases (entity_id, alias, normalized_alias)
+    VALUES (input_entity_id, input_alias, normalize_entity_name(input_alias))
+    ON CONFLICT (entity_id, normalized_alias) DO UPDATE
+        SET alias = entity_aliases.alias
+    RETURNING 
+        id, 
+        entity_id, 
+        alias, 
+        normalized_alias, 
+        created_at;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select all aliases of an entity
+CREATE OR REPLACE FUNCTION select_entity_aliases(input_entity_id UUID)
+RETURNS TABLE (
+    output_id UUID,
+    output_entity_id UUID,
+    output_alias TEXT,
+    output_normalized_alias TEXT,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        id, 
+        entity_id, 
+        alias, 
+        normalized_alias, 
+        created_at
+    FROM entity_aliases
+    WHERE entity_id = input_entity_id
+    ORDER BY created_at, alias;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select entities whose normalized name or one of whose aliases matches the normalized alias
+CREATE OR REPLACE FUNCTION select_entities_by_alias(
+    input_alias TEXT,
+    input_entity_type TEXT DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+DECLARE
+    normalized TEXT := normalize_entity_name(input_alias);
+BEGIN
+    RETURN QUERY
+    SELECT 
+        e.id, 
+        e.name, 
+        e.entity_type, 
+        e.metadata, 
+        e.created_at
+    FROM entities e
+    WHERE (input_entity_type IS NULL OR e.entity_type = input_entity_type)
+        AND (
+            normalize_entity_name(e.name) = normalized
+            OR EXISTS (
+                SELECT 1 FROM entity_aliases a
+                WHERE a.entity_id = e.id AND a.normalized_alias = normalized
+            )
+        )
+    ORDER BY e.name;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Merge an entity into another one.
+-- All edges of the dropped entity are rewired to the kept entity, edges between
+-- the two entities are removed and edges that became duplicates (e.g. a chunk mentioning
+-- both entities) are collapsed to the one with the highest weight. The name and aliases
+-- of the dropped entity become aliases of the kept entity and the metadata is merged with
+-- merge_entity_metadata (the kept entity wins on conflicting keys, also for the embedding).
+-- The mention_count is recounted from the remaining mention edges. The dropped entity is deleted.
+CREATE OR REPLACE FUNCTION merge_entities(
+    input_keep_id UUID,
+    input_drop_id UUID
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE
+)
+AS $$
+DECLARE
+    dropped entities%ROWTYPE;
+BEGIN
+    IF input_keep_id = input_drop_id THEN
+        RAISE EXCEPTION 'cannot merge entity % into itself', input_keep_id;
+    END IF;
+
+    PERFORM 1 FROM entities WHERE id = input_keep_id;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'entity % not found', input_keep_id;
+    END IF;
+
+    SELECT * INTO dropped FROM entities WHERE id = input_drop_id;
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'entity % not found', input_drop_id;
+    END IF;
+
+    -- Rewire edges
+    UPDATE edges SET source_entity_id = input_keep_id WHERE source_entity_id = input_drop_id;
+    UPDATE edges SET target_entity_id = input_keep_id WHERE target_entity_id = input_drop_id;
+
+    -- Edges between the merged entities would become self loops
+    DELETE FROM edges
+    WHERE source_entity_id = input_keep_id
+        AND target_entity_id = input_keep_id;
+
+    -- Collapse duplicate edges of the kept entity, keeping the highest weight
+    DELETE FROM edges e
+    USING (
+        SELECT
+            id,
+            ROW_NUMBER() OVER (
+                PARTITION BY source_chunk_id, target_chunk_id, source_entity_id, target_entity_id, edge_type
+                ORDER BY weight DESC NULLS LAST, created_at, id
+            ) AS position
+        FROM edges
+        WHERE source_entity_id = input_keep_id
+            OR target_entity_id = input_keep_id
+    ) d
+    WHERE e.id = d.id
+        AND d.position > 1;
+
+    -- Keep the dropped name and aliases
+    INSERT INTO entity_aliases (entity_id, alias, normalized_alias)
+    SELECT input_keep_id, a.alias, a.normalized_alias
+    FROM entity_aliases a
+    WHERE a.entity_id = input_drop_id
+    UNION ALL
+    SELECT input_keep_id, dropped.name, normalize_entity_name(dropped.name)
+    ON CONFLICT (entity_id, normalized_alias) DO NOTHING;
+
+    DELETE FROM entities WHERE id = input_drop_id;
+
+    RETURN QUERY
+    UPDATE entities
+    SET metadata = merge_entity_metadata(dropped.metadata, entities.metadata)
+            || jsonb_build_object('mention_count', (
+                SELECT COUNT(*)
+                FROM edges
+                WHERE target_entity_id = input_keep_id
+                    AND source_chunk_id IS NOT NULL
+                    AND edge_type = 'entity_mention'
+            )),
+        embedding = COALESCE(entities.embedding, dropped.embedding)
+    WHERE id = input_keep_id
+    RETURNING 
+        id, 
+        name, 
+        entity_type, 
+        metadata, 
+        created_at;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Update entity embedding
+CREATE OR REPLACE FUNCTION update_entity_embedding(
+    input_id UUID,
+    input_embedding VECTOR
+)
+RETURNS VOID
+AS $$
+BEGIN
+    UPDATE entities
+    SET embedding = input_embedding
+    WHERE id = input_id;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Select entities by vector similarity
+CREATE OR REPLACE FUNCTION select_entities_by_similarity(
+    input_embedding VECTOR,
+    input_limit INT,
+    input_threshold FLOAT DEFAULT 0.0,
+    input_entity_type TEXT DEFAULT NULL
+)
+RETURNS TABLE (
+    output_id UUID,
+    output_name TEXT,
+    output_entity_type TEXT,
+    output_metadata JSONB,
+    output_created_at TIMESTAMP WITH TIME ZONE,
+    output_embedding VECTOR,
+    output_similarity FLOAT
+)
+AS $$
+BEGIN
+    RETURN QUERY
+    SELECT 
+        e.id, 
+        e.name, 
+        e.entity_type, 
+        e.metadata, 
+        e.created_at,
+        e.embedding,
+        1 - (e.embedding <=> input_embedding) AS similarity
+    FROM entities e
+    WHERE e.embedding IS NOT NULL
+        AND (1 - (e.embedding <=> input_embedding)) >= input_threshold
+        AND (input_entity_type IS NULL OR e.entity_type = input_entity_type)
+    ORDER BY e.embedding <=> input_embedding
+    LIMIT input_limit;
+END;
+$$ LANGUAGE plpgsql;
+
 -- Get chunks that mention an entity
 CREATE OR REPLACE FUNCTION select_chunks_mentioning_entity(
     input_entity_id UUID
@@ -22,9 +22,14 @@ var edgesSQL string
 //go:embed entities.sql
 var entitiesSQL string
 
+//go:embed communities.sql
+var communitiesSQL string
+
 // Function lists for verification
 var ChunksFunctions = []string{
 	"init_chunks",
+	"select_chunks_text_search_config",
+	"change_chunks_text_search_config",
 	"insert_chunk",
 	"select_chunk",
 	"select_chunks_by_document",
@@ -32,8 +37,11 @@ var ChunksFunctions = []string{
 	"select_chunks_by_path_ancestor",
 	"select_chunks_by_similarity",
 	"select_chunks_by_similarity_with_context",
+	"select_chunks_by_keyword",
 	"delete_chunk",
 	"update_chunk_embedding",
+	"update_chunk_position",
+	"delete_chunks",
 }
 
 var DocumentsFunctions = []string{
@@ -44,6 +52,9 @@ var DocumentsFunctions = []string{
 	"search_documents",
 	"update_document",
 	"delete_document",
+	"select_document_by_source",
+	"lock_document_source",
+	"delete_document_content",
 }
 
 var EdgesFunctions = []string{
@@ -55,21 +66,53 @@ var EdgesFunctions = []string{
 	"select_edges_connected_to_chunk",
 	"select_edges_from_entity",
 	"select_edges_to_entity",
+	"select_edges_connected_to_node",
+	"select_edges_between_nodes",
+	"select_entity_edges",
 	"delete_edge",
 	"update_edge_weight",
 	"traverse_bfs_from_chunk",
+	"traverse_graph",
+	"link_semantic_neighbors",
+	"prune_semantic_edges",
+	"delete_semantic_edges",
+	"delete_document_edges",
 }
 
 var EntitiesFunctions = []string{
 	"init_entities",
+	"normalize_entity_name",
+	"merge_entity_metadata",
+	"merge_entity_metadata_agg",
+	"resolve_entity",
+	"resolve_entities",
 	"insert_entity",
+	"insert_entities_batch",
 	"select_entity",
+	"select_entities_by_ids",
 	"select_entity_by_name",
 	"search_entities",
 	"select_entities_by_type",
 	"delete_entity",
 	"update_entity_metadata",
 	"select_chunks_mentioning_entity",
+	"update_entity_embedding",
+	"select_entities_by_similarity",
+	"insert_entity_alias",
+	"select_entity_aliases",
+	"select_entities_by_alias",
+	"merge_entities",
+}
+
+var CommunitiesFunctions = []string{
+	"init_communities",
+	"select_community_entity_ids",
+	"insert_community",
+	"select_community",
+	"select_all_communities",
+	"select_communities_by_similarity",
+	"update_community_summary",
+	"delete_all_communities",
 }
 
 // Init intializes db extensions
@@ -199,6 +242,35 @@ func LoadEntitiesSql(db *sql.DB, force bool) error {
 	return nil
 }
 
+// LoadCommunitiesSql loads community-related SQL functions
+func LoadCommunitiesSql(db *sql.DB, force bool) error {
+	if !force {
+		exist, err := checkFunctions(db, CommunitiesFunctions)
+		if err != nil {
+			return fmt.Errorf("error checking existing communities functions: %w", err)
+		}
+		if exist {
+			return nil
+		}
+	}
+
+	_, err := db.Exec(communitiesSQL)
+	if err != nil {
+		return fmt.Errorf("error executing communities SQL: %w", err)
+	}
+
+	exist, err := checkFunctions(db, CommunitiesFunctions)
+	if err != nil {
+		return fmt.Errorf("error checking existing functions: %w", err)
+	}
+	if !exist {
+		return fmt.Errorf("not all required SQL functions were created")
+	}
+
+	log.Println("SQL communities functions loaded successfully")
+	return nil
+}
+
 // LoadAllSql loads all SQL functions
 func LoadAllSql(db *sql.DB, force bool) error {
 	if err := LoadChunksSql(db, force); err != nil {
@@ -217,6 +289,10 @@ func LoadAllSql(db *sql.DB, force bool) error {
 		return err
 	}
 
+	if err := LoadCommunitiesSql(db, force); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -175,6 +175,38 @@ func TestLoadEntitiesSql(t *testing.T) {
 	})
 }
 
+func TestLoadCommunitiesSql(t *testing.T) {
+	db := initDB(t)
+	defer db.Close()
+
+	// Initialize extensions first
+	err := Init(db.Instance)
+	require.NoError(t, err)
+
+	t.Run("Load communities SQL functions", func(t *testing.T) {
+		err := LoadCommunitiesSql(db.Instance, false)
+		assert.NoError(t, err)
+
+		// Verify all functions exist
+		for _, funcName := range CommunitiesFunctions {
+			var exists bool
+			err = db.Instance.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_proc WHERE proname = $1);", funcName).Scan(&exists)
+			require.NoError(t, err)
+			assert.True(t, exists, "Function %s should exist", funcName)
+		}
+	})
+
+	t.Run("Load communities SQL is idempotent without force", func(t *testing.T) {
+		err := LoadCommunitiesSql(db.Instance, false)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Load communities SQL with force reloads", func(t *testing.T) {
+		err := LoadCommunitiesSql(db.Instance, true)
+		assert.NoError(t, err)
+	})
+}
+
 func TestLoadAllSql(t *testing.T) {
 	db := initDB(t)
 	defer db.Close()
@@ -218,6 +250,14 @@ func TestLoadAllSql(t *testing.T) {
 			require.NoError(t, err)
 			assert.True(t, exists, "Entities function %s should exist", funcName)
 		}
+
+		// Verify all communities functions exist
+		for _, funcName := range CommunitiesFunctions {
+			var exists bool
+			err = db.Instance.QueryRow("SELECT EXISTS(SELECT 1 FROM pg_proc WHERE proname = $1);", funcName).Scan(&exists)
+			require.NoError(t, err)
+			assert.True(t, exists, "Communities function %s should exist", funcName)
+		}
 	})
 
 	t.Run("Load all SQL is idempotent without force", func(t *testing.T) {
@@ -292,6 +332,11 @@ func TestFunctionLists(t *testing.T) {
 		assert.NotEmpty(t, EntitiesFunctions, "EntitiesFunctions should not be empty")
 		assert.Greater(t, len(EntitiesFunctions), 5, "Should have multiple entity functions")
 	})
+
+	t.Run("CommunitiesFunctions list is not empty", func(t *testing.T) {
+		assert.NotEmpty(t, CommunitiesFunctions, "CommunitiesFunctions should not be empty")
+		assert.Greater(t, len(CommunitiesFunctions), 5, "Should have multiple community functions")
+	})
 }
 
 func TestEmbeddedSQL(t *testing.T) {
@@ -319,4 +364,9 @@ func TestEmbeddedSQL(t *testing.T) {
 		assert.NotEmpty(t, entitiesSQL, "entitiesSQL should be embedded")
 		assert.Contains(t, entitiesSQL, "CREATE", "Should contain CREATE statements")
 	})
+
+	t.Run("Communities SQL is embedded", func(t *testing.T) {
+		assert.NotEmpty(t, communitiesSQL, "communitiesSQL should be embedded")
+		assert.Contains(t, communitiesSQL, "CREATE", "Should contain CREATE statements")
+	})
 }